@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/fsnotify.v1"
 
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator"
 
 	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
 	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 	canarycontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/canary"
+	dnscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/dns"
 	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
 	statuscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/status"
 
@@ -24,6 +28,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	unidlingapi "github.com/openshift/api/unidling/v1alpha1"
 )
@@ -50,6 +55,28 @@ type StartOptions struct {
 	CanaryImage string
 	// ReleaseVersion is the cluster version which the operator will converge to.
 	ReleaseVersion string
+	// FieldManager is the field manager name the operator uses for
+	// server-side apply.
+	FieldManager string
+	// DNSZoneWriteRate is the maximum sustained number of writes per
+	// second to each DNS zone.
+	DNSZoneWriteRate float64
+	// DNSZoneWriteBurst is the maximum number of writes to each DNS zone
+	// in a burst.
+	DNSZoneWriteBurst int
+	// CertificateGenerationConcurrency is the maximum number of keys and
+	// certificates that the operator generates at a time.
+	CertificateGenerationConcurrency int
+	// MaxReconcileBackoff is the maximum delay before the operator
+	// reconciles an ingresscontroller again after repeated failures.
+	MaxReconcileBackoff time.Duration
+	// IngressControllerStatusSummary indicates whether the operator
+	// publishes a summary of all ingresscontrollers' status on the ingress
+	// clusteroperator.
+	IngressControllerStatusSummary bool
+	// IngressControllerFinalizer is the finalizer that the operator adds
+	// to ingresscontrollers.
+	IngressControllerFinalizer string
 }
 
 func NewStartCommand() *cobra.Command {
@@ -72,6 +99,13 @@ func NewStartCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&options.CanaryImage, "canary-image", "c", "", "image of the canary container that the operator will manage (optional)")
 	cmd.Flags().StringVarP(&options.ReleaseVersion, "release-version", "", statuscontroller.UnknownVersionValue, "the release version the operator should converge to (required)")
 	cmd.Flags().StringVarP(&options.MetricsListenAddr, "metrics-listen-addr", "", "127.0.0.1:60000", "metrics endpoint listen address (required)")
+	cmd.Flags().StringVarP(&options.FieldManager, "field-manager", "", "ingress-operator", "field manager name the operator uses for server-side apply (optional)")
+	cmd.Flags().Float64VarP(&options.DNSZoneWriteRate, "dns-zone-write-rate", "", 0, "maximum sustained number of writes per second to each DNS zone; 0 disables rate limiting (optional)")
+	cmd.Flags().IntVarP(&options.DNSZoneWriteBurst, "dns-zone-write-burst", "", 5, "maximum number of writes to each DNS zone in a burst when --dns-zone-write-rate is set (optional)")
+	cmd.Flags().IntVarP(&options.CertificateGenerationConcurrency, "certificate-generation-concurrency", "", 1, "maximum number of keys and certificates the operator generates at a time (optional)")
+	cmd.Flags().DurationVarP(&options.MaxReconcileBackoff, "max-reconcile-backoff", "", 5*time.Minute, "maximum delay before reconciling an ingresscontroller again after repeated failures; 0 disables the backoff (optional)")
+	cmd.Flags().BoolVarP(&options.IngressControllerStatusSummary, "ingress-controller-status-summary", "", false, "publish a summary of all ingresscontrollers' status in the ingress clusteroperator's status.extension (optional)")
+	cmd.Flags().StringVarP(&options.IngressControllerFinalizer, "ingresscontroller-finalizer", "", manifests.IngressControllerFinalizer, "finalizer the operator adds to ingresscontrollers to clean up their resources on deletion (optional)")
 	cmd.Flags().StringVarP(&options.ShutdownFile, "shutdown-file", "s", defaultTrustedCABundle, "if provided, shut down the operator when this file changes")
 
 	if err := cmd.MarkFlagRequired("namespace"); err != nil {
@@ -85,6 +119,9 @@ func NewStartCommand() *cobra.Command {
 }
 
 func start(opts *StartOptions) error {
+	if msgs := validation.IsQualifiedName(opts.IngressControllerFinalizer); len(msgs) != 0 {
+		return fmt.Errorf("invalid --ingresscontroller-finalizer %q: %s", opts.IngressControllerFinalizer, strings.Join(msgs, ", "))
+	}
 
 	kubeConfig, err := config.GetConfig()
 	if err != nil {
@@ -117,10 +154,17 @@ func start(opts *StartOptions) error {
 	defer cancel()
 
 	operatorConfig := operatorconfig.Config{
-		OperatorReleaseVersion: opts.ReleaseVersion,
-		Namespace:              opts.OperatorNamespace,
-		IngressControllerImage: opts.IngressControllerImage,
-		CanaryImage:            opts.CanaryImage,
+		OperatorReleaseVersion:           opts.ReleaseVersion,
+		Namespace:                        opts.OperatorNamespace,
+		IngressControllerImage:           opts.IngressControllerImage,
+		CanaryImage:                      opts.CanaryImage,
+		FieldManager:                     opts.FieldManager,
+		DNSZoneWriteRate:                 opts.DNSZoneWriteRate,
+		DNSZoneWriteBurst:                opts.DNSZoneWriteBurst,
+		CertificateGenerationConcurrency: opts.CertificateGenerationConcurrency,
+		MaxReconcileBackoff:              opts.MaxReconcileBackoff,
+		IngressControllerStatusSummary:   opts.IngressControllerStatusSummary,
+		IngressControllerFinalizer:       opts.IngressControllerFinalizer,
 	}
 
 	// Start operator metrics.
@@ -133,6 +177,10 @@ func start(opts *StartOptions) error {
 	if err := ingresscontroller.RegisterMetrics(); err != nil {
 		log.Error(err, "unable to register metrics for ingress_controller")
 	}
+	log.Info("registering Prometheus metrics for dns_controller")
+	if err := dnscontroller.RegisterMetrics(); err != nil {
+		log.Error(err, "unable to register metrics for dns_controller")
+	}
 
 	// Set up and start the file watcher.
 	watcher, err := fsnotify.NewWatcher()
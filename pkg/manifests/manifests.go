@@ -61,6 +61,40 @@ const (
 	// operator has ensured it's safe for deletion to proceeed.
 	DNSRecordFinalizer = "operator.openshift.io/ingress-dns"
 
+	// DNSRecordRetainUntilAnnotation is set on a dnsrecord that the
+	// operator retains after its ingresscontroller is deleted.  The value
+	// is the RFC 3339 time after which the operator deletes the dnsrecord
+	// unless an ingresscontroller with the same name has adopted it.
+	DNSRecordRetainUntilAnnotation = "ingress.operator.openshift.io/dnsrecord-retain-until"
+
+	// RouteAdmittedByAnnotation is set on routes that are admitted by
+	// ingresscontrollers that have the annotateAdmittedRoutes unsupported
+	// config override.  The value is a comma-separated, sorted list of the
+	// names of those ingresscontrollers.
+	RouteAdmittedByAnnotation = "ingress.operator.openshift.io/admitted-by"
+
+	// EffectiveTuningOptionsAnnotation is set on ingresscontrollers.  The
+	// value is the JSON encoding of the ingresscontroller's tuning options
+	// with the router's defaults filled in for options that
+	// spec.tuningOptions does not set.  The ingresscontroller API has no
+	// status field for them, so they are published in this annotation.
+	EffectiveTuningOptionsAnnotation = "ingress.operator.openshift.io/effective-tuning-options"
+
+	// RouterPodDrainStateAnnotation is set on router pods that are
+	// shutting down gracefully and draining their connections.  The value
+	// is RouterPodDrainStateDraining.  The annotation is absent on router
+	// pods that are not draining.
+	RouterPodDrainStateAnnotation = "ingress.operator.openshift.io/drain-state"
+
+	// RouterPodDrainStateDraining is the value of
+	// RouterPodDrainStateAnnotation for a draining router pod.
+	RouterPodDrainStateDraining = "Draining"
+
+	// RouterNamespaceEgressIPsAnnotation is set on the router namespace's
+	// netnamespace when the operator manages its egress IPs.  The operator
+	// only clears egress IPs that it has set.
+	RouterNamespaceEgressIPsAnnotation = "ingress.operator.openshift.io/router-namespace-egress-ips"
+
 	// DefaultIngressControllerName is the name of the default IngressController
 	// instance.
 	DefaultIngressControllerName = "default"
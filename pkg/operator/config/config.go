@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // Config is configuration for the operator and should include things like
 // operated images, scheduling configuration, etc.
 type Config struct {
@@ -15,5 +17,36 @@ type Config struct {
 	// CanaryImage is the ingress operator image, which runs a canary command.
 	CanaryImage string
 
+	// FieldManager is the field manager name the operator uses for
+	// server-side apply.
+	FieldManager string
+
+	// DNSZoneWriteRate is the maximum sustained number of writes per
+	// second to each DNS zone.  Zero means unlimited.
+	DNSZoneWriteRate float64
+
+	// DNSZoneWriteBurst is the maximum number of writes to each DNS zone
+	// in a burst.
+	DNSZoneWriteBurst int
+
+	// CertificateGenerationConcurrency is the maximum number of keys and
+	// certificates that the certificate controller generates at a time.
+	CertificateGenerationConcurrency int
+
+	// MaxReconcileBackoff is the maximum delay before the operator
+	// reconciles an ingresscontroller again after repeated failures.  Zero
+	// disables the backoff.
+	MaxReconcileBackoff time.Duration
+
+	// IngressControllerStatusSummary indicates whether the operator
+	// publishes a summary of all ingresscontrollers' status in the ingress
+	// clusteroperator's status.extension field.
+	IngressControllerStatusSummary bool
+
+	// IngressControllerFinalizer is the finalizer that the operator adds
+	// to ingresscontrollers to block their deletion until it has cleaned
+	// up their resources.
+	IngressControllerFinalizer string
+
 	Stop chan struct{}
 }
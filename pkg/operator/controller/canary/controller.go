@@ -2,6 +2,7 @@ package canary
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"strconv"
 	"sync"
@@ -52,6 +53,16 @@ const (
 	// a value of "true" (disabled otherwise).
 	CanaryRouteRotationAnnotation = "ingress.operator.openshift.io/rotate-canary-route"
 
+	// CanaryPinDefaultCertificateAnnotation is an annotation on the default
+	// ingress controller that specifies whether the canary check should
+	// verify the canary route's certificate by pinning it to the default
+	// ingress controller's default certificate instead of skipping
+	// verification.  This allows the canary check to verify the self-signed
+	// certificate that the operator generates during bootstrap.  Pinning is
+	// enabled when the annotation has a value of "true" (disabled
+	// otherwise).
+	CanaryPinDefaultCertificateAnnotation = "ingress.operator.openshift.io/pin-canary-default-certificate"
+
 	// CanaryHealthcheckCommand is a parameter to pass to the ingress-operator to call
 	// into the handler for the canary daemonset health check
 	CanaryHealthcheckCommand = "serve-healthcheck"
@@ -186,6 +197,11 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		r.mu.Unlock()
 	}
 
+	pinDefaultCertificate, _ := strconv.ParseBool(ic.Annotations[CanaryPinDefaultCertificateAnnotation])
+	r.mu.Lock()
+	r.pinDefaultCertificate = pinDefaultCertificate
+	r.mu.Unlock()
+
 	// Start probing the canary route.
 	routeProbeRunner.Do(func() {
 		r.startCanaryRoutePolling(r.config.Stop)
@@ -212,6 +228,7 @@ type reconciler struct {
 	// go-routine safe.
 	mu                        sync.Mutex
 	enableCanaryRouteRotation bool
+	pinDefaultCertificate     bool
 }
 
 func (r *reconciler) isCanaryRouteRotationEnabled() bool {
@@ -220,6 +237,32 @@ func (r *reconciler) isCanaryRouteRotationEnabled() bool {
 	return r.enableCanaryRouteRotation
 }
 
+func (r *reconciler) isDefaultCertificatePinningEnabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pinDefaultCertificate
+}
+
+// defaultCertificates returns the certificates in the default ingress
+// controller's effective default certificate secret.
+func (r *reconciler) defaultCertificates() ([]*x509.Certificate, error) {
+	ic := &operatorv1.IngressController{}
+	name := types.NamespacedName{Namespace: r.config.Namespace, Name: manifests.DefaultIngressControllerName}
+	if err := r.client.Get(context.TODO(), name, ic); err != nil {
+		return nil, fmt.Errorf("failed to get ingress controller %s: %w", name, err)
+	}
+	secret := &corev1.Secret{}
+	secretName := operatorcontroller.RouterEffectiveDefaultCertificateSecretName(ic, operatorcontroller.DefaultOperandNamespace)
+	if err := r.client.Get(context.TODO(), secretName, secret); err != nil {
+		return nil, fmt.Errorf("failed to get default certificate secret %s: %w", secretName, err)
+	}
+	certs, err := parseCertificates(secret.Data["tls.crt"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default certificate secret %s: %w", secretName, err)
+	}
+	return certs, nil
+}
+
 func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 	// Keep track of how many canary checks have passed
 	// so the route endpoint can be periodically cycled
@@ -276,7 +319,16 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 			return
 		}
 
-		err = probeRouteEndpoint(route)
+		var pinnedCertificates []*x509.Certificate
+		if r.isDefaultCertificatePinningEnabled() {
+			pinnedCertificates, err = r.defaultCertificates()
+			if err != nil {
+				log.Error(err, "failed to get default certificate to pin for canary check")
+				return
+			}
+		}
+
+		err = probeRouteEndpoint(route, pinnedCertificates)
 		if err != nil {
 			log.Error(err, "error performing canary route check")
 			SetCanaryRouteReachableMetric(route.Spec.Host, false)
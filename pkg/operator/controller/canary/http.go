@@ -1,7 +1,10 @@
 package canary
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -20,9 +23,57 @@ const (
 	echoServerPortAckHeader = "x-request-port"
 )
 
+// canaryTLSConfig returns the TLS configuration for canary checks.  If
+// pinnedCertificates is empty, the server's certificate is not verified.
+// Otherwise, the server's leaf certificate must be one of pinnedCertificates;
+// verification against the system trust store is skipped because the pinned
+// certificate may be self signed.
+func canaryTLSConfig(pinnedCertificates []*x509.Certificate) *tls.Config {
+	config := &tls.Config{InsecureSkipVerify: true}
+	if len(pinnedCertificates) != 0 {
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("server presented no certificate")
+			}
+			for _, cert := range pinnedCertificates {
+				if bytes.Equal(rawCerts[0], cert.Raw) {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate does not match the pinned default certificate")
+		}
+	}
+	return config
+}
+
+// parseCertificates parses the PEM-encoded certificates in the given data.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for len(data) > 0 {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return certs, nil
+}
+
 // probeRouteEndpoint probes the given route's host
-// and returns an error when applicable.
-func probeRouteEndpoint(route *routev1.Route) error {
+// and returns an error when applicable.  If pinnedCertificates
+// is not empty, the route's certificate must be one of them.
+func probeRouteEndpoint(route *routev1.Route, pinnedCertificates []*x509.Certificate) error {
 	if len(route.Spec.Host) == 0 {
 		return fmt.Errorf("route.Spec.Host is empty, cannot test route")
 	}
@@ -52,15 +103,14 @@ func probeRouteEndpoint(route *routev1.Route) error {
 		Timeout: timeout,
 		// The canary route uses edge termination and the
 		// default router certificate may be self signed, so
-		// skip certificate verification here. See
+		// skip certificate verification here unless the
+		// certificate is pinned. See
 		// https://bugzilla.redhat.com/show_bug.cgi?id=1932401.
-		// TODO: Add the router's certificate to the HTTP client
-		// so we can enable TLS verification.
 		Transport: &http.Transport{
 			// Use the cluster-wide proxy if it is available in the
 			// pod's environment.
 			Proxy:             http.ProxyFromEnvironment,
-			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig:   canaryTLSConfig(pinnedCertificates),
 			DisableKeepAlives: true, // BZ#2037447
 		},
 	}
@@ -0,0 +1,121 @@
+package canary
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newSelfSignedCertificate returns a new self-signed certificate.
+func newSelfSignedCertificate(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// TestCanaryTLSConfig verifies that canaryTLSConfig accepts a server whose
+// certificate is pinned and rejects a server whose certificate is not.
+func TestCanaryTLSConfig(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(CanaryHealthcheckResponse))
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	otherCertificate := newSelfSignedCertificate(t, "other.example.com")
+
+	testCases := []struct {
+		description string
+		pinned      []*x509.Certificate
+		expectError bool
+	}{
+		{
+			description: "no pinned certificate skips verification",
+			pinned:      nil,
+			expectError: false,
+		},
+		{
+			description: "pinned certificate matches",
+			pinned:      []*x509.Certificate{server.Certificate()},
+			expectError: false,
+		},
+		{
+			description: "pinned certificate matches one of several",
+			pinned:      []*x509.Certificate{otherCertificate, server.Certificate()},
+			expectError: false,
+		},
+		{
+			description: "unexpected certificate",
+			pinned:      []*x509.Certificate{otherCertificate},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			client := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig:   canaryTLSConfig(tc.pinned),
+					DisableKeepAlives: true,
+				},
+			}
+			response, err := client.Get(server.URL)
+			if err == nil {
+				response.Body.Close()
+			}
+			switch {
+			case tc.expectError && err == nil:
+				t.Fatal("expected an error, got nil")
+			case !tc.expectError && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestParseCertificates verifies that parseCertificates parses every
+// certificate in a PEM bundle and ignores other PEM blocks.
+func TestParseCertificates(t *testing.T) {
+	first := newSelfSignedCertificate(t, "first.example.com")
+	second := newSelfSignedCertificate(t, "second.example.com")
+
+	var bundle []byte
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: first.Raw})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("not a certificate")})...)
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: second.Raw})...)
+
+	certs, err := parseCertificates(bundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(certs) != 2 || !certs[0].Equal(first) || !certs[1].Equal(second) {
+		t.Errorf("expected the 2 certificates in the bundle, got %d", len(certs))
+	}
+
+	if _, err := parseCertificates([]byte("garbage")); err == nil {
+		t.Error("expected an error for data without certificates, got nil")
+	}
+}
@@ -27,7 +27,9 @@ func (r *reconciler) ensureRouterCASecret() (*corev1.Secret, error) {
 	if current != nil {
 		return current, nil
 	}
+	release := r.acquireGenerationSlot()
 	desired, err := desiredRouterCASecret(r.operatorNamespace)
+	release()
 	if err != nil {
 		return nil, err
 	}
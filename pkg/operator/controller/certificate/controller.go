@@ -17,6 +17,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"k8s.io/client-go/tools/record"
@@ -30,6 +31,7 @@ import (
 	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -40,26 +42,65 @@ const (
 
 var log = logf.Logger.WithName(controllerName)
 
-func New(mgr manager.Manager, operatorNamespace string) (runtimecontroller.Controller, error) {
+// New creates the certificate controller.  The controller reconciles, and so
+// generates keys and certificates for, at most generationConcurrency
+// ingresscontrollers at a time.
+func New(mgr manager.Manager, operatorNamespace string, generationConcurrency int) (runtimecontroller.Controller, error) {
+	generationSlots := newGenerationSlots(generationConcurrency)
 	reconciler := &reconciler{
 		client:            mgr.GetClient(),
 		recorder:          mgr.GetEventRecorderFor(controllerName),
 		operatorNamespace: operatorNamespace,
+		generationSlots:   generationSlots,
 	}
-	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{
+		Reconciler:              reconciler,
+		MaxConcurrentReconciles: cap(generationSlots),
+	})
 	if err != nil {
 		return nil, err
 	}
 	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}); err != nil {
 		return nil, err
 	}
+	// Watch secrets in the operator namespace so that default certificates
+	// are reissued when their issuer secrets change.
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(reconciler.issuerSecretToIngressControllers), predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetNamespace() == operatorNamespace
+	})); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+// issuerSecretToIngressControllers returns reconcile requests for the
+// ingresscontrollers whose default certificate issuer secret is the given
+// secret.
+func (r *reconciler) issuerSecretToIngressControllers(o client.Object) []reconcile.Request {
+	controllers := &operatorv1.IngressControllerList{}
+	if err := r.client.List(context.Background(), controllers, client.InNamespace(o.GetNamespace())); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for secret", "namespace", o.GetNamespace(), "name", o.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range controllers.Items {
+		ic := &controllers.Items[i]
+		if ingresscontroller.DefaultCertificateIssuerSecret(ic) != o.GetName() {
+			continue
+		}
+		log.Info("queueing ingresscontroller", "name", ic.Name, "secret", o.GetName())
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}})
+	}
+	return requests
+}
+
 type reconciler struct {
 	client            client.Client
 	recorder          record.EventRecorder
 	operatorNamespace string
+	// generationSlots limits the number of concurrent key and
+	// certificate generations.
+	generationSlots chan struct{}
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -105,6 +146,11 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 			if _, err := r.ensureDefaultCertificateForIngress(ca, deployment.Namespace, deploymentRef, ingress); err != nil {
 				errs = append(errs, fmt.Errorf("failed to ensure default cert for %s: %v", ingress.Name, err))
 			}
+			if nextRotation, err := r.ensureWildcardCertificatesForIngress(ca, deployment.Namespace, deploymentRef, ingress, time.Now()); err != nil {
+				errs = append(errs, fmt.Errorf("failed to ensure wildcard certs for %s: %v", ingress.Name, err))
+			} else if nextRotation > 0 {
+				result.RequeueAfter = nextRotation
+			}
 		}
 	}
 
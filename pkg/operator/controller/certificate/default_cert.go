@@ -2,28 +2,38 @@ package certificate
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"time"
 
 	"github.com/openshift/library-go/pkg/crypto"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ensureDefaultCertificateForIngress creates or deletes an operator-generated
 // default certificate for a given IngressController as appropriate.  Returns true
 // if it the secret exists, or false if it does not, as well as any errors.
 func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController) (bool, error) {
-	ca, err := crypto.GetCAFromBytes(caSecret.Data["tls.crt"], caSecret.Data["tls.key"])
+	ca, err := r.defaultCertificateIssuer(caSecret, ci, time.Now())
 	if err != nil {
-		return false, fmt.Errorf("failed to get CA from secret %s/%s: %v", caSecret.Namespace, caSecret.Name, err)
+		return false, err
 	}
+	release := r.acquireGenerationSlot()
 	wantCert, desired, err := desiredRouterDefaultCertificateSecret(ca, namespace, deploymentRef, ci)
+	release()
 	if err != nil {
 		return false, err
 	}
@@ -59,12 +69,103 @@ func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret,
 			return true, nil
 		}
 	case wantCert && haveCert:
-		// TODO Update if CA certificate changed.
+		if defaultCertificateIssuedBy(current, ca) {
+			return true, nil
+		}
+		// The issuer changed, for example because the
+		// ingresscontroller's issuer secret was set or removed, so
+		// reissue the certificate.
+		updated, err := controller.UpdateWithRetryOnConflict(r.client, current, func() client.Object {
+			// Another writer may have reissued the certificate.
+			if defaultCertificateIssuedBy(current, ca) {
+				return nil
+			}
+			updated := current.DeepCopy()
+			updated.Data = desired.Data
+			return updated
+		})
+		if err != nil {
+			return true, fmt.Errorf("failed to update default certificate: %v", err)
+		}
+		if updated {
+			r.recorder.Eventf(ci, "Normal", "UpdatedDefaultCertificate", "Reissued default wildcard certificate %q from its current issuer", current.Name)
+		}
 		return true, nil
 	}
 	return false, nil
 }
 
+// defaultCertificateIssuer returns the CA from which the given
+// ingresscontroller's default certificate is issued, which is the CA in the
+// ingresscontroller's default certificate issuer secret if it specifies one or
+// else the operator's self-signed CA in the given secret.
+func (r *reconciler) defaultCertificateIssuer(caSecret *corev1.Secret, ci *operatorv1.IngressController, now time.Time) (*crypto.CA, error) {
+	name := ingresscontroller.DefaultCertificateIssuerSecret(ci)
+	if len(name) == 0 {
+		ca, err := crypto.GetCAFromBytes(caSecret.Data["tls.crt"], caSecret.Data["tls.key"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CA from secret %s/%s: %v", caSecret.Namespace, caSecret.Name, err)
+		}
+		return ca, nil
+	}
+	key := types.NamespacedName{Namespace: ci.Namespace, Name: name}
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get default certificate issuer secret %s: %v", key, err)
+	}
+	ca, err := issuerCAFromBytes(secret.Data["tls.crt"], secret.Data["tls.key"], now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default certificate issuer secret %s: %v", key, err)
+	}
+	return ca, nil
+}
+
+// issuerCAFromBytes returns the CA with the given PEM-encoded certificate chain
+// and key after verifying that the first certificate is a CA certificate that
+// is valid at the given time and matches the key, and that each certificate in
+// the chain is issued by the next.
+func issuerCAFromBytes(certBytes, keyBytes []byte, now time.Time) (*crypto.CA, error) {
+	if _, err := tls.X509KeyPair(certBytes, keyBytes); err != nil {
+		return nil, fmt.Errorf("failed to load certificate and key: %v", err)
+	}
+	ca, err := crypto.GetCAFromBytes(certBytes, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	certs := ca.Config.Certs
+	issuer := certs[0]
+	if !issuer.IsCA {
+		return nil, fmt.Errorf("certificate %q is not a CA certificate", issuer.Subject.CommonName)
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, fmt.Errorf("certificate %q may not be used to sign certificates", issuer.Subject.CommonName)
+	}
+	if now.Before(issuer.NotBefore) || now.After(issuer.NotAfter) {
+		return nil, fmt.Errorf("certificate %q is valid only from %s to %s", issuer.Subject.CommonName, issuer.NotBefore.Format(time.RFC3339), issuer.NotAfter.Format(time.RFC3339))
+	}
+	for i := 1; i < len(certs); i++ {
+		if err := certs[i-1].CheckSignatureFrom(certs[i]); err != nil {
+			return nil, fmt.Errorf("certificate %q is not issued by the next certificate in the chain, %q: %v", certs[i-1].Subject.CommonName, certs[i].Subject.CommonName, err)
+		}
+	}
+	return ca, nil
+}
+
+// defaultCertificateIssuedBy returns a Boolean indicating whether the
+// certificate in the given default certificate secret was issued by the given
+// CA.
+func defaultCertificateIssuedBy(secret *corev1.Secret, ca *crypto.CA) bool {
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	return cert.CheckSignatureFrom(ca.Config.Certs[0]) == nil
+}
+
 // desiredRouterDefaultCertificateSecret returns the desired default certificate
 // secret.
 func desiredRouterDefaultCertificateSecret(ca *crypto.CA, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController) (bool, *corev1.Secret, error) {
@@ -1,14 +1,24 @@
 package certificate
 
 import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
+	"time"
 
 	"github.com/openshift/library-go/pkg/crypto"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const (
@@ -134,3 +144,145 @@ func TestDesiredRouterDefaultCertificateSecret(t *testing.T) {
 		}
 	}
 }
+
+// newIssuerCA returns a root CA and the PEM-encoded certificate chain and key
+// of an intermediate CA that the root CA issued.
+func newIssuerCA(t *testing.T) (*crypto.CA, []byte, []byte) {
+	t.Helper()
+	rootConfig, err := crypto.MakeSelfSignedCAConfigForDuration("cluster-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make root CA: %v", err)
+	}
+	root := &crypto.CA{Config: rootConfig, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	intermediateConfig, err := crypto.MakeCAConfigForDuration("ingress-intermediate", time.Hour, root)
+	if err != nil {
+		t.Fatalf("failed to make intermediate CA: %v", err)
+	}
+	certBytes, keyBytes, err := intermediateConfig.GetPEMBytes()
+	if err != nil {
+		t.Fatalf("failed to encode intermediate CA: %v", err)
+	}
+	return root, certBytes, keyBytes
+}
+
+// TestIssuerCAFromBytes verifies that issuerCAFromBytes accepts a valid CA
+// with its chain and rejects invalid CAs.
+func TestIssuerCAFromBytes(t *testing.T) {
+	_, intermediateCert, intermediateKey := newIssuerCA(t)
+	_, otherCert, otherKey := newIssuerCA(t)
+	// The intermediate certificate followed by the root of another chain.
+	intermediateBlock, _ := pem.Decode(intermediateCert)
+	_, rest := pem.Decode(otherCert)
+	brokenChain := append(pem.EncodeToMemory(intermediateBlock), rest...)
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		cert, key   []byte
+		now         time.Time
+		expectError bool
+	}{
+		{"intermediate CA with chain", intermediateCert, intermediateKey, now, false},
+		{"non-CA certificate", []byte(cert), []byte(key), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"mismatched key", intermediateCert, otherKey, now, true},
+		{"expired CA", intermediateCert, intermediateKey, now.Add(2 * time.Hour), true},
+		{"broken chain", brokenChain, intermediateKey, now, true},
+		{"empty secret", nil, nil, now, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ca, err := issuerCAFromBytes(tc.cert, tc.key, tc.now)
+			switch {
+			case tc.expectError && err == nil:
+				t.Error("expected an error")
+			case !tc.expectError && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case !tc.expectError && len(ca.Config.Certs) != 2:
+				t.Errorf("expected the CA to have a chain of 2 certificates, got %d", len(ca.Config.Certs))
+			}
+		})
+	}
+}
+
+// TestEnsureDefaultCertificateForIngressWithIssuer verifies that the default
+// certificate is issued from the ingresscontroller's configured issuer CA with
+// a chain that verifies against the issuer's root, and that it is reissued
+// from the operator's CA when the issuer is removed.
+func TestEnsureDefaultCertificateForIngressWithIssuer(t *testing.T) {
+	root, intermediateCert, intermediateKey := newIssuerCA(t)
+	routerCACert, routerCAKey, err := generateRouterCA()
+	if err != nil {
+		t.Fatalf("failed to generate CA: %v", err)
+	}
+	routerCASecret := &corev1.Secret{Data: map[string][]byte{"tls.crt": routerCACert, "tls.key": routerCAKey}}
+	issuerSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-intermediate-ca", Namespace: "openshift-ingress-operator"},
+		Data:       map[string][]byte{"tls.crt": intermediateCert, "tls.key": intermediateKey},
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"defaultCertificateIssuerSecret":"ingress-intermediate-ca"}`)},
+		},
+		Status: operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(issuerSecret).Build()
+	r := &reconciler{client: cl, recorder: record.NewFakeRecorder(10)}
+	getCertificates := func() []*x509.Certificate {
+		t.Helper()
+		secret := &corev1.Secret{}
+		if err := cl.Get(context.Background(), controller.RouterOperatorGeneratedDefaultCertificateSecretName(ic, "openshift-ingress"), secret); err != nil {
+			t.Fatalf("failed to get default certificate: %v", err)
+		}
+		var certs []*x509.Certificate
+		for rest := secret.Data["tls.crt"]; ; {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("failed to parse certificate: %v", err)
+			}
+			certs = append(certs, cert)
+		}
+		return certs
+	}
+
+	if _, err := r.ensureDefaultCertificateForIngress(routerCASecret, "openshift-ingress", deploymentRef, ic); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	certs := getCertificates()
+	if len(certs) != 3 {
+		t.Fatalf("expected the default certificate and a chain of 2 CA certificates, got %d certificates", len(certs))
+	}
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	roots.AddCert(root.Config.Certs[0])
+	intermediates.AddCert(certs[1])
+	if _, err := certs[0].Verify(x509.VerifyOptions{DNSName: "foo.apps.example.com", Roots: roots, Intermediates: intermediates}); err != nil {
+		t.Errorf("expected the default certificate to chain to the configured CA: %v", err)
+	}
+
+	// Removing the issuer reissues the certificate from the operator's CA.
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{}
+	if _, err := r.ensureDefaultCertificateForIngress(routerCASecret, "openshift-ingress", deploymentRef, ic); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	routerCA, err := crypto.GetCAFromBytes(routerCACert, routerCAKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := getCertificates()[0].CheckSignatureFrom(routerCA.Config.Certs[0]); err != nil {
+		t.Errorf("expected the default certificate to be reissued from the operator's CA: %v", err)
+	}
+
+	// An invalid issuer is reported and does not replace the certificate.
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"defaultCertificateIssuerSecret":"missing"}`)}
+	if _, err := r.ensureDefaultCertificateForIngress(routerCASecret, "openshift-ingress", deploymentRef, ic); err == nil {
+		t.Error("expected an error for a missing issuer secret")
+	}
+	if err := getCertificates()[0].CheckSignatureFrom(routerCA.Config.Certs[0]); err != nil {
+		t.Errorf("expected the default certificate not to be replaced: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package certificate
+
+// newGenerationSlots returns a semaphore that allows at most the given number
+// of concurrent certificate generations.  A limit of less than 1 is treated as
+// 1.
+func newGenerationSlots(limit int) chan struct{} {
+	if limit < 1 {
+		limit = 1
+	}
+	return make(chan struct{}, limit)
+}
+
+// acquireGenerationSlot blocks until the reconciler may generate a key or
+// certificate and returns a function that releases the slot.  Generating RSA
+// keys is CPU-intensive, so the reconciler bounds the number of concurrent
+// generations so that reconciling many ingresscontrollers at once does not
+// starve the operator.  A reconciler without a semaphore does not limit
+// generation.
+func (r *reconciler) acquireGenerationSlot() func() {
+	if r.generationSlots == nil {
+		return func() {}
+	}
+	r.generationSlots <- struct{}{}
+	return func() { <-r.generationSlots }
+}
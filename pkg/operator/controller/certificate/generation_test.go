@@ -0,0 +1,106 @@
+package certificate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestAcquireGenerationSlot verifies that no more than the configured number
+// of generations run concurrently.
+func TestAcquireGenerationSlot(t *testing.T) {
+	for _, limit := range []int{0, 1, 3} {
+		r := &reconciler{generationSlots: newGenerationSlots(limit)}
+		expected := limit
+		if expected < 1 {
+			expected = 1
+		}
+
+		var (
+			mu        sync.Mutex
+			active    int
+			maxActive int
+			wg        sync.WaitGroup
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release := r.acquireGenerationSlot()
+				defer release()
+				mu.Lock()
+				active++
+				if active > maxActive {
+					maxActive = active
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				active--
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		if maxActive > expected {
+			t.Errorf("limit %d: expected at most %d concurrent generations, got %d", limit, expected, maxActive)
+		}
+	}
+}
+
+// TestEnsureWildcardCertificatesForIngressWaitsForGenerationSlot verifies that
+// the reconciler does not generate a certificate while all generation slots
+// are in use.
+func TestEnsureWildcardCertificatesForIngressWaitsForGenerationSlot(t *testing.T) {
+	caCert, caKey, err := generateRouterCA()
+	if err != nil {
+		t.Fatalf("failed to generate CA: %v", err)
+	}
+	caSecret := &corev1.Secret{Data: map[string][]byte{"tls.crt": caCert, "tls.key": caKey}}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{
+				Raw: []byte(`{"additionalWildcardDomains":["internal.example.com"]}`),
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	r := &reconciler{
+		client:          fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+		recorder:        record.NewFakeRecorder(10),
+		generationSlots: newGenerationSlots(1),
+	}
+
+	release := r.acquireGenerationSlot()
+	done := make(chan error)
+	go func() {
+		_, err := r.ensureWildcardCertificatesForIngress(caSecret, "openshift-ingress", deploymentRef, ic, time.Now())
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("expected generation to wait for a slot, but it finished (error: %v)", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected generation to finish after the slot was released")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ensureDefaultIngressCertConfigMap will create or update the configmap containing the public half of the default ingress wildcard certificate
@@ -83,18 +85,19 @@ func (r *reconciler) createRouterCAConfigMap(cm *corev1.ConfigMap) (bool, error)
 	return true, nil
 }
 
-// updateRouterCAConfigMaps updates the router CA configmap. Returns true if the
-// configmap was updated, false otherwise.
+// updateRouterCAConfigMaps updates the router CA configmap, retrying if the
+// update conflicts with another writer's. Returns true if the configmap was
+// updated, false otherwise.
 func (r *reconciler) updateRouterCAConfigMap(current, desired *corev1.ConfigMap) (bool, error) {
-	if routerCAConfigMapsEqual(current, desired) {
-		return false, nil
-	}
-	updated := current.DeepCopy()
-	updated.Data = desired.Data
-	if err := r.client.Update(context.TODO(), updated); err != nil {
-		return false, err
-	}
-	return true, nil
+	current = current.DeepCopy()
+	return controller.UpdateWithRetryOnConflict(r.client, current, func() client.Object {
+		if routerCAConfigMapsEqual(current, desired) {
+			return nil
+		}
+		updated := current.DeepCopy()
+		updated.Data = desired.Data
+		return updated
+	})
 }
 
 // deleteRouterCAConfigMap deletes the router CA configmap. Returns true if the
@@ -0,0 +1,168 @@
+package certificate
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// wildcardCertificateRotationWindow is how long before a generated wildcard
+// certificate expires that the operator replaces it.
+const wildcardCertificateRotationWindow = 30 * 24 * time.Hour
+
+// ensureWildcardCertificatesForIngress creates, rotates, or deletes
+// operator-generated wildcard certificates for the given ingresscontroller's
+// additional wildcard domains.  Each certificate is rotated independently of
+// the others.  Returns the time until the next certificate needs to be
+// rotated, or zero if there are no certificates, as well as any errors.
+func (r *reconciler) ensureWildcardCertificatesForIngress(caSecret *corev1.Secret, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController, now time.Time) (time.Duration, error) {
+	ca, err := crypto.GetCAFromBytes(caSecret.Data["tls.crt"], caSecret.Data["tls.key"])
+	if err != nil {
+		return 0, fmt.Errorf("failed to get CA from secret %s/%s: %v", caSecret.Namespace, caSecret.Name, err)
+	}
+
+	var nextRotation time.Duration
+	wanted := sets.NewString()
+	for _, domain := range ingresscontroller.AdditionalWildcardDomains(ci) {
+		name := controller.RouterWildcardCertificateSecretName(ci, namespace, domain)
+		wanted.Insert(name.Name)
+
+		current := &corev1.Secret{}
+		haveCert := true
+		if err := r.client.Get(context.TODO(), name, current); err != nil {
+			if !errors.IsNotFound(err) {
+				return 0, err
+			}
+			haveCert = false
+		}
+		rotate, rotateAt := true, time.Time{}
+		if haveCert {
+			rotate, rotateAt = wildcardCertificateNeedsRotation(current, ca, domain, now)
+		}
+		if rotate {
+			release := r.acquireGenerationSlot()
+			desired, err := desiredWildcardCertificateSecret(ca, namespace, deploymentRef, ci, domain)
+			release()
+			if err != nil {
+				return 0, err
+			}
+			if haveCert {
+				updated, err := controller.UpdateWithRetryOnConflict(r.client, current, func() client.Object {
+					// Another writer may have rotated the certificate.
+					if rotate, _ := wildcardCertificateNeedsRotation(current, ca, domain, now); !rotate {
+						return nil
+					}
+					updated := current.DeepCopy()
+					updated.Labels = desired.Labels
+					updated.Data = desired.Data
+					return updated
+				})
+				if err != nil {
+					return 0, fmt.Errorf("failed to rotate wildcard certificate %s: %v", name, err)
+				}
+				if updated {
+					r.recorder.Eventf(ci, "Normal", "RotatedWildcardCertificate", "Rotated wildcard certificate %q for domain %q", name.Name, domain)
+				}
+			} else {
+				if err := r.client.Create(context.TODO(), desired); err != nil {
+					return 0, fmt.Errorf("failed to create wildcard certificate %s: %v", name, err)
+				}
+				r.recorder.Eventf(ci, "Normal", "CreatedWildcardCertificate", "Created wildcard certificate %q for domain %q", name.Name, domain)
+			}
+			current = desired
+			_, rotateAt = wildcardCertificateNeedsRotation(current, ca, domain, now)
+		}
+		if d := rotateAt.Sub(now); nextRotation == 0 || d < nextRotation {
+			nextRotation = d
+		}
+	}
+
+	// Delete certificates for domains that are no longer configured.
+	secrets := &corev1.SecretList{}
+	if err := r.client.List(context.TODO(), secrets, client.InNamespace(namespace), client.MatchingLabels{controller.WildcardCertificateLabel: ci.Name}); err != nil {
+		return 0, fmt.Errorf("failed to list wildcard certificates: %v", err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if wanted.Has(secret.Name) {
+			continue
+		}
+		if err := r.client.Delete(context.TODO(), secret); err != nil && !errors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete wildcard certificate %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+		r.recorder.Eventf(ci, "Normal", "DeletedWildcardCertificate", "Deleted wildcard certificate %q", secret.Name)
+	}
+
+	return nextRotation, nil
+}
+
+// desiredWildcardCertificateSecret returns a secret with a newly generated
+// wildcard certificate for the given domain.
+func desiredWildcardCertificateSecret(ca *crypto.CA, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController, domain string) (*corev1.Secret, error) {
+	cert, err := ca.MakeServerCert(sets.NewString("*."+domain), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make certificate for domain %q: %v", domain, err)
+	}
+	certBytes, keyBytes, err := cert.GetPEMBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode certificate for domain %q: %v", domain, err)
+	}
+	name := controller.RouterWildcardCertificateSecretName(ci, namespace, domain)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ci.Name,
+				controller.WildcardCertificateLabel:    ci.Name,
+			},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": certBytes,
+			"tls.key": keyBytes,
+		},
+	}
+	secret.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return secret, nil
+}
+
+// wildcardCertificateNeedsRotation returns a Boolean indicating whether the
+// wildcard certificate in the given secret needs to be replaced because it is
+// missing or invalid, is not for the given domain, was not signed by the given
+// CA, or expires within the rotation window, as well as the time at which it
+// will need to be replaced.
+func wildcardCertificateNeedsRotation(secret *corev1.Secret, ca *crypto.CA, domain string, now time.Time) (bool, time.Time) {
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil || len(secret.Data["tls.key"]) == 0 {
+		return true, now
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true, now
+	}
+	if err := cert.CheckSignatureFrom(ca.Config.Certs[0]); err != nil {
+		return true, now
+	}
+	if !sets.NewString(cert.DNSNames...).Equal(sets.NewString("*." + domain)) {
+		return true, now
+	}
+	rotateAt := cert.NotAfter.Add(-wildcardCertificateRotationWindow)
+	return !now.Before(rotateAt), rotateAt
+}
@@ -0,0 +1,149 @@
+package certificate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestEnsureWildcardCertificatesForIngress verifies that the operator
+// generates a wildcard certificate for each additional wildcard domain,
+// rotates each certificate independently, and deletes certificates for domains
+// that are removed.
+func TestEnsureWildcardCertificatesForIngress(t *testing.T) {
+	caCert, caKey, err := generateRouterCA()
+	if err != nil {
+		t.Fatalf("failed to generate CA: %v", err)
+	}
+	caSecret := &corev1.Secret{Data: map[string][]byte{"tls.crt": caCert, "tls.key": caKey}}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{
+				Raw: []byte(`{"additionalWildcardDomains":["internal.example.com","partners.example.com"]}`),
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	namespace := "openshift-ingress"
+	internalName := controller.RouterWildcardCertificateSecretName(ic, namespace, "internal.example.com")
+	partnersName := controller.RouterWildcardCertificateSecretName(ic, namespace, "partners.example.com")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	r := &reconciler{client: cl, recorder: record.NewFakeRecorder(10)}
+	getSecret := func(name string) *corev1.Secret {
+		t.Helper()
+		secret := &corev1.Secret{}
+		if err := cl.Get(context.Background(), controller.RouterWildcardCertificateSecretName(ic, namespace, name), secret); err != nil {
+			t.Fatalf("failed to get wildcard certificate for %s: %v", name, err)
+		}
+		return secret
+	}
+
+	now := time.Now()
+	next, err := r.ensureWildcardCertificatesForIngress(caSecret, namespace, deploymentRef, ic, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next <= 0 || next > 2*365*24*time.Hour {
+		t.Errorf("unexpected time until next rotation: %v", next)
+	}
+	internal, partners := getSecret("internal.example.com"), getSecret("partners.example.com")
+	ca, err := crypto.GetCAFromBytes(caCert, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for domain, secret := range map[string]*corev1.Secret{"internal.example.com": internal, "partners.example.com": partners} {
+		if rotate, _ := wildcardCertificateNeedsRotation(secret, ca, domain, now); rotate {
+			t.Errorf("expected a valid wildcard certificate for %s", domain)
+		}
+		if secret.Labels[controller.WildcardCertificateLabel] != ic.Name {
+			t.Errorf("expected secret %s to have label %s=%s", secret.Name, controller.WildcardCertificateLabel, ic.Name)
+		}
+	}
+
+	// Reconciling again does not replace the certificates.
+	if _, err := r.ensureWildcardCertificatesForIngress(caSecret, namespace, deploymentRef, ic, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(getSecret("internal.example.com").Data["tls.crt"]) != string(internal.Data["tls.crt"]) {
+		t.Error("expected the certificate not to be replaced")
+	}
+
+	// A certificate that is not for its domain is replaced without
+	// affecting the other certificate.
+	corrupted := internal.DeepCopy()
+	corrupted.Data["tls.crt"] = partners.Data["tls.crt"]
+	if err := cl.Update(context.Background(), corrupted); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	if _, err := r.ensureWildcardCertificatesForIngress(caSecret, namespace, deploymentRef, ic, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotate, _ := wildcardCertificateNeedsRotation(getSecret("internal.example.com"), ca, "internal.example.com", now); rotate {
+		t.Error("expected the internal.example.com certificate to be replaced")
+	}
+	if string(getSecret("partners.example.com").Data["tls.crt"]) != string(partners.Data["tls.crt"]) {
+		t.Error("expected the partners.example.com certificate not to be replaced")
+	}
+
+	// Removing a domain deletes its certificate.
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"additionalWildcardDomains":["partners.example.com"]}`)}
+	if _, err := r.ensureWildcardCertificatesForIngress(caSecret, namespace, deploymentRef, ic, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cl.Get(context.Background(), internalName, &corev1.Secret{}); !errors.IsNotFound(err) {
+		t.Errorf("expected secret %s to be deleted, got %v", internalName, err)
+	}
+	if err := cl.Get(context.Background(), partnersName, &corev1.Secret{}); err != nil {
+		t.Errorf("expected secret %s to remain: %v", partnersName, err)
+	}
+}
+
+// TestWildcardCertificateNeedsRotation verifies that a wildcard certificate is
+// rotated once it is within the rotation window of its expiration.
+func TestWildcardCertificateNeedsRotation(t *testing.T) {
+	caCert, caKey, err := generateRouterCA()
+	if err != nil {
+		t.Fatalf("failed to generate CA: %v", err)
+	}
+	ca, err := crypto.GetCAFromBytes(caCert, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	secret, err := desiredWildcardCertificateSecret(ca, "openshift-ingress", metav1.OwnerReference{}, ic, "internal.example.com")
+	if err != nil {
+		t.Fatalf("failed to generate wildcard certificate: %v", err)
+	}
+
+	now := time.Now()
+	rotate, rotateAt := wildcardCertificateNeedsRotation(secret, ca, "internal.example.com", now)
+	if rotate {
+		t.Error("expected a new certificate not to need rotation")
+	}
+	if rotate, _ := wildcardCertificateNeedsRotation(secret, ca, "internal.example.com", rotateAt); !rotate {
+		t.Error("expected the certificate to need rotation within the rotation window")
+	}
+	if rotate, _ := wildcardCertificateNeedsRotation(secret, ca, "other.example.com", now); !rotate {
+		t.Error("expected a certificate for another domain to need rotation")
+	}
+	if rotate, _ := wildcardCertificateNeedsRotation(&corev1.Secret{}, ca, "internal.example.com", now); !rotate {
+		t.Error("expected an empty secret to need rotation")
+	}
+}
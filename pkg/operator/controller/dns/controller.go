@@ -73,12 +73,16 @@ func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, erro
 		client:   mgr.GetClient(),
 		cache:    mgr.GetCache(),
 		recorder: mgr.GetEventRecorderFor(controllerName),
+
+		zoneRateLimiter: newZoneRateLimiter(config.ZoneWriteRate, config.ZoneWriteBurst),
 	}
 	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
 	if err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &iov1.DNSRecord{}}, &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{}); err != nil {
+	// Watch annotation changes so that the controller notices when a
+	// dnsrecord is retained or adopted.
+	if err := c.Watch(&source.Kind{Type: &iov1.DNSRecord{}}, &handler.EnqueueRequestForObject{}, predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})); err != nil {
 		return nil, err
 	}
 	if err := c.Watch(&source.Kind{Type: &configv1.DNS{}}, handler.EnqueueRequestsFromMapFunc(reconciler.ToDNSRecords)); err != nil {
@@ -109,6 +113,14 @@ func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, erro
 type Config struct {
 	Namespace              string
 	OperatorReleaseVersion string
+	// ZoneWriteRate is the maximum sustained number of writes per second
+	// to each DNS zone.  Updates to records that are already published are
+	// deferred when they would exceed the limit.  If it is zero, writes
+	// are not rate-limited.
+	ZoneWriteRate float64
+	// ZoneWriteBurst is the maximum number of writes to each DNS zone that
+	// may be made in a burst when ZoneWriteRate is set.
+	ZoneWriteBurst int
 }
 
 type reconciler struct {
@@ -120,6 +132,7 @@ type reconciler struct {
 	infraConfig      *configv1.Infrastructure
 	cloudCredentials *corev1.Secret
 	recorder         record.EventRecorder
+	zoneRateLimiter  *zoneRateLimiter
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -162,6 +175,12 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{RequeueAfter: 1 * time.Minute}, nil
 	}
 
+	// A dnsrecord that was retained after its ingresscontroller was deleted
+	// is deleted once its grace period expires.
+	if retainUntil, ok := record.Annotations[manifests.DNSRecordRetainUntilAnnotation]; ok {
+		return r.reconcileRetainedRecord(ctx, record, ingressName, retainUntil)
+	}
+
 	// Existing 4.1 records will have a zero TTL. Instead of making all the client implementations guard against
 	// zero TTLs, simply ignore the record until the TTL is updated by the ingresscontroller controller. Report
 	// this through events so we can detect problems with our migration.
@@ -189,11 +208,16 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	if dnsConfig.Spec.PublicZone != nil {
 		zones = append(zones, *dnsConfig.Spec.PublicZone)
 	}
-	statuses, result := r.publishRecordToZones(zones, record)
+	statuses, result, deferred := r.publishRecordToZones(zones, record)
 	if !dnsZoneStatusSlicesEqual(statuses, record.Status.Zones) {
 		updated := record.DeepCopy()
 		updated.Status.Zones = statuses
-		updated.Status.ObservedGeneration = updated.Generation
+		// Leave the observed generation unchanged if publishing was
+		// deferred for any zone so that the deferred zone is published
+		// when the record is reconciled again.
+		if !deferred {
+			updated.Status.ObservedGeneration = updated.Generation
+		}
 		if err := r.client.Status().Update(ctx, updated); err != nil {
 			log.Error(err, "failed to update dnsrecord; will retry", "dnsrecord", updated)
 			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
@@ -204,6 +228,38 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	return result, nil
 }
 
+// reconcileRetainedRecord deletes the given dnsrecord, which was retained after
+// the ingresscontroller with the given name was deleted, if the given retention
+// time has passed.  Otherwise it requeues the dnsrecord for when the retention
+// time passes.  If an ingresscontroller with the same name exists, the
+// dnsrecord is left for that ingresscontroller to adopt.
+func (r *reconciler) reconcileRetainedRecord(ctx context.Context, record *iov1.DNSRecord, ingressName, retainUntil string) (reconcile.Result, error) {
+	expiry, err := time.Parse(time.RFC3339, retainUntil)
+	if err != nil {
+		log.Error(err, "invalid retention time on dnsrecord; deleting it", "dnsrecord", record.Name, "annotation", manifests.DNSRecordRetainUntilAnnotation)
+	} else if remaining := expiry.Sub(clock.Now()); remaining > 0 {
+		log.Info("dnsrecord is retained; will delete it when the grace period expires", "dnsrecord", record.Name, "until", retainUntil)
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	// Use the client rather than the cache so that a record that a
+	// just-recreated ingresscontroller is about to adopt is not deleted.
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: record.Namespace, Name: ingressName}, &operatorv1.IngressController{}); err == nil {
+		log.Info("dnsrecord grace period expired but its ingresscontroller was recreated; leaving it for adoption", "dnsrecord", record.Name, "ingresscontroller", ingressName)
+		return reconcile.Result{RequeueAfter: 1 * time.Minute}, nil
+	} else if !errors.IsNotFound(err) {
+		log.Error(err, "failed to get ingresscontroller for dnsrecord; will retry", "dnsrecord", record.Name, "ingresscontroller", ingressName)
+		return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
+	}
+
+	if err := r.client.Delete(ctx, record); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "failed to delete retained dnsrecord; will retry", "dnsrecord", record.Name)
+		return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
+	}
+	log.Info("deleted retained dnsrecord after its grace period expired", "dnsrecord", record.Name)
+	return reconcile.Result{}, nil
+}
+
 // createDNSProviderIfNeeded creates a new DNS provider if none has yet been
 // created or if the infrastructure platform status or cloud credentials have
 // changed since the current provider was created.  After creating a new
@@ -255,8 +311,13 @@ func (r *reconciler) createDNSProviderIfNeeded(dnsConfig *configv1.DNS) error {
 	return nil
 }
 
-func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1.DNSRecord) ([]iov1.DNSZoneStatus, reconcile.Result) {
+// publishRecordToZones publishes the given record to the given zones.  Returns
+// the record's updated zone statuses, the reconcile result, and a Boolean
+// value indicating whether publishing to any zone was deferred because of the
+// zone's rate limit.
+func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1.DNSRecord) ([]iov1.DNSZoneStatus, reconcile.Result, bool) {
 	result := reconcile.Result{}
+	deferred := false
 	var statuses []iov1.DNSZoneStatus
 	for i := range zones {
 		zone := zones[i]
@@ -269,16 +330,30 @@ func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1
 			continue
 		}
 
+		// Publishing a record that is not yet published is urgent;
+		// updating a published record can wait for the rate limit.
+		alreadyPublished := recordIsAlreadyPublishedToZone(record, &zone)
+		if delay := r.zoneRateLimiter.reserve(zone, clock.Now(), !alreadyPublished); delay > 0 {
+			log.Info("deferring DNS record update to stay within the zone's rate limit", "record", record.Spec, "dnszone", zone, "delay", delay)
+			deferred = true
+			if result.RequeueAfter == 0 || delay < result.RequeueAfter {
+				result.RequeueAfter = delay
+			}
+			continue
+		}
+
 		condition := iov1.DNSZoneCondition{
 			Status:             string(operatorv1.ConditionUnknown),
 			Type:               iov1.DNSRecordFailedConditionType,
 			LastTransitionTime: metav1.Now(),
 		}
 
-		if recordIsAlreadyPublishedToZone(record, &zone) {
+		if alreadyPublished {
 			log.Info("replacing DNS record", "record", record.Spec, "dnszone", zone)
 
-			if err := r.dnsProvider.Replace(record, zone); err != nil {
+			if err := observeDNSProviderCall(r.providerName(), dnsOperationReplace, func() error {
+				return r.dnsProvider.Replace(record, zone)
+			}); err != nil {
 				log.Error(err, "failed to replace DNS record in zone", "record", record.Spec, "dnszone", zone)
 				condition.Status = string(operatorv1.ConditionTrue)
 				condition.Reason = "ProviderError"
@@ -291,7 +366,9 @@ func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1
 				condition.Message = "The DNS provider succeeded in replacing the record"
 			}
 		} else {
-			if err := r.dnsProvider.Ensure(record, zone); err != nil {
+			if err := observeDNSProviderCall(r.providerName(), dnsOperationEnsure, func() error {
+				return r.dnsProvider.Ensure(record, zone)
+			}); err != nil {
 				log.Error(err, "failed to publish DNS record to zone", "record", record.Spec, "dnszone", zone)
 				condition.Status = string(operatorv1.ConditionTrue)
 				condition.Reason = "ProviderError"
@@ -309,7 +386,22 @@ func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1
 			Conditions: []iov1.DNSZoneCondition{condition},
 		})
 	}
-	return mergeStatuses(zones, record.Status.DeepCopy().Zones, statuses), result
+	return mergeStatuses(zones, record.Status.DeepCopy().Zones, statuses), result, deferred
+}
+
+// providerName returns the name of the DNS provider for use as the
+// "provider" label of the DNS publish metrics, which is the platform type
+// for which the provider was created.
+func (r *reconciler) providerName() string {
+	if r.infraConfig != nil {
+		if ps := r.infraConfig.Status.PlatformStatus; ps != nil && len(ps.Type) != 0 {
+			return string(ps.Type)
+		}
+		if len(r.infraConfig.Status.Platform) != 0 {
+			return string(r.infraConfig.Status.Platform)
+		}
+	}
+	return "unknown"
 }
 
 // recordIsAlreadyPublishedToZone returns a Boolean value indicating whether the
@@ -340,7 +432,10 @@ func (r *reconciler) delete(record *iov1.DNSRecord) error {
 		if !recordIsAlreadyPublishedToZone(record, &zone) {
 			continue
 		}
-		err := r.dnsProvider.Delete(record, zone)
+		r.zoneRateLimiter.reserve(zone, clock.Now(), true)
+		err := observeDNSProviderCall(r.providerName(), dnsOperationDelete, func() error {
+			return r.dnsProvider.Delete(record, zone)
+		})
 		if err != nil {
 			errs = append(errs, err)
 		} else {
@@ -348,12 +443,17 @@ func (r *reconciler) delete(record *iov1.DNSRecord) error {
 		}
 	}
 	if len(errs) == 0 {
-		updated := record.DeepCopy()
-		if slice.ContainsString(updated.Finalizers, manifests.DNSRecordFinalizer) {
-			updated.Finalizers = slice.RemoveString(updated.Finalizers, manifests.DNSRecordFinalizer)
-			if err := r.client.Update(context.TODO(), updated); err != nil {
-				errs = append(errs, fmt.Errorf("failed to remove finalizer from dnsrecord %s: %v", record.Name, err))
+		current := record.DeepCopy()
+		_, err := controller.UpdateWithRetryOnConflict(r.client, current, func() client.Object {
+			if !slice.ContainsString(current.Finalizers, manifests.DNSRecordFinalizer) {
+				return nil
 			}
+			updated := current.DeepCopy()
+			updated.Finalizers = slice.RemoveString(updated.Finalizers, manifests.DNSRecordFinalizer)
+			return updated
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to remove finalizer from dnsrecord %s: %v", record.Name, err))
 		}
 	}
 	return utilerrors.NewAggregate(errs)
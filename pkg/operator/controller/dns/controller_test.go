@@ -1,16 +1,21 @@
 package dns
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	iov1 "github.com/openshift/api/operatoringress/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -53,7 +58,7 @@ func TestPublishRecordToZones(t *testing.T) {
 			//TODO To write a fake provider that can return errors and add more test cases.
 			dnsProvider: &dns.FakeProvider{},
 		}
-		actual, _ := r.publishRecordToZones(test.zones, record)
+		actual, _, _ := r.publishRecordToZones(test.zones, record)
 		var conditions []string
 		for _, dnsStatus := range actual {
 			for _, condition := range dnsStatus.Conditions {
@@ -152,7 +157,7 @@ func TestPublishRecordToZonesMergesStatus(t *testing.T) {
 		r := &reconciler{dnsProvider: &dns.FakeProvider{}}
 		zone := []configv1.DNSZone{{ID: "zone2"}}
 		oldStatuses := record.Status.DeepCopy().Zones
-		newStatuses, _ := r.publishRecordToZones(zone, record)
+		newStatuses, _, _ := r.publishRecordToZones(zone, record)
 		if !dnsZoneStatusSlicesEqual(oldStatuses, tc.oldZoneStatuses) {
 			t.Fatalf("%q: publishRecordToZones mutated the record's status conditions\nold: %#v\nnew: %#v", tc.description, oldStatuses, tc.oldZoneStatuses)
 		}
@@ -628,3 +633,243 @@ func TestCustomCABundle(t *testing.T) {
 		})
 	}
 }
+
+// countingProvider is a DNS provider that counts the writes it receives.
+type countingProvider struct {
+	ensures, replaces, deletes int
+}
+
+func (p *countingProvider) Ensure(*iov1.DNSRecord, configv1.DNSZone) error {
+	p.ensures++
+	return nil
+}
+
+func (p *countingProvider) Delete(*iov1.DNSRecord, configv1.DNSZone) error {
+	p.deletes++
+	return nil
+}
+
+func (p *countingProvider) Replace(*iov1.DNSRecord, configv1.DNSZone) error {
+	p.replaces++
+	return nil
+}
+
+// TestPublishRecordToZonesRateLimit verifies that publishRecordToZones defers
+// updates that exceed a zone's rate limit and applies them once the limit
+// allows, while publishing unpublished records immediately.
+func TestPublishRecordToZonesRateLimit(t *testing.T) {
+	fakeClock := utilclock.NewFakeClock(time.Now())
+	oldClock := clock
+	clock = fakeClock
+	defer func() { clock = oldClock }()
+
+	zone1 := configv1.DNSZone{ID: "zone1"}
+	zone2 := configv1.DNSZone{Tags: map[string]string{"Name": "zone2"}}
+	publishedStatus := func(zones ...configv1.DNSZone) []iov1.DNSZoneStatus {
+		var statuses []iov1.DNSZoneStatus
+		for _, zone := range zones {
+			statuses = append(statuses, iov1.DNSZoneStatus{
+				DNSZone: zone,
+				Conditions: []iov1.DNSZoneCondition{{
+					Type:   iov1.DNSRecordFailedConditionType,
+					Status: string(operatorv1.ConditionFalse),
+				}},
+			})
+		}
+		return statuses
+	}
+	// newRecord returns a record whose spec has changed since it was
+	// published to the given zones.
+	newRecord := func(publishedZones ...configv1.DNSZone) *iov1.DNSRecord {
+		return &iov1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec: iov1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com.",
+				RecordType: iov1.ARecordType,
+				Targets:    []string{"192.0.2.1"},
+				RecordTTL:  30,
+			},
+			Status: iov1.DNSRecordStatus{
+				ObservedGeneration: 1,
+				Zones:              publishedStatus(publishedZones...),
+			},
+		}
+	}
+
+	provider := &countingProvider{}
+	r := &reconciler{
+		dnsProvider: provider,
+		// Allow 1 write per minute per zone with no bursting.
+		zoneRateLimiter: newZoneRateLimiter(1.0/60, 1),
+	}
+
+	// The first update to zone1 uses the zone's only token.
+	if _, result, deferred := r.publishRecordToZones([]configv1.DNSZone{zone1}, newRecord(zone1)); deferred || result.RequeueAfter != 0 {
+		t.Fatalf("expected first update to be applied, got deferred=%t, requeueAfter=%v", deferred, result.RequeueAfter)
+	}
+	if provider.replaces != 1 {
+		t.Fatalf("expected 1 replace, got %d", provider.replaces)
+	}
+
+	// A second update to zone1 exceeds the limit and is deferred.  The
+	// zone's status is left unchanged.
+	record := newRecord(zone1)
+	statuses, result, deferred := r.publishRecordToZones([]configv1.DNSZone{zone1}, record)
+	if !deferred {
+		t.Fatal("expected second update to be deferred")
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Minute {
+		t.Errorf("expected requeue within a minute, got %v", result.RequeueAfter)
+	}
+	if provider.replaces != 1 {
+		t.Errorf("expected deferred update not to be written, got %d replaces", provider.replaces)
+	}
+	if !dnsZoneStatusSlicesEqual(statuses, record.Status.Zones) {
+		t.Errorf("expected deferred zone's status to be unchanged, got %#v", statuses)
+	}
+
+	// zone2 has its own bucket, so an update to zone2 is applied even
+	// though zone1's limit is exhausted.
+	if _, _, deferred := r.publishRecordToZones([]configv1.DNSZone{zone1, zone2}, newRecord(zone1, zone2)); !deferred {
+		t.Error("expected update to zone1 to be deferred")
+	}
+	if provider.replaces != 2 {
+		t.Errorf("expected update to zone2 to be written, got %d replaces", provider.replaces)
+	}
+
+	// A record that has not been published to zone1 is urgent and is
+	// published immediately despite the limit.
+	if _, _, deferred := r.publishRecordToZones([]configv1.DNSZone{zone1}, newRecord()); deferred {
+		t.Error("expected unpublished record to be published immediately")
+	}
+	if provider.ensures != 1 {
+		t.Errorf("expected 1 ensure, got %d", provider.ensures)
+	}
+
+	// Once the limit allows (the urgent write borrowed a token, so two
+	// intervals must pass), the deferred update is eventually applied.
+	fakeClock.Step(time.Minute)
+	if _, _, deferred := r.publishRecordToZones([]configv1.DNSZone{zone1}, newRecord(zone1)); !deferred {
+		t.Error("expected update to be deferred while the borrowed token is repaid")
+	}
+	fakeClock.Step(time.Minute)
+	if _, _, deferred := r.publishRecordToZones([]configv1.DNSZone{zone1}, newRecord(zone1)); deferred {
+		t.Error("expected deferred update to be applied after the limit allows")
+	}
+	if provider.replaces != 3 {
+		t.Errorf("expected 3 replaces, got %d", provider.replaces)
+	}
+}
+
+// TestNewZoneRateLimiter verifies that rate limiting is disabled unless a
+// positive rate is configured.
+func TestNewZoneRateLimiter(t *testing.T) {
+	if l := newZoneRateLimiter(0, 5); l != nil {
+		t.Errorf("expected nil limiter for zero rate, got %#v", l)
+	}
+	var l *zoneRateLimiter
+	for i := 0; i < 100; i++ {
+		if delay := l.reserve(configv1.DNSZone{ID: "zone1"}, time.Now(), false); delay != 0 {
+			t.Fatalf("expected nil limiter not to defer writes, got %v", delay)
+		}
+	}
+}
+
+// TestReconcileRetainedRecord verifies that reconcileRetainedRecord keeps a
+// retained dnsrecord during its grace period, deletes it once the grace period
+// expires, and leaves it for adoption if its ingresscontroller was recreated.
+func TestReconcileRetainedRecord(t *testing.T) {
+	oldClock := clock
+	defer func() { clock = oldClock }()
+
+	deletedAt := time.Now()
+	retainUntil := deletedAt.Add(5 * time.Minute).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name             string
+		retainUntil      string
+		elapsed          time.Duration
+		recreated        bool
+		expectDeleted    bool
+		expectRequeueMin time.Duration
+		expectRequeueMax time.Duration
+	}{
+		{
+			name:             "within the grace period",
+			retainUntil:      retainUntil,
+			elapsed:          time.Minute,
+			expectDeleted:    false,
+			expectRequeueMin: 3 * time.Minute,
+			expectRequeueMax: 4 * time.Minute,
+		},
+		{
+			name:             "recreated within the grace period",
+			retainUntil:      retainUntil,
+			elapsed:          time.Minute,
+			recreated:        true,
+			expectDeleted:    false,
+			expectRequeueMin: 3 * time.Minute,
+			expectRequeueMax: 4 * time.Minute,
+		},
+		{
+			name:          "grace period expired",
+			retainUntil:   retainUntil,
+			elapsed:       10 * time.Minute,
+			expectDeleted: true,
+		},
+		{
+			name:             "recreated after the grace period expired",
+			retainUntil:      retainUntil,
+			elapsed:          10 * time.Minute,
+			recreated:        true,
+			expectDeleted:    false,
+			expectRequeueMin: time.Minute,
+			expectRequeueMax: time.Minute,
+		},
+		{
+			name:          "invalid retention time",
+			retainUntil:   "soon",
+			expectDeleted: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clock = utilclock.NewFakeClock(deletedAt.Add(tc.elapsed))
+
+			scheme := runtime.NewScheme()
+			iov1.AddToScheme(scheme)
+			operatorv1.AddToScheme(scheme)
+			record := &iov1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "openshift-ingress-operator",
+					Name:        "default-wildcard",
+					Labels:      map[string]string{manifests.OwningIngressControllerLabel: "default"},
+					Annotations: map[string]string{manifests.DNSRecordRetainUntilAnnotation: tc.retainUntil},
+					Finalizers:  []string{manifests.DNSRecordFinalizer},
+				},
+			}
+			objects := []runtime.Object{record}
+			if tc.recreated {
+				objects = append(objects, &operatorv1.IngressController{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+				})
+			}
+			cl := fake.NewFakeClientWithScheme(scheme, objects...)
+			r := &reconciler{client: cl}
+
+			result, err := r.reconcileRetainedRecord(context.Background(), record, "default", tc.retainUntil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.RequeueAfter < tc.expectRequeueMin || result.RequeueAfter > tc.expectRequeueMax {
+				t.Errorf("expected requeue after between %v and %v, got %v", tc.expectRequeueMin, tc.expectRequeueMax, result.RequeueAfter)
+			}
+			current := &iov1.DNSRecord{}
+			if err := cl.Get(context.Background(), types.NamespacedName{Namespace: record.Namespace, Name: record.Name}, current); err != nil {
+				t.Fatalf("failed to get dnsrecord: %v", err)
+			}
+			if deleted := current.DeletionTimestamp != nil; deleted != tc.expectDeleted {
+				t.Errorf("expected deleted to be %t, got %t", tc.expectDeleted, deleted)
+			}
+		})
+	}
+}
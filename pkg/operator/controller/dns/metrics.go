@@ -0,0 +1,61 @@
+package dns
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// dnsPublishDuration reports how long DNS provider calls take, by
+	// provider and operation, using the ingress_dns_publish_duration_seconds
+	// metric.
+	dnsPublishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ingress_dns_publish_duration_seconds",
+		Help:    "Time taken by the DNS provider to publish, replace, or delete a DNS record, in seconds.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"provider", "operation"})
+
+	// dnsPublishFailures counts failed DNS provider calls, by provider and
+	// operation, using the ingress_dns_publish_failures_total metric.
+	dnsPublishFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_dns_publish_failures_total",
+		Help: "Number of times the DNS provider failed to publish, replace, or delete a DNS record.",
+	}, []string{"provider", "operation"})
+
+	// metricsList is a list of metrics for this package.
+	metricsList = []prometheus.Collector{
+		dnsPublishDuration,
+		dnsPublishFailures,
+	}
+)
+
+const (
+	// dnsOperationEnsure, dnsOperationReplace, and dnsOperationDelete are
+	// the values of the "operation" label of the DNS publish metrics.
+	dnsOperationEnsure  = "ensure"
+	dnsOperationReplace = "replace"
+	dnsOperationDelete  = "delete"
+)
+
+// observeDNSProviderCall calls fn, which performs the given operation using
+// the given DNS provider, and records its duration and whether it failed in
+// the DNS publish metrics.  It returns the error from fn.
+func observeDNSProviderCall(provider, operation string, fn func() error) error {
+	start := clock.Now()
+	err := fn()
+	dnsPublishDuration.WithLabelValues(provider, operation).Observe(clock.Since(start).Seconds())
+	if err != nil {
+		dnsPublishFailures.WithLabelValues(provider, operation).Inc()
+	}
+	return err
+}
+
+// RegisterMetrics calls prometheus.Register on each metric in metricsList, and
+// returns on errors.
+func RegisterMetrics() error {
+	for _, metric := range metricsList {
+		if err := prometheus.Register(metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
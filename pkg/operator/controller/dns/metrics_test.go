@@ -0,0 +1,137 @@
+package dns
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// failingProvider is a DNS provider whose Ensure calls fail.
+type failingProvider struct {
+	countingProvider
+}
+
+func (p *failingProvider) Ensure(*iov1.DNSRecord, configv1.DNSZone) error {
+	return fmt.Errorf("simulated failure")
+}
+
+// histogramSampleCount returns the number of observations that the given
+// histogram has recorded.
+func histogramSampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	if err := observer.(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatalf("failed to read histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// TestDNSPublishMetrics verifies that publishing, replacing, and deleting DNS
+// records records the duration of each DNS provider call and counts failed
+// calls, by provider and operation.
+func TestDNSPublishMetrics(t *testing.T) {
+	dnsPublishDuration.Reset()
+	dnsPublishFailures.Reset()
+
+	published := configv1.DNSZone{ID: "published"}
+	unpublished := configv1.DNSZone{ID: "unpublished"}
+	// The record's spec has changed since it was published, so it is
+	// replaced in the zone to which it is already published.
+	record := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com.",
+			RecordType: iov1.ARecordType,
+			Targets:    []string{"192.0.2.1"},
+			RecordTTL:  30,
+		},
+		Status: iov1.DNSRecordStatus{
+			ObservedGeneration: 1,
+			Zones: []iov1.DNSZoneStatus{{
+				DNSZone: published,
+				Conditions: []iov1.DNSZoneCondition{{
+					Type:   iov1.DNSRecordFailedConditionType,
+					Status: string(operatorv1.ConditionFalse),
+				}},
+			}},
+		},
+	}
+	r := &reconciler{
+		dnsProvider: &failingProvider{},
+		infraConfig: &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{
+				PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType},
+			},
+		},
+	}
+
+	// Replacing the record in the published zone succeeds, and ensuring
+	// the record in the unpublished zone fails.
+	r.publishRecordToZones([]configv1.DNSZone{published, unpublished}, record)
+	if err := r.delete(record); err != nil {
+		t.Fatalf("unexpected error deleting record: %v", err)
+	}
+
+	for _, tc := range []struct {
+		operation      string
+		expectCalls    uint64
+		expectFailures float64
+	}{
+		{dnsOperationEnsure, 1, 1},
+		{dnsOperationReplace, 1, 0},
+		{dnsOperationDelete, 1, 0},
+	} {
+		if calls := histogramSampleCount(t, dnsPublishDuration.WithLabelValues("AWS", tc.operation)); calls != tc.expectCalls {
+			t.Errorf("expected %d %s durations to be recorded, got %d", tc.expectCalls, tc.operation, calls)
+		}
+		if failures := testutil.ToFloat64(dnsPublishFailures.WithLabelValues("AWS", tc.operation)); failures != tc.expectFailures {
+			t.Errorf("expected %v %s failures to be counted, got %v", tc.expectFailures, tc.operation, failures)
+		}
+	}
+}
+
+// TestProviderName verifies that the provider label of the DNS publish
+// metrics is the platform type.
+func TestProviderName(t *testing.T) {
+	testCases := []struct {
+		name        string
+		infraConfig *configv1.Infrastructure
+		expected    string
+	}{
+		{
+			name:     "no infrastructure config",
+			expected: "unknown",
+		},
+		{
+			name: "platform status",
+			infraConfig: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{
+				PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType},
+			}},
+			expected: "GCP",
+		},
+		{
+			name: "deprecated platform field",
+			infraConfig: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{
+				Platform: configv1.AzurePlatformType,
+			}},
+			expected: "Azure",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &reconciler{infraConfig: tc.infraConfig}
+			if actual := r.providerName(); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package dns
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"golang.org/x/time/rate"
+)
+
+// zoneRateLimiter limits the rate of writes to each DNS zone using a token
+// bucket per zone so that frequent record churn does not exceed the DNS
+// provider's API quotas.  A nil *zoneRateLimiter imposes no limit.
+type zoneRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// newZoneRateLimiter returns a zoneRateLimiter that allows writesPerSecond
+// writes per second to each zone with bursts of up to burst writes.  Returns
+// nil if writesPerSecond is not positive.
+func newZoneRateLimiter(writesPerSecond float64, burst int) *zoneRateLimiter {
+	if writesPerSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &zoneRateLimiter{
+		limit:    rate.Limit(writesPerSecond),
+		burst:    burst,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+// limiterForZone returns the token bucket for the given zone, creating it if
+// necessary.
+func (l *zoneRateLimiter) limiterForZone(zone configv1.DNSZone) *rate.Limiter {
+	// Zones are identified either by ID or by tags; fmt prints maps
+	// sorted by key, so the key is stable.
+	key := fmt.Sprintf("%s/%v", zone.ID, zone.Tags)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[key] = limiter
+	}
+	return limiter
+}
+
+// reserve takes a token for a write to the given zone at the given time.
+// Urgent writes are always allowed, but they still take a token (possibly
+// borrowing from the future) so that they count against the limit.  If a
+// non-urgent write would exceed the limit, no token is taken, and reserve
+// returns how long to defer the write.  Otherwise reserve returns zero.
+func (l *zoneRateLimiter) reserve(zone configv1.DNSZone, now time.Time, urgent bool) time.Duration {
+	if l == nil {
+		return 0
+	}
+	reservation := l.limiterForZone(zone).ReserveN(now, 1)
+	if urgent || !reservation.OK() {
+		return 0
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay
+	}
+	return 0
+}
@@ -0,0 +1,303 @@
+package ingress
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	"github.com/prometheus/common/expfmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// activeConnectionsPollInterval is how often the operator scrapes the
+	// metrics of router pods for ingresscontrollers that have the
+	// reportActiveConnections unsupported config override.
+	activeConnectionsPollInterval = 1 * time.Minute
+
+	// activeConnectionsScrapeTimeout is how long the operator waits for a
+	// router pod's metrics.
+	activeConnectionsScrapeTimeout = 10 * time.Second
+
+	// routerCurrentSessionsMetric is the router metric that reports the
+	// number of current sessions on each HAProxy frontend.
+	routerCurrentSessionsMetric = "haproxy_frontend_current_sessions"
+
+	// serviceCAFile is the path to the service CA bundle, which signs the
+	// router's metrics certificate, in the operator's pod.
+	serviceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+)
+
+// routerClientFrontends are the HAProxy frontends on which the router accepts
+// client connections.  Other frontends, such as those for SNI and non-SNI
+// TLS, receive connections that the "public_ssl" frontend passes on, so
+// counting them would count those connections twice.
+var routerClientFrontends = map[string]bool{
+	"public":     true,
+	"public_ssl": true,
+}
+
+// activeConnectionsScraper returns the number of active client connections to
+// the given router pod of the given ingresscontroller.
+type activeConnectionsScraper func(ic *operatorv1.IngressController, pod *corev1.Pod) (int64, error)
+
+// routerPodConnections is the result of scraping a router pod's metrics.
+type routerPodConnections struct {
+	// pod is the name of the router pod.
+	pod string
+	// connections is the number of active client connections to the pod.
+	connections int64
+	// err is the error, if any, from scraping the pod's metrics.
+	err error
+}
+
+// reportActiveConnectionsEnabled returns whether the given ingresscontroller
+// has the reportActiveConnections unsupported config override.
+func reportActiveConnectionsEnabled(ic *operatorv1.IngressController) bool {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	return err == nil && overrides.ReportActiveConnections
+}
+
+// parseActiveConnections parses router metrics in the Prometheus text format
+// and returns the number of current sessions on the frontends on which the
+// router accepts client connections.
+func parseActiveConnections(r io.Reader) (int64, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	family, ok := families[routerCurrentSessionsMetric]
+	if !ok {
+		return 0, fmt.Errorf("metrics do not include %s", routerCurrentSessionsMetric)
+	}
+	var total int64
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "frontend" && routerClientFrontends[label.GetValue()] {
+				total += int64(metric.GetGauge().GetValue())
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// computeActiveConnectionsCondition computes the ingresscontroller's
+// "ActiveConnections" status condition from the results of scraping its
+// router pods' metrics.  The condition is true if the metrics of at least one
+// router pod could be scraped, and its message reports the order of magnitude
+// of the total number of active client connections.  The message omits exact
+// and per-pod counts so that it only changes, and causes the ingresscontroller
+// to be reconciled, when the load changes significantly.
+func computeActiveConnectionsCondition(results []routerPodConnections) operatorv1.OperatorCondition {
+	if len(results) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerActiveConnectionsConditionType,
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "NoRouterPods",
+			Message: "No router pods are running.",
+		}
+	}
+	var (
+		total    int64
+		scraped  int
+		failures int
+	)
+	for _, result := range results {
+		if result.err != nil {
+			failures++
+			continue
+		}
+		total += result.connections
+		scraped++
+	}
+	if scraped == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerActiveConnectionsConditionType,
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "ScrapeFailed",
+			Message: fmt.Sprintf("Failed to scrape the metrics of any of the %d router pods.", failures),
+		}
+	}
+	message := fmt.Sprintf("Router pods have %s active connections in total.", activeConnectionsRange(total))
+	if failures != 0 {
+		message += fmt.Sprintf(" Failed to scrape the metrics of %d of %d router pods.", failures, len(results))
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerActiveConnectionsConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "MetricsScraped",
+		Message: message,
+	}
+}
+
+// activeConnectionsRange returns the range of powers of ten in which the given
+// number of connections falls, such as "100-999".
+func activeConnectionsRange(connections int64) string {
+	lower, upper := int64(0), int64(10)
+	for connections >= upper && upper < math.MaxInt64/10 {
+		lower, upper = upper, upper*10
+	}
+	return fmt.Sprintf("%d-%d", lower, upper-1)
+}
+
+// pollActiveConnections periodically updates the "ActiveConnections" status
+// condition of each ingresscontroller that has the reportActiveConnections
+// unsupported config override until the given context is done.
+func (r *reconciler) pollActiveConnections(ctx context.Context) error {
+	wait.UntilWithContext(ctx, r.syncActiveConnections, activeConnectionsPollInterval)
+	return nil
+}
+
+// syncActiveConnections scrapes the metrics of the router pods of each
+// ingresscontroller that has the reportActiveConnections unsupported config
+// override and updates the ingresscontroller's "ActiveConnections" status
+// condition.  It removes the condition from ingresscontrollers that do not
+// have the override.
+func (r *reconciler) syncActiveConnections(ctx context.Context) {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.client.List(ctx, ingresses, client.InNamespace(r.config.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers in order to report active connections")
+		return
+	}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		updated := ic.DeepCopy()
+		if reportActiveConnectionsEnabled(ic) {
+			results, err := r.scrapeRouterPods(ctx, ic)
+			if err != nil {
+				log.Error(err, "failed to scrape router pods", "ingresscontroller", ic.Name)
+				continue
+			}
+			updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeActiveConnectionsCondition(results))
+		} else {
+			updated.Status.Conditions = removeCondition(updated.Status.Conditions, IngressControllerActiveConnectionsConditionType)
+		}
+		if conditionsEqual(ic.Status.Conditions, updated.Status.Conditions) {
+			continue
+		}
+		// A conflict with the reconciler's status update is resolved
+		// on the next poll.
+		if err := r.client.Status().Update(ctx, updated); err != nil {
+			log.Error(err, "failed to update active connections status condition", "ingresscontroller", ic.Name)
+		}
+	}
+}
+
+// scrapeRouterPods returns the number of active client connections to each of
+// the given ingresscontroller's running router pods.
+func (r *reconciler) scrapeRouterPods(ctx context.Context, ic *operatorv1.IngressController) ([]routerPodConnections, error) {
+	pods := &corev1.PodList{}
+	labels := controller.IngressControllerDeploymentPodSelector(ic).MatchLabels
+	if err := r.client.List(ctx, pods, client.InNamespace(controller.DefaultOperandNamespace), client.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("failed to list router pods: %w", err)
+	}
+	scrape := r.scrapeActiveConnections
+	if scrape == nil {
+		var err error
+		if scrape, err = r.newRouterActiveConnectionsScraper(ctx, ic); err != nil {
+			return nil, err
+		}
+	}
+	var results []routerPodConnections
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || len(pod.Status.PodIP) == 0 {
+			continue
+		}
+		connections, err := scrape(ic, pod)
+		if err != nil {
+			log.Error(err, "failed to scrape router pod metrics", "ingresscontroller", ic.Name, "pod", pod.Name)
+		}
+		results = append(results, routerPodConnections{pod: pod.Name, connections: connections, err: err})
+	}
+	return results, nil
+}
+
+// routerPodStatsPort returns the port on which the given router pod serves
+// metrics, or zero if the pod does not declare one.
+func routerPodStatsPort(pod *corev1.Pod) int32 {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == StatsPortName {
+				return port.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+// newRouterActiveConnectionsScraper returns an activeConnectionsScraper that
+// scrapes the metrics of the given ingresscontroller's router pods using the
+// router's stats credentials.  The router serves its metrics using a
+// certificate for the ingresscontroller's internal service that the service
+// CA signs.  The credentials and CA bundle are read once for all of the
+// ingresscontroller's router pods and again on the next poll so that rotated
+// ones are picked up.  Each pod is scraped once per poll, so the scraper does
+// not keep connections alive between scrapes.
+func (r *reconciler) newRouterActiveConnectionsScraper(ctx context.Context, ic *operatorv1.IngressController) (activeConnectionsScraper, error) {
+	secret := &corev1.Secret{}
+	secretName := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: fmt.Sprintf("router-stats-%s", ic.Name)}
+	if err := r.client.Get(ctx, secretName, secret); err != nil {
+		return nil, fmt.Errorf("failed to get stats secret %s: %w", secretName, err)
+	}
+	caBundle, err := ioutil.ReadFile(serviceCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service CA bundle: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("failed to parse service CA bundle %s", serviceCAFile)
+	}
+	service := controller.InternalIngressControllerServiceName(ic)
+	httpClient := &http.Client{
+		Timeout: activeConnectionsScrapeTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    roots,
+				ServerName: fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+			},
+			DisableKeepAlives: true,
+		},
+	}
+	username, password := string(secret.Data["statsUsername"]), string(secret.Data["statsPassword"])
+	return func(ic *operatorv1.IngressController, pod *corev1.Pod) (int64, error) {
+		port := routerPodStatsPort(pod)
+		if port == 0 {
+			return 0, fmt.Errorf("pod has no %s port", StatsPortName)
+		}
+		url := fmt.Sprintf("https://%s/metrics", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.SetBasicAuth(username, password)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get metrics: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("failed to get metrics: %s", resp.Status)
+		}
+		return parseActiveConnections(resp.Body)
+	}, nil
+}
@@ -0,0 +1,215 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeRouterMetrics is an excerpt of the router's metrics.
+const fakeRouterMetrics = `# HELP haproxy_frontend_current_sessions Current number of active sessions.
+# TYPE haproxy_frontend_current_sessions gauge
+haproxy_frontend_current_sessions{frontend="fe_no_sni"} 4
+haproxy_frontend_current_sessions{frontend="fe_sni"} 7
+haproxy_frontend_current_sessions{frontend="public"} 12
+haproxy_frontend_current_sessions{frontend="public_ssl"} 11
+# HELP haproxy_up Was the last scrape of haproxy successful.
+# TYPE haproxy_up gauge
+haproxy_up 1
+`
+
+// TestParseActiveConnections verifies that parseActiveConnections counts the
+// current sessions on the frontends that accept client connections.
+func TestParseActiveConnections(t *testing.T) {
+	connections, err := parseActiveConnections(strings.NewReader(fakeRouterMetrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connections != 23 {
+		t.Errorf("expected 23 active connections, got %d", connections)
+	}
+
+	if _, err := parseActiveConnections(strings.NewReader("haproxy_up 1\n")); err == nil {
+		t.Error("expected an error for metrics without current sessions")
+	}
+	if _, err := parseActiveConnections(strings.NewReader("not metrics")); err == nil {
+		t.Error("expected an error for invalid metrics")
+	}
+}
+
+// TestComputeActiveConnectionsCondition verifies that
+// computeActiveConnectionsCondition reports the order of magnitude of the total
+// active connections and the number of pods whose metrics could not be
+// scraped, and that its message does not change with small changes in load.
+func TestComputeActiveConnectionsCondition(t *testing.T) {
+	testCases := []struct {
+		name          string
+		results       []routerPodConnections
+		expectStatus  operatorv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			name:          "no pods",
+			expectStatus:  operatorv1.ConditionUnknown,
+			expectReason:  "NoRouterPods",
+			expectMessage: "No router pods are running.",
+		},
+		{
+			name: "all pods scraped",
+			results: []routerPodConnections{
+				{pod: "router-default-b", connections: 5},
+				{pod: "router-default-a", connections: 18},
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "MetricsScraped",
+			expectMessage: "Router pods have 10-99 active connections in total.",
+		},
+		{
+			name: "all pods scraped with slightly different load",
+			results: []routerPodConnections{
+				{pod: "router-default-b", connections: 40},
+				{pod: "router-default-a", connections: 31},
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "MetricsScraped",
+			expectMessage: "Router pods have 10-99 active connections in total.",
+		},
+		{
+			name: "no connections",
+			results: []routerPodConnections{
+				{pod: "router-default-a"},
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "MetricsScraped",
+			expectMessage: "Router pods have 0-9 active connections in total.",
+		},
+		{
+			name: "some pods failed",
+			results: []routerPodConnections{
+				{pod: "router-default-a", connections: 1800},
+				{pod: "router-default-b", err: fmt.Errorf("connection refused")},
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "MetricsScraped",
+			expectMessage: "Router pods have 1000-9999 active connections in total. Failed to scrape the metrics of 1 of 2 router pods.",
+		},
+		{
+			name: "all pods failed",
+			results: []routerPodConnections{
+				{pod: "router-default-a", err: fmt.Errorf("connection refused")},
+			},
+			expectStatus:  operatorv1.ConditionUnknown,
+			expectReason:  "ScrapeFailed",
+			expectMessage: "Failed to scrape the metrics of any of the 1 router pods.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := computeActiveConnectionsCondition(tc.results)
+			if actual.Status != tc.expectStatus || actual.Reason != tc.expectReason {
+				t.Errorf("expected status %s and reason %s, got %s and %s", tc.expectStatus, tc.expectReason, actual.Status, actual.Reason)
+			}
+			if actual.Message != tc.expectMessage {
+				t.Errorf("expected message %q, got %q", tc.expectMessage, actual.Message)
+			}
+		})
+	}
+}
+
+// TestSyncActiveConnections verifies that syncActiveConnections sets the
+// "ActiveConnections" status condition from the running router pods' metrics
+// on ingresscontrollers that have the reportActiveConnections unsupported
+// config override and removes it from ingresscontrollers that do not.
+func TestSyncActiveConnections(t *testing.T) {
+	reporting := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"reportActiveConnections":true}`)},
+		},
+	}
+	notReporting := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "other"},
+		Status: operatorv1.IngressControllerStatus{
+			Conditions: []operatorv1.OperatorCondition{{
+				Type:   IngressControllerActiveConnectionsConditionType,
+				Status: operatorv1.ConditionTrue,
+			}},
+		},
+	}
+	routerPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: controller.DefaultOperandNamespace,
+				Name:      name,
+				Labels:    controller.IngressControllerDeploymentPodSelector(reporting).MatchLabels,
+			},
+			Status: corev1.PodStatus{Phase: phase, PodIP: "10.0.0.1"},
+		}
+	}
+	s := runtime.NewScheme()
+	if err := operatorv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		reporting,
+		notReporting,
+		routerPod("router-default-a", corev1.PodRunning),
+		routerPod("router-default-b", corev1.PodRunning),
+		routerPod("router-default-c", corev1.PodPending),
+	).Build()
+	scraped := map[string]int{}
+	r := &reconciler{
+		config: Config{Namespace: "openshift-ingress-operator"},
+		client: cl,
+		scrapeActiveConnections: func(ic *operatorv1.IngressController, pod *corev1.Pod) (int64, error) {
+			scraped[pod.Name]++
+			return parseActiveConnections(strings.NewReader(fakeRouterMetrics))
+		},
+	}
+
+	activeConnectionsCondition := func(ic *operatorv1.IngressController) *operatorv1.OperatorCondition {
+		for i := range ic.Status.Conditions {
+			if ic.Status.Conditions[i].Type == IngressControllerActiveConnectionsConditionType {
+				return &ic.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	r.syncActiveConnections(context.TODO())
+
+	if len(scraped) != 2 || scraped["router-default-a"] != 1 || scraped["router-default-b"] != 1 {
+		t.Errorf("expected the two running router pods to be scraped once each, got %v", scraped)
+	}
+	actual := &operatorv1.IngressController{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: reporting.Namespace, Name: reporting.Name}, actual); err != nil {
+		t.Fatal(err)
+	}
+	condition := activeConnectionsCondition(actual)
+	expectMessage := "Router pods have 10-99 active connections in total."
+	if condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Message != expectMessage {
+		t.Errorf("expected condition with status True and message %q, got %+v", expectMessage, condition)
+	}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: notReporting.Namespace, Name: notReporting.Name}, actual); err != nil {
+		t.Fatal(err)
+	}
+	if condition := activeConnectionsCondition(actual); condition != nil {
+		t.Errorf("expected condition to be removed, got %+v", condition)
+	}
+}
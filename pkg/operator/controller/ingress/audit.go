@@ -0,0 +1,173 @@
+package ingress
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// maxAuditedFields is the maximum number of changed fields that an audit
+// record lists.
+const maxAuditedFields = 20
+
+// auditLog is the logger for audit records of changes that the operator
+// applies in response to ingresscontroller changes.
+var auditLog = log.WithName("audit")
+
+// auditRecord describes a change that the operator applied to a resource that
+// it manages for an ingresscontroller.
+type auditRecord struct {
+	// IngressController is the name of the ingresscontroller.
+	IngressController string
+	// Generation is the ingresscontroller's generation that the change
+	// reconciled.
+	Generation int64
+	// ChangedBy is the field manager that most recently changed the
+	// ingresscontroller's spec, or the empty string if it is unknown.
+	ChangedBy string
+	// Action is what the operator did, for example "Updated".
+	Action string
+	// Resource is the kind of the changed resource.
+	Resource string
+	// Namespace and Name identify the changed resource.
+	Namespace, Name string
+	// ChangedFields are the paths of the fields that changed, up to
+	// maxAuditedFields.
+	ChangedFields []string
+	// Truncated indicates whether more fields changed than ChangedFields
+	// lists.
+	Truncated bool
+}
+
+// newAuditRecord returns an audit record of the given action on the given
+// resource for the given ingresscontroller, with the fields that differ
+// between the given current and updated objects.
+func newAuditRecord(ic *operatorv1.IngressController, action, resource, namespace, name string, current, updated interface{}) auditRecord {
+	fields, truncated := changedFields(current, updated)
+	return auditRecord{
+		IngressController: ic.Name,
+		Generation:        ic.Generation,
+		ChangedBy:         specManager(ic),
+		Action:            action,
+		Resource:          resource,
+		Namespace:         namespace,
+		Name:              name,
+		ChangedFields:     fields,
+		Truncated:         truncated,
+	}
+}
+
+// recordAudit logs the given audit record and, if the ingresscontroller's
+// auditEvents unsupported config override is set, records an event on the
+// ingresscontroller.
+func (r *reconciler) recordAudit(ic *operatorv1.IngressController, record auditRecord) {
+	auditLog.Info("applied change",
+		"ingresscontroller", record.IngressController,
+		"generation", record.Generation,
+		"changedBy", record.ChangedBy,
+		"action", record.Action,
+		"resource", record.Resource,
+		"namespace", record.Namespace,
+		"name", record.Name,
+		"changedFields", record.ChangedFields,
+		"truncated", record.Truncated,
+	)
+	if overrides, err := getUnsupportedConfigOverrides(ic); err != nil || !overrides.AuditEvents {
+		return
+	}
+	changedBy := record.ChangedBy
+	if len(changedBy) == 0 {
+		changedBy = "unknown"
+	}
+	fields := strings.Join(record.ChangedFields, ", ")
+	if record.Truncated {
+		fields += ", ..."
+	}
+	r.recorder.Eventf(ic, "Normal", "SpecChangeApplied", "%s %s %s/%s for generation %d (changed by %s): %s", record.Action, strings.ToLower(record.Resource), record.Namespace, record.Name, record.Generation, changedBy, fields)
+}
+
+// specManager returns the name of the field manager that most recently changed
+// the given ingresscontroller's spec according to its managed fields, or the
+// empty string if no managed fields entry has the spec.
+func specManager(ic *operatorv1.IngressController) string {
+	var (
+		manager string
+		latest  time.Time
+	)
+	for _, entry := range ic.ManagedFields {
+		if entry.Subresource != "" || entry.FieldsV1 == nil || !strings.Contains(string(entry.FieldsV1.Raw), `"f:spec"`) {
+			continue
+		}
+		var t time.Time
+		if entry.Time != nil {
+			t = entry.Time.Time
+		}
+		if len(manager) == 0 || t.After(latest) {
+			manager, latest = entry.Manager, t
+		}
+	}
+	return manager
+}
+
+// changedFields returns the sorted paths of the fields that differ between the
+// given objects, up to maxAuditedFields, and a Boolean indicating whether
+// there were more.
+func changedFields(current, updated interface{}) ([]string, bool) {
+	reporter := &fieldReporter{fields: map[string]struct{}{}}
+	cmp.Equal(current, updated, cmpopts.EquateEmpty(), cmp.Reporter(reporter))
+	fields := make([]string, 0, len(reporter.fields))
+	for field := range reporter.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	if len(fields) > maxAuditedFields {
+		return fields[:maxAuditedFields], true
+	}
+	return fields, false
+}
+
+// fieldReporter is a cmp.Reporter that collects the paths of the fields that
+// differ.
+type fieldReporter struct {
+	path   cmp.Path
+	fields map[string]struct{}
+}
+
+func (r *fieldReporter) PushStep(step cmp.PathStep) {
+	r.path = append(r.path, step)
+}
+
+func (r *fieldReporter) Report(result cmp.Result) {
+	if result.Equal() {
+		return
+	}
+	var path strings.Builder
+	for _, step := range r.path {
+		switch s := step.(type) {
+		case cmp.StructField:
+			if path.Len() != 0 {
+				path.WriteString(".")
+			}
+			path.WriteString(s.Name())
+		case cmp.SliceIndex:
+			// The index is -1 if the element was inserted or
+			// removed.
+			if i := s.Key(); i >= 0 {
+				fmt.Fprintf(&path, "[%d]", i)
+			}
+		case cmp.MapIndex:
+			fmt.Fprintf(&path, "[%v]", s.Key())
+		}
+	}
+	r.fields[path.String()] = struct{}{}
+}
+
+func (r *fieldReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
@@ -0,0 +1,159 @@
+package ingress
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// TestNewAuditRecord verifies that newAuditRecord records the
+// ingresscontroller's generation, the field manager that last changed the
+// ingresscontroller's spec, and the fields that the change updated.
+func TestNewAuditRecord(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "default",
+			Generation: 3,
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager:   "kubectl-edit",
+					Operation: metav1.ManagedFieldsOperationUpdate,
+					Time:      &earlier,
+					FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+				},
+				{
+					Manager:   "oc-patch",
+					Operation: metav1.ManagedFieldsOperationUpdate,
+					Time:      &later,
+					FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:tuningOptions":{}}}`)},
+				},
+				{
+					Manager:     "ingress-operator",
+					Operation:   metav1.ManagedFieldsOperationUpdate,
+					Subresource: "status",
+					Time:        &later,
+					FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:status":{}}`)},
+				},
+				{
+					Manager:   "ingress-operator",
+					Operation: metav1.ManagedFieldsOperationUpdate,
+					Time:      &later,
+					FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:finalizers":{}}}`)},
+				},
+			},
+		},
+	}
+	one, two := int32(1), int32(2)
+	current := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &one,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "router",
+						Env:  []corev1.EnvVar{{Name: "ROUTER_THREADS", Value: "4"}},
+					}},
+				},
+			},
+		},
+	}
+	updated := current.DeepCopy()
+	updated.Spec.Replicas = &two
+	updated.Spec.Template.Spec.Containers[0].Env[0].Value = "8"
+
+	audit := newAuditRecord(ic, "Updated", "Deployment", updated.Namespace, updated.Name, current, updated)
+	expected := auditRecord{
+		IngressController: "default",
+		Generation:        3,
+		ChangedBy:         "oc-patch",
+		Action:            "Updated",
+		Resource:          "Deployment",
+		Namespace:         "openshift-ingress",
+		Name:              "router-default",
+		ChangedFields: []string{
+			"Spec.Replicas",
+			"Spec.Template.Spec.Containers[0].Env[0].Value",
+		},
+	}
+	if !reflect.DeepEqual(audit, expected) {
+		t.Errorf("expected audit record %+v, got %+v", expected, audit)
+	}
+}
+
+// TestChangedFieldsTruncated verifies that changedFields lists at most
+// maxAuditedFields fields.
+func TestChangedFieldsTruncated(t *testing.T) {
+	current, updated := map[string]string{}, map[string]string{}
+	for i := 0; i < maxAuditedFields+5; i++ {
+		updated[strings.Repeat("x", i+1)] = "y"
+	}
+	fields, truncated := changedFields(current, updated)
+	if len(fields) != maxAuditedFields || !truncated {
+		t.Errorf("expected %d fields and truncation, got %d fields and truncated=%t", maxAuditedFields, len(fields), truncated)
+	}
+}
+
+// TestRecordAudit verifies that recordAudit records an event with the audit
+// record only if the auditEvents unsupported config override is set.
+func TestRecordAudit(t *testing.T) {
+	audit := auditRecord{
+		IngressController: "default",
+		Generation:        3,
+		ChangedBy:         "oc-patch",
+		Action:            "Updated",
+		Resource:          "Deployment",
+		Namespace:         "openshift-ingress",
+		Name:              "router-default",
+		ChangedFields:     []string{"Spec.Replicas"},
+	}
+	testCases := []struct {
+		name        string
+		overrides   string
+		expectEvent string
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+		},
+		{
+			name:        "audit events",
+			overrides:   `{"auditEvents":true}`,
+			expectEvent: "Normal SpecChangeApplied Updated deployment openshift-ingress/router-default for generation 3 (changed by oc-patch): Spec.Replicas",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			r := &reconciler{recorder: recorder}
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec: operatorv1.IngressControllerSpec{
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+				},
+			}
+			r.recordAudit(ic, audit)
+			select {
+			case event := <-recorder.Events:
+				if event != tc.expectEvent {
+					t.Errorf("expected event %q, got %q", tc.expectEvent, event)
+				}
+			default:
+				if len(tc.expectEvent) != 0 {
+					t.Errorf("expected event %q, got none", tc.expectEvent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// canaryRouterReplicas splits the given total number of router replicas between
+// the stable and canary router deployments so that the canary deployment
+// receives approximately the given percentage of traffic.  The service
+// balances connections across the endpoints of both deployments, so the share
+// of traffic follows the share of replicas.  A weight strictly between 0 and
+// 100 always gives each deployment at least 1 replica so that both receive
+// traffic, which means the sum may exceed the total if the total is less than
+// 2.
+func canaryRouterReplicas(total, weight int32) (stable, canary int32) {
+	switch {
+	case weight <= 0:
+		return total, 0
+	case weight >= 100:
+		return 0, total
+	}
+	canary = int32(math.Round(float64(total) * float64(weight) / 100))
+	if canary < 1 {
+		canary = 1
+	}
+	stable = total - canary
+	if stable < 1 {
+		stable = 1
+		if total > 1 {
+			canary = total - 1
+		}
+	}
+	return stable, canary
+}
+
+// desiredCanaryRouterDeployment returns the canary router deployment for the
+// given desired stable router deployment.  The canary deployment is identical
+// to the stable one except that it runs the given image with the given number
+// of replicas and its pods have the canary router label, which keeps the two
+// deployments' selectors disjoint.  The router service selects pods of both
+// deployments.
+func desiredCanaryRouterDeployment(ci *operatorv1.IngressController, stable *appsv1.Deployment, image string, replicas int32) *appsv1.Deployment {
+	deployment := stable.DeepCopy()
+	name := controller.CanaryRouterDeploymentName(ci)
+	deployment.Name = name.Name
+	deployment.Namespace = name.Namespace
+	deployment.Spec.Replicas = &replicas
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{}}
+	if stable.Spec.Selector != nil {
+		for k, v := range stable.Spec.Selector.MatchLabels {
+			selector.MatchLabels[k] = v
+		}
+	}
+	selector.MatchLabels[controller.CanaryRouterLabel] = "true"
+	deployment.Spec.Selector = selector
+	if deployment.Spec.Template.Labels == nil {
+		deployment.Spec.Template.Labels = map[string]string{}
+	}
+	deployment.Spec.Template.Labels[controller.CanaryRouterLabel] = "true"
+
+	for i := range deployment.Spec.Template.Spec.Containers {
+		if deployment.Spec.Template.Spec.Containers[i].Name == "router" {
+			deployment.Spec.Template.Spec.Containers[i].Image = image
+		}
+	}
+	return deployment
+}
+
+// ensureCanaryRouterDeployment ensures that the canary router deployment
+// exists and matches the given desired deployment, or that it does not exist
+// if desired is nil.
+func (r *reconciler) ensureCanaryRouterDeployment(ci *operatorv1.IngressController, desired *appsv1.Deployment) error {
+	name := controller.CanaryRouterDeploymentName(ci)
+	current := &appsv1.Deployment{}
+	haveDepl := true
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get canary router deployment %s: %v", name, err)
+		}
+		haveDepl = false
+	}
+
+	switch {
+	case desired == nil && !haveDepl:
+		return nil
+	case desired == nil && haveDepl:
+		return r.deleteCanaryRouterDeployment(ci, current)
+	case !haveDepl:
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create canary router deployment %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created canary router deployment", "namespace", desired.Namespace, "name", desired.Name, "replicas", *desired.Spec.Replicas)
+		r.recorder.Eventf(ci, "Normal", "CreatedCanaryRouter", "Created canary router deployment %s/%s", desired.Namespace, desired.Name)
+		return nil
+	}
+
+	changed, updated := deploymentConfigChanged(current, desired)
+	if !changed {
+		return nil
+	}
+	audit := newAuditRecord(ci, "Updated", "Deployment", updated.Namespace, updated.Name, current, updated)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update canary router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated canary router deployment", "namespace", updated.Namespace, "name", updated.Name, "replicas", *updated.Spec.Replicas)
+	r.recordAudit(ci, audit)
+	return nil
+}
+
+// deleteCanaryRouterDeployment deletes the given canary router deployment.
+func (r *reconciler) deleteCanaryRouterDeployment(ci *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	if err := r.client.Delete(context.TODO(), deployment); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete canary router deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+	}
+	log.Info("deleted canary router deployment", "namespace", deployment.Namespace, "name", deployment.Name)
+	r.recorder.Eventf(ci, "Normal", "DeletedCanaryRouter", "Deleted canary router deployment %s/%s", deployment.Namespace, deployment.Name)
+	return nil
+}
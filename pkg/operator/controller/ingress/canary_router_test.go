@@ -0,0 +1,192 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestCanaryRouterReplicas verifies that canaryRouterReplicas maps a weight to
+// the expected split of replicas between the stable and canary deployments.
+func TestCanaryRouterReplicas(t *testing.T) {
+	testCases := []struct {
+		total, weight              int32
+		expectStable, expectCanary int32
+	}{
+		{total: 4, weight: 0, expectStable: 4, expectCanary: 0},
+		{total: 4, weight: 100, expectStable: 0, expectCanary: 4},
+		{total: 4, weight: 25, expectStable: 3, expectCanary: 1},
+		{total: 4, weight: 50, expectStable: 2, expectCanary: 2},
+		{total: 10, weight: 33, expectStable: 7, expectCanary: 3},
+		{total: 10, weight: 1, expectStable: 9, expectCanary: 1},
+		{total: 10, weight: 99, expectStable: 1, expectCanary: 9},
+		{total: 2, weight: 90, expectStable: 1, expectCanary: 1},
+		{total: 1, weight: 10, expectStable: 1, expectCanary: 1},
+		{total: 1, weight: 90, expectStable: 1, expectCanary: 1},
+	}
+	for _, tc := range testCases {
+		stable, canary := canaryRouterReplicas(tc.total, tc.weight)
+		if stable != tc.expectStable || canary != tc.expectCanary {
+			t.Errorf("total %d, weight %d: expected %d stable and %d canary replicas, got %d and %d", tc.total, tc.weight, tc.expectStable, tc.expectCanary, stable, canary)
+		}
+	}
+}
+
+// TestDesiredCanaryRouterDeployment verifies that the canary router deployment
+// runs the canary image with the canary replicas and that its selector is
+// disjoint from the stable deployment's.
+func TestDesiredCanaryRouterDeployment(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	stable, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+
+	canary := desiredCanaryRouterDeployment(ic, stable, "quay.io/openshift/router:canary", 2)
+	expectedName := controller.CanaryRouterDeploymentName(ic)
+	if canary.Name != expectedName.Name || canary.Namespace != expectedName.Namespace {
+		t.Errorf("expected name %s, got %s/%s", expectedName, canary.Namespace, canary.Name)
+	}
+	if canary.Spec.Replicas == nil || *canary.Spec.Replicas != 2 {
+		t.Errorf("expected 2 replicas, got %v", canary.Spec.Replicas)
+	}
+	if image := canary.Spec.Template.Spec.Containers[0].Image; image != "quay.io/openshift/router:canary" {
+		t.Errorf("expected canary image, got %q", image)
+	}
+	if image := stable.Spec.Template.Spec.Containers[0].Image; image != ingressControllerImage {
+		t.Errorf("expected stable deployment to keep image %q, got %q", ingressControllerImage, image)
+	}
+	if canary.Spec.Selector.MatchLabels[controller.CanaryRouterLabel] != "true" {
+		t.Errorf("expected canary selector to have label %s, got %v", controller.CanaryRouterLabel, canary.Spec.Selector.MatchLabels)
+	}
+	if _, ok := stable.Spec.Selector.MatchLabels[controller.CanaryRouterLabel]; ok {
+		t.Errorf("expected stable selector not to have label %s", controller.CanaryRouterLabel)
+	}
+	if canary.Spec.Template.Labels[controller.CanaryRouterLabel] != "true" {
+		t.Errorf("expected canary pod template to have label %s", controller.CanaryRouterLabel)
+	}
+	if v := canary.Spec.Template.Labels[controller.ControllerDeploymentLabel]; v != ic.Name {
+		t.Errorf("expected canary pods to keep label %s=%s so that the service selects them, got %q", controller.ControllerDeploymentLabel, ic.Name, v)
+	}
+}
+
+// TestSplitCanaryRouterReplicasRollingUpdateCounts verifies that the replicas
+// are split with the canary router deployment before the rolling update
+// parameters are converted into pod counts so that each deployment's counts
+// follow its own replicas.
+func TestSplitCanaryRouterReplicasRollingUpdateCounts(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Status.EndpointPublishingStrategy.Type = operatorv1.PrivateStrategyType
+	replicas := int32(4)
+	ic.Spec.Replicas = &replicas
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"canaryRouter":{"image":"canary","weight":25},"rollingUpdate":{"maxUnavailable":"50%","maxSurge":"25%","useIntegerCounts":true}}`)}
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stable, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+
+	canary := splitCanaryRouterReplicas(ic, overrides, stable)
+	if canary == nil {
+		t.Fatal("expected a canary router deployment")
+	}
+	for _, deployment := range []*appsv1.Deployment{stable, canary} {
+		if err := useIntegerRollingUpdateCountsForOverride(deployment, overrides.RollingUpdate); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// maxUnavailable and maxSurge are rounded down and up, respectively,
+	// for each deployment's replicas.  For all 4 replicas, maxUnavailable
+	// would be 2.
+	testCases := []struct {
+		deployment           *appsv1.Deployment
+		expectReplicas       int32
+		expectMaxUnavailable intstr.IntOrString
+		expectMaxSurge       intstr.IntOrString
+	}{
+		{stable, 3, intstr.FromInt(1), intstr.FromInt(1)},
+		{canary, 1, intstr.FromInt(0), intstr.FromInt(1)},
+	}
+	for _, tc := range testCases {
+		rollingUpdate := tc.deployment.Spec.Strategy.RollingUpdate
+		if *tc.deployment.Spec.Replicas != tc.expectReplicas {
+			t.Errorf("expected %s to have %d replicas, got %d", tc.deployment.Name, tc.expectReplicas, *tc.deployment.Spec.Replicas)
+		}
+		if *rollingUpdate.MaxUnavailable != tc.expectMaxUnavailable {
+			t.Errorf("expected %s to have maxUnavailable %s, got %s", tc.deployment.Name, tc.expectMaxUnavailable.String(), rollingUpdate.MaxUnavailable.String())
+		}
+		if *rollingUpdate.MaxSurge != tc.expectMaxSurge {
+			t.Errorf("expected %s to have maxSurge %s, got %s", tc.deployment.Name, tc.expectMaxSurge.String(), rollingUpdate.MaxSurge.String())
+		}
+	}
+}
+
+// TestEnsureCanaryRouterDeployment verifies that ensureCanaryRouterDeployment
+// creates, updates, and deletes the canary router deployment.
+func TestEnsureCanaryRouterDeployment(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	r := &reconciler{
+		client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+		recorder: record.NewFakeRecorder(10),
+	}
+	stable := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{controller.ControllerDeploymentLabel: "default"}},
+		},
+	}
+	stable.Spec.Template.Labels = map[string]string{controller.ControllerDeploymentLabel: "default"}
+	stable.Spec.Template.Spec.Containers = []corev1.Container{{Name: "router", Image: "stable"}}
+	name := controller.CanaryRouterDeploymentName(ic)
+
+	getReplicas := func() int32 {
+		t.Helper()
+		deployment := &appsv1.Deployment{}
+		if err := r.client.Get(context.TODO(), name, deployment); err != nil {
+			t.Fatalf("failed to get canary router deployment: %v", err)
+		}
+		return *deployment.Spec.Replicas
+	}
+
+	if err := r.ensureCanaryRouterDeployment(ic, desiredCanaryRouterDeployment(ic, stable, "canary", 1)); err != nil {
+		t.Fatalf("failed to create canary router deployment: %v", err)
+	}
+	if replicas := getReplicas(); replicas != 1 {
+		t.Errorf("expected 1 replica, got %d", replicas)
+	}
+
+	if err := r.ensureCanaryRouterDeployment(ic, desiredCanaryRouterDeployment(ic, stable, "canary", 3)); err != nil {
+		t.Fatalf("failed to update canary router deployment: %v", err)
+	}
+	if replicas := getReplicas(); replicas != 3 {
+		t.Errorf("expected 3 replicas, got %d", replicas)
+	}
+
+	if err := r.ensureCanaryRouterDeployment(ic, nil); err != nil {
+		t.Fatalf("failed to delete canary router deployment: %v", err)
+	}
+	if err := r.client.Get(context.TODO(), name, &appsv1.Deployment{}); !errors.IsNotFound(err) {
+		t.Errorf("expected canary router deployment to be deleted, got %v", err)
+	}
+	if err := r.ensureCanaryRouterDeployment(ic, nil); err != nil {
+		t.Errorf("expected no error when the canary router deployment is already absent, got %v", err)
+	}
+}
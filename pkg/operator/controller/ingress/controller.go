@@ -3,9 +3,12 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"net"
+	"reflect"
 	"regexp"
 	"regexp/syntax"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,12 +28,14 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	iov1 "github.com/openshift/api/operatoringress/v1"
+	routev1 "github.com/openshift/api/route/v1"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -45,22 +50,57 @@ import (
 
 const (
 	controllerName = "ingress_controller"
+	// defaultControllerName is the name of the controller that reconciles
+	// only the default ingresscontroller.
+	defaultControllerName = "ingress_controller_default"
 )
 
 // TODO: consider moving these to openshift/api
 const (
-	IngressControllerAdmittedConditionType                       = "Admitted"
-	IngressControllerPodsScheduledConditionType                  = "PodsScheduled"
-	IngressControllerDeploymentAvailableConditionType            = "DeploymentAvailable"
-	IngressControllerDeploymentReplicasMinAvailableConditionType = "DeploymentReplicasMinAvailable"
-	IngressControllerDeploymentReplicasAllAvailableConditionType = "DeploymentReplicasAllAvailable"
-	IngressControllerCanaryCheckSuccessConditionType             = "CanaryChecksSucceeding"
+	IngressControllerAdmittedConditionType                         = "Admitted"
+	IngressControllerPodsScheduledConditionType                    = "PodsScheduled"
+	IngressControllerDeploymentAvailableConditionType              = "DeploymentAvailable"
+	IngressControllerDeploymentReplicasMinAvailableConditionType   = "DeploymentReplicasMinAvailable"
+	IngressControllerDeploymentReplicasAllAvailableConditionType   = "DeploymentReplicasAllAvailable"
+	IngressControllerDeploymentProgressingConditionType            = "DeploymentProgressing"
+	IngressControllerCanaryCheckSuccessConditionType               = "CanaryChecksSucceeding"
+	IngressControllerDefaultBackendServiceMissingConditionType     = "DefaultBackendServiceMissing"
+	IngressControllerReplicasBelowRecommendedConditionType         = "ReplicasBelowRecommended"
+	IngressControllerEffectiveReplicasConditionType                = "EffectiveReplicas"
+	IngressControllerHostNetworkNodeIPsAvailableConditionType      = "HostNetworkNodeIPsAvailable"
+	IngressControllerDNSVerifiedConditionType                      = "DNSVerified"
+	IngressControllerServiceProvisionedConditionType               = "ServiceProvisioned"
+	IngressControllerCertificateValidConditionType                 = "CertificateValid"
+	IngressControllerDNSProviderConditionType                      = "DNSProvider"
+	IngressControllerRouterPodsReadyConditionType                  = "RouterPodsReady"
+	IngressControllerInsufficientNodesForAntiAffinityConditionType = "InsufficientNodesForAntiAffinity"
+	IngressControllerActiveConnectionsConditionType                = "ActiveConnections"
 
 	routerDefaultHeaderBufferSize           = 32768
 	routerDefaultHeaderBufferMaxRewriteSize = 8192
 	routerDefaultHostNetworkHTTPPort        = 80
 	routerDefaultHostNetworkHTTPSPort       = 443
 	routerDefaultHostNetworkStatsPort       = 1936
+
+	// Services behind load balancers should roll out new instances only after we are certain
+	// the new instance is part of rotation. This is set based on the highest value across all
+	// platforms, excluding custom load balancers like an F5, but our recommendation for these
+	// values for those should be indentical to the slowest cloud, AWS (which does not allow
+	// health checks to be more frequent than 10 seconds).
+	routerDefaultMinReadySeconds = (2 + /* max healthy checks required to be brought into rotation across all platforms */
+		1) * /* we could miss one */
+		10 /* the longest health check interval on any platform */
+
+	// routerDefaultProgressDeadlineSeconds is the number of seconds that a
+	// rolling update of the router deployment may make no progress before
+	// the deployment reports that its progress deadline is exceeded.
+	routerDefaultProgressDeadlineSeconds = 600
+
+	routerDefaultClientTimeout = 30 * time.Second
+	routerDefaultServerTimeout = 30 * time.Second
+	// grpcDefaultTimeout is the client and server timeout that the router
+	// uses in gRPC mode so that long-lived streams are not cut off.
+	grpcDefaultTimeout = 1 * time.Hour
 )
 
 var (
@@ -88,42 +128,219 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 		client:   mgr.GetClient(),
 		cache:    mgr.GetCache(),
 		recorder: mgr.GetEventRecorderFor(controllerName),
+		backoff:  newReconcileBackoff(config.MaxReconcileBackoff),
+
+		statusWebhookQueue: make(chan statusWebhookNotification, statusWebhookQueueSize),
 	}
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: reconciler})
 	if err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	// Reconcile the default ingresscontroller using a separate controller,
+	// which has its own queue and worker, so that its reconcile requests
+	// are processed ahead of other ingresscontrollers' requests when the
+	// queue is backed up.
+	defaultController, err := controller.New(defaultControllerName, mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+	isDefault := isDefaultIngressControllerRequest(config.Namespace)
+	isNotDefault := func(request reconcile.Request) bool { return !isDefault(request) }
+	// watchSource watches the sources that newSource returns using both
+	// controllers, each of which handles only the reconcile requests for
+	// its own ingresscontrollers.
+	watchSource := func(newSource func() source.Source, h handler.EventHandler, predicates ...predicate.Predicate) error {
+		if err := c.Watch(newSource(), filterRequests(h, isNotDefault), predicates...); err != nil {
+			return err
+		}
+		return defaultController.Watch(newSource(), filterRequests(h, isDefault), predicates...)
+	}
+	// watch watches objects of the given type in the manager's cache.
+	watch := func(obj client.Object, h handler.EventHandler, predicates ...predicate.Predicate) error {
+		return watchSource(func() source.Source {
+			return &source.Kind{Type: obj.DeepCopyObject().(client.Object)}
+		}, h, predicates...)
+	}
+	if err := watch(&operatorv1.IngressController{}, &handler.EnqueueRequestForObject{}); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
+	// Periodically report active connections for ingresscontrollers that
+	// request it.
+	if err := mgr.Add(manager.RunnableFunc(reconciler.pollActiveConnections)); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
+	// Send status webhook notifications in the background so that slow
+	// endpoints do not block reconciliation.
+	if err := mgr.Add(manager.RunnableFunc(reconciler.runStatusWebhookNotifier)); err != nil {
 		return nil, err
 	}
-	// Add watch for deleted pods specifically for ensuring ingress deletion.
-	if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, enqueueRequestForOwningIngressController(config.Namespace), predicate.Funcs{
-		CreateFunc:  func(e event.CreateEvent) bool { return false },
-		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
-		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+	if err := watch(&appsv1.Deployment{}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
+		return nil, err
+	}
+	if err := watch(&corev1.Service{}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
+		return nil, err
+	}
+	// Add watch for deleted pods specifically for ensuring ingress deletion,
+	// for changes to pod readiness so that the RouterPodsReady status
+	// condition is kept up to date, and for pods starting to shut down so
+	// that their drain state is reported.
+	if err := watch(&corev1.Pod{}, enqueueRequestForOwningIngressController(config.Namespace), predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, newPod := e.ObjectOld.(*corev1.Pod), e.ObjectNew.(*corev1.Pod)
+			return isPodReady(oldPod) != isPodReady(newPod) || routerPodDrainState(oldPod) != routerPodDrainState(newPod)
+		},
 		GenericFunc: func(e event.GenericEvent) bool { return false },
 	}); err != nil {
 		return nil, err
 	}
 	// add watch for changes in DNS config
-	if err := c.Watch(&source.Kind{Type: &configv1.DNS{}}, handler.EnqueueRequestsFromMapFunc(reconciler.ingressConfigToIngressController)); err != nil {
+	if err := watch(&configv1.DNS{}, handler.EnqueueRequestsFromMapFunc(reconciler.ingressConfigToIngressController)); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &iov1.DNSRecord{}}, &handler.EnqueueRequestForOwner{OwnerType: &operatorv1.IngressController{}}); err != nil {
+	if err := watch(&iov1.DNSRecord{}, &handler.EnqueueRequestForOwner{OwnerType: &operatorv1.IngressController{}}); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &configv1.Ingress{}}, handler.EnqueueRequestsFromMapFunc(reconciler.ingressConfigToIngressController)); err != nil {
+	if err := watch(&configv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(reconciler.ingressConfigToIngressController)); err != nil {
+		return nil, err
+	}
+	// Watch configmaps in the operand namespace so that changes to a
+	// maintenance page are rolled out.
+	if err := watch(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(reconciler.maintenancePageConfigMapToIngressController), predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetNamespace() == operatorcontroller.DefaultOperandNamespace
+	})); err != nil {
+		return nil, err
+	}
+	// Watch nodes so that the replicas of ingresscontrollers whose replicas
+	// follow the node count are kept up to date.
+	if err := watch(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(reconciler.nodeToIngressControllers), predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return true },
+		DeleteFunc: func(e event.DeleteEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, newNode := e.ObjectOld.(*corev1.Node), e.ObjectNew.(*corev1.Node)
+			return !reflect.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				!reflect.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+				oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+				isNodeReady(oldNode) != isNodeReady(newNode)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, err
+	}
+	// Watch for routes being admitted, unadmitted, or rejected so that
+	// admitted-by annotations are kept up to date and rejections are
+	// reported, and for routes being created, deleted, or relabeled so
+	// that route quotas are enforced.  Routes are in users' namespaces,
+	// which the manager's cache does not include, so they are watched
+	// using a separate cache for all namespaces.
+	routeCache, err := newRouteCache(mgr)
+	if err != nil {
+		return nil, err
+	}
+	reconciler.routeCache = routeCache
+	if err := watchSource(func() source.Source {
+		return newRouteSource(routeCache)
+	}, handler.EnqueueRequestsFromMapFunc(reconciler.routeToIngressControllers), routePredicate); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// routeAdmittedByIndex is the name of the route cache's index of the
+// ingresscontroller names in routes' admitted-by annotations.
+const routeAdmittedByIndex = "routeAdmittedBy"
+
+// newRouteCache returns a cache of routes in all namespaces, which indexes
+// routes by the names in their admitted-by annotations, and adds it to the
+// manager so that it is started with the manager.
+func newRouteCache(mgr manager.Manager) (cache.Cache, error) {
+	routeCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route cache: %w", err)
+	}
+	if err := routeCache.IndexField(context.Background(), &routev1.Route{}, routeAdmittedByIndex, routeAdmittedByIndexFunc); err != nil {
+		return nil, fmt.Errorf("failed to index routes by admitted-by annotation: %w", err)
+	}
+	if err := mgr.Add(routeCache); err != nil {
+		return nil, fmt.Errorf("failed to add route cache to manager: %w", err)
+	}
+	return routeCache, nil
+}
+
+// routeAdmittedByIndexFunc returns the ingresscontroller names in the given
+// route's admitted-by annotation.
+func routeAdmittedByIndexFunc(o client.Object) []string {
+	return routeAdmittedByNames(o.GetAnnotations()[manifests.RouteAdmittedByAnnotation]).List()
+}
+
+// newRouteSource returns a source of events for routes in the given cache.
+func newRouteSource(routeCache cache.Cache) source.Source {
+	return source.NewKindWithCache(&routev1.Route{}, routeCache)
+}
+
+// routePredicate filters route events down to routes being created or deleted
+// and to updates that change a route's status, labels, or admitted-by
+// annotation.
+var routePredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool { return true },
+	DeleteFunc: func(e event.DeleteEvent) bool { return true },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldRoute, newRoute := e.ObjectOld.(*routev1.Route), e.ObjectNew.(*routev1.Route)
+		return !reflect.DeepEqual(oldRoute.Status.Ingress, newRoute.Status.Ingress) ||
+			!reflect.DeepEqual(oldRoute.Labels, newRoute.Labels) ||
+			oldRoute.Annotations[manifests.RouteAdmittedByAnnotation] != newRoute.Annotations[manifests.RouteAdmittedByAnnotation]
+	},
+	GenericFunc: func(e event.GenericEvent) bool { return false },
+}
+
+// routeToIngressControllers returns reconcile requests for the
+// ingresscontrollers that have the annotateAdmittedRoutes or
+// reportRouteRejections unsupported config override and that have admitted or
+// rejected the given route or are listed in its admitted-by annotation, and for
+// the ingresscontrollers that have the routeQuota unsupported config override.
+func (r *reconciler) routeToIngressControllers(o client.Object) []reconcile.Request {
+	route, ok := o.(*routev1.Route)
+	if !ok {
+		return nil
+	}
+	var requests []reconcile.Request
+	quotaNames := sets.NewString()
+	controllers := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.Background(), controllers, client.InNamespace(r.config.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for route", "namespace", route.Namespace, "name", route.Name)
+	}
+	for i := range controllers.Items {
+		ic := &controllers.Items[i]
+		if overrides, err := getUnsupportedConfigOverrides(ic); err != nil || overrides.RouteQuota == nil {
+			continue
+		}
+		quotaNames.Insert(ic.Name)
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	names := routeAdmittedByNames(route.Annotations[manifests.RouteAdmittedByAnnotation])
+	for i := range route.Status.Ingress {
+		names.Insert(route.Status.Ingress[i].RouterName)
+	}
+	for _, name := range names.Difference(quotaNames).List() {
+		ic := &operatorv1.IngressController{}
+		key := types.NamespacedName{Namespace: r.config.Namespace, Name: name}
+		if err := r.cache.Get(context.Background(), key, ic); err != nil {
+			continue
+		}
+		if overrides, err := getUnsupportedConfigOverrides(ic); err != nil || !(overrides.AnnotateAdmittedRoutes || overrides.ReportRouteRejections) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
 func (r *reconciler) ingressConfigToIngressController(o client.Object) []reconcile.Request {
 	var requests []reconcile.Request
 	controllers := &operatorv1.IngressControllerList{}
@@ -178,6 +395,20 @@ func enqueueRequestForOwningIngressController(namespace string) handler.EventHan
 type Config struct {
 	Namespace              string
 	IngressControllerImage string
+	// FieldManager is the field manager name that the operator uses when
+	// it applies router deployments and services using server-side apply.
+	// If it is empty, "ingress-operator" is used.
+	FieldManager string
+	// MaxReconcileBackoff is the maximum delay before the operator
+	// reconciles an ingresscontroller again after repeated failures to
+	// reconcile it.  Zero disables the backoff, in which case the operator
+	// retries after the delay that each failure requests.
+	MaxReconcileBackoff time.Duration
+	// Finalizer is the finalizer that the operator adds to
+	// ingresscontrollers to block their deletion until it has cleaned up
+	// their resources.  If it is empty,
+	// manifests.IngressControllerFinalizer is used.
+	Finalizer string
 }
 
 // reconciler handles the actual ingress reconciliation logic in response to
@@ -187,6 +418,27 @@ type reconciler struct {
 	client   client.Client
 	cache    cache.Cache
 	recorder record.EventRecorder
+
+	// routeCache is a cache of routes in all namespaces, which the
+	// manager's cache does not include.
+	routeCache cache.Cache
+
+	// backoff tracks consecutive failures to reconcile each
+	// ingresscontroller.
+	backoff *reconcileBackoff
+
+	// scrapeActiveConnections scrapes a router pod's active connections.
+	// If it is nil, scrapeRouterActiveConnections is used.
+	scrapeActiveConnections activeConnectionsScraper
+
+	// statusWebhookQueue holds status webhook notifications until
+	// runStatusWebhookNotifier sends them.
+	statusWebhookQueue chan statusWebhookNotification
+
+	// reportedRouteRejections maps a key identifying an ingresscontroller
+	// and a route that it rejected to the rejection that the operator last
+	// reported using an event so that each rejection is reported once.
+	reportedRouteRejections sync.Map
 }
 
 // admissionRejection is an error type for ingresscontroller admission
@@ -215,6 +467,7 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 			// stale queue entries (or something edge triggering from a related
 			// resource that got deleted async).
 			log.Info("ingresscontroller not found; reconciliation will be skipped", "request", request)
+			r.backoff.succeeded(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, fmt.Errorf("failed to get ingresscontroller %q: %v", request, err)
@@ -290,16 +543,20 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	// The ingresscontroller is safe to process, so ensure it.
+	// The ingresscontroller is safe to process, so ensure it.  If ensuring
+	// it fails repeatedly, back off so that a persistent failure does not
+	// cause reconciliation to hot-loop.
 	if err := r.ensureIngressController(ingress, dnsConfig, infraConfig, platformStatus, ingressConfig, apiConfig, networkConfig); err != nil {
 		switch e := err.(type) {
 		case retryable.Error:
-			log.Error(e, "got retryable error; requeueing", "after", e.After())
-			return reconcile.Result{RequeueAfter: e.After()}, nil
+			after := r.backoff.failed(request.NamespacedName, e.After())
+			log.Error(e, "got retryable error; requeueing", "after", after)
+			return reconcile.Result{RequeueAfter: after}, nil
 		default:
 			return reconcile.Result{}, err
 		}
 	}
+	r.backoff.succeeded(request.NamespacedName)
 	return reconcile.Result{}, nil
 }
 
@@ -317,7 +574,7 @@ func (r *reconciler) admit(current *operatorv1.IngressController, ingressConfig
 	// get the default from the APIServer config (which is assumed to be
 	// valid).
 
-	if err := r.validate(updated); err != nil {
+	if err := r.validate(updated, ingressConfig, platformStatus); err != nil {
 		switch err := err.(type) {
 		case *admissionRejection:
 			updated.Status.Conditions = MergeConditions(updated.Status.Conditions, operatorv1.OperatorCondition{
@@ -346,6 +603,9 @@ func (r *reconciler) admit(current *operatorv1.IngressController, ingressConfig
 	if !manageDNSForDomain(updated.Status.Domain, platformStatus, dnsConfig) {
 		r.recorder.Eventf(updated, "Warning", "DomainNotMatching", fmt.Sprintf("Domain [%s] of ingresscontroller does not match the baseDomain [%s] of the cluster DNS config, so DNS management is not supported.", updated.Status.Domain, dnsConfig.Spec.BaseDomain))
 	}
+	for _, warning := range tuningOptionWarnings(updated) {
+		r.recorder.Event(updated, "Warning", "ConflictingTuningOptions", warning)
+	}
 
 	if !IngressStatusesEqual(current.Status, updated.Status) {
 		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
@@ -415,6 +675,7 @@ func setDefaultPublishingStrategy(ic *operatorv1.IngressController, platformStat
 				Scope: operatorv1.ExternalLoadBalancer,
 			}
 		}
+		setDefaultProviderParameters(effectiveStrategy.LoadBalancer, platformStatus)
 	case operatorv1.NodePortServiceStrategyType:
 		if effectiveStrategy.NodePort == nil {
 			effectiveStrategy.NodePort = &operatorv1.NodePortStrategy{}
@@ -469,6 +730,13 @@ func setDefaultPublishingStrategy(ic *operatorv1.IngressController, platformStat
 				changed = true
 			}
 
+			// Resolve the provider parameters of a status that was
+			// published before the operator computed their defaults.
+			if statusLB.ProviderParameters == nil && specLB.ProviderParameters != nil {
+				statusLB.ProviderParameters = specLB.ProviderParameters.DeepCopy()
+				changed = true
+			}
+
 			// Detect changes to provider-specific parameters.
 			// Currently the only platforms with configurable
 			// provider-specific parameters are AWS and GCP.
@@ -478,9 +746,34 @@ func setDefaultPublishingStrategy(ic *operatorv1.IngressController, platformStat
 			}
 			switch lbType {
 			case operatorv1.AWSLoadBalancerProvider:
-				// The only provider parameter that is supported
-				// for AWS is the connection idle timeout for
-				// classic ELBs.
+				// Detect changes to the AWS load balancer type.
+				// Changing the type requires recreating the
+				// service load-balancer;
+				// computeIngressProgressingCondition reports
+				// the pending change until the service is
+				// recreated.
+				specAWSType, statusAWSType := operatorv1.AWSClassicLoadBalancer, operatorv1.AWSClassicLoadBalancer
+				if specLB.ProviderParameters.AWS != nil && len(specLB.ProviderParameters.AWS.Type) != 0 {
+					specAWSType = specLB.ProviderParameters.AWS.Type
+				}
+				if statusLB.ProviderParameters != nil && statusLB.ProviderParameters.AWS != nil && len(statusLB.ProviderParameters.AWS.Type) != 0 {
+					statusAWSType = statusLB.ProviderParameters.AWS.Type
+				}
+				if specAWSType != statusAWSType {
+					if statusLB.ProviderParameters == nil {
+						statusLB.ProviderParameters = &operatorv1.ProviderLoadBalancerParameters{}
+					}
+					statusLB.ProviderParameters.Type = operatorv1.AWSLoadBalancerProvider
+					if statusLB.ProviderParameters.AWS == nil {
+						statusLB.ProviderParameters.AWS = &operatorv1.AWSLoadBalancerParameters{}
+					}
+					statusLB.ProviderParameters.AWS.Type = specAWSType
+					changed = true
+				}
+
+				// The only other provider parameter that is
+				// supported for AWS is the connection idle
+				// timeout for classic ELBs.
 				var specIdleTimeout, statusIdleTimeout metav1.Duration
 				if specLB.ProviderParameters != nil && specLB.ProviderParameters.AWS != nil && specLB.ProviderParameters.AWS.ClassicLoadBalancerParameters != nil {
 					specIdleTimeout = specLB.ProviderParameters.AWS.ClassicLoadBalancerParameters.ConnectionIdleTimeout
@@ -595,6 +888,34 @@ func setDefaultPublishingStrategy(ic *operatorv1.IngressController, platformStat
 	return false
 }
 
+// setDefaultProviderParameters sets the provider-specific parameters of the
+// given load balancer strategy that the operator computes for the given
+// platform if the strategy omits them, so that the ingresscontroller's status
+// shows the parameters that are in effect.  Only AWS and GCP have provider
+// parameters, and on AWS, a Classic load balancer is used by default.
+func setDefaultProviderParameters(lb *operatorv1.LoadBalancerStrategy, platformStatus *configv1.PlatformStatus) {
+	var providerType operatorv1.LoadBalancerProviderType
+	switch platformStatus.Type {
+	case configv1.AWSPlatformType:
+		providerType = operatorv1.AWSLoadBalancerProvider
+	case configv1.GCPPlatformType:
+		providerType = operatorv1.GCPLoadBalancerProvider
+	default:
+		return
+	}
+	if lb.ProviderParameters == nil {
+		lb.ProviderParameters = &operatorv1.ProviderLoadBalancerParameters{Type: providerType}
+	}
+	if lb.ProviderParameters.Type == operatorv1.AWSLoadBalancerProvider {
+		if lb.ProviderParameters.AWS == nil {
+			lb.ProviderParameters.AWS = &operatorv1.AWSLoadBalancerParameters{}
+		}
+		if len(lb.ProviderParameters.AWS.Type) == 0 {
+			lb.ProviderParameters.AWS.Type = operatorv1.AWSClassicLoadBalancer
+		}
+	}
+}
+
 // tlsProfileSpecForIngressController returns a TLS profile spec based on either
 // the profile specified by the given ingresscontroller, the profile specified
 // by the APIServer config if the ingresscontroller does not specify one, or the
@@ -643,13 +964,17 @@ func tlsProfileSpecForSecurityProfile(profile *configv1.TLSSecurityProfile) *con
 // returns an error value, which will have a non-nil value of type
 // admissionRejection if the ingresscontroller is invalid, or a non-nil value of
 // a different type if validation could not be completed.
-func (r *reconciler) validate(ic *operatorv1.IngressController) error {
+func (r *reconciler) validate(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress, platformStatus *configv1.PlatformStatus) error {
 	var errors []error
 
 	ingresses := &operatorv1.IngressControllerList{}
 	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.config.Namespace)); err != nil {
 		return fmt.Errorf("failed to list ingresscontrollers: %v", err)
 	}
+	nodes := &corev1.NodeList{}
+	if err := r.cache.List(context.TODO(), nodes); err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
 
 	if err := validateDomain(ic); err != nil {
 		errors = append(errors, err)
@@ -666,6 +991,48 @@ func (r *reconciler) validate(ic *operatorv1.IngressController) error {
 	if err := validateClientTLS(ic); err != nil {
 		errors = append(errors, err)
 	}
+	if err := validateTunnelTimeout(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateSyslogDestination(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateCaptureHTTPHeaders(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateForwardedHeaderPolicy(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateUniqueIdHeader(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateUnsupportedConfigOverrides(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateHTTP2MaxConcurrentStreams(ic, ingressConfig); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateGRPCMode(ic, ingressConfig); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateALPNProtocols(ic, ingressConfig); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateTuningTimeouts(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateThreadCount(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateHealthCheckInterval(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateTuningOptionConflicts(ic); err != nil {
+		errors = append(errors, err)
+	}
+	if err := validateLoadBalancerZones(ic, platformStatus, nodes.Items); err != nil {
+		errors = append(errors, err)
+	}
 	if err := utilerrors.NewAggregate(errors); err != nil {
 		return &admissionRejection{err.Error()}
 	}
@@ -788,6 +1155,213 @@ func validateHTTPHeaderBufferValues(ic *operatorv1.IngressController) error {
 	return nil
 }
 
+// validateTunnelTimeout validates the given ingresscontroller's tunnel
+// timeout, if it specifies one.  The tunnel timeout applies to long-lived
+// connections such as WebSockets once they have been upgraded, so it must not
+// be shorter than the client or server timeout.
+func validateTunnelTimeout(ic *operatorv1.IngressController) error {
+	tuning := ic.Spec.TuningOptions
+	if tuning.TunnelTimeout == nil || tuning.TunnelTimeout.Duration <= 0 {
+		return nil
+	}
+	tunnelTimeout := tuning.TunnelTimeout.Duration
+
+	// ClientTimeout and ServerTimeout are optional fields.  Substitute
+	// the default values used by the router when either field is empty.
+	clientTimeout := routerDefaultClientTimeout
+	if tuning.ClientTimeout != nil && tuning.ClientTimeout.Duration > 0 {
+		clientTimeout = tuning.ClientTimeout.Duration
+	}
+	serverTimeout := routerDefaultServerTimeout
+	if tuning.ServerTimeout != nil && tuning.ServerTimeout.Duration > 0 {
+		serverTimeout = tuning.ServerTimeout.Duration
+	}
+
+	var errs []error
+	if tunnelTimeout < clientTimeout {
+		errs = append(errs, fmt.Errorf("invalid spec.tuningOptions.tunnelTimeout: tunnelTimeout (%s) "+
+			"must not be less than clientTimeout (%s)", tunnelTimeout, clientTimeout))
+	}
+	if tunnelTimeout < serverTimeout {
+		errs = append(errs, fmt.Errorf("invalid spec.tuningOptions.tunnelTimeout: tunnelTimeout (%s) "+
+			"must not be less than serverTimeout (%s)", tunnelTimeout, serverTimeout))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateForwardedHeaderPolicy returns an error if the given
+// ingresscontroller specifies an unknown spec.httpHeaders.forwardedHeaderPolicy.
+// An empty policy means "Append".
+func validateForwardedHeaderPolicy(ic *operatorv1.IngressController) error {
+	if ic.Spec.HTTPHeaders == nil {
+		return nil
+	}
+	switch policy := ic.Spec.HTTPHeaders.ForwardedHeaderPolicy; policy {
+	case "", operatorv1.AppendHTTPHeaderPolicy, operatorv1.ReplaceHTTPHeaderPolicy, operatorv1.IfNoneHTTPHeaderPolicy, operatorv1.NeverHTTPHeaderPolicy:
+		return nil
+	default:
+		return fmt.Errorf("invalid spec.httpHeaders.forwardedHeaderPolicy %q: must be %q, %q, %q, or %q", policy, operatorv1.AppendHTTPHeaderPolicy, operatorv1.ReplaceHTTPHeaderPolicy, operatorv1.IfNoneHTTPHeaderPolicy, operatorv1.NeverHTTPHeaderPolicy)
+	}
+}
+
+// httpTokenRegexp matches HTTP header names, which are tokens as defined in
+// RFC 2616 section 2.2.
+var httpTokenRegexp = regexp.MustCompile("^[-!#$%&'*+.0-9A-Z^_`a-z|~]+$")
+
+// haproxyLogVariables is the set of HAProxy log format variables that the
+// unique id header format may use.
+var haproxyLogVariables = sets.NewString(
+	"o", "B", "CC", "CS", "H", "HM", "HP", "HPO", "HQ", "HU", "HV", "ID",
+	"ST", "T", "Ta", "Tc", "Td", "Th", "Ti", "Tq", "TR", "Tr", "Ts", "Tt",
+	"Tu", "Tw", "U", "ac", "b", "bc", "bi", "bp", "bq", "ci", "cp", "f",
+	"fc", "fi", "fp", "ft", "hr", "hrl", "hs", "hsl", "lc", "ms", "pid", "r",
+	"rc", "rt", "s", "sc", "si", "sp", "sq", "sslc", "sslv", "t", "tr",
+	"trg", "trl", "ts", "tsc",
+)
+
+// validateUniqueIdHeader returns an error if the given ingresscontroller
+// specifies a unique id header name that is not a valid HTTP header name or a
+// unique id header format that HAProxy does not support.
+func validateUniqueIdHeader(ic *operatorv1.IngressController) error {
+	if ic.Spec.HTTPHeaders == nil {
+		return nil
+	}
+	uniqueId := ic.Spec.HTTPHeaders.UniqueId
+	var errs []error
+	if len(uniqueId.Name) != 0 && !httpTokenRegexp.MatchString(uniqueId.Name) {
+		errs = append(errs, fmt.Errorf("invalid spec.httpHeaders.uniqueId.name %q: must be a valid HTTP header name", uniqueId.Name))
+	}
+	if err := validateLogFormat(uniqueId.Format); err != nil {
+		errs = append(errs, fmt.Errorf("invalid spec.httpHeaders.uniqueId.format %q: %v", uniqueId.Format, err))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateLogFormat returns an error if the given HAProxy log format has a
+// control character, an incomplete "%" sequence, an unknown flag, or an
+// unknown variable.  Sample fetch expressions ("%[...]") are not checked.
+func validateLogFormat(format string) error {
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c < 0x20 || c == 0x7f {
+			return fmt.Errorf("control characters are not allowed")
+		}
+		if c != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		if i < len(format) && format[i] == '{' {
+			end := strings.IndexByte(format[i:], '}')
+			if end < 0 {
+				return fmt.Errorf("unterminated flags at offset %d", i)
+			}
+			for _, flag := range strings.Split(format[i+1:i+end], ",") {
+				switch strings.TrimLeft(flag, "+-") {
+				case "Q", "X", "E":
+				default:
+					return fmt.Errorf("unknown flag %q", flag)
+				}
+			}
+			i += end + 1
+		}
+		if i < len(format) && format[i] == '[' {
+			end := strings.IndexByte(format[i:], ']')
+			if end <= 1 {
+				return fmt.Errorf("unterminated or empty sample fetch expression at offset %d", i)
+			}
+			i += end
+			continue
+		}
+		start := i
+		for i < len(format) && (format[i] >= 'a' && format[i] <= 'z' || format[i] >= 'A' && format[i] <= 'Z') {
+			i++
+		}
+		if start == i {
+			return fmt.Errorf("missing variable after %%")
+		}
+		if name := format[start:i]; !haproxyLogVariables.Has(name) {
+			return fmt.Errorf("unknown variable %%%s", name)
+		}
+		i--
+	}
+	return nil
+}
+
+// validateSyslogDestination validates the given ingresscontroller's syslog
+// access logging destination, if it has one.  The address must be an IP
+// address or a host name, and together with the port it must form a valid
+// endpoint.
+func validateSyslogDestination(ic *operatorv1.IngressController) error {
+	if ic.Spec.Logging == nil || ic.Spec.Logging.Access == nil {
+		return nil
+	}
+	destination := ic.Spec.Logging.Access.Destination
+	if destination.Type != operatorv1.SyslogLoggingDestinationType {
+		return nil
+	}
+	syslog := destination.Syslog
+	if syslog == nil {
+		return fmt.Errorf("spec.logging.access.destination.syslog must be specified when spec.logging.access.destination.type is %q", operatorv1.SyslogLoggingDestinationType)
+	}
+
+	var errs []error
+	if net.ParseIP(syslog.Address) == nil {
+		if msgs := validation.IsDNS1123Subdomain(syslog.Address); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.logging.access.destination.syslog.address %q: must be an IP address or a host name: %s", syslog.Address, strings.Join(msgs, ", ")))
+		}
+	}
+	if syslog.Port < 1 || syslog.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid spec.logging.access.destination.syslog.port %d: must be between 1 and 65535", syslog.Port))
+	}
+	if syslog.MaxLength != 0 && (syslog.MaxLength < 480 || syslog.MaxLength > 4096) {
+		errs = append(errs, fmt.Errorf("invalid spec.logging.access.destination.syslog.maxLength %d: must be between 480 and 4096", syslog.MaxLength))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// maxCaptureHTTPHeaders is the maximum total number of request and response
+// headers that an ingresscontroller may capture.  HAProxy allocates a buffer
+// for each captured header in every session, so the operator bounds the number
+// of captures to bound the router's memory usage.
+const maxCaptureHTTPHeaders = 20
+
+// validateCaptureHTTPHeaders returns an error if the given ingresscontroller
+// captures an HTTP header with an invalid name or a non-positive maximum
+// length, or captures more than maxCaptureHTTPHeaders headers.  Captured
+// headers are only configurable as part of access logging, so they cannot be
+// specified when access logging is disabled.
+func validateCaptureHTTPHeaders(ic *operatorv1.IngressController) error {
+	if ic.Spec.Logging == nil || ic.Spec.Logging.Access == nil {
+		return nil
+	}
+	captureHeaders := ic.Spec.Logging.Access.HTTPCaptureHeaders
+	var errs []error
+	for _, captures := range []struct {
+		field   string
+		headers []operatorv1.IngressControllerCaptureHTTPHeader
+	}{
+		{"request", captureHeaders.Request},
+		{"response", captureHeaders.Response},
+	} {
+		field := captures.field
+		for i, header := range captures.headers {
+			if !httpTokenRegexp.MatchString(header.Name) {
+				errs = append(errs, fmt.Errorf("invalid spec.logging.access.httpCaptureHeaders.%s[%d].name %q: must be a valid HTTP header name", field, i, header.Name))
+			}
+			if header.MaxLength < 1 {
+				errs = append(errs, fmt.Errorf("invalid spec.logging.access.httpCaptureHeaders.%s[%d].maxLength %d: must be positive", field, i, header.MaxLength))
+			}
+		}
+	}
+	if n := len(captureHeaders.Request) + len(captureHeaders.Response); n > maxCaptureHTTPHeaders {
+		errs = append(errs, fmt.Errorf("invalid spec.logging.access.httpCaptureHeaders: %d headers are specified, but at most %d may be captured", n, maxCaptureHTTPHeaders))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 // validateClientTLS validates the given ingresscontroller's client TLS
 // configuration.
 func validateClientTLS(ic *operatorv1.IngressController) error {
@@ -806,11 +1380,23 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController)
 	errs := []error{}
 
 	// Delete the wildcard DNS record, and block ingresscontroller finalization
-	// until the dnsrecord has been finalized.
-	if err := r.deleteWildcardDNSRecord(ingress); err != nil {
+	// until the dnsrecord has been finalized.  If the ingresscontroller
+	// specifies a grace period, retain the dnsrecord instead so that an
+	// ingresscontroller with the same name can adopt it.
+	gracePeriod := dnsRecordDeletionGracePeriod(ingress)
+	if gracePeriod > 0 {
+		if _, err := r.retainWildcardDNSRecord(ingress, gracePeriod, time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to retain wildcard dnsrecord for ingress %s/%s: %v", ingress.Namespace, ingress.Name, err))
+		}
+	} else if err := r.deleteWildcardDNSRecord(ingress); err != nil {
 		errs = append(errs, fmt.Errorf("failed to delete wildcard dnsrecord for ingress %s/%s: %v", ingress.Namespace, ingress.Name, err))
 	}
-	haveRec, _, err := r.currentWildcardDNSRecord(ingress)
+	haveRec, rec, err := r.currentWildcardDNSRecord(ingress)
+	if haveRec && gracePeriod > 0 {
+		// A retained dnsrecord does not block finalization.
+		_, retained := rec.Annotations[manifests.DNSRecordRetainUntilAnnotation]
+		haveRec = !retained
+	}
 	switch {
 	case err != nil:
 		errs = append(errs, fmt.Errorf("failed to get current wildcard dnsrecord for ingress %s/%s: %v", ingress.Namespace, ingress.Name, err))
@@ -820,6 +1406,9 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController)
 		// The router deployment manages the load-balancer service
 		// which is used to find the hosted zone id. Delete the deployment
 		// only when the dnsrecord does not exist.
+		if err := r.releaseSharedLoadBalancerServices(ingress, ""); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release shared load balancer service for ingress %s/%s: %v", ingress.Namespace, ingress.Name, err))
+		}
 		if err := r.ensureRouterDeleted(ingress); err != nil {
 			errs = append(errs, fmt.Errorf("failed to delete deployment for ingress %s/%s: %v", ingress.Namespace, ingress.Name, err))
 		}
@@ -849,20 +1438,62 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController)
 	// Delete the metrics related to the ingresscontroller
 	DeleteIngressControllerConditionsMetric(ingress)
 	DeleteActiveNLBMetrics(ingress)
+	DeleteDefaultCertificateExpiryMetric(ingress)
 
 	if len(errs) == 0 {
-		// Remove the ingresscontroller finalizer.
-		if slice.ContainsString(ingress.Finalizers, manifests.IngressControllerFinalizer) {
-			updated := ingress.DeepCopy()
-			updated.Finalizers = slice.RemoveString(updated.Finalizers, manifests.IngressControllerFinalizer)
-			if err := r.client.Update(context.TODO(), updated); err != nil {
-				errs = append(errs, fmt.Errorf("failed to remove finalizer from ingresscontroller %s: %v", ingress.Name, err))
-			}
+		if err := r.removeIngressControllerFinalizer(ingress); err != nil {
+			errs = append(errs, err)
 		}
 	}
 	return retryable.NewMaybeRetryableAggregate(errs)
 }
 
+// finalizer returns the finalizer that the operator uses to block deletion of
+// ingresscontrollers until it has cleaned up their resources.
+func (r *reconciler) finalizer() string {
+	if len(r.config.Finalizer) != 0 {
+		return r.config.Finalizer
+	}
+	return manifests.IngressControllerFinalizer
+}
+
+// ensureIngressControllerFinalizer adds the operator's finalizer to the given
+// ingresscontroller if it does not already have it.  Returns the current
+// ingresscontroller and an error value.
+func (r *reconciler) ensureIngressControllerFinalizer(ci *operatorv1.IngressController) (*operatorv1.IngressController, error) {
+	finalizer := r.finalizer()
+	if slice.ContainsString(ci.Finalizers, finalizer) {
+		return ci, nil
+	}
+	updated := ci.DeepCopy()
+	updated.Finalizers = append(updated.Finalizers, finalizer)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return ci, fmt.Errorf("failed to update finalizers: %v", err)
+	}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, updated); err != nil {
+		return ci, fmt.Errorf("failed to get ingresscontroller: %v", err)
+	}
+	return updated, nil
+}
+
+// removeIngressControllerFinalizer removes the operator's finalizer from the
+// given ingresscontroller.  The default finalizer is removed as well so that
+// ingresscontrollers that the operator finalized before its finalizer was
+// configured can be deleted.
+func (r *reconciler) removeIngressControllerFinalizer(ingress *operatorv1.IngressController) error {
+	updated := ingress.DeepCopy()
+	for _, finalizer := range []string{r.finalizer(), manifests.IngressControllerFinalizer} {
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, finalizer)
+	}
+	if len(updated.Finalizers) == len(ingress.Finalizers) {
+		return nil
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to remove finalizer from ingresscontroller %s: %v", ingress.Name, err)
+	}
+	return nil
+}
+
 // ensureIngressController ensures all necessary router resources exist for a
 // given ingresscontroller.  Any error values are collected into either a
 // retryable.Error value, if any of the error values are retryable, or else an
@@ -870,16 +1501,14 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController)
 func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure, platformStatus *configv1.PlatformStatus, ingressConfig *configv1.Ingress, apiConfig *configv1.APIServer, networkConfig *configv1.Network) error {
 	// Before doing anything at all with the controller, ensure it has a finalizer
 	// so we can clean up later.
-	if !slice.ContainsString(ci.Finalizers, manifests.IngressControllerFinalizer) {
-		updated := ci.DeepCopy()
-		updated.Finalizers = append(updated.Finalizers, manifests.IngressControllerFinalizer)
-		if err := r.client.Update(context.TODO(), updated); err != nil {
-			return fmt.Errorf("failed to update finalizers: %v", err)
-		}
-		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, updated); err != nil {
-			return fmt.Errorf("failed to get ingresscontroller: %v", err)
-		}
-		ci = updated
+	ci, err := r.ensureIngressControllerFinalizer(ci)
+	if err != nil {
+		return err
+	}
+
+	ci, err = r.syncEffectiveTuningOptions(ci)
+	if err != nil {
+		return fmt.Errorf("failed to sync effective tuning options: %w", err)
 	}
 
 	if _, _, err := r.ensureClusterRole(); err != nil {
@@ -907,6 +1536,14 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		errs = append(errs, err)
 	}
 
+	if err := r.ensureRouterNamespaceEgressIPs(ci, networkConfig); err != nil {
+		errs = append(errs, fmt.Errorf("failed to ensure router namespace egress IPs: %w", err))
+	}
+
+	if err := r.ensurePrometheusRule(ci); err != nil {
+		errs = append(errs, fmt.Errorf("failed to ensure prometheusrule: %w", err))
+	}
+
 	var haveClientCAConfigmap bool
 	clientCAConfigmap := &corev1.ConfigMap{}
 	if len(ci.Spec.ClientTLS.ClientCA.Name) != 0 {
@@ -918,6 +1555,23 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		haveClientCAConfigmap = true
 	}
 
+	// The default backend service is managed by the user, so a missing
+	// service is reported in status rather than blocking the deployment.
+	var defaultBackendService *corev1.Service
+	if overrides, err := getUnsupportedConfigOverrides(ci); err != nil {
+		errs = append(errs, err)
+	} else if backend := overrides.DefaultBackend; backend != nil {
+		service := &corev1.Service{}
+		name := types.NamespacedName{Namespace: operatorcontroller.DefaultOperandNamespace, Name: backend.Name}
+		if err := r.cache.Get(context.TODO(), name, service); err != nil {
+			if !kerrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to get default backend service %s: %w", name, err))
+			}
+		} else {
+			defaultBackendService = service
+		}
+	}
+
 	haveDepl, deployment, err := r.ensureRouterDeployment(ci, infraConfig, ingressConfig, apiConfig, networkConfig, haveClientCAConfigmap, clientCAConfigmap, platformStatus)
 	if err != nil {
 		errs = append(errs, fmt.Errorf("failed to ensure deployment: %v", err))
@@ -949,8 +1603,11 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		}
 	}
 
-	if _, _, err := r.ensureNodePortService(ci, deploymentRef); err != nil {
+	var nodePortService *corev1.Service
+	if _, nodePort, err := r.ensureNodePortService(ci, deploymentRef); err != nil {
 		errs = append(errs, err)
+	} else {
+		nodePortService = nodePort
 	}
 
 	if internalSvc, err := r.ensureInternalIngressControllerService(ci, deploymentRef); err != nil {
@@ -959,11 +1616,19 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		errs = append(errs, fmt.Errorf("failed to integrate metrics with openshift-monitoring for ingresscontroller %s: %v", ci.Name, err))
 	}
 
+	if _, _, err := r.ensureHeadlessService(ci, deploymentRef); err != nil {
+		errs = append(errs, fmt.Errorf("failed to ensure headless router service for ingresscontroller %s: %v", ci.Name, err))
+	}
+
 	if _, _, err := r.ensureRsyslogConfigMap(ci, deploymentRef); err != nil {
 		errs = append(errs, err)
 	}
 
-	if _, _, err := r.ensureRouterPodDisruptionBudget(ci, deploymentRef); err != nil {
+	if _, _, err := r.ensureRouterPodDisruptionBudget(ci, ingressConfig, infraConfig, deploymentRef); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := r.syncRouterPodDrainStates(ci); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -977,7 +1642,7 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		errs = append(errs, fmt.Errorf("failed to list pods in namespace %q: %v", operatorcontroller.DefaultOperatorNamespace, err))
 	}
 
-	syncStatusErr, updated := r.syncIngressControllerStatus(ci, deployment, deploymentRef, pods.Items, lbService, operandEvents.Items, wildcardRecord, dnsConfig, platformStatus)
+	syncStatusErr, updated := r.syncIngressControllerStatus(ci, deployment, deploymentRef, pods.Items, lbService, nodePortService, operandEvents.Items, wildcardRecord, dnsConfig, platformStatus, defaultBackendService, ingressConfig, infraConfig)
 	errs = append(errs, syncStatusErr)
 
 	// If syncIngressControllerStatus updated our ingress status, it's important we query for that new object.
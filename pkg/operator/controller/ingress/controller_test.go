@@ -1,6 +1,8 @@
 package ingress
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -11,6 +13,10 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 // TestSetDefaultDomain verifies that setDefaultDomain behaves correctly.
@@ -122,6 +128,35 @@ func TestSetDefaultPublishingStrategySetsPlatformDefaults(t *testing.T) {
 				},
 			},
 		}
+		ingressControllerWithAWSLoadBalancer = &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+					LoadBalancer: &operatorv1.LoadBalancerStrategy{
+						Scope: operatorv1.ExternalLoadBalancer,
+						ProviderParameters: &operatorv1.ProviderLoadBalancerParameters{
+							Type: operatorv1.AWSLoadBalancerProvider,
+							AWS: &operatorv1.AWSLoadBalancerParameters{
+								Type: operatorv1.AWSClassicLoadBalancer,
+							},
+						},
+					},
+				},
+			},
+		}
+		ingressControllerWithGCPLoadBalancer = &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+					LoadBalancer: &operatorv1.LoadBalancerStrategy{
+						Scope: operatorv1.ExternalLoadBalancer,
+						ProviderParameters: &operatorv1.ProviderLoadBalancerParameters{
+							Type: operatorv1.GCPLoadBalancerProvider,
+						},
+					},
+				},
+			},
+		}
 		ingressControllerWithHostNetwork = &operatorv1.IngressController{
 			Status: operatorv1.IngressControllerStatus{
 				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
@@ -155,7 +190,7 @@ func TestSetDefaultPublishingStrategySetsPlatformDefaults(t *testing.T) {
 		{
 			name:           "AWS",
 			platformStatus: makePlatformStatus(configv1.AWSPlatformType),
-			expectedIC:     ingressControllerWithLoadBalancer,
+			expectedIC:     ingressControllerWithAWSLoadBalancer,
 		},
 		{
 			name:           "Azure",
@@ -175,7 +210,7 @@ func TestSetDefaultPublishingStrategySetsPlatformDefaults(t *testing.T) {
 		{
 			name:           "GCP",
 			platformStatus: makePlatformStatus(configv1.GCPPlatformType),
-			expectedIC:     ingressControllerWithLoadBalancer,
+			expectedIC:     ingressControllerWithGCPLoadBalancer,
 		},
 		{
 			name:           "IBM Cloud",
@@ -232,6 +267,135 @@ func TestSetDefaultPublishingStrategySetsPlatformDefaults(t *testing.T) {
 	}
 }
 
+// TestSetDefaultPublishingStrategyResolvesMinimalSpec verifies that
+// setDefaultPublishingStrategy publishes a fully resolved endpoint publishing
+// strategy, including the parameters that the operator computes, for a spec
+// that only specifies the strategy type, and that it resolves the parameters
+// of a status that omits them.
+func TestSetDefaultPublishingStrategyResolvesMinimalSpec(t *testing.T) {
+	awsClassic := &operatorv1.ProviderLoadBalancerParameters{
+		Type: operatorv1.AWSLoadBalancerProvider,
+		AWS:  &operatorv1.AWSLoadBalancerParameters{Type: operatorv1.AWSClassicLoadBalancer},
+	}
+	testCases := []struct {
+		name     string
+		platform configv1.PlatformType
+		spec     *operatorv1.EndpointPublishingStrategy
+		status   *operatorv1.EndpointPublishingStrategy
+		expected *operatorv1.EndpointPublishingStrategy
+	}{
+		{
+			name:     "LoadBalancerService on AWS",
+			platform: configv1.AWSPlatformType,
+			spec:     &operatorv1.EndpointPublishingStrategy{Type: operatorv1.LoadBalancerServiceStrategyType},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{
+					Scope:              operatorv1.ExternalLoadBalancer,
+					ProviderParameters: awsClassic,
+				},
+			},
+		},
+		{
+			name:     "internal LoadBalancerService with AWS provider type only",
+			platform: configv1.AWSPlatformType,
+			spec: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{
+					Scope:              operatorv1.InternalLoadBalancer,
+					ProviderParameters: &operatorv1.ProviderLoadBalancerParameters{Type: operatorv1.AWSLoadBalancerProvider},
+				},
+			},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{
+					Scope:              operatorv1.InternalLoadBalancer,
+					ProviderParameters: awsClassic,
+				},
+			},
+		},
+		{
+			name:     "LoadBalancerService on AWS with unresolved status",
+			platform: configv1.AWSPlatformType,
+			spec:     &operatorv1.EndpointPublishingStrategy{Type: operatorv1.LoadBalancerServiceStrategyType},
+			status: &operatorv1.EndpointPublishingStrategy{
+				Type:         operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{Scope: operatorv1.ExternalLoadBalancer},
+			},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{
+					Scope:              operatorv1.ExternalLoadBalancer,
+					ProviderParameters: awsClassic,
+				},
+			},
+		},
+		{
+			name:     "LoadBalancerService on Azure",
+			platform: configv1.AzurePlatformType,
+			spec:     &operatorv1.EndpointPublishingStrategy{Type: operatorv1.LoadBalancerServiceStrategyType},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type:         operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{Scope: operatorv1.ExternalLoadBalancer},
+			},
+		},
+		{
+			name:     "NodePortService",
+			platform: configv1.AWSPlatformType,
+			spec:     &operatorv1.EndpointPublishingStrategy{Type: operatorv1.NodePortServiceStrategyType},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type:     operatorv1.NodePortServiceStrategyType,
+				NodePort: &operatorv1.NodePortStrategy{Protocol: operatorv1.TCPProtocol},
+			},
+		},
+		{
+			name:     "HostNetwork",
+			platform: configv1.BareMetalPlatformType,
+			spec:     &operatorv1.EndpointPublishingStrategy{Type: operatorv1.HostNetworkStrategyType},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.HostNetworkStrategyType,
+				HostNetwork: &operatorv1.HostNetworkStrategy{
+					Protocol:  operatorv1.TCPProtocol,
+					HTTPPort:  80,
+					HTTPSPort: 443,
+					StatsPort: 1936,
+				},
+			},
+		},
+		{
+			name:     "Private",
+			platform: configv1.GCPPlatformType,
+			spec:     &operatorv1.EndpointPublishingStrategy{Type: operatorv1.PrivateStrategyType},
+			expected: &operatorv1.EndpointPublishingStrategy{
+				Type:    operatorv1.PrivateStrategyType,
+				Private: &operatorv1.PrivateStrategy{Protocol: operatorv1.TCPProtocol},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				Spec:   operatorv1.IngressControllerSpec{EndpointPublishingStrategy: tc.spec},
+				Status: operatorv1.IngressControllerStatus{EndpointPublishingStrategy: tc.status},
+			}
+			originalSpec := tc.spec.DeepCopy()
+			platformStatus := &configv1.PlatformStatus{Type: tc.platform}
+			if !setDefaultPublishingStrategy(ic, platformStatus) {
+				t.Error("expected setDefaultPublishingStrategy to update the status")
+			}
+			if diff := cmp.Diff(tc.expected, ic.Status.EndpointPublishingStrategy); len(diff) != 0 {
+				t.Errorf("unexpected status endpoint publishing strategy: %s", diff)
+			}
+			if setDefaultPublishingStrategy(ic, platformStatus) {
+				t.Error("expected the resolved status to be stable")
+			}
+			if diff := cmp.Diff(originalSpec, ic.Spec.EndpointPublishingStrategy); len(diff) != 0 {
+				t.Errorf("expected the spec not to be mutated: %s", diff)
+			}
+		})
+	}
+}
+
 // TestSetDefaultPublishingStrategyHandlesUpdates verifies that
 // setDefaultPublishingStrategy correctly handles changes to
 // spec.endpointPublishingStrategy.
@@ -388,14 +552,26 @@ func TestSetDefaultPublishingStrategyHandlesUpdates(t *testing.T) {
 		{
 			name:           "loadbalancer type changed from ELB to NLB",
 			ic:             makeIC(spec(nlb()), status(elb())),
-			expectedResult: false,
-			expectedIC:     makeIC(spec(nlb()), status(elb())),
+			expectedResult: true,
+			expectedIC:     makeIC(spec(nlb()), status(nlb())),
 		},
 		{
 			name:           "loadbalancer type changed from NLB to ELB",
 			ic:             makeIC(spec(elb()), status(nlb())),
-			expectedResult: false,
-			expectedIC:     makeIC(spec(elb()), status(nlb())),
+			expectedResult: true,
+			expectedIC:     makeIC(spec(elb()), status(elb())),
+		},
+		{
+			name:           "loadbalancer type changed from unset to NLB",
+			ic:             makeIC(spec(nlb()), status(lb(operatorv1.ExternalLoadBalancer))),
+			expectedResult: true,
+			expectedIC:     makeIC(spec(nlb()), status(nlb())),
+		},
+		{
+			name:           "loadbalancer type changed from unset to ELB",
+			ic:             makeIC(spec(elb()), status(lb(operatorv1.ExternalLoadBalancer))),
+			expectedResult: true,
+			expectedIC:     makeIC(spec(elb()), status(elb())),
 		},
 		{
 			name:           "loadbalancer ELB connection idle timeout changed from unset with null provider parameters to 2m",
@@ -893,6 +1069,21 @@ func TestValidateHTTPHeaderBufferValues(t *testing.T) {
 			},
 			valid: false,
 		},
+		{
+			description: "invalid tuningOptions values, equal values",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				HeaderBufferBytes:           16384,
+				HeaderBufferMaxRewriteBytes: 16384,
+			},
+			valid: false,
+		},
+		{
+			description: "valid tuningOptions values, HeaderBufferBytes not set",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				HeaderBufferMaxRewriteBytes: 16384,
+			},
+			valid: true,
+		},
 		{
 			description: "invalid tuningOptions values, HeaderBufferMaxRewriteBytes not set",
 			tuningOptions: operatorv1.IngressControllerTuningOptions{
@@ -923,6 +1114,331 @@ func TestValidateHTTPHeaderBufferValues(t *testing.T) {
 	}
 }
 
+// TestValidateTunnelTimeout verifies that validateTunnelTimeout rejects a
+// tunnel timeout that is shorter than the effective client or server timeout.
+func TestValidateTunnelTimeout(t *testing.T) {
+	testCases := []struct {
+		description   string
+		tuningOptions operatorv1.IngressControllerTuningOptions
+		valid         bool
+	}{
+		{
+			description:   "no tunnelTimeout",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{},
+			valid:         true,
+		},
+		{
+			description: "tunnelTimeout longer than the defaults",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				TunnelTimeout: &metav1.Duration{Duration: time.Hour},
+			},
+			valid: true,
+		},
+		{
+			description: "tunnelTimeout equal to clientTimeout and serverTimeout",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				ClientTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+				ServerTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+				TunnelTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			valid: true,
+		},
+		{
+			description: "tunnelTimeout shorter than the default timeouts",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				TunnelTimeout: &metav1.Duration{Duration: 10 * time.Second},
+			},
+			valid: false,
+		},
+		{
+			description: "tunnelTimeout shorter than clientTimeout",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				ClientTimeout: &metav1.Duration{Duration: 2 * time.Hour},
+				TunnelTimeout: &metav1.Duration{Duration: time.Hour},
+			},
+			valid: false,
+		},
+		{
+			description: "tunnelTimeout shorter than serverTimeout",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				ServerTimeout: &metav1.Duration{Duration: 2 * time.Hour},
+				TunnelTimeout: &metav1.Duration{Duration: time.Hour},
+			},
+			valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Spec.TuningOptions = tc.tuningOptions
+		err := validateTunnelTimeout(ic)
+		if tc.valid && err != nil {
+			t.Errorf("%q: Expected valid tunnelTimeout to not return a validation error: %v", tc.description, err)
+		}
+
+		if !tc.valid && err == nil {
+			t.Errorf("%q: Expected invalid tunnelTimeout to return a validation error", tc.description)
+		}
+	}
+}
+
+// TestValidateForwardedHeaderPolicy verifies that
+// validateForwardedHeaderPolicy accepts the four forwarded header policies and
+// rejects unknown ones.
+func TestValidateForwardedHeaderPolicy(t *testing.T) {
+	testCases := []struct {
+		policy operatorv1.IngressControllerHTTPHeaderPolicy
+		valid  bool
+	}{
+		{"", true},
+		{operatorv1.AppendHTTPHeaderPolicy, true},
+		{operatorv1.ReplaceHTTPHeaderPolicy, true},
+		{operatorv1.IfNoneHTTPHeaderPolicy, true},
+		{operatorv1.NeverHTTPHeaderPolicy, true},
+		{"append", false},
+		{"Disable", false},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Spec.HTTPHeaders = &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: tc.policy}
+		err := validateForwardedHeaderPolicy(ic)
+		if tc.valid && err != nil {
+			t.Errorf("%q: expected valid policy to not return a validation error: %v", tc.policy, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%q: expected invalid policy to return a validation error", tc.policy)
+		}
+	}
+}
+
+// TestValidateUniqueIdHeader verifies that validateUniqueIdHeader accepts
+// valid HTTP header names and HAProxy log formats and rejects invalid ones.
+func TestValidateUniqueIdHeader(t *testing.T) {
+	testCases := []struct {
+		description string
+		uniqueId    operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy
+		valid       bool
+	}{
+		{"empty", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{}, true},
+		{"name only", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "X-Request-Id"}, true},
+		{"default format", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: `%{+X}o\ %ci:%cp_%fi:%fp_%Ts_%rt:%pid`}, true},
+		{"escaped percent and literal text", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "id-%%-%ID"}, true},
+		{"sample fetch", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%[req.hdr(host)]-%rt"}, true},
+		{"multiple flags", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%{+Q,-X}ci"}, true},
+		{"invalid name", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique id"}, false},
+		{"name with colon", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique:id"}, false},
+		{"unknown variable", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%foo"}, false},
+		{"trailing percent", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%ci%"}, false},
+		{"unknown flag", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%{+Z}ci"}, false},
+		{"unterminated flags", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%{+X"}, false},
+		{"unterminated sample fetch", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%[src"}, false},
+		{"control character", operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "unique-id", Format: "%ci\n"}, false},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Spec.HTTPHeaders = &operatorv1.IngressControllerHTTPHeaders{UniqueId: tc.uniqueId}
+		err := validateUniqueIdHeader(ic)
+		if tc.valid && err != nil {
+			t.Errorf("%q: expected valid unique id header to not return a validation error: %v", tc.description, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%q: expected invalid unique id header to return a validation error", tc.description)
+		}
+	}
+}
+
+// TestValidateSyslogDestination verifies that validateSyslogDestination
+// accepts syslog destinations with a valid address and port and rejects invalid
+// ones.
+func TestValidateSyslogDestination(t *testing.T) {
+	testCases := []struct {
+		description string
+		destination operatorv1.LoggingDestination
+		valid       bool
+	}{
+		{
+			description: "container destination",
+			destination: operatorv1.LoggingDestination{
+				Type: operatorv1.ContainerLoggingDestinationType,
+			},
+			valid: true,
+		},
+		{
+			description: "IPv4 address",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "1.2.3.4", Port: 514},
+			},
+			valid: true,
+		},
+		{
+			description: "IPv6 address",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "2001:db8::1", Port: 514},
+			},
+			valid: true,
+		},
+		{
+			description: "host name with maxLength",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "syslog.example.com", Port: 6514, MaxLength: 4096},
+			},
+			valid: true,
+		},
+		{
+			description: "missing syslog parameters",
+			destination: operatorv1.LoggingDestination{
+				Type: operatorv1.SyslogLoggingDestinationType,
+			},
+			valid: false,
+		},
+		{
+			description: "invalid address",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "1.2.3.4:514", Port: 514},
+			},
+			valid: false,
+		},
+		{
+			description: "empty address",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Port: 514},
+			},
+			valid: false,
+		},
+		{
+			description: "zero port",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "1.2.3.4"},
+			},
+			valid: false,
+		},
+		{
+			description: "port out of range",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "1.2.3.4", Port: 65536},
+			},
+			valid: false,
+		},
+		{
+			description: "maxLength too small",
+			destination: operatorv1.LoggingDestination{
+				Type:   operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{Address: "1.2.3.4", Port: 514, MaxLength: 100},
+			},
+			valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Spec.Logging = &operatorv1.IngressControllerLogging{
+			Access: &operatorv1.AccessLogging{Destination: tc.destination},
+		}
+		err := validateSyslogDestination(ic)
+		if tc.valid && err != nil {
+			t.Errorf("%q: expected valid syslog destination to not return a validation error: %v", tc.description, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%q: expected invalid syslog destination to return a validation error", tc.description)
+		}
+	}
+}
+
+// TestValidateCaptureHTTPHeaders verifies that validateCaptureHTTPHeaders
+// rejects captured headers with invalid names or maximum lengths and rejects
+// too many captured headers.
+func TestValidateCaptureHTTPHeaders(t *testing.T) {
+	headers := func(n int) []operatorv1.IngressControllerCaptureHTTPHeader {
+		var headers []operatorv1.IngressControllerCaptureHTTPHeader
+		for i := 0; i < n; i++ {
+			headers = append(headers, operatorv1.IngressControllerCaptureHTTPHeader{
+				Name:      fmt.Sprintf("X-Header-%d", i),
+				MaxLength: 64,
+			})
+		}
+		return headers
+	}
+	testCases := []struct {
+		description    string
+		captureHeaders operatorv1.IngressControllerCaptureHTTPHeaders
+		valid          bool
+	}{
+		{
+			description: "no captured headers",
+			valid:       true,
+		},
+		{
+			description: "request and response headers",
+			captureHeaders: operatorv1.IngressControllerCaptureHTTPHeaders{
+				Request:  []operatorv1.IngressControllerCaptureHTTPHeader{{Name: "Host", MaxLength: 90}},
+				Response: []operatorv1.IngressControllerCaptureHTTPHeader{{Name: "Content-Type", MaxLength: 32}},
+			},
+			valid: true,
+		},
+		{
+			description: "maximum number of headers",
+			captureHeaders: operatorv1.IngressControllerCaptureHTTPHeaders{
+				Request:  headers(maxCaptureHTTPHeaders / 2),
+				Response: headers(maxCaptureHTTPHeaders - maxCaptureHTTPHeaders/2),
+			},
+			valid: true,
+		},
+		{
+			description: "too many headers",
+			captureHeaders: operatorv1.IngressControllerCaptureHTTPHeaders{
+				Request:  headers(maxCaptureHTTPHeaders / 2),
+				Response: headers(maxCaptureHTTPHeaders - maxCaptureHTTPHeaders/2 + 1),
+			},
+			valid: false,
+		},
+		{
+			description: "invalid header name",
+			captureHeaders: operatorv1.IngressControllerCaptureHTTPHeaders{
+				Request: []operatorv1.IngressControllerCaptureHTTPHeader{{Name: "X Forwarded", MaxLength: 64}},
+			},
+			valid: false,
+		},
+		{
+			description: "zero maxLength",
+			captureHeaders: operatorv1.IngressControllerCaptureHTTPHeaders{
+				Response: []operatorv1.IngressControllerCaptureHTTPHeader{{Name: "Location"}},
+			},
+			valid: false,
+		},
+		{
+			description: "negative maxLength",
+			captureHeaders: operatorv1.IngressControllerCaptureHTTPHeaders{
+				Request: []operatorv1.IngressControllerCaptureHTTPHeader{{Name: "Host", MaxLength: -1}},
+			},
+			valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		ic.Spec.Logging = &operatorv1.IngressControllerLogging{
+			Access: &operatorv1.AccessLogging{
+				Destination:        operatorv1.LoggingDestination{Type: operatorv1.ContainerLoggingDestinationType},
+				HTTPCaptureHeaders: tc.captureHeaders,
+			},
+		}
+		err := validateCaptureHTTPHeaders(ic)
+		if tc.valid && err != nil {
+			t.Errorf("%q: expected valid captured headers to not return a validation error: %v", tc.description, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%q: expected invalid captured headers to return a validation error", tc.description)
+		}
+	}
+}
+
 // TestValidateClientTLS verifies the validateClientTLS accepts PCRE-compliant
 // patterns and rejects invalid patterns.
 func TestValidateClientTLS(t *testing.T) {
@@ -1195,3 +1711,80 @@ func TestIsProxyProtocolNeeded(t *testing.T) {
 		}
 	}
 }
+
+// TestIngressControllerFinalizer verifies that the operator adds its
+// configured finalizer, or the default finalizer if none is configured, to an
+// ingresscontroller and removes it again, along with the default finalizer if
+// the ingresscontroller still has it.
+func TestIngressControllerFinalizer(t *testing.T) {
+	const customFinalizer = "example.com/ingresscontroller-cleanup"
+	testCases := []struct {
+		name              string
+		configured        string
+		initialFinalizers []string
+		expectFinalizers  []string
+	}{
+		{
+			name:             "default finalizer",
+			expectFinalizers: []string{"ingresscontroller.operator.openshift.io/finalizer-ingresscontroller"},
+		},
+		{
+			name:             "custom finalizer",
+			configured:       customFinalizer,
+			expectFinalizers: []string{customFinalizer},
+		},
+		{
+			name:              "custom finalizer with existing finalizers",
+			configured:        customFinalizer,
+			initialFinalizers: []string{"example.com/other", "ingresscontroller.operator.openshift.io/finalizer-ingresscontroller"},
+			expectFinalizers:  []string{"example.com/other", "ingresscontroller.operator.openshift.io/finalizer-ingresscontroller", customFinalizer},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			if err := operatorv1.AddToScheme(s); err != nil {
+				t.Fatal(err)
+			}
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  "openshift-ingress-operator",
+					Name:       "default",
+					Finalizers: tc.initialFinalizers,
+				},
+			}
+			cl := fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()
+			r := &reconciler{client: cl, config: Config{Finalizer: tc.configured}}
+			name := types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}
+
+			// Created: the configured finalizer is added.
+			updated, err := r.ensureIngressControllerFinalizer(ic)
+			if err != nil {
+				t.Fatalf("failed to ensure finalizer: %v", err)
+			}
+			if !reflect.DeepEqual(updated.Finalizers, tc.expectFinalizers) {
+				t.Errorf("expected finalizers %v, got %v", tc.expectFinalizers, updated.Finalizers)
+			}
+			if again, err := r.ensureIngressControllerFinalizer(updated); err != nil {
+				t.Fatalf("failed to ensure finalizer: %v", err)
+			} else if !reflect.DeepEqual(again.Finalizers, tc.expectFinalizers) {
+				t.Errorf("expected finalizers %v to be unchanged, got %v", tc.expectFinalizers, again.Finalizers)
+			}
+
+			// Deleted: the configured and default finalizers are
+			// removed, and other finalizers are kept.
+			if err := r.removeIngressControllerFinalizer(updated); err != nil {
+				t.Fatalf("failed to remove finalizer: %v", err)
+			}
+			current := &operatorv1.IngressController{}
+			if err := cl.Get(context.TODO(), name, current); err != nil {
+				t.Fatal(err)
+			}
+			for _, finalizer := range current.Finalizers {
+				if finalizer != "example.com/other" {
+					t.Errorf("expected finalizer %s to be removed, got %v", finalizer, current.Finalizers)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package ingress
 import (
 	"context"
 	"crypto/x509"
-	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"hash"
@@ -30,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -59,14 +59,110 @@ const (
 
 	RouterHeaderBufferSize           = "ROUTER_BUF_SIZE"
 	RouterHeaderBufferMaxRewriteSize = "ROUTER_MAX_REWRITE_SIZE"
+	RouterMaxHeaderCountEnvName      = "ROUTER_MAX_HEADER_COUNT"
+
+	RouterSSLCacheSizeEnvName = "ROUTER_SSL_CACHE_SIZE"
+
+	RouterSSLMaxRecordSizeEnvName = "ROUTER_SSL_MAX_RECORD"
+
+	RouterMaxSSLRateEnvName = "ROUTER_MAX_SSL_RATE"
+
+	RouterBackendPoolMaxConnectionsEnvName = "ROUTER_BACKEND_POOL_MAX_CONN"
+
+	RouterServerMaxConnectionsEnvName = "ROUTER_SERVER_MAX_CONN"
+
+	RouterBacklogEnvName = "ROUTER_BACKLOG"
+
+	RouterStatsTimeoutEnvName = "ROUTER_STATS_TIMEOUT"
+
+	// RouterTCPKeepaliveEnvName specifies whether the router enables TCP
+	// keepalive on client and server sockets.  The idle time, probe
+	// interval, and probe count are set using the following variables;
+	// the kernel's defaults are used for any that are unset.
+	RouterTCPKeepaliveEnvName         = "ROUTER_TCP_KEEPALIVE"
+	RouterTCPKeepaliveIdleEnvName     = "ROUTER_TCP_KEEPALIVE_IDLE"
+	RouterTCPKeepaliveIntervalEnvName = "ROUTER_TCP_KEEPALIVE_INTERVAL"
+	RouterTCPKeepaliveCountEnvName    = "ROUTER_TCP_KEEPALIVE_COUNT"
+
+	// RouterTracingEnvName specifies whether the router emits
+	// OpenTelemetry spans for requests.  The collector endpoint and the
+	// sampling ratio are set using the following variables.
+	RouterTracingEnvName              = "ROUTER_TRACING"
+	RouterTracingEndpointEnvName      = "ROUTER_TRACING_OTLP_ENDPOINT"
+	RouterTracingSamplingRatioEnvName = "ROUTER_TRACING_SAMPLING_RATIO"
+
+	// RouterDefaultHSTSHeaderEnvName specifies the Strict-Transport-Security
+	// header that the router adds to TLS responses for routes that do not
+	// have the haproxy.router.openshift.io/hsts_header annotation.
+	RouterDefaultHSTSHeaderEnvName = "ROUTER_DEFAULT_HSTS_HEADER"
+
+	RouterPreferServerCiphersEnvName = "ROUTER_PREFER_SERVER_CIPHERS"
+
+	RouterRejectInvalidDefaultCertificateEnvName = "ROUTER_REJECT_INVALID_DEFAULT_CERTIFICATE"
+
+	// RouterStrictRequestFramingEnvName specifies whether the router
+	// rejects HTTP/1 requests with ambiguous framing (HAProxy's strict h1
+	// parsing) or accepts them (option accept-invalid-http-request).
+	RouterStrictRequestFramingEnvName = "ROUTER_STRICT_REQUEST_FRAMING"
+
+	RouterWildcardCertificatesDirEnvName = "ROUTER_WILDCARD_CERTIFICATES_DIR"
+	wildcardCertificatesVolumeName       = "wildcard-certificates"
+	wildcardCertificatesVolumeMountPath  = "/etc/pki/tls/wildcard"
+
+	RouterHTTP2MaxConcurrentStreamsEnvName = "ROUTER_H2_MAX_CONCURRENT_STREAMS"
+
+	// RouterALPNProtocolsEnvName is a comma-separated list of the
+	// protocols that the router advertises using TLS ALPN, in order of
+	// preference.
+	RouterALPNProtocolsEnvName = "ROUTER_ALPN_PROTOCOLS"
 
 	RouterLoadBalancingAlgorithmEnvName    = "ROUTER_LOAD_BALANCE_ALGORITHM"
 	RouterTCPLoadBalancingAlgorithmEnvName = "ROUTER_TCP_BALANCE_SCHEME"
 
 	RouterMaxConnectionsEnvName = "ROUTER_MAX_CONNECTIONS"
 
+	// RouterDefaultRateLimitHTTPEnvName is the router's default limit on
+	// HTTP requests per second from a single client IP address for each
+	// route.  A route overrides it using the
+	// haproxy.router.openshift.io/rate-limit-connections.rate-http
+	// annotation.
+	RouterDefaultRateLimitHTTPEnvName = "ROUTER_DEFAULT_RATE_LIMIT_HTTP"
+
+	// RouterDefaultHostHeaderEnvName is the hostname to which the router
+	// rewrites the Host header of requests to each route's backends.  A
+	// route overrides it using the haproxy.router.openshift.io/host-header
+	// annotation.
+	RouterDefaultHostHeaderEnvName = "ROUTER_DEFAULT_HOST_HEADER"
+
+	// RouterReadMethodsEnvName is a space-separated list of the HTTP
+	// methods of read requests.  If it is set, the router sends requests
+	// with other methods to the service that a route's
+	// haproxy.router.openshift.io/write-backend annotation names.
+	RouterReadMethodsEnvName = "ROUTER_READ_METHODS"
+
+	// RouterTrustForwardedProtoEnvName specifies whether the router uses
+	// the X-Forwarded-Proto header to determine the scheme of requests
+	// for redirects.
+	RouterTrustForwardedProtoEnvName = "ROUTER_TRUST_FORWARDED_PROTO"
+
+	// RouterHTTPConnectionModeEnvName is the HAProxy option that
+	// specifies whether the router keeps connections open after each
+	// response: "http-keep-alive", "http-server-close", or "httpclose".
+	RouterHTTPConnectionModeEnvName = "ROUTER_HTTP_CONNECTION_MODE"
+
+	// RouterGRPCModeEnvName specifies whether the router proxies routes
+	// using HTTP/2 end-to-end without buffering by default.  A route
+	// opts out using the haproxy.router.openshift.io/grpc-mode
+	// annotation.
+	RouterGRPCModeEnvName = "ROUTER_GRPC_MODE"
+
 	RouterReloadIntervalEnvName = "RELOAD_INTERVAL"
 
+	RouterDefaultBackendEnvName = "ROUTER_DEFAULT_BACKEND_SERVICE"
+
+	RouterErrorFile503EnvName = "ROUTER_ERRORFILE_503"
+	RouterErrorFile404EnvName = "ROUTER_ERRORFILE_404"
+
 	RouterDontLogNull      = "ROUTER_DONT_LOG_NULL"
 	RouterHTTPIgnoreProbes = "ROUTER_HTTP_IGNORE_PROBES"
 
@@ -85,13 +181,31 @@ const (
 
 	WorkloadPartitioningManagement = "target.workload.openshift.io/management"
 
+	// ClientCAConfigMapHashAnnotation is the pod template annotation that
+	// records a hash of the client CA configmap's content so that the router
+	// is rolled out when, and only when, the CA bundle changes.
+	ClientCAConfigMapHashAnnotation = "ingress.operator.openshift.io/client-ca-configmap-hash"
+
+	// RsyslogConfigHashAnnotation is the pod template annotation that
+	// records a hash of the logging sidecar's configuration so that the
+	// router is rolled out when the configuration changes.
+	RsyslogConfigHashAnnotation = "ingress.operator.openshift.io/rsyslog-config-hash"
+
 	RouterClientAuthPolicy = "ROUTER_MUTUAL_TLS_AUTH"
 	RouterClientAuthCA     = "ROUTER_MUTUAL_TLS_AUTH_CA"
 	RouterClientAuthCRL    = "ROUTER_MUTUAL_TLS_AUTH_CRL"
 	RouterClientAuthFilter = "ROUTER_MUTUAL_TLS_AUTH_FILTER"
 
+	// RouterClientAuthPerRoute tells the router to enforce the client
+	// certificate policy per route, using the policy from the route's
+	// "haproxy.router.openshift.io/client-certificate-policy" annotation
+	// or else RouterClientAuthDefaultPolicy.
+	RouterClientAuthPerRoute      = "ROUTER_MUTUAL_TLS_AUTH_PER_ROUTE"
+	RouterClientAuthDefaultPolicy = "ROUTER_MUTUAL_TLS_AUTH_DEFAULT_POLICY"
+
 	RouterEnableCompression    = "ROUTER_ENABLE_COMPRESSION"
 	RouterCompressionMIMETypes = "ROUTER_COMPRESSION_MIME"
+	RouterCompressionLevel     = "ROUTER_COMPRESSION_LEVEL"
 	RouterBackendCheckInterval = "ROUTER_BACKEND_CHECK_INTERVAL"
 
 	RouterServiceHTTPPort  = "ROUTER_SERVICE_HTTP_PORT"
@@ -108,6 +222,10 @@ const (
 // ensureRouterDeployment ensures the router deployment exists for a given
 // ingresscontroller.
 func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure, ingressConfig *configv1.Ingress, apiConfig *configv1.APIServer, networkConfig *configv1.Network, haveClientCAConfigmap bool, clientCAConfigmap *corev1.ConfigMap, platformStatus *configv1.PlatformStatus) (bool, *appsv1.Deployment, error) {
+	overrides, err := getUnsupportedConfigOverrides(ci)
+	if err != nil {
+		return false, nil, err
+	}
 	haveDepl, current, err := r.currentRouterDeployment(ci)
 	if err != nil {
 		return false, nil, err
@@ -120,6 +238,56 @@ func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, in
 	if err != nil {
 		return haveDepl, current, fmt.Errorf("failed to build router deployment: %v", err)
 	}
+	if haveMaintenancePage, maintenancePage, err := r.currentMaintenancePageConfigMap(ci); err != nil {
+		return haveDepl, current, err
+	} else if haveMaintenancePage {
+		configureMaintenancePage(desired, maintenancePage)
+	}
+	if replicasFromNodeCountEnabled(ci) {
+		replicas, err := r.nodeCountReplicas(ci, desired, current)
+		if err != nil {
+			return haveDepl, current, err
+		}
+		desired.Spec.Replicas = &replicas
+	}
+	// Split the replicas with the canary router deployment, if any, before
+	// the rolling update parameters are derived from the replicas so that
+	// each deployment's parameters follow its own replicas.
+	canary := splitCanaryRouterReplicas(ci, overrides, desired)
+	for _, deployment := range []*appsv1.Deployment{desired, canary} {
+		if deployment == nil {
+			continue
+		}
+		if err := r.adjustRollingUpdate(ci, overrides, deployment, platformStatus); err != nil {
+			return haveDepl, current, err
+		}
+	}
+	// The pod disruption budget and node drains limit the rollout of the
+	// stable deployment, which has most of the replicas.
+	if haveDepl {
+		if overrides.LimitRolloutToPodDisruptionBudget {
+			if havePDB, pdb, err := r.currentRouterPodDisruptionBudget(ci); err != nil {
+				return haveDepl, current, err
+			} else if havePDB && isOwnedBy(pdb, current) && limitRolloutToPodDisruptionBudget(desired, pdb) {
+				log.Info("limiting rollout to the pod disruption budget", "ingresscontroller", ci.Name, "maxSurge", desired.Spec.Strategy.RollingUpdate.MaxSurge.String(), "maxUnavailable", desired.Spec.Strategy.RollingUpdate.MaxUnavailable.String())
+			}
+		}
+		// The pod deletions that the drain causes trigger
+		// reconciliation, which restores the usual value once no router
+		// pod is on a draining node.  Evictions already count against
+		// the pod disruption budget, so relaxing maxUnavailable for a
+		// drain takes precedence over the budget's limit.
+		if overrides.RelaxMaxUnavailableDuringDrain {
+			if draining, err := r.countRouterPodsOnDrainingNodes(ci); err != nil {
+				return haveDepl, current, err
+			} else if relaxMaxUnavailableForDrain(desired, draining) {
+				log.Info("relaxing maxUnavailable for router pods on draining nodes", "ingresscontroller", ci.Name, "pods", draining, "maxUnavailable", desired.Spec.Strategy.RollingUpdate.MaxUnavailable.String())
+			}
+		}
+	}
+	if err := r.ensureCanaryRouterDeployment(ci, canary); err != nil {
+		return haveDepl, current, err
+	}
 
 	switch {
 	case !haveDepl:
@@ -128,7 +296,7 @@ func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, in
 		}
 		return r.currentRouterDeployment(ci)
 	case haveDepl:
-		if updated, err := r.updateRouterDeployment(current, desired); err != nil {
+		if updated, err := r.updateRouterDeployment(ci, current, desired); err != nil {
 			return true, current, err
 		} else if updated {
 			return r.currentRouterDeployment(ci)
@@ -137,9 +305,70 @@ func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, in
 	return true, current, nil
 }
 
+// splitCanaryRouterReplicas moves the canary router's share of the given
+// desired router deployment's replicas to a canary router deployment, which it
+// returns, or returns nil if the ingresscontroller has no canary router.
+func splitCanaryRouterReplicas(ci *operatorv1.IngressController, overrides *unsupportedConfigOverrides, desired *appsv1.Deployment) *appsv1.Deployment {
+	if overrides.CanaryRouter == nil || desired.Spec.Replicas == nil {
+		return nil
+	}
+	stableReplicas, canaryReplicas := canaryRouterReplicas(*desired.Spec.Replicas, overrides.CanaryRouter.Weight)
+	canary := desiredCanaryRouterDeployment(ci, desired, overrides.CanaryRouter.Image, canaryReplicas)
+	desired.Spec.Replicas = &stableReplicas
+	return canary
+}
+
+// adjustRollingUpdate adjusts the rolling update parameters of the given
+// router deployment for its replicas: it converts percentages into pod counts
+// if the rollingUpdate unsupported config override requests it, and otherwise
+// adjusts surge for the ingresscontroller's endpoint publishing strategy.  The
+// strategy is not part of the pod template, so adjusting it does not trigger a
+// rollout.
+func (r *reconciler) adjustRollingUpdate(ci *operatorv1.IngressController, overrides *unsupportedConfigOverrides, deployment *appsv1.Deployment, platformStatus *configv1.PlatformStatus) error {
+	if overrides.RollingUpdate != nil {
+		return useIntegerRollingUpdateCountsForOverride(deployment, overrides.RollingUpdate)
+	}
+	switch ci.Status.EndpointPublishingStrategy.Type {
+	case operatorv1.HostNetworkStrategyType:
+		// HostNetwork does not use surge because surge pods need free
+		// nodes, but with node autoscaling, a surge pod that cannot be
+		// scheduled makes the autoscaler add a node.
+		surge := overrides.HostNetworkSurge
+		if !surge {
+			var err error
+			if surge, err = r.nodeAutoscalingEnabled(); err != nil {
+				return err
+			}
+		}
+		if surge && surgeForHostNetwork(deployment) {
+			log.Info("using surge for host network rollout", "ingresscontroller", ci.Name, "deployment", deployment.Name)
+		}
+	case operatorv1.LoadBalancerServiceStrategyType:
+		// The traffic policy is detected from the load balancer service
+		// that the operator would create so that it follows any
+		// platform-specific policy.
+		if !overrides.DisableSurgeForLocalTrafficPolicy {
+			return nil
+		}
+		if local, err := usesLocalExternalTrafficPolicy(ci, platformStatus); err != nil {
+			return err
+		} else if local {
+			if changed, err := disableSurgeForLocalTrafficPolicy(deployment); err != nil {
+				return err
+			} else if changed {
+				log.Info("disabling surge for the Local external traffic policy", "ingresscontroller", ci.Name, "deployment", deployment.Name, "maxUnavailable", deployment.Spec.Strategy.RollingUpdate.MaxUnavailable.String())
+			}
+		}
+	}
+	return nil
+}
+
 // ensureRouterDeleted ensures that any router resources associated with the
 // ingresscontroller are deleted.
 func (r *reconciler) ensureRouterDeleted(ci *operatorv1.IngressController) error {
+	if err := r.ensureCanaryRouterDeployment(ci, nil); err != nil {
+		return err
+	}
 	deployment := &appsv1.Deployment{}
 	name := controller.RouterDeploymentName(ci)
 	deployment.Name = name.Name
@@ -169,8 +398,14 @@ func HTTP2IsEnabledByAnnotation(m map[string]string) (bool, bool) {
 // http/2, or if the ingress config enables http/2. It will return
 // false for the case where the ingress config has been enabled but
 // the ingress controller explicitly overrides that by having the
-// annotation present (even if its value is "false").
+// annotation present (even if its value is "false").  The http2
+// unsupported config override, if set, takes precedence over both
+// annotations.
 func HTTP2IsEnabled(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress) bool {
+	if overrides, err := getUnsupportedConfigOverrides(ic); err == nil && overrides.HTTP2 != nil {
+		return *overrides.HTTP2
+	}
+
 	controllerHasHTTP2Annotation, controllerHasHTTP2Enabled := HTTP2IsEnabledByAnnotation(ic.Annotations)
 	_, configHasHTTP2Enabled := HTTP2IsEnabledByAnnotation(ingressConfig.Annotations)
 
@@ -221,6 +456,141 @@ func determineDeploymentReplicas(ic *operatorv1.IngressController, ingressConfig
 	return DetermineReplicas(ingressConfig, infraConfig)
 }
 
+// nodePlacementSelector converts the given node placement selector into a
+// node selector and, if necessary, a node affinity.  A node selector can only
+// require labels to have specific values, so if the given selector has match
+// expressions that cannot be expressed that way, its match labels become the
+// node selector and its match expressions become a required node affinity
+// term.
+func nodePlacementSelector(selector *metav1.LabelSelector) (map[string]string, *corev1.NodeAffinity, error) {
+	if nodeSelector, err := metav1.LabelSelectorAsMap(selector); err == nil {
+		return nodeSelector, nil, nil
+	}
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return nil, nil, err
+	}
+	nodeSelector := map[string]string{}
+	for k, v := range selector.MatchLabels {
+		nodeSelector[k] = v
+	}
+	requirements := make([]corev1.NodeSelectorRequirement, 0, len(selector.MatchExpressions))
+	for _, expr := range selector.MatchExpressions {
+		// The label selector operators In, NotIn, Exists, and
+		// DoesNotExist have the same names as the corresponding node
+		// selector operators.
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      expr.Key,
+			Operator: corev1.NodeSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: requirements}},
+		},
+	}
+	return nodeSelector, nodeAffinity, nil
+}
+
+// effectiveRouteAdmissionPolicy returns the given ingresscontroller's route
+// admission policy with defaults applied.
+func effectiveRouteAdmissionPolicy(ci *operatorv1.IngressController) operatorv1.RouteAdmissionPolicy {
+	routeAdmission := operatorv1.RouteAdmissionPolicy{
+		NamespaceOwnership: operatorv1.StrictNamespaceOwnershipCheck,
+		WildcardPolicy:     operatorv1.WildcardPolicyDisallowed,
+	}
+	if admission := ci.Spec.RouteAdmission; admission != nil {
+		if len(admission.NamespaceOwnership) > 0 {
+			routeAdmission.NamespaceOwnership = admission.NamespaceOwnership
+		}
+		if len(admission.WildcardPolicy) > 0 {
+			routeAdmission.WildcardPolicy = admission.WildcardPolicy
+		}
+	}
+	return routeAdmission
+}
+
+// useIntegerRollingUpdateCountsForOverride converts the given router
+// deployment's rolling update parameters into pod counts based on its replicas
+// if the given rollingUpdate unsupported config override requests it.  The
+// replicas must be final, so this is done after desiredRouterDeployment.
+func useIntegerRollingUpdateCountsForOverride(deployment *appsv1.Deployment, rollingUpdate *rollingUpdateOverride) error {
+	if !rollingUpdate.UseIntegerCounts || deployment.Spec.Strategy.RollingUpdate == nil || deployment.Spec.Replicas == nil {
+		return nil
+	}
+	roundUp := rollingUpdate.MaxUnavailableRounding == roundingCeil
+	return useIntegerRollingUpdateCounts(deployment.Spec.Strategy.RollingUpdate, *deployment.Spec.Replicas, roundUp)
+}
+
+// useIntegerRollingUpdateCounts converts the given rolling update parameters
+// from percentages into pod counts based on the given number of replicas.
+// maxSurge is rounded up, as the deployment controller rounds it, and
+// maxUnavailable is rounded up if roundUp is true or else down, as the
+// deployment controller rounds it.  If both would be zero, maxUnavailable is
+// set to 1 so that rollouts can make progress, which is what the deployment
+// controller does.
+func useIntegerRollingUpdateCounts(rollingUpdate *appsv1.RollingUpdateDeployment, replicas int32, roundUp bool) error {
+	maxSurge, maxUnavailable := 0, 0
+	if rollingUpdate.MaxSurge != nil {
+		v, err := intstr.GetScaledValueFromIntOrPercent(rollingUpdate.MaxSurge, int(replicas), true)
+		if err != nil {
+			return fmt.Errorf("invalid maxSurge %q: %w", rollingUpdate.MaxSurge.String(), err)
+		}
+		maxSurge = v
+	}
+	if rollingUpdate.MaxUnavailable != nil {
+		v, err := intstr.GetScaledValueFromIntOrPercent(rollingUpdate.MaxUnavailable, int(replicas), roundUp)
+		if err != nil {
+			return fmt.Errorf("invalid maxUnavailable %q: %w", rollingUpdate.MaxUnavailable.String(), err)
+		}
+		maxUnavailable = v
+	}
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxUnavailable = 1
+	}
+	surge, unavailable := intstr.FromInt(maxSurge), intstr.FromInt(maxUnavailable)
+	rollingUpdate.MaxSurge, rollingUpdate.MaxUnavailable = &surge, &unavailable
+	return nil
+}
+
+// disableSurgeForLocalTrafficPolicy sets the given deployment's rolling update
+// strategy to use no surge pods.  With the "Local" external traffic policy, a
+// surge pod on a node makes the node a load balancer target, and the load
+// balancer may send connections to the node that are dropped when the old pod
+// on the node is drained.  Without surge, each new pod replaces an old pod on
+// the same node as the affinity policy prefers.  maxUnavailable is converted
+// into a pod count, rounding down as the deployment controller does, but it is
+// at least 1 so that the rollout can make progress and, with multiple
+// replicas, less than the number of replicas so that some nodes keep local
+// endpoints.  Returns a Boolean indicating whether the deployment was changed.
+func disableSurgeForLocalTrafficPolicy(deployment *appsv1.Deployment) (bool, error) {
+	rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+	if rollingUpdate == nil || deployment.Spec.Replicas == nil {
+		return false, nil
+	}
+	replicas := int(*deployment.Spec.Replicas)
+	maxUnavailable := 1
+	if rollingUpdate.MaxUnavailable != nil {
+		v, err := intstr.GetScaledValueFromIntOrPercent(rollingUpdate.MaxUnavailable, replicas, false)
+		if err != nil {
+			return false, fmt.Errorf("invalid maxUnavailable %q: %w", rollingUpdate.MaxUnavailable.String(), err)
+		}
+		maxUnavailable = v
+	}
+	if replicas > 1 && maxUnavailable >= replicas {
+		maxUnavailable = replicas - 1
+	}
+	if maxUnavailable < 1 {
+		maxUnavailable = 1
+	}
+	surge, unavailable := intstr.FromInt(0), intstr.FromInt(maxUnavailable)
+	if rollingUpdate.MaxSurge != nil && *rollingUpdate.MaxSurge == surge && rollingUpdate.MaxUnavailable != nil && *rollingUpdate.MaxUnavailable == unavailable {
+		return false, nil
+	}
+	rollingUpdate.MaxSurge, rollingUpdate.MaxUnavailable = &surge, &unavailable
+	return true, nil
+}
+
 // desiredRouterDeployment returns the desired router deployment.
 func desiredRouterDeployment(ci *operatorv1.IngressController, ingressControllerImage string, ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure, apiConfig *configv1.APIServer, networkConfig *configv1.Network, proxyNeeded bool, haveClientCAConfigmap bool, clientCAConfigmap *corev1.ConfigMap) (*appsv1.Deployment, error) {
 	deployment := manifests.RouterDeployment()
@@ -241,15 +611,7 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	gracePeriod := int64(60 * 60)
 	deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &gracePeriod
 
-	// Services behind load balancers should roll out new instances only after we are certain
-	// the new instance is part of rotation. This is set based on the highest value across all
-	// platforms, excluding custom load balancers like an F5, but our recommendation for these
-	// values for those should be indentical to the slowest cloud, AWS (which does not allow
-	// health checks to be more frequent than 10 seconds).
-	deployment.Spec.MinReadySeconds =
-		(2 + /* max healthy checks required to be brought into rotation across all platforms */
-			1) * /* we could miss one */
-			10 /* the longest health check interval on any platform */
+	deployment.Spec.MinReadySeconds = routerDefaultMinReadySeconds
 
 	volumes := deployment.Spec.Template.Spec.Volumes
 	routerVolumeMounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts
@@ -257,6 +619,24 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	desiredReplicas := determineDeploymentReplicas(ci, ingressConfig, infraConfig)
 	deployment.Spec.Replicas = &desiredReplicas
 
+	unsupportedConfigOverrides, err := getUnsupportedConfigOverrides(ci)
+	if err != nil {
+		return nil, err
+	}
+	// Load balancers that take longer to bring a new instance into
+	// rotation than the default allows need a longer window.
+	if unsupportedConfigOverrides.MinReadySeconds != nil {
+		deployment.Spec.MinReadySeconds = *unsupportedConfigOverrides.MinReadySeconds
+	}
+	// A rolling update that makes no progress, for example because a new
+	// pod cannot be scheduled, is reported once the deadline is exceeded
+	// rather than hanging silently.
+	progressDeadlineSeconds := int32(routerDefaultProgressDeadlineSeconds)
+	if unsupportedConfigOverrides.ProgressDeadlineSeconds != nil {
+		progressDeadlineSeconds = *unsupportedConfigOverrides.ProgressDeadlineSeconds
+	}
+	deployment.Spec.ProgressDeadlineSeconds = &progressDeadlineSeconds
+
 	configureAffinity := false
 	switch ci.Status.EndpointPublishingStrategy.Type {
 	case operatorv1.HostNetworkStrategyType:
@@ -264,14 +644,24 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		// set equal to the node pool size, in which case, using surge
 		// for rolling updates would fail to create new replicas (in the
 		// absence of node auto-scaling).  Thus, when using HostNetwork,
-		// we set max unavailable to 25% and surge to 0.
-		pointerTo := func(ios intstr.IntOrString) *intstr.IntOrString { return &ios }
-		deployment.Spec.Strategy = appsv1.DeploymentStrategy{
-			Type: appsv1.RollingUpdateDeploymentStrategyType,
-			RollingUpdate: &appsv1.RollingUpdateDeployment{
-				MaxUnavailable: pointerTo(intstr.FromString("25%")),
-				MaxSurge:       pointerTo(intstr.FromInt(0)),
-			},
+		// we set max unavailable to 25% and surge to 0 (ensureRouterDeployment
+		// uses surge if the cluster autoscaler is enabled).  Alternatively,
+		// the ingresscontroller may request the Recreate strategy so
+		// that all replicas are replaced at once, for example when
+		// rollouts are coordinated with an external drain.
+		if unsupportedConfigOverrides.DeploymentStrategy == deploymentStrategyRecreate {
+			deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			}
+		} else {
+			pointerTo := func(ios intstr.IntOrString) *intstr.IntOrString { return &ios }
+			deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: pointerTo(intstr.FromString("25%")),
+					MaxSurge:       pointerTo(intstr.FromInt(0)),
+				},
+			}
 		}
 
 		// Pod replicas for ingress controllers that use the host
@@ -279,7 +669,8 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		// would conflict with each other by trying to bind the same
 		// ports.  The scheduler avoids scheduling multiple pods that
 		// use host networking and specify the same port to the same
-		// node.  Thus no affinity policy is required when using
+		// node, and the HTTP, HTTPS, and stats ports are all specified
+		// as host ports.  Thus no affinity policy is required when using
 		// HostNetwork.
 	case operatorv1.PrivateStrategyType, operatorv1.LoadBalancerServiceStrategyType, operatorv1.NodePortServiceStrategyType:
 		// To avoid downtime during a rolling update, we need two
@@ -321,39 +712,19 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		// rolling update continues to have local endpoints for the
 		// duration of and at the completion of the update.
 		configureAffinity = true
-		deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{
-			PodAffinity: &corev1.PodAffinity{
-				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
-					{
-						Weight: int32(100),
-						PodAffinityTerm: corev1.PodAffinityTerm{
-							TopologyKey: "kubernetes.io/hostname",
-							LabelSelector: &metav1.LabelSelector{
-								MatchExpressions: []metav1.LabelSelectorRequirement{
-									{
-										Key:      controller.ControllerDeploymentLabel,
-										Operator: metav1.LabelSelectorOpIn,
-										Values:   []string{controller.IngressControllerDeploymentLabel(ci)},
-									},
-									{
-										Key:      controller.ControllerDeploymentHashLabel,
-										Operator: metav1.LabelSelectorOpNotIn,
-										// Values is set at the end of this function.
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			// TODO: Once https://issues.redhat.com/browse/RFE-1759
-			// is implemented, replace
-			// "RequiredDuringSchedulingIgnoredDuringExecution" with
-			// "PreferredDuringSchedulingIgnoredDuringExecution".
-			PodAntiAffinity: &corev1.PodAntiAffinity{
-				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
-					{
-						TopologyKey: "kubernetes.io/hostname",
+		topologyKey := corev1.LabelHostname
+		if key := unsupportedConfigOverrides.AffinityTopologyKey; len(key) != 0 {
+			topologyKey = key
+		}
+		if deployment.Spec.Template.Spec.Affinity == nil {
+			deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{}
+		}
+		deployment.Spec.Template.Spec.Affinity.PodAffinity = &corev1.PodAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: int32(100),
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						TopologyKey: topologyKey,
 						LabelSelector: &metav1.LabelSelector{
 							MatchExpressions: []metav1.LabelSelectorRequirement{
 								{
@@ -363,7 +734,7 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 								},
 								{
 									Key:      controller.ControllerDeploymentHashLabel,
-									Operator: metav1.LabelSelectorOpIn,
+									Operator: metav1.LabelSelectorOpNotIn,
 									// Values is set at the end of this function.
 								},
 							},
@@ -372,6 +743,62 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 				},
 			},
 		}
+		deployment.Spec.Template.Spec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		// If the node of a pod of the old generation cannot accept a
+		// new pod, the zone-aware term at least keeps the new pod in
+		// the same zone, which matters for source IP preservation with
+		// zonal load balancers.
+		if unsupportedConfigOverrides.ZoneAwareAffinity && topologyKey != corev1.LabelTopologyZone {
+			podAffinity := deployment.Spec.Template.Spec.Affinity.PodAffinity
+			zoneTerm := *podAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].DeepCopy()
+			zoneTerm.Weight = int32(50)
+			zoneTerm.PodAffinityTerm.TopologyKey = corev1.LabelTopologyZone
+			podAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(podAffinity.PreferredDuringSchedulingIgnoredDuringExecution, zoneTerm)
+		}
+		antiAffinityTerm := corev1.PodAffinityTerm{
+			TopologyKey: topologyKey,
+			LabelSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      controller.ControllerDeploymentLabel,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{controller.IngressControllerDeploymentLabel(ci)},
+					},
+					{
+						Key:      controller.ControllerDeploymentHashLabel,
+						Operator: metav1.LabelSelectorOpIn,
+						// Values is set at the end of this function.
+					},
+				},
+			},
+		}
+		// Required anti-affinity leaves replicas in excess of the
+		// number of eligible nodes pending, so the ingresscontroller
+		// may relax it to preferred anti-affinity, at the cost of
+		// possibly colocating replicas of the same generation and thus
+		// losing a node's local endpoints during a rolling update.
+		if unsupportedConfigOverrides.PodAntiAffinity == podAntiAffinityPreferred {
+			deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.WeightedPodAffinityTerm{{
+				Weight:          int32(100),
+				PodAffinityTerm: antiAffinityTerm,
+			}}
+		} else {
+			deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []corev1.PodAffinityTerm{antiAffinityTerm}
+		}
+	}
+
+	// Apply the rolling update parameters that the ingresscontroller
+	// overrides.  The computed values above are kept for any parameter
+	// that is not overridden.
+	if rollingUpdate := unsupportedConfigOverrides.RollingUpdate; rollingUpdate != nil && deployment.Spec.Strategy.RollingUpdate != nil {
+		if rollingUpdate.MaxUnavailable != nil {
+			maxUnavailable := *rollingUpdate.MaxUnavailable
+			deployment.Spec.Strategy.RollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		if rollingUpdate.MaxSurge != nil {
+			maxSurge := *rollingUpdate.MaxSurge
+			deployment.Spec.Strategy.RollingUpdate.MaxSurge = &maxSurge
+		}
 	}
 
 	// Configure topology constraints to spread replicas across availability
@@ -462,11 +889,11 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		routerVolumeMounts = append(routerVolumeMounts, httpErrorCodeVolumeMount)
 		if len(configmapName.Name) != 0 {
 			env = append(env, corev1.EnvVar{
-				Name:  "ROUTER_ERRORFILE_503",
+				Name:  RouterErrorFile503EnvName,
 				Value: "/var/lib/haproxy/conf/error_code_pages/error-page-503.http",
 			})
 			env = append(env, corev1.EnvVar{
-				Name:  "ROUTER_ERRORFILE_404",
+				Name:  RouterErrorFile404EnvName,
 				Value: "/var/lib/haproxy/conf/error_code_pages/error-page-404.http",
 			})
 		}
@@ -476,22 +903,13 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TLS_CERT_FILE", Value: filepath.Join(certsVolumeMountPath, "tls.crt")})
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TLS_KEY_FILE", Value: filepath.Join(certsVolumeMountPath, "tls.key")})
 
-	var unsupportedConfigOverrides struct {
-		LoadBalancingAlgorithm string `json:"loadBalancingAlgorithm"`
-		DynamicConfigManager   string `json:"dynamicConfigManager"`
-		ReloadInterval         int32  `json:"reloadInterval"`
-	}
-	if len(ci.Spec.UnsupportedConfigOverrides.Raw) > 0 {
-		if err := json.Unmarshal(ci.Spec.UnsupportedConfigOverrides.Raw, &unsupportedConfigOverrides); err != nil {
-			return nil, fmt.Errorf("ingresscontroller %q has invalid spec.unsupportedConfigOverrides: %w", ci.Name, err)
-		}
-	}
-
 	// For non-TLS, edge-terminated, and reencrypt routes, use the
 	// "random" balancing algorithm by default, but allow an unsupported
 	// config override to override it.  For passthrough routes, use the
 	// "source" balancing algorithm in order to provide some
-	// session-affinity.
+	// session-affinity.  The sourceHashBalancing override selects a hash
+	// for the other routes as well; the router cannot read the headers of
+	// passthrough connections, so those always use "source".
 	// We've had issues with "random" in the past due to it incurring significant
 	// memory overhead with large weights on the server lines in haproxy config;
 	// however we mitigated that in openshift-router by effectively setting all
@@ -503,6 +921,9 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	case "leastconn":
 		loadBalancingAlgorithm = "leastconn"
 	}
+	if balancing := unsupportedConfigOverrides.SourceHashBalancing; balancing != nil {
+		loadBalancingAlgorithm = balancing.algorithm()
+	}
 	env = append(env, corev1.EnvVar{
 		Name:  RouterLoadBalancingAlgorithmEnvName,
 		Value: loadBalancingAlgorithm,
@@ -541,6 +962,13 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		})
 	}
 
+	if backend := unsupportedConfigOverrides.DefaultBackend; backend != nil && len(backend.Name) != 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  RouterDefaultBackendEnvName,
+			Value: defaultBackendAddress(backend),
+		})
+	}
+
 	if len(ci.Status.Domain) > 0 {
 		cName := "router-" + ci.Name + "." + ci.Status.Domain
 		env = append(env,
@@ -553,20 +981,24 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		env = append(env, corev1.EnvVar{Name: "ROUTER_USE_PROXY_PROTOCOL", Value: "true"})
 	}
 
-	threads := RouterHAProxyThreadsDefaultValue
-	if ci.Spec.TuningOptions.ThreadCount > 0 {
-		threads = int(ci.Spec.TuningOptions.ThreadCount)
-	}
-	env = append(env, corev1.EnvVar{Name: RouterHAProxyThreadsEnvName, Value: strconv.Itoa(threads)})
+	routerResources := desiredRouterResources(deployment.Spec.Template.Spec.Containers[0].Resources, unsupportedConfigOverrides)
+	threads := routerThreadCount(ci, routerResources)
+	env = append(env, corev1.EnvVar{Name: RouterHAProxyThreadsEnvName, Value: strconv.Itoa(int(threads))})
 
+	// In gRPC mode, long-lived streams need longer timeouts than the
+	// router's defaults, but explicit tuning options take precedence.
 	if ci.Spec.TuningOptions.ClientTimeout != nil && ci.Spec.TuningOptions.ClientTimeout.Duration > 0*time.Second {
 		env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_CLIENT_TIMEOUT", Value: durationToHAProxyTimespec(ci.Spec.TuningOptions.ClientTimeout.Duration)})
+	} else if unsupportedConfigOverrides.GRPCMode {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_CLIENT_TIMEOUT", Value: durationToHAProxyTimespec(grpcDefaultTimeout)})
 	}
 	if ci.Spec.TuningOptions.ClientFinTimeout != nil && ci.Spec.TuningOptions.ClientFinTimeout.Duration > 0*time.Second {
 		env = append(env, corev1.EnvVar{Name: "ROUTER_CLIENT_FIN_TIMEOUT", Value: durationToHAProxyTimespec(ci.Spec.TuningOptions.ClientFinTimeout.Duration)})
 	}
 	if ci.Spec.TuningOptions.ServerTimeout != nil && ci.Spec.TuningOptions.ServerTimeout.Duration > 0*time.Second {
 		env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_SERVER_TIMEOUT", Value: durationToHAProxyTimespec(ci.Spec.TuningOptions.ServerTimeout.Duration)})
+	} else if unsupportedConfigOverrides.GRPCMode {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_SERVER_TIMEOUT", Value: durationToHAProxyTimespec(grpcDefaultTimeout)})
 	}
 	if ci.Spec.TuningOptions.ServerFinTimeout != nil && ci.Spec.TuningOptions.ServerFinTimeout.Duration > 0*time.Second {
 		env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_SERVER_FIN_TIMEOUT", Value: durationToHAProxyTimespec(ci.Spec.TuningOptions.ServerFinTimeout.Duration)})
@@ -594,12 +1026,23 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 
 	if ci.Spec.NodePlacement != nil {
 		if ci.Spec.NodePlacement.NodeSelector != nil {
-			var err error
-			nodeSelector, err = metav1.LabelSelectorAsMap(ci.Spec.NodePlacement.NodeSelector)
+			var (
+				nodeAffinity *corev1.NodeAffinity
+				err          error
+			)
+			nodeSelector, nodeAffinity, err = nodePlacementSelector(ci.Spec.NodePlacement.NodeSelector)
 			if err != nil {
 				return nil, fmt.Errorf("ingresscontroller %q has invalid spec.nodePlacement.nodeSelector: %v",
 					ci.Name, err)
 			}
+			// Merge the node affinity with any pod affinity and
+			// anti-affinity policy rather than replacing it.
+			if nodeAffinity != nil {
+				if deployment.Spec.Template.Spec.Affinity == nil {
+					deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{}
+				}
+				deployment.Spec.Template.Spec.Affinity.NodeAffinity = nodeAffinity
+			}
 		}
 		if ci.Spec.NodePlacement.Tolerations != nil {
 			deployment.Spec.Template.Spec.Tolerations = ci.Spec.NodePlacement.Tolerations
@@ -620,11 +1063,20 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		})
 	}
 
+	routeSelector := labels.Everything()
 	if ci.Spec.RouteSelector != nil {
-		routeSelector, err := metav1.LabelSelectorAsSelector(ci.Spec.RouteSelector)
+		routeSelector, err = metav1.LabelSelectorAsSelector(ci.Spec.RouteSelector)
 		if err != nil {
 			return nil, fmt.Errorf("ingresscontroller %q has invalid spec.routeSelector: %v", ci.Name, err)
 		}
+	}
+	// Exclude routes that exceed the ingresscontroller's route quota.
+	if requirements, err := routeQuotaExceededSelector(ci); err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has an invalid route quota: %v", ci.Name, err)
+	} else if len(requirements) != 0 {
+		routeSelector = routeSelector.Add(requirements...)
+	}
+	if !routeSelector.Empty() {
 		env = append(env, corev1.EnvVar{Name: "ROUTE_LABELS", Value: routeSelector.String()})
 	}
 
@@ -690,6 +1142,44 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	secretName := controller.RouterEffectiveDefaultCertificateSecretName(ci, deployment.Namespace)
 	deployment.Spec.Template.Spec.Volumes[0].Secret.SecretName = secretName.Name
 
+	// Project the certificates for any additional wildcard domains into
+	// one directory with a subdirectory per domain, from which the router
+	// selects certificates using SNI.  The sources are optional so that
+	// the router can start before the certificate controller has
+	// generated a certificate.  The kubelet updates the projected files
+	// when a certificate is rotated, so rotation does not require a
+	// rollout.
+	if domains := unsupportedConfigOverrides.AdditionalWildcardDomains; len(domains) != 0 {
+		optional := true
+		var sources []corev1.VolumeProjection
+		for _, domain := range domains {
+			name := controller.RouterWildcardCertificateSecretName(ci, deployment.Namespace, domain)
+			sources = append(sources, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name.Name},
+					Items: []corev1.KeyToPath{
+						{Key: "tls.crt", Path: domain + "/tls.crt"},
+						{Key: "tls.key", Path: domain + "/tls.key"},
+					},
+					Optional: &optional,
+				},
+			})
+		}
+		wildcardCertsVolume := corev1.Volume{
+			Name: wildcardCertificatesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+			},
+		}
+		volumes = append(volumes, wildcardCertsVolume)
+		routerVolumeMounts = append(routerVolumeMounts, corev1.VolumeMount{
+			Name:      wildcardCertsVolume.Name,
+			MountPath: wildcardCertificatesVolumeMountPath,
+			ReadOnly:  true,
+		})
+		env = append(env, corev1.EnvVar{Name: RouterWildcardCertificatesDirEnvName, Value: wildcardCertificatesVolumeMountPath})
+	}
+
 	if accessLogging := accessLoggingForIngressController(ci); accessLogging != nil {
 		switch {
 		case accessLogging.Destination.Type == operatorv1.ContainerLoggingDestinationType:
@@ -750,6 +1240,18 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 				},
 			}
 
+			// rsyslog only reads its configuration on startup.
+			rsyslogConfig, err := rsyslogConfigurationForIngressController(ci)
+			if err != nil {
+				return nil, err
+			}
+			if deployment.Spec.Template.Annotations == nil {
+				deployment.Spec.Template.Annotations = map[string]string{}
+			}
+			deployment.Spec.Template.Annotations[RsyslogConfigHashAnnotation] = configMapContentHash(&corev1.ConfigMap{
+				Data: map[string]string{"rsyslog.conf": rsyslogConfig},
+			})
+
 			env = append(env,
 				corev1.EnvVar{Name: RouterSyslogAddressEnvName, Value: socketPath},
 				corev1.EnvVar{Name: RouterLogLevelEnvName, Value: "info"},
@@ -770,6 +1272,11 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 			address := accessLogging.Destination.Syslog.Address
 			port := accessLogging.Destination.Syslog.Port
 			endpoint := net.JoinHostPort(address, fmt.Sprintf("%d", port))
+			if unsupportedConfigOverrides.SyslogProtocol == syslogProtocolTCP {
+				// HAProxy sends logs over TCP to targets
+				// with the "tcp@" prefix.
+				endpoint = "tcp@" + endpoint
+			}
 			env = append(env,
 				corev1.EnvVar{Name: RouterLogLevelEnvName, Value: "info"},
 				corev1.EnvVar{Name: RouterSyslogAddressEnvName, Value: endpoint},
@@ -853,6 +1360,22 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	}
 	env = append(env, corev1.EnvVar{Name: "SSL_MIN_VERSION", Value: minTLSVersion})
 
+	switch unsupportedConfigOverrides.CipherPreference {
+	case cipherPreferenceServer:
+		env = append(env, corev1.EnvVar{Name: RouterPreferServerCiphersEnvName, Value: "true"})
+	case cipherPreferenceClient:
+		env = append(env, corev1.EnvVar{Name: RouterPreferServerCiphersEnvName, Value: "false"})
+	}
+
+	if unsupportedConfigOverrides.CertificateFailurePolicy == certificateFailurePolicyFailClosed {
+		env = append(env, corev1.EnvVar{Name: RouterRejectInvalidDefaultCertificateEnvName, Value: "true"})
+	}
+
+	// Set the framing mode explicitly so that the router is strict by
+	// default regardless of the router image's default.
+	strictRequestFraming := unsupportedConfigOverrides.RequestSmugglingProtection != requestSmugglingProtectionLenient
+	env = append(env, corev1.EnvVar{Name: RouterStrictRequestFramingEnvName, Value: strconv.FormatBool(strictRequestFraming)})
+
 	usingIPv4 := false
 	usingIPv6 := false
 	for _, clusterNetworkEntry := range networkConfig.Status.ClusterNetwork {
@@ -874,18 +1397,7 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		env = append(env, corev1.EnvVar{Name: "ROUTER_IP_V4_V6_MODE", Value: mode})
 	}
 
-	routeAdmission := operatorv1.RouteAdmissionPolicy{
-		NamespaceOwnership: operatorv1.StrictNamespaceOwnershipCheck,
-		WildcardPolicy:     operatorv1.WildcardPolicyDisallowed,
-	}
-	if admission := ci.Spec.RouteAdmission; admission != nil {
-		if len(admission.NamespaceOwnership) > 0 {
-			routeAdmission.NamespaceOwnership = admission.NamespaceOwnership
-		}
-		if len(admission.WildcardPolicy) > 0 {
-			routeAdmission.WildcardPolicy = admission.WildcardPolicy
-		}
-	}
+	routeAdmission := effectiveRouteAdmissionPolicy(ci)
 	switch routeAdmission.NamespaceOwnership {
 	case operatorv1.StrictNamespaceOwnershipCheck:
 		env = append(env, corev1.EnvVar{Name: "ROUTER_DISABLE_NAMESPACE_OWNERSHIP_CHECK", Value: "false"})
@@ -916,6 +1428,20 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	}
 	env = append(env, corev1.EnvVar{Name: RouterForwardedHeadersPolicy, Value: routerForwardedHeadersPolicyValue})
 
+	// The X-Forwarded-Proto header can only be trusted if the router
+	// keeps the incoming forwarded headers.
+	if unsupportedConfigOverrides.TrustForwardedProto && forwardedHeaderPolicy != operatorv1.ReplaceHTTPHeaderPolicy {
+		env = append(env, corev1.EnvVar{Name: RouterTrustForwardedProtoEnvName, Value: "true"})
+	}
+
+	if unsupportedConfigOverrides.GRPCMode {
+		env = append(env, corev1.EnvVar{Name: RouterGRPCModeEnvName, Value: "true"})
+	}
+
+	if option, ok := httpConnectionModeOptions[unsupportedConfigOverrides.HTTPConnectionMode]; ok {
+		env = append(env, corev1.EnvVar{Name: RouterHTTPConnectionModeEnvName, Value: option})
+	}
+
 	if ci.Spec.HTTPHeaders != nil && len(ci.Spec.HTTPHeaders.UniqueId.Name) > 0 {
 		headerName := ci.Spec.HTTPHeaders.UniqueId.Name
 		headerFormat := ci.Spec.HTTPHeaders.UniqueId.Format
@@ -943,9 +1469,15 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 
 	if HTTP2IsEnabled(ci, ingressConfig) {
 		env = append(env, corev1.EnvVar{Name: RouterDisableHTTP2EnvName, Value: "false"})
+		if v := unsupportedConfigOverrides.HTTP2MaxConcurrentStreams; v != 0 {
+			env = append(env, corev1.EnvVar{Name: RouterHTTP2MaxConcurrentStreamsEnvName, Value: strconv.Itoa(int(v))})
+		}
 	} else {
 		env = append(env, corev1.EnvVar{Name: RouterDisableHTTP2EnvName, Value: "true"})
 	}
+	if len(unsupportedConfigOverrides.ALPNProtocols) != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterALPNProtocolsEnvName, Value: strings.Join(unsupportedConfigOverrides.ALPNProtocols, ",")})
+	}
 
 	if enabled, value := HardStopAfterIsEnabled(ci, ingressConfig); enabled {
 		env = append(env, corev1.EnvVar{Name: RouterHardStopAfterEnvName, Value: value})
@@ -963,6 +1495,88 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 			int(ci.Spec.TuningOptions.HeaderBufferMaxRewriteBytes))})
 	}
 
+	if unsupportedConfigOverrides.MaxHeaderCount != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterMaxHeaderCountEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.MaxHeaderCount))})
+	}
+
+	if unsupportedConfigOverrides.SSLCacheSize != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterSSLCacheSizeEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.SSLCacheSize))})
+	}
+
+	if unsupportedConfigOverrides.SSLMaxRecordSize != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterSSLMaxRecordSizeEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.SSLMaxRecordSize))})
+	}
+
+	if unsupportedConfigOverrides.MaxSSLHandshakeRate != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterMaxSSLRateEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.MaxSSLHandshakeRate))})
+	}
+
+	if unsupportedConfigOverrides.BackendPoolMaxConnections != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterBackendPoolMaxConnectionsEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.BackendPoolMaxConnections))})
+	}
+
+	if unsupportedConfigOverrides.ServerMaxConnections != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterServerMaxConnectionsEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.ServerMaxConnections))})
+	}
+
+	if unsupportedConfigOverrides.Backlog != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterBacklogEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.Backlog))})
+	}
+
+	if keepalive := unsupportedConfigOverrides.TCPKeepalive; keepalive != nil {
+		env = append(env, corev1.EnvVar{Name: RouterTCPKeepaliveEnvName, Value: "true"})
+		if keepalive.IdleSeconds > 0 {
+			idle := time.Duration(keepalive.IdleSeconds) * time.Second
+			env = append(env, corev1.EnvVar{Name: RouterTCPKeepaliveIdleEnvName, Value: durationToHAProxyTimespec(idle)})
+		}
+		if keepalive.IntervalSeconds > 0 {
+			interval := time.Duration(keepalive.IntervalSeconds) * time.Second
+			env = append(env, corev1.EnvVar{Name: RouterTCPKeepaliveIntervalEnvName, Value: durationToHAProxyTimespec(interval)})
+		}
+		if keepalive.Count > 0 {
+			env = append(env, corev1.EnvVar{Name: RouterTCPKeepaliveCountEnvName, Value: strconv.Itoa(int(keepalive.Count))})
+		}
+	}
+
+	if tracing := unsupportedConfigOverrides.Tracing; tracing != nil {
+		env = append(env,
+			corev1.EnvVar{Name: RouterTracingEnvName, Value: "true"},
+			corev1.EnvVar{Name: RouterTracingEndpointEnvName, Value: tracing.Endpoint},
+		)
+		if tracing.SamplingRatio != nil {
+			env = append(env, corev1.EnvVar{Name: RouterTracingSamplingRatioEnvName, Value: strconv.FormatFloat(*tracing.SamplingRatio, 'f', -1, 64)})
+		}
+	}
+
+	if hsts := unsupportedConfigOverrides.HSTS; hsts != nil {
+		env = append(env, corev1.EnvVar{Name: RouterDefaultHSTSHeaderEnvName, Value: hsts.header()})
+	}
+
+	if unsupportedConfigOverrides.StatsTimeoutSeconds != 0 {
+		timeout := time.Duration(unsupportedConfigOverrides.StatsTimeoutSeconds) * time.Second
+		env = append(env, corev1.EnvVar{Name: RouterStatsTimeoutEnvName, Value: durationToHAProxyTimespec(timeout)})
+	}
+
+	if unsupportedConfigOverrides.DefaultRouteRequestRateLimit != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterDefaultRateLimitHTTPEnvName, Value: strconv.Itoa(
+			int(unsupportedConfigOverrides.DefaultRouteRequestRateLimit))})
+	}
+
+	if host := unsupportedConfigOverrides.DefaultHostHeader; len(host) != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterDefaultHostHeaderEnvName, Value: host})
+	}
+
+	if methods := unsupportedConfigOverrides.ReadMethods; len(methods) != 0 {
+		env = append(env, corev1.EnvVar{Name: RouterReadMethodsEnvName, Value: strings.Join(methods, " ")})
+	}
+
 	if len(ci.Spec.ClientTLS.ClientCertificatePolicy) != 0 {
 		var clientAuthPolicy string
 		switch ci.Spec.ClientTLS.ClientCertificatePolicy {
@@ -971,9 +1585,21 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		case operatorv1.ClientCertificatePolicyOptional:
 			clientAuthPolicy = "optional"
 		}
-		env = append(env,
-			corev1.EnvVar{Name: RouterClientAuthPolicy, Value: clientAuthPolicy},
-		)
+		if unsupportedConfigOverrides.PerRouteClientCertificatePolicy {
+			// The TLS handshake must request a client certificate
+			// without requiring one so that the router can decide
+			// per route whether to reject a connection that does
+			// not present a valid certificate.
+			env = append(env,
+				corev1.EnvVar{Name: RouterClientAuthPolicy, Value: "optional"},
+				corev1.EnvVar{Name: RouterClientAuthPerRoute, Value: "true"},
+				corev1.EnvVar{Name: RouterClientAuthDefaultPolicy, Value: clientAuthPolicy},
+			)
+		} else {
+			env = append(env,
+				corev1.EnvVar{Name: RouterClientAuthPolicy, Value: clientAuthPolicy},
+			)
+		}
 
 		if len(ci.Spec.ClientTLS.ClientCA.Name) != 0 {
 			clientCAConfigmapName := controller.ClientCAConfigMapName(ci)
@@ -1013,6 +1639,11 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 				// need to configure a configmap volume.  The
 				// crl controller is responsible for managing
 				// the configmap.
+				if deployment.Spec.Template.Annotations == nil {
+					deployment.Spec.Template.Annotations = map[string]string{}
+				}
+				deployment.Spec.Template.Annotations[ClientCAConfigMapHashAnnotation] = configMapContentHash(clientCAConfigmap)
+
 				var clientCAData []byte
 				if v, ok := clientCAConfigmap.Data[clientCABundleFilename]; !ok {
 					return nil, fmt.Errorf("client CA configmap %s/%s is missing %q", clientCAConfigmap.Namespace, clientCAConfigmap.Name, clientCABundleFilename)
@@ -1086,11 +1717,30 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		env = append(env, corev1.EnvVar{Name: RouterEnableCompression, Value: "true"})
 		mimes := GetMIMETypes(ci.Spec.HTTPCompression.MimeTypes)
 		env = append(env, corev1.EnvVar{Name: RouterCompressionMIMETypes, Value: strings.Join(mimes, " ")})
+		// The compression level has no effect unless compression is
+		// enabled.
+		if unsupportedConfigOverrides.CompressionLevel != 0 {
+			env = append(env, corev1.EnvVar{Name: RouterCompressionLevel, Value: strconv.Itoa(int(unsupportedConfigOverrides.CompressionLevel))})
+		}
 	}
 
 	// Add the environment variables to the container
 	deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, env...)
 
+	deployment.Spec.Template.Spec.Containers[0].Resources = routerResources
+
+	// Leave the fsGroup to the pod's security context constraint unless
+	// it is overridden.  The constraint that the router uses requires the
+	// fsGroup to be in the namespace's group range, so setting a default
+	// here would make pod admission fail.
+	if unsupportedConfigOverrides.FSGroup != nil {
+		fsGroup := *unsupportedConfigOverrides.FSGroup
+		if deployment.Spec.Template.Spec.SecurityContext == nil {
+			deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		deployment.Spec.Template.Spec.SecurityContext.FSGroup = &fsGroup
+	}
+
 	// Add the ports to the container
 	deployment.Spec.Template.Spec.Containers[0].Ports = append(
 		deployment.Spec.Template.Spec.Containers[0].Ports,
@@ -1110,6 +1760,38 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 			Protocol:      corev1.ProtocolTCP,
 		},
 	)
+	// With host networking, the API defaults each port's host port to its
+	// container port.  Set the host ports explicitly so that the desired
+	// deployment matches the defaulted one and so that the scheduler, which
+	// does not place pods that use the same host port on the same node,
+	// accounts for the stats port as well as the HTTP and HTTPS ports.
+	if deployment.Spec.Template.Spec.HostNetwork {
+		for i := range deployment.Spec.Template.Spec.Containers[0].Ports {
+			port := &deployment.Spec.Template.Spec.Containers[0].Ports[i]
+			port.HostPort = port.ContainerPort
+		}
+	}
+
+	// If the load balancer service is shared, label the pods so that the
+	// shared service selects them, and give the HTTP and HTTPS ports
+	// additional names that are unique to the ingresscontroller so that
+	// the shared service can target this ingresscontroller's pods.
+	if name := unsupportedConfigOverrides.SharedLoadBalancerService; len(name) != 0 && ci.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType {
+		deployment.Spec.Template.Labels[controller.SharedLoadBalancerServiceLabel] = name
+		deployment.Spec.Template.Spec.Containers[0].Ports = append(
+			deployment.Spec.Template.Spec.Containers[0].Ports,
+			corev1.ContainerPort{
+				Name:          sharedHTTPPortName(ci),
+				ContainerPort: httpPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+			corev1.ContainerPort{
+				Name:          sharedHTTPSPortName(ci),
+				ContainerPort: httpsPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		)
+	}
 
 	// Compute the hash for topology spread constraints and possibly
 	// affinity policy now, after all the other fields have been computed,
@@ -1119,8 +1801,15 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	values := []string{hash}
 	deployment.Spec.Template.Spec.TopologySpreadConstraints[0].LabelSelector.MatchExpressions[0].Values = values
 	if configureAffinity {
-		deployment.Spec.Template.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm.LabelSelector.MatchExpressions[1].Values = values
-		deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchExpressions[1].Values = values
+		for i := range deployment.Spec.Template.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			deployment.Spec.Template.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm.LabelSelector.MatchExpressions[1].Values = values
+		}
+		antiAffinity := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity
+		if len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0 {
+			antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchExpressions[1].Values = values
+		} else {
+			antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm.LabelSelector.MatchExpressions[1].Values = values
+		}
 	}
 
 	return deployment, nil
@@ -1259,6 +1948,27 @@ func deploymentTemplateHash(deployment *appsv1.Deployment) string {
 	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
 
+// configMapContentHash returns a stringified hash value for the content of the
+// given configmap.  Metadata such as the resource version, labels, and
+// annotations is ignored so that metadata-only updates do not change the hash.
+func configMapContentHash(configmap *corev1.ConfigMap) string {
+	hasher := fnv.New32a()
+	deepHashObject(hasher, struct {
+		Data       map[string]string
+		BinaryData map[string][]byte
+	}{configmap.Data, configmap.BinaryData})
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// hasRequiredPodAntiAffinity returns a Boolean value indicating whether the
+// given deployment's pods must be scheduled on nodes that do not have other
+// pods of the deployment, which desiredRouterDeployment requires for
+// deployment strategies that do not depend on colocating replicas.
+func hasRequiredPodAntiAffinity(deployment *appsv1.Deployment) bool {
+	affinity := deployment.Spec.Template.Spec.Affinity
+	return affinity != nil && affinity.PodAntiAffinity != nil && len(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0
+}
+
 // hashableDeployment returns a copy of the given deployment with exactly the
 // fields from deployment that should be used for computing its hash copied
 // over.  In particular, these are the fields that desiredRouterDeployment sets.
@@ -1298,6 +2008,14 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 					return cmpMatchExpressions(exprs[i], exprs[j])
 				})
 			}
+			for _, term := range affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				labelSelector := term.PodAffinityTerm.LabelSelector
+				zeroOutDeploymentHash(labelSelector)
+				exprs := labelSelector.MatchExpressions
+				sort.Slice(exprs, func(i, j int) bool {
+					return cmpMatchExpressions(exprs[i], exprs[j])
+				})
+			}
 		}
 	}
 	hashableDeployment.Spec.Template.Spec.Affinity = affinity
@@ -1343,6 +2061,7 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 			StartupProbe:    hashableProbe(container.StartupProbe),
 			SecurityContext: container.SecurityContext,
 			Ports:           container.Ports,
+			Resources:       hashableResources(container.Resources),
 		}
 	}
 	sort.Slice(containers, func(i, j int) bool {
@@ -1350,6 +2069,9 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 	})
 	hashableDeployment.Spec.Template.Spec.Containers = containers
 	hashableDeployment.Spec.Template.Spec.DNSPolicy = deployment.Spec.Template.Spec.DNSPolicy
+	if sc := deployment.Spec.Template.Spec.SecurityContext; sc != nil && sc.FSGroup != nil {
+		hashableDeployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{FSGroup: sc.FSGroup}
+	}
 	hashableDeployment.Spec.Template.Spec.HostNetwork = deployment.Spec.Template.Spec.HostNetwork
 	volumes := make([]corev1.Volume, len(deployment.Spec.Template.Spec.Volumes))
 	for i, vol := range deployment.Spec.Template.Spec.Volumes {
@@ -1362,13 +2084,16 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 		if vol.Secret != nil && vol.Secret.DefaultMode != nil && *vol.Secret.DefaultMode == int32(420) {
 			volumes[i].Secret.DefaultMode = nil
 		}
+		if vol.Projected != nil && vol.Projected.DefaultMode != nil && *vol.Projected.DefaultMode == int32(420) {
+			volumes[i].Projected.DefaultMode = nil
+		}
 	}
 	sort.Slice(volumes, func(i, j int) bool {
 		return volumes[i].Name < volumes[j].Name
 	})
 	hashableDeployment.Spec.Template.Spec.Volumes = volumes
 	hashableDeployment.Spec.Template.Annotations = make(map[string]string)
-	annotations := []string{LivenessGracePeriodSecondsAnnotation, WorkloadPartitioningManagement}
+	annotations := []string{LivenessGracePeriodSecondsAnnotation, WorkloadPartitioningManagement, ClientCAConfigMapHashAnnotation, RsyslogConfigHashAnnotation, MaintenancePageConfigMapHashAnnotation}
 	for _, key := range annotations {
 		if val, ok := deployment.Spec.Template.Annotations[key]; ok && len(val) > 0 {
 			hashableDeployment.Spec.Template.Annotations[key] = val
@@ -1383,6 +2108,7 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 	// update of the deployment but should not trigger a rolling update.
 	hashableDeployment.Labels = deployment.Labels
 	hashableDeployment.Spec.MinReadySeconds = deployment.Spec.MinReadySeconds
+	hashableDeployment.Spec.ProgressDeadlineSeconds = deployment.Spec.ProgressDeadlineSeconds
 	hashableDeployment.Spec.Strategy = deployment.Spec.Strategy
 	var replicas *int32
 	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas != int32(1) {
@@ -1443,6 +2169,53 @@ func hashableProbe(probe *corev1.Probe) *corev1.Probe {
 	return &hashableProbe
 }
 
+// desiredRouterResources returns the given default resource requirements of the
+// router container with the ephemeral-storage and CPU requests and limits that
+// the given unsupported config overrides specify.
+func desiredRouterResources(defaults corev1.ResourceRequirements, overrides *unsupportedConfigOverrides) corev1.ResourceRequirements {
+	resources := *defaults.DeepCopy()
+	apply := func(name corev1.ResourceName, override *resourceOverride) {
+		if override == nil {
+			return
+		}
+		if override.Request != nil {
+			if resources.Requests == nil {
+				resources.Requests = corev1.ResourceList{}
+			}
+			resources.Requests[name] = *override.Request
+		}
+		if override.Limit != nil {
+			if resources.Limits == nil {
+				resources.Limits = corev1.ResourceList{}
+			}
+			resources.Limits[name] = *override.Limit
+		}
+	}
+	apply(corev1.ResourceEphemeralStorage, overrides.EphemeralStorage)
+	apply(corev1.ResourceCPU, overrides.CPU)
+	return resources
+}
+
+// hashableResources returns a copy of the given resource requirements with
+// quantities in a canonical form so that equal quantities have equal hashes
+// regardless of how they were parsed.
+func hashableResources(resources corev1.ResourceRequirements) corev1.ResourceRequirements {
+	canonicalize := func(list corev1.ResourceList) corev1.ResourceList {
+		if len(list) == 0 {
+			return nil
+		}
+		canonical := make(corev1.ResourceList, len(list))
+		for name, quantity := range list {
+			canonical[name] = *resource.NewMilliQuantity(quantity.MilliValue(), quantity.Format)
+		}
+		return canonical
+	}
+	return corev1.ResourceRequirements{
+		Limits:   canonicalize(resources.Limits),
+		Requests: canonicalize(resources.Requests),
+	}
+}
+
 // currentRouterDeployment returns the current router deployment.
 func (r *reconciler) currentRouterDeployment(ci *operatorv1.IngressController) (bool, *appsv1.Deployment, error) {
 	deployment := &appsv1.Deployment{}
@@ -1457,7 +2230,7 @@ func (r *reconciler) currentRouterDeployment(ci *operatorv1.IngressController) (
 
 // createRouterDeployment creates a router deployment.
 func (r *reconciler) createRouterDeployment(deployment *appsv1.Deployment) error {
-	if err := r.client.Create(context.TODO(), deployment); err != nil {
+	if err := r.applyObject(deployment); err != nil {
 		return fmt.Errorf("failed to create router deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
 	}
 	log.Info("created router deployment", "namespace", deployment.Namespace, "name", deployment.Name)
@@ -1465,7 +2238,7 @@ func (r *reconciler) createRouterDeployment(deployment *appsv1.Deployment) error
 }
 
 // updateRouterDeployment updates a router deployment.
-func (r *reconciler) updateRouterDeployment(current, desired *appsv1.Deployment) (bool, error) {
+func (r *reconciler) updateRouterDeployment(ci *operatorv1.IngressController, current, desired *appsv1.Deployment) (bool, error) {
 	changed, updated := deploymentConfigChanged(current, desired)
 	if !changed {
 		return false, nil
@@ -1473,10 +2246,12 @@ func (r *reconciler) updateRouterDeployment(current, desired *appsv1.Deployment)
 
 	// Diff before updating because the client may mutate the object.
 	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
-	if err := r.client.Update(context.TODO(), updated); err != nil {
+	audit := newAuditRecord(ci, "Updated", "Deployment", updated.Namespace, updated.Name, current, updated)
+	if err := r.applyUpdate(current.DeepCopy(), desired.DeepCopy()); err != nil {
 		return false, fmt.Errorf("failed to update router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	log.Info("updated router deployment", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
+	r.recordAudit(ci, audit)
 	return true, nil
 }
 
@@ -1515,13 +2290,15 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	updated.Spec.Template.Spec.DNSPolicy = expected.Spec.Template.Spec.DNSPolicy
 	updated.Spec.Template.Labels = expected.Spec.Template.Labels
 
-	annotations := []string{LivenessGracePeriodSecondsAnnotation, WorkloadPartitioningManagement}
+	annotations := []string{LivenessGracePeriodSecondsAnnotation, WorkloadPartitioningManagement, ClientCAConfigMapHashAnnotation, RsyslogConfigHashAnnotation, MaintenancePageConfigMapHashAnnotation}
 	for _, key := range annotations {
 		if val, ok := expected.Spec.Template.Annotations[key]; ok && len(val) > 0 {
 			if updated.Spec.Template.Annotations == nil {
 				updated.Spec.Template.Annotations = make(map[string]string)
 			}
 			updated.Spec.Template.Annotations[key] = val
+		} else {
+			delete(updated.Spec.Template.Annotations, key)
 		}
 	}
 
@@ -1532,6 +2309,16 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	}
 	updated.Spec.Template.Spec.Volumes = volumes
 	updated.Spec.Template.Spec.NodeSelector = expected.Spec.Template.Spec.NodeSelector
+	var fsGroup *int64
+	if expected.Spec.Template.Spec.SecurityContext != nil {
+		fsGroup = expected.Spec.Template.Spec.SecurityContext.FSGroup
+	}
+	if fsGroup != nil && updated.Spec.Template.Spec.SecurityContext == nil {
+		updated.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if updated.Spec.Template.Spec.SecurityContext != nil {
+		updated.Spec.Template.Spec.SecurityContext.FSGroup = fsGroup
+	}
 	updated.Spec.Template.Spec.Containers[0].SecurityContext = expected.Spec.Template.Spec.Containers[0].SecurityContext
 	updated.Spec.Template.Spec.Containers[0].Env = expected.Spec.Template.Spec.Containers[0].Env
 	updated.Spec.Template.Spec.Containers[0].Image = expected.Spec.Template.Spec.Containers[0].Image
@@ -1540,6 +2327,7 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	copyProbe(expected.Spec.Template.Spec.Containers[0].StartupProbe, updated.Spec.Template.Spec.Containers[0].StartupProbe)
 	updated.Spec.Template.Spec.Containers[0].VolumeMounts = expected.Spec.Template.Spec.Containers[0].VolumeMounts
 	updated.Spec.Template.Spec.Containers[0].Ports = expected.Spec.Template.Spec.Containers[0].Ports
+	updated.Spec.Template.Spec.Containers[0].Resources = expected.Spec.Template.Spec.Containers[0].Resources
 	updated.Spec.Template.Spec.Tolerations = expected.Spec.Template.Spec.Tolerations
 	updated.Spec.Template.Spec.TopologySpreadConstraints = expected.Spec.Template.Spec.TopologySpreadConstraints
 	updated.Spec.Template.Spec.Affinity = expected.Spec.Template.Spec.Affinity
@@ -1549,6 +2337,7 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	}
 	updated.Spec.Replicas = &replicas
 	updated.Spec.MinReadySeconds = expected.Spec.MinReadySeconds
+	updated.Spec.ProgressDeadlineSeconds = expected.Spec.ProgressDeadlineSeconds
 	return true, updated
 }
 
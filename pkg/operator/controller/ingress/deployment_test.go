@@ -1,7 +1,14 @@
 package ingress
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
@@ -1344,6 +1351,14 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if .spec.progressDeadlineSeconds changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deadline := int32(900)
+				deployment.Spec.ProgressDeadlineSeconds = &deadline
+			},
+			expect: true,
+		},
 		{
 			description: "if .spec.HTTPCompressionPolicy changes",
 			mutate: func(deployment *appsv1.Deployment) {
@@ -1970,3 +1985,2487 @@ func TestDesiredRouterDeploymentDefaultPlacement(t *testing.T) {
 	}
 
 }
+
+// TestDesiredRouterDeploymentDefaultBackend verifies that desiredRouterDeployment
+// sets ROUTER_DEFAULT_BACKEND_SERVICE when the ingresscontroller specifies a
+// default backend service using an unsupported config override.
+func TestDesiredRouterDeploymentDefaultBackend(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterDefaultBackendEnvName, false, ""},
+		},
+		{
+			name:      "default backend with default port",
+			overrides: `{"defaultBackend":{"name":"fallback"}}`,
+			expectEnv: envData{RouterDefaultBackendEnvName, true, "fallback.openshift-ingress.svc:80"},
+		},
+		{
+			name:      "default backend with explicit port",
+			overrides: `{"defaultBackend":{"name":"fallback","port":8080}}`,
+			expectEnv: envData{RouterDefaultBackendEnvName, true, "fallback.openshift-ingress.svc:8080"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentPerRouteClientCertificatePolicy verifies that
+// desiredRouterDeployment configures the router to enforce the client
+// certificate policy per route when the perRouteClientCertificatePolicy
+// unsupported config override is set.
+func TestDesiredRouterDeploymentPerRouteClientCertificatePolicy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		policy    operatorv1.ClientCertificatePolicy
+		overrides string
+		expectEnv []envData
+	}{
+		{
+			name:      "required, controller-wide",
+			policy:    operatorv1.ClientCertificatePolicyRequired,
+			overrides: "",
+			expectEnv: []envData{
+				{RouterClientAuthPolicy, true, "required"},
+				{RouterClientAuthPerRoute, false, ""},
+				{RouterClientAuthDefaultPolicy, false, ""},
+			},
+		},
+		{
+			name:      "required, per route",
+			policy:    operatorv1.ClientCertificatePolicyRequired,
+			overrides: `{"perRouteClientCertificatePolicy":true}`,
+			expectEnv: []envData{
+				{RouterClientAuthPolicy, true, "optional"},
+				{RouterClientAuthPerRoute, true, "true"},
+				{RouterClientAuthDefaultPolicy, true, "required"},
+			},
+		},
+		{
+			name:      "optional, per route",
+			policy:    operatorv1.ClientCertificatePolicyOptional,
+			overrides: `{"perRouteClientCertificatePolicy":true}`,
+			expectEnv: []envData{
+				{RouterClientAuthPolicy, true, "optional"},
+				{RouterClientAuthPerRoute, true, "true"},
+				{RouterClientAuthDefaultPolicy, true, "optional"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.ClientTLS.ClientCertificatePolicy = tc.policy
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentNumericOverrides verifies that
+// desiredRouterDeployment sets the environment variable for each numeric
+// unsupported config override only when the override is set to a nonzero
+// value.
+func TestDesiredRouterDeploymentNumericOverrides(t *testing.T) {
+	testCases := []struct {
+		override    string
+		envName     string
+		value       int
+		expectValue string
+	}{
+		{"maxHeaderCount", RouterMaxHeaderCountEnvName, 150, "150"},
+		{"sslCacheSize", RouterSSLCacheSizeEnvName, 50000, "50000"},
+		{"sslMaxRecordSize", RouterSSLMaxRecordSizeEnvName, 1400, "1400"},
+		{"backendPoolMaxConnections", RouterBackendPoolMaxConnectionsEnvName, 64, "64"},
+		{"serverMaxConnections", RouterServerMaxConnectionsEnvName, 250, "250"},
+		{"backlog", RouterBacklogEnvName, 4096, "4096"},
+		{"maxSSLHandshakeRate", RouterMaxSSLRateEnvName, 500, "500"},
+		{"statsTimeoutSeconds", RouterStatsTimeoutEnvName, 30, "30s"},
+		{"statsTimeoutSeconds", RouterStatsTimeoutEnvName, 120, "2m"},
+		{"defaultRouteRequestRateLimit", RouterDefaultRateLimitHTTPEnvName, 100, "100"},
+	}
+	for _, tc := range testCases {
+		overrides := []struct {
+			name      string
+			overrides string
+			expectEnv envData
+		}{
+			{"no override", "", envData{tc.envName, false, ""}},
+			{"zero", fmt.Sprintf(`{%q:0}`, tc.override), envData{tc.envName, false, ""}},
+			{"set", fmt.Sprintf(`{%q:%d}`, tc.override, tc.value), envData{tc.envName, true, tc.expectValue}},
+		}
+		for _, o := range overrides {
+			t.Run(fmt.Sprintf("%s=%d/%s", tc.override, tc.value, o.name), func(t *testing.T) {
+				ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+				ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(o.overrides)}
+				deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+				if err != nil {
+					t.Fatalf("invalid router Deployment: %v", err)
+				}
+				if err := checkDeploymentEnvironment(t, deployment, []envData{o.expectEnv}); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// TestDesiredRouterDeploymentHeaderBufferMaxRewrite verifies that
+// desiredRouterDeployment sets ROUTER_MAX_REWRITE_SIZE and ROUTER_BUF_SIZE
+// independently and only when the corresponding tuning option is set.
+func TestDesiredRouterDeploymentHeaderBufferMaxRewrite(t *testing.T) {
+	testCases := []struct {
+		name          string
+		tuningOptions operatorv1.IngressControllerTuningOptions
+		expectEnv     []envData
+	}{
+		{
+			name:          "neither set",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{},
+			expectEnv: []envData{
+				{RouterHeaderBufferSize, false, ""},
+				{RouterHeaderBufferMaxRewriteSize, false, ""},
+			},
+		},
+		{
+			name: "only max rewrite set",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				HeaderBufferMaxRewriteBytes: 16384,
+			},
+			expectEnv: []envData{
+				{RouterHeaderBufferSize, false, ""},
+				{RouterHeaderBufferMaxRewriteSize, true, "16384"},
+			},
+		},
+		{
+			name: "both set",
+			tuningOptions: operatorv1.IngressControllerTuningOptions{
+				HeaderBufferBytes:           65536,
+				HeaderBufferMaxRewriteBytes: 16384,
+			},
+			expectEnv: []envData{
+				{RouterHeaderBufferSize, true, "65536"},
+				{RouterHeaderBufferMaxRewriteSize, true, "16384"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.TuningOptions = tc.tuningOptions
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentTrustForwardedProto verifies that
+// desiredRouterDeployment sets ROUTER_TRUST_FORWARDED_PROTO when the
+// trustForwardedProto unsupported config override is set, unless the forwarded
+// header policy replaces incoming forwarded headers.
+func TestDesiredRouterDeploymentTrustForwardedProto(t *testing.T) {
+	testCases := []struct {
+		name      string
+		policy    operatorv1.IngressControllerHTTPHeaderPolicy
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterTrustForwardedProtoEnvName, false, ""},
+		},
+		{
+			name:      "default policy",
+			overrides: `{"trustForwardedProto":true}`,
+			expectEnv: envData{RouterTrustForwardedProtoEnvName, true, "true"},
+		},
+		{
+			name:      "append policy",
+			policy:    operatorv1.AppendHTTPHeaderPolicy,
+			overrides: `{"trustForwardedProto":true}`,
+			expectEnv: envData{RouterTrustForwardedProtoEnvName, true, "true"},
+		},
+		{
+			name:      "if-none policy",
+			policy:    operatorv1.IfNoneHTTPHeaderPolicy,
+			overrides: `{"trustForwardedProto":true}`,
+			expectEnv: envData{RouterTrustForwardedProtoEnvName, true, "true"},
+		},
+		{
+			name:      "replace policy",
+			policy:    operatorv1.ReplaceHTTPHeaderPolicy,
+			overrides: `{"trustForwardedProto":true}`,
+			expectEnv: envData{RouterTrustForwardedProtoEnvName, false, ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			if len(tc.policy) != 0 {
+				ic.Spec.HTTPHeaders = &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: tc.policy}
+			}
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentTopologySpreadConstraints verifies that
+// desiredRouterDeployment spreads replicas across zones in addition to the
+// hostname anti-affinity policy, and that the constraint does not block
+// scheduling on clusters with a single zone.
+func TestDesiredRouterDeploymentTopologySpreadConstraints(t *testing.T) {
+	for _, endpointType := range []operatorv1.EndpointPublishingStrategyType{
+		operatorv1.PrivateStrategyType,
+		operatorv1.LoadBalancerServiceStrategyType,
+		operatorv1.NodePortServiceStrategyType,
+	} {
+		t.Run(string(endpointType), func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Status.EndpointPublishingStrategy.Type = endpointType
+			two := int32(2)
+			ic.Spec.Replicas = &two
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			constraints := deployment.Spec.Template.Spec.TopologySpreadConstraints
+			if len(constraints) != 1 {
+				t.Fatalf("expected 1 topology spread constraint, got %d", len(constraints))
+			}
+			constraint := constraints[0]
+			if constraint.TopologyKey != corev1.LabelTopologyZone {
+				t.Errorf("expected topology key %q, got %q", corev1.LabelTopologyZone, constraint.TopologyKey)
+			}
+			if constraint.MaxSkew != 1 {
+				t.Errorf("expected maxSkew 1, got %d", constraint.MaxSkew)
+			}
+			if constraint.WhenUnsatisfiable != corev1.ScheduleAnyway {
+				t.Errorf("expected whenUnsatisfiable %q, got %q", corev1.ScheduleAnyway, constraint.WhenUnsatisfiable)
+			}
+			selector := constraint.LabelSelector
+			if selector == nil || len(selector.MatchExpressions) != 1 || selector.MatchExpressions[0].Key != controller.ControllerDeploymentHashLabel || len(selector.MatchExpressions[0].Values) != 1 {
+				t.Errorf("expected the constraint to select the deployment's pods by hash, got %v", selector)
+			}
+			affinity := deployment.Spec.Template.Spec.Affinity
+			if affinity == nil || affinity.PodAntiAffinity == nil || len(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) == 0 {
+				t.Error("expected the hostname anti-affinity policy to be kept")
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentRollingUpdate verifies that
+// desiredRouterDeployment applies the rollingUpdate unsupported config
+// override's parameters and keeps the computed value for any parameter that
+// the override does not set, and that useIntegerRollingUpdateCountsForOverride
+// converts percentages into pod counts if the override requests integer
+// counts.
+func TestDesiredRouterDeploymentRollingUpdate(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		endpointType         operatorv1.EndpointPublishingStrategyType
+		replicas             int32
+		overrides            string
+		expectMaxUnavailable intstr.IntOrString
+		expectMaxSurge       intstr.IntOrString
+	}{
+		{
+			name:                 "no override",
+			endpointType:         operatorv1.PrivateStrategyType,
+			overrides:            "",
+			expectMaxUnavailable: intstr.FromString("50%"),
+			expectMaxSurge:       intstr.FromString("25%"),
+		},
+		{
+			name:                 "no override with HostNetwork",
+			endpointType:         operatorv1.HostNetworkStrategyType,
+			overrides:            "",
+			expectMaxUnavailable: intstr.FromString("25%"),
+			expectMaxSurge:       intstr.FromInt(0),
+		},
+		{
+			name:                 "maxUnavailable only",
+			endpointType:         operatorv1.PrivateStrategyType,
+			overrides:            `{"rollingUpdate":{"maxUnavailable":1}}`,
+			expectMaxUnavailable: intstr.FromInt(1),
+			expectMaxSurge:       intstr.FromString("25%"),
+		},
+		{
+			name:                 "both parameters with HostNetwork",
+			endpointType:         operatorv1.HostNetworkStrategyType,
+			overrides:            `{"rollingUpdate":{"maxUnavailable":"10%","maxSurge":1}}`,
+			expectMaxUnavailable: intstr.FromString("10%"),
+			expectMaxSurge:       intstr.FromInt(1),
+		},
+		{
+			name:                 "integer counts, rounding maxUnavailable down",
+			endpointType:         operatorv1.PrivateStrategyType,
+			replicas:             3,
+			overrides:            `{"rollingUpdate":{"useIntegerCounts":true}}`,
+			expectMaxUnavailable: intstr.FromInt(1),
+			expectMaxSurge:       intstr.FromInt(1),
+		},
+		{
+			name:                 "integer counts, rounding maxUnavailable up",
+			endpointType:         operatorv1.PrivateStrategyType,
+			replicas:             3,
+			overrides:            `{"rollingUpdate":{"useIntegerCounts":true,"maxUnavailableRounding":"Ceil"}}`,
+			expectMaxUnavailable: intstr.FromInt(2),
+			expectMaxSurge:       intstr.FromInt(1),
+		},
+		{
+			name:                 "integer counts with overridden percentages",
+			endpointType:         operatorv1.PrivateStrategyType,
+			replicas:             10,
+			overrides:            `{"rollingUpdate":{"maxUnavailable":"15%","maxSurge":"15%","useIntegerCounts":true}}`,
+			expectMaxUnavailable: intstr.FromInt(1),
+			expectMaxSurge:       intstr.FromInt(2),
+		},
+		{
+			name:                 "integer counts with HostNetwork, rounding maxUnavailable down to zero",
+			endpointType:         operatorv1.HostNetworkStrategyType,
+			replicas:             3,
+			overrides:            `{"rollingUpdate":{"useIntegerCounts":true}}`,
+			expectMaxUnavailable: intstr.FromInt(1),
+			expectMaxSurge:       intstr.FromInt(0),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Status.EndpointPublishingStrategy.Type = tc.endpointType
+			if tc.replicas != 0 {
+				ic.Spec.Replicas = &tc.replicas
+			}
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			overrides, err := getUnsupportedConfigOverrides(ic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if overrides.RollingUpdate != nil {
+				if err := useIntegerRollingUpdateCountsForOverride(deployment, overrides.RollingUpdate); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+			if rollingUpdate == nil {
+				t.Fatal("expected a rolling update strategy")
+			}
+			if *rollingUpdate.MaxUnavailable != tc.expectMaxUnavailable {
+				t.Errorf("expected maxUnavailable %s, got %s", tc.expectMaxUnavailable.String(), rollingUpdate.MaxUnavailable.String())
+			}
+			if *rollingUpdate.MaxSurge != tc.expectMaxSurge {
+				t.Errorf("expected maxSurge %s, got %s", tc.expectMaxSurge.String(), rollingUpdate.MaxSurge.String())
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentRecreateStrategy verifies that
+// desiredRouterDeployment uses the Recreate strategy without rolling update
+// parameters for a HostNetwork ingresscontroller that requests it using the
+// deploymentStrategy unsupported config override.
+func TestDesiredRouterDeploymentRecreateStrategy(t *testing.T) {
+	testCases := []struct {
+		name                string
+		overrides           string
+		expectType          appsv1.DeploymentStrategyType
+		expectRollingUpdate bool
+	}{
+		{
+			name:                "no override",
+			overrides:           "",
+			expectType:          appsv1.RollingUpdateDeploymentStrategyType,
+			expectRollingUpdate: true,
+		},
+		{
+			name:                "RollingUpdate",
+			overrides:           `{"deploymentStrategy":"RollingUpdate"}`,
+			expectType:          appsv1.RollingUpdateDeploymentStrategyType,
+			expectRollingUpdate: true,
+		},
+		{
+			name:                "Recreate",
+			overrides:           `{"deploymentStrategy":"Recreate"}`,
+			expectType:          appsv1.RecreateDeploymentStrategyType,
+			expectRollingUpdate: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Status.EndpointPublishingStrategy.Type = operatorv1.HostNetworkStrategyType
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if deployment.Spec.Strategy.Type != tc.expectType {
+				t.Errorf("expected strategy type %q, got %q", tc.expectType, deployment.Spec.Strategy.Type)
+			}
+			if haveRollingUpdate := deployment.Spec.Strategy.RollingUpdate != nil; haveRollingUpdate != tc.expectRollingUpdate {
+				t.Errorf("expected rolling update parameters to be set: %t, got %t", tc.expectRollingUpdate, haveRollingUpdate)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentGRPCMode verifies that desiredRouterDeployment
+// enables gRPC mode and raises the default client and server timeouts
+// according to the grpcMode unsupported config override, and that explicit
+// tuning options take precedence over the gRPC mode timeouts.
+func TestDesiredRouterDeploymentGRPCMode(t *testing.T) {
+	testCases := []struct {
+		name          string
+		overrides     string
+		serverTimeout *metav1.Duration
+		expectEnv     []envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: []envData{
+				{RouterGRPCModeEnvName, false, ""},
+				{"ROUTER_DEFAULT_CLIENT_TIMEOUT", false, ""},
+				{"ROUTER_DEFAULT_SERVER_TIMEOUT", false, ""},
+			},
+		},
+		{
+			name:      "gRPC mode",
+			overrides: `{"grpcMode":true}`,
+			expectEnv: []envData{
+				{RouterGRPCModeEnvName, true, "true"},
+				{"ROUTER_DEFAULT_CLIENT_TIMEOUT", true, "1h"},
+				{"ROUTER_DEFAULT_SERVER_TIMEOUT", true, "1h"},
+			},
+		},
+		{
+			name:          "gRPC mode with server timeout",
+			overrides:     `{"grpcMode":true}`,
+			serverTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			expectEnv: []envData{
+				{RouterGRPCModeEnvName, true, "true"},
+				{"ROUTER_DEFAULT_CLIENT_TIMEOUT", true, "1h"},
+				{"ROUTER_DEFAULT_SERVER_TIMEOUT", true, "5m"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			ic.Spec.TuningOptions.ServerTimeout = tc.serverTimeout
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentTCPKeepalive verifies that
+// desiredRouterDeployment enables TCP keepalive and sets only the parameters
+// that the tcpKeepalive unsupported config override sets.
+func TestDesiredRouterDeploymentTCPKeepalive(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv []envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: []envData{
+				{RouterTCPKeepaliveEnvName, false, ""},
+				{RouterTCPKeepaliveIdleEnvName, false, ""},
+				{RouterTCPKeepaliveIntervalEnvName, false, ""},
+				{RouterTCPKeepaliveCountEnvName, false, ""},
+			},
+		},
+		{
+			name:      "kernel defaults",
+			overrides: `{"tcpKeepalive":{}}`,
+			expectEnv: []envData{
+				{RouterTCPKeepaliveEnvName, true, "true"},
+				{RouterTCPKeepaliveIdleEnvName, false, ""},
+				{RouterTCPKeepaliveIntervalEnvName, false, ""},
+				{RouterTCPKeepaliveCountEnvName, false, ""},
+			},
+		},
+		{
+			name:      "all parameters",
+			overrides: `{"tcpKeepalive":{"idleSeconds":300,"intervalSeconds":15,"count":4}}`,
+			expectEnv: []envData{
+				{RouterTCPKeepaliveEnvName, true, "true"},
+				{RouterTCPKeepaliveIdleEnvName, true, "5m"},
+				{RouterTCPKeepaliveIntervalEnvName, true, "15s"},
+				{RouterTCPKeepaliveCountEnvName, true, "4"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentTracing verifies that desiredRouterDeployment
+// enables tracing with the collector endpoint and sets the sampling ratio only
+// if the tracing unsupported config override sets it.
+func TestDesiredRouterDeploymentTracing(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv []envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: []envData{
+				{RouterTracingEnvName, false, ""},
+				{RouterTracingEndpointEnvName, false, ""},
+				{RouterTracingSamplingRatioEnvName, false, ""},
+			},
+		},
+		{
+			name:      "endpoint only",
+			overrides: `{"tracing":{"endpoint":"otel-collector.observability.svc:4317"}}`,
+			expectEnv: []envData{
+				{RouterTracingEnvName, true, "true"},
+				{RouterTracingEndpointEnvName, true, "otel-collector.observability.svc:4317"},
+				{RouterTracingSamplingRatioEnvName, false, ""},
+			},
+		},
+		{
+			name:      "endpoint and sampling ratio",
+			overrides: `{"tracing":{"endpoint":"10.0.0.5:4317","samplingRatio":0.05}}`,
+			expectEnv: []envData{
+				{RouterTracingEnvName, true, "true"},
+				{RouterTracingEndpointEnvName, true, "10.0.0.5:4317"},
+				{RouterTracingSamplingRatioEnvName, true, "0.05"},
+			},
+		},
+		{
+			name:      "zero sampling ratio",
+			overrides: `{"tracing":{"endpoint":"10.0.0.5:4317","samplingRatio":0}}`,
+			expectEnv: []envData{
+				{RouterTracingEnvName, true, "true"},
+				{RouterTracingEndpointEnvName, true, "10.0.0.5:4317"},
+				{RouterTracingSamplingRatioEnvName, true, "0"},
+			},
+		},
+		{
+			name:      "full sampling ratio",
+			overrides: `{"tracing":{"endpoint":"10.0.0.5:4317","samplingRatio":1}}`,
+			expectEnv: []envData{
+				{RouterTracingEnvName, true, "true"},
+				{RouterTracingEndpointEnvName, true, "10.0.0.5:4317"},
+				{RouterTracingSamplingRatioEnvName, true, "1"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentPodAntiAffinity verifies that
+// desiredRouterDeployment uses required anti-affinity for router pods of the
+// same generation unless the podAntiAffinity unsupported config override is
+// "Preferred", in which case it uses preferred anti-affinity with weight 100.
+func TestDesiredRouterDeploymentPodAntiAffinity(t *testing.T) {
+	testCases := []struct {
+		name            string
+		overrides       string
+		expectPreferred bool
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+		},
+		{
+			name:      "required",
+			overrides: `{"podAntiAffinity":"Required"}`,
+		},
+		{
+			name:            "preferred",
+			overrides:       `{"podAntiAffinity":"Preferred"}`,
+			expectPreferred: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			antiAffinity := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity
+			hash := deployment.Spec.Template.Labels[controller.ControllerDeploymentHashLabel]
+			var term corev1.PodAffinityTerm
+			if tc.expectPreferred {
+				if len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0 {
+					t.Errorf("expected no required anti-affinity, got %v", antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+				}
+				if len(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+					t.Fatalf("expected 1 preferred anti-affinity term, got %v", antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+				}
+				if weight := antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Weight; weight != 100 {
+					t.Errorf("expected weight 100, got %d", weight)
+				}
+				term = antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+				if hasRequiredPodAntiAffinity(deployment) {
+					t.Error("expected hasRequiredPodAntiAffinity to return false")
+				}
+			} else {
+				if len(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 0 {
+					t.Errorf("expected no preferred anti-affinity, got %v", antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+				}
+				if len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 1 {
+					t.Fatalf("expected 1 required anti-affinity term, got %v", antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+				}
+				term = antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0]
+				if !hasRequiredPodAntiAffinity(deployment) {
+					t.Error("expected hasRequiredPodAntiAffinity to return true")
+				}
+			}
+			if term.TopologyKey != "kubernetes.io/hostname" {
+				t.Errorf("expected topology key kubernetes.io/hostname, got %q", term.TopologyKey)
+			}
+			exprs := term.LabelSelector.MatchExpressions
+			if len(exprs) != 2 || exprs[1].Key != controller.ControllerDeploymentHashLabel || !reflect.DeepEqual(exprs[1].Values, []string{hash}) {
+				t.Errorf("expected the term to select pods with hash %q, got %v", hash, exprs)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentMinReadySeconds verifies that
+// desiredRouterDeployment sets minReadySeconds to 30 unless the
+// minReadySeconds unsupported config override specifies a value.
+func TestDesiredRouterDeploymentMinReadySeconds(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expect    int32
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expect:    30,
+		},
+		{
+			name:      "longer",
+			overrides: `{"minReadySeconds":90}`,
+			expect:    90,
+		},
+		{
+			name:      "zero",
+			overrides: `{"minReadySeconds":0}`,
+			expect:    0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if deployment.Spec.MinReadySeconds != tc.expect {
+				t.Errorf("expected minReadySeconds %d, got %d", tc.expect, deployment.Spec.MinReadySeconds)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentProgressDeadlineSeconds verifies that
+// desiredRouterDeployment sets progressDeadlineSeconds to 600 unless the
+// progressDeadlineSeconds unsupported config override specifies a value.
+func TestDesiredRouterDeploymentProgressDeadlineSeconds(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expect    int32
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expect:    600,
+		},
+		{
+			name:      "shorter",
+			overrides: `{"progressDeadlineSeconds":120}`,
+			expect:    120,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if deployment.Spec.ProgressDeadlineSeconds == nil || *deployment.Spec.ProgressDeadlineSeconds != tc.expect {
+				t.Errorf("expected progressDeadlineSeconds %d, got %v", tc.expect, deployment.Spec.ProgressDeadlineSeconds)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentHostPorts verifies that desiredRouterDeployment
+// specifies the HTTP, HTTPS, and stats ports as host ports for the HostNetwork
+// endpoint publishing strategy and specifies no host ports otherwise.
+func TestDesiredRouterDeploymentHostPorts(t *testing.T) {
+	testCases := []struct {
+		name        string
+		endpoint    operatorv1.EndpointPublishingStrategy
+		expectPorts map[string]int32
+	}{
+		{
+			name:        "private",
+			endpoint:    operatorv1.EndpointPublishingStrategy{Type: operatorv1.PrivateStrategyType},
+			expectPorts: map[string]int32{HTTPPortName: 0, HTTPSPortName: 0, StatsPortName: 0},
+		},
+		{
+			name:        "host network with default ports",
+			endpoint:    operatorv1.EndpointPublishingStrategy{Type: operatorv1.HostNetworkStrategyType},
+			expectPorts: map[string]int32{HTTPPortName: 80, HTTPSPortName: 443, StatsPortName: 1936},
+		},
+		{
+			name: "host network with custom ports",
+			endpoint: operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.HostNetworkStrategyType,
+				HostNetwork: &operatorv1.HostNetworkStrategy{
+					HTTPPort:  8080,
+					HTTPSPort: 8443,
+					StatsPort: 9146,
+				},
+			},
+			expectPorts: map[string]int32{HTTPPortName: 8080, HTTPSPortName: 8443, StatsPortName: 9146},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Status.EndpointPublishingStrategy = tc.endpoint.DeepCopy()
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			hostPorts := map[string]int32{}
+			for _, port := range deployment.Spec.Template.Spec.Containers[0].Ports {
+				hostPorts[port.Name] = port.HostPort
+			}
+			if !reflect.DeepEqual(hostPorts, tc.expectPorts) {
+				t.Errorf("expected host ports %v, got %v", tc.expectPorts, hostPorts)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentAffinityTopologyKey verifies that
+// desiredRouterDeployment uses the affinityTopologyKey unsupported config
+// override as the topology key of the affinity and anti-affinity policies and
+// uses "kubernetes.io/hostname" if the override is not set.
+func TestDesiredRouterDeploymentAffinityTopologyKey(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expect    string
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expect:    "kubernetes.io/hostname",
+		},
+		{
+			name:      "rack",
+			overrides: `{"affinityTopologyKey":"topology.example.com/rack"}`,
+			expect:    "topology.example.com/rack",
+		},
+		{
+			name:      "rack with preferred anti-affinity",
+			overrides: `{"affinityTopologyKey":"topology.example.com/rack","podAntiAffinity":"Preferred"}`,
+			expect:    "topology.example.com/rack",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			affinity := deployment.Spec.Template.Spec.Affinity
+			if key := affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm.TopologyKey; key != tc.expect {
+				t.Errorf("expected affinity topology key %q, got %q", tc.expect, key)
+			}
+			var terms []corev1.PodAffinityTerm
+			terms = append(terms, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+			for _, term := range affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				terms = append(terms, term.PodAffinityTerm)
+			}
+			if len(terms) != 1 || terms[0].TopologyKey != tc.expect {
+				t.Errorf("expected 1 anti-affinity term with topology key %q, got %v", tc.expect, terms)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentZoneAwareAffinity verifies that
+// desiredRouterDeployment adds a lower-weight zone affinity term with the same
+// selector as the hostname term, including the deployment hash, only when the
+// zoneAwareAffinity unsupported config override is set.
+func TestDesiredRouterDeploymentZoneAwareAffinity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		overrides        string
+		expectZoneWeight int32
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+		},
+		{
+			name:             "zone-aware",
+			overrides:        `{"zoneAwareAffinity":true}`,
+			expectZoneWeight: 50,
+		},
+		{
+			name:      "zone-aware with zone topology key",
+			overrides: `{"zoneAwareAffinity":true,"affinityTopologyKey":"topology.kubernetes.io/zone"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			terms := deployment.Spec.Template.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+			if tc.expectZoneWeight == 0 {
+				if len(terms) != 1 {
+					t.Fatalf("expected 1 affinity term, got %v", terms)
+				}
+				return
+			}
+			if len(terms) != 2 {
+				t.Fatalf("expected 2 affinity terms, got %v", terms)
+			}
+			zoneTerm := terms[1]
+			if zoneTerm.PodAffinityTerm.TopologyKey != corev1.LabelTopologyZone || zoneTerm.Weight != tc.expectZoneWeight {
+				t.Errorf("expected a term with topology key %q and weight %d, got %q and %d", corev1.LabelTopologyZone, tc.expectZoneWeight, zoneTerm.PodAffinityTerm.TopologyKey, zoneTerm.Weight)
+			}
+			if zoneTerm.Weight >= terms[0].Weight {
+				t.Errorf("expected the zone term's weight %d to be lower than the hostname term's weight %d", zoneTerm.Weight, terms[0].Weight)
+			}
+			hash := deployment.Spec.Template.Labels[controller.ControllerDeploymentHashLabel]
+			if !reflect.DeepEqual(zoneTerm.PodAffinityTerm.LabelSelector, terms[0].PodAffinityTerm.LabelSelector) {
+				t.Errorf("expected the zone term to have the same selector as the hostname term, got %v and %v", zoneTerm.PodAffinityTerm.LabelSelector, terms[0].PodAffinityTerm.LabelSelector)
+			}
+			if values := zoneTerm.PodAffinityTerm.LabelSelector.MatchExpressions[1].Values; len(values) != 1 || values[0] != hash {
+				t.Errorf("expected the zone term to select pods without hash %q, got %v", hash, values)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentNodePlacementWithAffinity verifies that
+// desiredRouterDeployment applies the node placement's tolerations and node
+// selector, expressing match expressions that a node selector cannot express
+// as node affinity, without discarding the pod affinity and anti-affinity
+// policy.
+func TestDesiredRouterDeploymentNodePlacementWithAffinity(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Status.EndpointPublishingStrategy.Type = operatorv1.LoadBalancerServiceStrategyType
+	toleration := corev1.Toleration{
+		Key:      "node-role.kubernetes.io/infra",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	ic.Spec.NodePlacement = &operatorv1.NodePlacement{
+		NodeSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""},
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "topology.kubernetes.io/zone",
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{"zone-c"},
+			}},
+		},
+		Tolerations: []corev1.Toleration{toleration},
+	}
+	deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	podSpec := deployment.Spec.Template.Spec
+	if !reflect.DeepEqual(podSpec.NodeSelector, map[string]string{"node-role.kubernetes.io/infra": ""}) {
+		t.Errorf("unexpected node selector: %v", podSpec.NodeSelector)
+	}
+	if !reflect.DeepEqual(podSpec.Tolerations, []corev1.Toleration{toleration}) {
+		t.Errorf("unexpected tolerations: %v", podSpec.Tolerations)
+	}
+	affinity := podSpec.Affinity
+	if affinity == nil {
+		t.Fatal("expected affinity to be set")
+	}
+	expectedNodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "topology.kubernetes.io/zone",
+					Operator: corev1.NodeSelectorOpNotIn,
+					Values:   []string{"zone-c"},
+				}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(affinity.NodeAffinity, expectedNodeAffinity) {
+		t.Errorf("expected node affinity %v, got %v", expectedNodeAffinity, affinity.NodeAffinity)
+	}
+	if affinity.PodAffinity == nil || len(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution) == 0 {
+		t.Errorf("expected pod affinity to be preserved, got %v", affinity.PodAffinity)
+	}
+	if affinity.PodAntiAffinity == nil || len(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) == 0 {
+		t.Errorf("expected pod anti-affinity to be preserved, got %v", affinity.PodAntiAffinity)
+	}
+	checkDeploymentHash(t, deployment)
+
+	// A selector that a node selector can express does not use node
+	// affinity.
+	ic.Spec.NodePlacement.NodeSelector.MatchExpressions[0].Operator = metav1.LabelSelectorOpIn
+	ic.Spec.NodePlacement.NodeSelector.MatchExpressions[0].Values = []string{"zone-a"}
+	deployment, err = desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if nodeAffinity := deployment.Spec.Template.Spec.Affinity.NodeAffinity; nodeAffinity != nil {
+		t.Errorf("expected no node affinity, got %v", nodeAffinity)
+	}
+	if v := deployment.Spec.Template.Spec.NodeSelector["topology.kubernetes.io/zone"]; v != "zone-a" {
+		t.Errorf("expected node selector to require zone-a, got %v", deployment.Spec.Template.Spec.NodeSelector)
+	}
+}
+
+// TestDisableSurgeForLocalTrafficPolicy verifies that
+// disableSurgeForLocalTrafficPolicy sets maxSurge to 0 and converts
+// maxUnavailable into a pod count that lets the rollout make progress while
+// keeping some replicas.
+func TestDisableSurgeForLocalTrafficPolicy(t *testing.T) {
+	pointerTo := func(ios intstr.IntOrString) *intstr.IntOrString { return &ios }
+	testCases := []struct {
+		name                 string
+		replicas             int32
+		maxUnavailable       intstr.IntOrString
+		maxSurge             intstr.IntOrString
+		expectChanged        bool
+		expectMaxUnavailable intstr.IntOrString
+	}{
+		{
+			name:                 "2 replicas, default strategy",
+			replicas:             2,
+			maxUnavailable:       intstr.FromString("50%"),
+			maxSurge:             intstr.FromString("25%"),
+			expectChanged:        true,
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+		{
+			name:                 "1 replica, rounded down to zero",
+			replicas:             1,
+			maxUnavailable:       intstr.FromString("50%"),
+			maxSurge:             intstr.FromString("25%"),
+			expectChanged:        true,
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+		{
+			name:                 "8 replicas",
+			replicas:             8,
+			maxUnavailable:       intstr.FromString("25%"),
+			maxSurge:             intstr.FromString("25%"),
+			expectChanged:        true,
+			expectMaxUnavailable: intstr.FromInt(2),
+		},
+		{
+			name:                 "all replicas unavailable",
+			replicas:             3,
+			maxUnavailable:       intstr.FromString("100%"),
+			maxSurge:             intstr.FromString("25%"),
+			expectChanged:        true,
+			expectMaxUnavailable: intstr.FromInt(2),
+		},
+		{
+			name:                 "already without surge",
+			replicas:             2,
+			maxUnavailable:       intstr.FromInt(1),
+			maxSurge:             intstr.FromInt(0),
+			expectChanged:        false,
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &tc.replicas,
+					Strategy: appsv1.DeploymentStrategy{
+						Type: appsv1.RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDeployment{
+							MaxUnavailable: pointerTo(tc.maxUnavailable),
+							MaxSurge:       pointerTo(tc.maxSurge),
+						},
+					},
+				},
+			}
+			changed, err := disableSurgeForLocalTrafficPolicy(deployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tc.expectChanged {
+				t.Errorf("expected changed %t, got %t", tc.expectChanged, changed)
+			}
+			rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+			if *rollingUpdate.MaxSurge != intstr.FromInt(0) || *rollingUpdate.MaxUnavailable != tc.expectMaxUnavailable {
+				t.Errorf("expected maxSurge 0 and maxUnavailable %s, got %s and %s", tc.expectMaxUnavailable.String(), rollingUpdate.MaxSurge.String(), rollingUpdate.MaxUnavailable.String())
+			}
+		})
+	}
+
+	recreate := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		},
+	}
+	if changed, err := disableSurgeForLocalTrafficPolicy(recreate); err != nil || changed {
+		t.Errorf("expected no change for a deployment with the Recreate strategy, got %t, %v", changed, err)
+	}
+}
+
+// TestDesiredRouterDeploymentThreadCount verifies that desiredRouterDeployment
+// sets ROUTER_THREADS from spec.tuningOptions.threadCount or from the router
+// container's CPU resources, and that a change in the thread count rolls out
+// the router deployment.
+func TestDesiredRouterDeploymentThreadCount(t *testing.T) {
+	testCases := []struct {
+		name          string
+		threadCount   int32
+		overrides     string
+		expectThreads string
+		expectCPU     map[string]string
+	}{
+		{
+			name:          "default",
+			expectThreads: "4",
+			expectCPU:     map[string]string{"request": "100m"},
+		},
+		{
+			name:          "thread count",
+			threadCount:   8,
+			expectThreads: "8",
+			expectCPU:     map[string]string{"request": "100m"},
+		},
+		{
+			name:          "CPU limit",
+			overrides:     `{"cpu":{"limit":"2"}}`,
+			expectThreads: "2",
+			expectCPU:     map[string]string{"request": "100m", "limit": "2"},
+		},
+		{
+			name:          "CPU request",
+			overrides:     `{"cpu":{"request":"12"}}`,
+			expectThreads: "12",
+			expectCPU:     map[string]string{"request": "12"},
+		},
+		{
+			name:          "thread count above HAProxy's maximum",
+			threadCount:   100,
+			expectThreads: "64",
+			expectCPU:     map[string]string{"request": "100m"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.TuningOptions.ThreadCount = tc.threadCount
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{{RouterHAProxyThreadsEnvName, true, tc.expectThreads}}); err != nil {
+				t.Error(err)
+			}
+			resources := deployment.Spec.Template.Spec.Containers[0].Resources
+			actualCPU := map[string]string{}
+			if v, ok := resources.Requests[corev1.ResourceCPU]; ok {
+				actualCPU["request"] = v.String()
+			}
+			if v, ok := resources.Limits[corev1.ResourceCPU]; ok {
+				actualCPU["limit"] = v.String()
+			}
+			if !reflect.DeepEqual(actualCPU, tc.expectCPU) {
+				t.Errorf("expected CPU resources %v, got %v", tc.expectCPU, actualCPU)
+			}
+		})
+	}
+
+	t.Run("changing the thread count rolls out the deployment", func(t *testing.T) {
+		ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+		current, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"cpu":{"limit":"2"}}`)}
+		expected, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		changed, updated := deploymentConfigChanged(current, expected)
+		if !changed {
+			t.Fatal("expected a change in the thread count to change the deployment")
+		}
+		if err := checkDeploymentEnvironment(t, updated, []envData{{RouterHAProxyThreadsEnvName, true, "2"}}); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// TestDesiredRouterDeploymentRouteQuota verifies that desiredRouterDeployment
+// excludes routes that exceed the route quota from the router's route selector
+// only when the routeQuota unsupported config override is set.
+func TestDesiredRouterDeploymentRouteQuota(t *testing.T) {
+	testCases := []struct {
+		name          string
+		routeSelector *metav1.LabelSelector
+		overrides     string
+		expectEnv     envData
+	}{
+		{
+			name:      "no route selector or quota",
+			expectEnv: envData{"ROUTE_LABELS", false, ""},
+		},
+		{
+			name:          "route selector",
+			routeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"type": "public"}},
+			expectEnv:     envData{"ROUTE_LABELS", true, "type=public"},
+		},
+		{
+			name:      "route quota",
+			overrides: `{"routeQuota":{"default":100}}`,
+			expectEnv: envData{"ROUTE_LABELS", true, "!route-quota-exceeded.ingress.operator.openshift.io/default"},
+		},
+		{
+			name:          "route selector and quota",
+			routeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"type": "public"}},
+			overrides:     `{"routeQuota":{"default":100}}`,
+			expectEnv:     envData{"ROUTE_LABELS", true, "!route-quota-exceeded.ingress.operator.openshift.io/default,type=public"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.RouteSelector = tc.routeSelector
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentRequestSmugglingProtection verifies that
+// desiredRouterDeployment sets ROUTER_STRICT_REQUEST_FRAMING according to the
+// requestSmugglingProtection unsupported config override and that the router
+// is strict by default.
+func TestDesiredRouterDeploymentRequestSmugglingProtection(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterStrictRequestFramingEnvName, true, "true"},
+		},
+		{
+			name:      "strict",
+			overrides: `{"requestSmugglingProtection":"Strict"}`,
+			expectEnv: envData{RouterStrictRequestFramingEnvName, true, "true"},
+		},
+		{
+			name:      "lenient",
+			overrides: `{"requestSmugglingProtection":"Lenient"}`,
+			expectEnv: envData{RouterStrictRequestFramingEnvName, true, "false"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentSyslog verifies that desiredRouterDeployment
+// configures the router to send access logs to the syslog endpoint in
+// spec.logging.access.destination.syslog over the protocol that the
+// syslogProtocol unsupported config override specifies, and that changing the
+// destination changes the deployment.
+func TestDesiredRouterDeploymentSyslog(t *testing.T) {
+	syslog := func(address string, port uint32) *operatorv1.IngressControllerLogging {
+		return &operatorv1.IngressControllerLogging{
+			Access: &operatorv1.AccessLogging{
+				Destination: operatorv1.LoggingDestination{
+					Type: operatorv1.SyslogLoggingDestinationType,
+					Syslog: &operatorv1.SyslogLoggingDestinationParameters{
+						Address:   address,
+						Port:      port,
+						Facility:  "local3",
+						MaxLength: 2048,
+					},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		name      string
+		logging   *operatorv1.IngressControllerLogging
+		overrides string
+		expectEnv []envData
+	}{
+		{
+			name:    "UDP by default",
+			logging: syslog("1.2.3.4", 514),
+			expectEnv: []envData{
+				{RouterSyslogAddressEnvName, true, "1.2.3.4:514"},
+				{RouterSyslogFacilityEnvName, true, "local3"},
+				{RouterSyslogMaxLengthEnvName, true, "2048"},
+				{RouterLogLevelEnvName, true, "info"},
+			},
+		},
+		{
+			name:      "UDP",
+			logging:   syslog("2001:db8::1", 514),
+			overrides: `{"syslogProtocol":"UDP"}`,
+			expectEnv: []envData{
+				{RouterSyslogAddressEnvName, true, "[2001:db8::1]:514"},
+			},
+		},
+		{
+			name:      "TCP",
+			logging:   syslog("syslog.example.com", 6514),
+			overrides: `{"syslogProtocol":"TCP"}`,
+			expectEnv: []envData{
+				{RouterSyslogAddressEnvName, true, "tcp@syslog.example.com:6514"},
+				{RouterSyslogFacilityEnvName, true, "local3"},
+				{RouterSyslogMaxLengthEnvName, true, "2048"},
+			},
+		},
+		{
+			name:      "TCP without syslog destination",
+			overrides: `{"syslogProtocol":"TCP"}`,
+			expectEnv: []envData{
+				{RouterSyslogAddressEnvName, false, ""},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.Logging = tc.logging
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("destination change", func(t *testing.T) {
+		ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+		ic.Spec.Logging = syslog("1.2.3.4", 514)
+		current, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		for _, mutate := range []func(*operatorv1.IngressController){
+			func(ic *operatorv1.IngressController) { ic.Spec.Logging = syslog("5.6.7.8", 514) },
+			func(ic *operatorv1.IngressController) { ic.Spec.Logging = syslog("1.2.3.4", 10514) },
+			func(ic *operatorv1.IngressController) {
+				ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"syslogProtocol":"TCP"}`)}
+			},
+		} {
+			changedIC := ic.DeepCopy()
+			mutate(changedIC)
+			expected, err := desiredRouterDeployment(changedIC, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if changed, _ := deploymentConfigChanged(current, expected); !changed {
+				t.Errorf("expected the deployment to change for destination %+v and overrides %s", changedIC.Spec.Logging.Access.Destination.Syslog, string(changedIC.Spec.UnsupportedConfigOverrides.Raw))
+			}
+		}
+	})
+}
+
+// TestDesiredRouterDeploymentForwardedHeaderPolicy verifies that
+// desiredRouterDeployment sets ROUTER_SET_FORWARDED_HEADERS according to
+// spec.httpHeaders.forwardedHeaderPolicy, defaulting to "append", and that
+// changing the policy changes the deployment.
+func TestDesiredRouterDeploymentForwardedHeaderPolicy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		httpHeaders *operatorv1.IngressControllerHTTPHeaders
+		expectValue string
+	}{
+		{
+			name:        "no httpHeaders",
+			expectValue: "append",
+		},
+		{
+			name:        "empty policy",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{},
+			expectValue: "append",
+		},
+		{
+			name:        "Append",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: operatorv1.AppendHTTPHeaderPolicy},
+			expectValue: "append",
+		},
+		{
+			name:        "Replace",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: operatorv1.ReplaceHTTPHeaderPolicy},
+			expectValue: "replace",
+		},
+		{
+			name:        "IfNone",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: operatorv1.IfNoneHTTPHeaderPolicy},
+			expectValue: "if-none",
+		},
+		{
+			name:        "Never",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: operatorv1.NeverHTTPHeaderPolicy},
+			expectValue: "never",
+		},
+	}
+	var previous *appsv1.Deployment
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.HTTPHeaders = tc.httpHeaders
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{{RouterForwardedHeadersPolicy, true, tc.expectValue}}); err != nil {
+				t.Error(err)
+			}
+			if previous != nil {
+				changed, _ := deploymentConfigChanged(previous, deployment)
+				if expectChange := forwardedHeadersValue(t, previous) != tc.expectValue; changed != expectChange {
+					t.Errorf("expected deploymentConfigChanged to return %t, got %t", expectChange, changed)
+				}
+			}
+			previous = deployment
+		})
+	}
+}
+
+// forwardedHeadersValue returns the value of ROUTER_SET_FORWARDED_HEADERS
+// in the given deployment's router container.
+func forwardedHeadersValue(t *testing.T, deployment *appsv1.Deployment) string {
+	t.Helper()
+	for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == RouterForwardedHeadersPolicy {
+			return env.Value
+		}
+	}
+	t.Fatalf("deployment has no %s environment variable", RouterForwardedHeadersPolicy)
+	return ""
+}
+
+// TestDesiredRouterDeploymentHSTS verifies that desiredRouterDeployment sets
+// ROUTER_DEFAULT_HSTS_HEADER to the Strict-Transport-Security header for the
+// hsts unsupported config override.
+func TestDesiredRouterDeploymentHSTS(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterDefaultHSTSHeaderEnvName, false, ""},
+		},
+		{
+			name:      "maxAge only",
+			overrides: `{"hsts":{"maxAge":31536000}}`,
+			expectEnv: envData{RouterDefaultHSTSHeaderEnvName, true, "max-age=31536000"},
+		},
+		{
+			name:      "zero maxAge",
+			overrides: `{"hsts":{"maxAge":0}}`,
+			expectEnv: envData{RouterDefaultHSTSHeaderEnvName, true, "max-age=0"},
+		},
+		{
+			name:      "includeSubDomains and preload",
+			overrides: `{"hsts":{"maxAge":63072000,"includeSubDomains":true,"preload":true}}`,
+			expectEnv: envData{RouterDefaultHSTSHeaderEnvName, true, "max-age=63072000;includeSubDomains;preload"},
+		},
+		{
+			name:      "preload only",
+			overrides: `{"hsts":{"maxAge":63072000,"preload":true}}`,
+			expectEnv: envData{RouterDefaultHSTSHeaderEnvName, true, "max-age=63072000;preload"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentSourceHashBalancing verifies that
+// desiredRouterDeployment sets ROUTER_LOAD_BALANCE_ALGORITHM for each hash of
+// the sourceHashBalancing unsupported config override and always balances
+// passthrough routes by source IP.
+func TestDesiredRouterDeploymentSourceHashBalancing(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv []envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: []envData{
+				{RouterLoadBalancingAlgorithmEnvName, true, "random"},
+				{RouterTCPLoadBalancingAlgorithmEnvName, true, "source"},
+			},
+		},
+		{
+			name:      "default hash",
+			overrides: `{"sourceHashBalancing":{}}`,
+			expectEnv: []envData{
+				{RouterLoadBalancingAlgorithmEnvName, true, "source"},
+				{RouterTCPLoadBalancingAlgorithmEnvName, true, "source"},
+			},
+		},
+		{
+			name:      "source IP hash",
+			overrides: `{"sourceHashBalancing":{"hash":"SourceIP"}}`,
+			expectEnv: []envData{
+				{RouterLoadBalancingAlgorithmEnvName, true, "source"},
+				{RouterTCPLoadBalancingAlgorithmEnvName, true, "source"},
+			},
+		},
+		{
+			name:      "header hash",
+			overrides: `{"sourceHashBalancing":{"hash":"Header","headerName":"X-Session-Id"}}`,
+			expectEnv: []envData{
+				{RouterLoadBalancingAlgorithmEnvName, true, "hdr(X-Session-Id)"},
+				{RouterTCPLoadBalancingAlgorithmEnvName, true, "source"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentUniqueId verifies that desiredRouterDeployment
+// sets ROUTER_UNIQUE_ID_HEADER_NAME and ROUTER_UNIQUE_ID_FORMAT only when
+// spec.httpHeaders.uniqueId.name is set, and that changing the unique id
+// header changes the deployment.
+func TestDesiredRouterDeploymentUniqueId(t *testing.T) {
+	testCases := []struct {
+		name      string
+		uniqueId  operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy
+		expectEnv []envData
+	}{
+		{
+			name: "empty",
+			expectEnv: []envData{
+				{RouterUniqueHeaderName, false, ""},
+				{RouterUniqueHeaderFormat, false, ""},
+			},
+		},
+		{
+			name:     "format without name",
+			uniqueId: operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Format: "%ci"},
+			expectEnv: []envData{
+				{RouterUniqueHeaderName, false, ""},
+				{RouterUniqueHeaderFormat, false, ""},
+			},
+		},
+		{
+			name:     "default format",
+			uniqueId: operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "X-Request-Id"},
+			expectEnv: []envData{
+				{RouterUniqueHeaderName, true, "X-Request-Id"},
+				{RouterUniqueHeaderFormat, true, `"%{+X}o %ci:%cp_%fi:%fp_%Ts_%rt:%pid"`},
+			},
+		},
+		{
+			name:     "custom format",
+			uniqueId: operatorv1.IngressControllerHTTPUniqueIdHeaderPolicy{Name: "X-Request-Id", Format: "%ci_%rt"},
+			expectEnv: []envData{
+				{RouterUniqueHeaderName, true, "X-Request-Id"},
+				{RouterUniqueHeaderFormat, true, `"%ci_%rt"`},
+			},
+		},
+	}
+	var previous *appsv1.Deployment
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.HTTPHeaders = &operatorv1.IngressControllerHTTPHeaders{UniqueId: tc.uniqueId}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+			if previous != nil && len(tc.uniqueId.Name) != 0 {
+				if changed, _ := deploymentConfigChanged(previous, deployment); !changed {
+					t.Error("expected the deployment to change")
+				}
+			}
+			previous = deployment
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentHTTPConnectionMode verifies that
+// desiredRouterDeployment sets ROUTER_HTTP_CONNECTION_MODE to the HAProxy
+// option for the httpConnectionMode unsupported config override.
+func TestDesiredRouterDeploymentHTTPConnectionMode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterHTTPConnectionModeEnvName, false, ""},
+		},
+		{
+			name:      "keep-alive",
+			overrides: `{"httpConnectionMode":"KeepAlive"}`,
+			expectEnv: envData{RouterHTTPConnectionModeEnvName, true, "http-keep-alive"},
+		},
+		{
+			name:      "server close",
+			overrides: `{"httpConnectionMode":"ServerClose"}`,
+			expectEnv: envData{RouterHTTPConnectionModeEnvName, true, "http-server-close"},
+		},
+		{
+			name:      "close",
+			overrides: `{"httpConnectionMode":"Close"}`,
+			expectEnv: envData{RouterHTTPConnectionModeEnvName, true, "httpclose"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentReadMethods verifies that desiredRouterDeployment
+// enables method-based routing using ROUTER_READ_METHODS only when the
+// readMethods unsupported config override is set.
+func TestDesiredRouterDeploymentReadMethods(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterReadMethodsEnvName, false, ""},
+		},
+		{
+			name:      "empty list",
+			overrides: `{"readMethods":[]}`,
+			expectEnv: envData{RouterReadMethodsEnvName, false, ""},
+		},
+		{
+			name:      "read methods set",
+			overrides: `{"readMethods":["GET","HEAD"]}`,
+			expectEnv: envData{RouterReadMethodsEnvName, true, "GET HEAD"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentCompressionLevel verifies that
+// desiredRouterDeployment sets ROUTER_COMPRESSION_LEVEL from the
+// compressionLevel unsupported config override only when HTTP compression is
+// enabled.
+func TestDesiredRouterDeploymentCompressionLevel(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mimeTypes []operatorv1.CompressionMIMEType
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "compression enabled, no override",
+			mimeTypes: []operatorv1.CompressionMIMEType{"text/html"},
+			overrides: "",
+			expectEnv: envData{RouterCompressionLevel, false, ""},
+		},
+		{
+			name:      "compression enabled, level set",
+			mimeTypes: []operatorv1.CompressionMIMEType{"text/html"},
+			overrides: `{"compressionLevel":6}`,
+			expectEnv: envData{RouterCompressionLevel, true, "6"},
+		},
+		{
+			name:      "compression disabled, level set",
+			overrides: `{"compressionLevel":6}`,
+			expectEnv: envData{RouterCompressionLevel, false, ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.HTTPCompression.MimeTypes = tc.mimeTypes
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentDynamicConfigManager verifies that
+// desiredRouterDeployment sets ROUTER_HAPROXY_CONFIG_MANAGER, which enables
+// dynamic updates using the HAProxy runtime API, only when the
+// dynamicConfigManager unsupported config override is true.
+func TestDesiredRouterDeploymentDynamicConfigManager(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterHAProxyConfigManager, false, ""},
+		},
+		{
+			name:      "disabled",
+			overrides: `{"dynamicConfigManager":"false"}`,
+			expectEnv: envData{RouterHAProxyConfigManager, false, ""},
+		},
+		{
+			name:      "invalid value",
+			overrides: `{"dynamicConfigManager":"maybe"}`,
+			expectEnv: envData{RouterHAProxyConfigManager, false, ""},
+		},
+		{
+			name:      "enabled",
+			overrides: `{"dynamicConfigManager":"true"}`,
+			expectEnv: envData{RouterHAProxyConfigManager, true, "true"},
+		},
+		{
+			name:      "enabled with an alternative spelling",
+			overrides: `{"dynamicConfigManager":"True"}`,
+			expectEnv: envData{RouterHAProxyConfigManager, true, "true"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentDefaultHostHeader verifies that
+// desiredRouterDeployment sets ROUTER_DEFAULT_HOST_HEADER only when the
+// defaultHostHeader unsupported config override is set.
+func TestDesiredRouterDeploymentDefaultHostHeader(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterDefaultHostHeaderEnvName, false, ""},
+		},
+		{
+			name:      "empty host header",
+			overrides: `{"defaultHostHeader":""}`,
+			expectEnv: envData{RouterDefaultHostHeaderEnvName, false, ""},
+		},
+		{
+			name:      "host header set",
+			overrides: `{"defaultHostHeader":"backend.example.svc"}`,
+			expectEnv: envData{RouterDefaultHostHeaderEnvName, true, "backend.example.svc"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentHTTP2 verifies that desiredRouterDeployment
+// enables HTTP/2 according to the http2 unsupported config override, which
+// takes precedence over the annotations on the ingresscontroller and the
+// cluster ingress config, and that toggling the override changes the router
+// deployment.
+func TestDesiredRouterDeploymentHTTP2(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		controllerAnnotation string
+		configAnnotation     string
+		overrides            string
+		expectDisabled       string
+	}{
+		{
+			name:           "default",
+			expectDisabled: "true",
+		},
+		{
+			name:           "override enables HTTP/2",
+			overrides:      `{"http2":true}`,
+			expectDisabled: "false",
+		},
+		{
+			name:           "override disables HTTP/2",
+			overrides:      `{"http2":false}`,
+			expectDisabled: "true",
+		},
+		{
+			name:                 "override takes precedence over the ingresscontroller annotation",
+			controllerAnnotation: "true",
+			overrides:            `{"http2":false}`,
+			expectDisabled:       "true",
+		},
+		{
+			name:             "override takes precedence over the ingress config annotation",
+			configAnnotation: "false",
+			overrides:        `{"http2":true}`,
+			expectDisabled:   "false",
+		},
+		{
+			name:                 "unset override defers to the annotations",
+			controllerAnnotation: "true",
+			overrides:            `{"http2":null}`,
+			expectDisabled:       "false",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			if len(tc.controllerAnnotation) != 0 {
+				ic.Annotations = map[string]string{RouterDefaultEnableHTTP2Annotation: tc.controllerAnnotation}
+			}
+			if len(tc.configAnnotation) != 0 {
+				ingressConfig.Annotations = map[string]string{RouterDefaultEnableHTTP2Annotation: tc.configAnnotation}
+			}
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{{RouterDisableHTTP2EnvName, true, tc.expectDisabled}}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("toggling the override updates the deployment", func(t *testing.T) {
+		ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+		current, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"http2":true}`)}
+		expected, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		changed, updated := deploymentConfigChanged(current, expected)
+		if !changed {
+			t.Fatal("expected enabling HTTP/2 to change the deployment")
+		}
+		if err := checkDeploymentEnvironment(t, updated, []envData{{RouterDisableHTTP2EnvName, true, "false"}}); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// TestDesiredRouterDeploymentHTTP2MaxConcurrentStreams verifies that
+// desiredRouterDeployment sets ROUTER_H2_MAX_CONCURRENT_STREAMS only when the
+// http2MaxConcurrentStreams unsupported config override is set and HTTP/2 is
+// enabled.
+func TestDesiredRouterDeploymentHTTP2MaxConcurrentStreams(t *testing.T) {
+	testCases := []struct {
+		name         string
+		http2Enabled bool
+		overrides    string
+		expectEnv    envData
+	}{
+		{
+			name:         "no override",
+			http2Enabled: true,
+			overrides:    "",
+			expectEnv:    envData{RouterHTTP2MaxConcurrentStreamsEnvName, false, ""},
+		},
+		{
+			name:         "override with HTTP/2 enabled",
+			http2Enabled: true,
+			overrides:    `{"http2MaxConcurrentStreams":250}`,
+			expectEnv:    envData{RouterHTTP2MaxConcurrentStreamsEnvName, true, "250"},
+		},
+		{
+			name:         "override with HTTP/2 disabled",
+			http2Enabled: false,
+			overrides:    `{"http2MaxConcurrentStreams":250}`,
+			expectEnv:    envData{RouterHTTP2MaxConcurrentStreamsEnvName, false, ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Annotations = map[string]string{RouterDefaultEnableHTTP2Annotation: strconv.FormatBool(tc.http2Enabled)}
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentALPNProtocols verifies that
+// desiredRouterDeployment sets ROUTER_ALPN_PROTOCOLS to the alpnProtocols
+// unsupported config override, in order, only when it is set.
+func TestDesiredRouterDeploymentALPNProtocols(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterALPNProtocolsEnvName, false, ""},
+		},
+		{
+			name:      "h2 preferred",
+			overrides: `{"alpnProtocols":["h2","http/1.1"]}`,
+			expectEnv: envData{RouterALPNProtocolsEnvName, true, "h2,http/1.1"},
+		},
+		{
+			name:      "HTTP/1.1 preferred",
+			overrides: `{"alpnProtocols":["http/1.1","h2"]}`,
+			expectEnv: envData{RouterALPNProtocolsEnvName, true, "http/1.1,h2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Annotations = map[string]string{RouterDefaultEnableHTTP2Annotation: "true"}
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentCipherPreference verifies that
+// desiredRouterDeployment sets ROUTER_PREFER_SERVER_CIPHERS according to the
+// cipherPreference unsupported config override.
+func TestDesiredRouterDeploymentCipherPreference(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterPreferServerCiphersEnvName, false, ""},
+		},
+		{
+			name:      "server preference",
+			overrides: `{"cipherPreference":"Server"}`,
+			expectEnv: envData{RouterPreferServerCiphersEnvName, true, "true"},
+		},
+		{
+			name:      "client preference",
+			overrides: `{"cipherPreference":"Client"}`,
+			expectEnv: envData{RouterPreferServerCiphersEnvName, true, "false"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentCertificateFailurePolicy verifies that
+// desiredRouterDeployment configures the router to reject invalid default
+// certificates only in fail-closed mode.
+func TestDesiredRouterDeploymentCertificateFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+		expectEnv envData
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+			expectEnv: envData{RouterRejectInvalidDefaultCertificateEnvName, false, ""},
+		},
+		{
+			name:      "fail open",
+			overrides: `{"certificateFailurePolicy":"FailOpen"}`,
+			expectEnv: envData{RouterRejectInvalidDefaultCertificateEnvName, false, ""},
+		},
+		{
+			name:      "fail closed",
+			overrides: `{"certificateFailurePolicy":"FailClosed"}`,
+			expectEnv: envData{RouterRejectInvalidDefaultCertificateEnvName, true, "true"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, []envData{tc.expectEnv}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentAdditionalWildcardDomains verifies that
+// desiredRouterDeployment projects the certificate for each additional wildcard
+// domain into a per-domain directory and points the router at it.
+func TestDesiredRouterDeploymentAdditionalWildcardDomains(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{
+		Raw: []byte(`{"additionalWildcardDomains":["internal.example.com","partners.example.com"]}`),
+	}
+	deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if err := checkDeploymentEnvironment(t, deployment, []envData{
+		{RouterWildcardCertificatesDirEnvName, true, wildcardCertificatesVolumeMountPath},
+	}); err != nil {
+		t.Error(err)
+	}
+
+	var volume *corev1.Volume
+	for i := range deployment.Spec.Template.Spec.Volumes {
+		if deployment.Spec.Template.Spec.Volumes[i].Name == wildcardCertificatesVolumeName {
+			volume = &deployment.Spec.Template.Spec.Volumes[i]
+		}
+	}
+	if volume == nil || volume.Projected == nil {
+		t.Fatalf("expected a projected %s volume, got %v", wildcardCertificatesVolumeName, volume)
+	}
+	if len(volume.Projected.Sources) != 2 {
+		t.Fatalf("expected 2 projected sources, got %d", len(volume.Projected.Sources))
+	}
+	for i, domain := range []string{"internal.example.com", "partners.example.com"} {
+		source := volume.Projected.Sources[i].Secret
+		expectedName := controller.RouterWildcardCertificateSecretName(ic, deployment.Namespace, domain).Name
+		if source == nil || source.Name != expectedName {
+			t.Errorf("expected source %d to project secret %s, got %v", i, expectedName, source)
+			continue
+		}
+		if source.Optional == nil || !*source.Optional {
+			t.Errorf("expected the source for secret %s to be optional", expectedName)
+		}
+		expectedItems := []corev1.KeyToPath{
+			{Key: "tls.crt", Path: domain + "/tls.crt"},
+			{Key: "tls.key", Path: domain + "/tls.key"},
+		}
+		if !reflect.DeepEqual(source.Items, expectedItems) {
+			t.Errorf("expected items %v for secret %s, got %v", expectedItems, expectedName, source.Items)
+		}
+	}
+	mounted := false
+	for _, mount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if mount.Name == wildcardCertificatesVolumeName && mount.MountPath == wildcardCertificatesVolumeMountPath && mount.ReadOnly {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("expected the %s volume to be mounted read-only at %s", wildcardCertificatesVolumeName, wildcardCertificatesVolumeMountPath)
+	}
+}
+
+// TestDesiredRouterDeploymentTunnelTimeout verifies that
+// desiredRouterDeployment sets ROUTER_DEFAULT_TUNNEL_TIMEOUT independently of
+// the server timeout.
+func TestDesiredRouterDeploymentTunnelTimeout(t *testing.T) {
+	testCases := []struct {
+		name          string
+		serverTimeout *metav1.Duration
+		tunnelTimeout *metav1.Duration
+		expectEnv     []envData
+	}{
+		{
+			name:      "no timeouts",
+			expectEnv: []envData{{"ROUTER_DEFAULT_SERVER_TIMEOUT", false, ""}, {"ROUTER_DEFAULT_TUNNEL_TIMEOUT", false, ""}},
+		},
+		{
+			name:          "tunnel timeout only",
+			tunnelTimeout: &metav1.Duration{Duration: 2 * time.Hour},
+			expectEnv:     []envData{{"ROUTER_DEFAULT_SERVER_TIMEOUT", false, ""}, {"ROUTER_DEFAULT_TUNNEL_TIMEOUT", true, "2h"}},
+		},
+		{
+			name:          "tunnel timeout and server timeout",
+			serverTimeout: &metav1.Duration{Duration: time.Minute},
+			tunnelTimeout: &metav1.Duration{Duration: 24 * time.Hour},
+			expectEnv:     []envData{{"ROUTER_DEFAULT_SERVER_TIMEOUT", true, "1m"}, {"ROUTER_DEFAULT_TUNNEL_TIMEOUT", true, "24h"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Spec.TuningOptions.ServerTimeout = tc.serverTimeout
+			ic.Spec.TuningOptions.TunnelTimeout = tc.tunnelTimeout
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			if err := checkDeploymentEnvironment(t, deployment, tc.expectEnv); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentClientCAConfigMapHash verifies that the router
+// deployment is updated when the content of the client CA configmap changes
+// but not when only the configmap's metadata changes.
+func TestDesiredRouterDeploymentClientCAConfigMapHash(t *testing.T) {
+	makeCABundle := func(cn string) string {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		certTemplate := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: cn},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		cert, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, &key.PublicKey, key)
+		if err != nil {
+			t.Fatalf("failed to generate certificate: %v", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}))
+	}
+	caBundle := makeCABundle("ca.example.com")
+	otherCABundle := makeCABundle("other-ca.example.com")
+
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Spec.ClientTLS.ClientCertificatePolicy = operatorv1.ClientCertificatePolicyRequired
+	ic.Spec.ClientTLS.ClientCA.Name = "client-ca"
+	clientCAConfigmap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.ClientCAConfigMapName(ic).Name,
+			Namespace:       controller.ClientCAConfigMapName(ic).Namespace,
+			ResourceVersion: "1",
+		},
+		Data: map[string]string{"ca-bundle.pem": caBundle},
+	}
+	current, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, true, clientCAConfigmap)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if len(current.Spec.Template.Annotations[ClientCAConfigMapHashAnnotation]) == 0 {
+		t.Fatalf("expected the %s annotation to be set", ClientCAConfigMapHashAnnotation)
+	}
+
+	testCases := []struct {
+		name          string
+		mutate        func(*corev1.ConfigMap)
+		expectChanged bool
+	}{
+		{
+			name: "metadata-only update",
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.ResourceVersion = "2"
+				cm.Labels = map[string]string{"foo": "bar"}
+				cm.Annotations = map[string]string{"foo": "bar"}
+			},
+			expectChanged: false,
+		},
+		{
+			name: "content update",
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.ResourceVersion = "2"
+				cm.Data["ca-bundle.pem"] = otherCABundle
+			},
+			expectChanged: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			updatedConfigmap := clientCAConfigmap.DeepCopy()
+			tc.mutate(updatedConfigmap)
+			expected, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, true, updatedConfigmap)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			changed, updated := deploymentConfigChanged(current, expected)
+			if changed != tc.expectChanged {
+				t.Fatalf("expected deploymentConfigChanged to return %t, got %t", tc.expectChanged, changed)
+			}
+			if changed && deploymentTemplateHash(updated) == deploymentTemplateHash(current) {
+				t.Error("expected the pod template hash to change so that the router is rolled out")
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentAccessLogRateLimit verifies that changing the
+// accessLogRateLimit unsupported config override rolls out the router so that
+// the logging sidecar picks up its new configuration.
+func TestDesiredRouterDeploymentAccessLogRateLimit(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Spec.Logging = &operatorv1.IngressControllerLogging{
+		Access: &operatorv1.AccessLogging{
+			Destination: operatorv1.LoggingDestination{
+				Type:      operatorv1.ContainerLoggingDestinationType,
+				Container: &operatorv1.ContainerLoggingDestinationParameters{},
+			},
+		},
+	}
+	current, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if len(current.Spec.Template.Annotations[RsyslogConfigHashAnnotation]) == 0 {
+		t.Fatalf("expected the %s annotation to be set", RsyslogConfigHashAnnotation)
+	}
+
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"accessLogRateLimit":100}`)}
+	expected, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	changed, updated := deploymentConfigChanged(current, expected)
+	if !changed {
+		t.Fatal("expected deploymentConfigChanged to detect the change to the rate limit")
+	}
+	if deploymentTemplateHash(updated) == deploymentTemplateHash(current) {
+		t.Error("expected the pod template hash to change so that the router is rolled out")
+	}
+}
+
+// TestDesiredRouterDeploymentEphemeralStorage verifies that
+// desiredRouterDeployment applies the ephemeral-storage request and limit from
+// the ephemeralStorage unsupported config override to the router container
+// and that changing them updates the deployment.
+func TestDesiredRouterDeploymentEphemeralStorage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		overrides     string
+		expectRequest string
+		expectLimit   string
+	}{
+		{
+			name:      "no override",
+			overrides: "",
+		},
+		{
+			name:          "request only",
+			overrides:     `{"ephemeralStorage":{"request":"512Mi"}}`,
+			expectRequest: "512Mi",
+		},
+		{
+			name:          "request and limit",
+			overrides:     `{"ephemeralStorage":{"request":"512Mi","limit":"1Gi"}}`,
+			expectRequest: "512Mi",
+			expectLimit:   "1Gi",
+		},
+	}
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	original, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			resources := deployment.Spec.Template.Spec.Containers[0].Resources
+			for _, check := range []struct {
+				kind     string
+				list     corev1.ResourceList
+				expected string
+			}{
+				{"request", resources.Requests, tc.expectRequest},
+				{"limit", resources.Limits, tc.expectLimit},
+			} {
+				quantity, ok := check.list[corev1.ResourceEphemeralStorage]
+				switch {
+				case len(check.expected) == 0 && ok:
+					t.Errorf("expected no ephemeral-storage %s, got %s", check.kind, quantity.String())
+				case len(check.expected) != 0 && !ok:
+					t.Errorf("expected ephemeral-storage %s %s, got none", check.kind, check.expected)
+				case len(check.expected) != 0 && quantity.String() != check.expected:
+					t.Errorf("expected ephemeral-storage %s %s, got %s", check.kind, check.expected, quantity.String())
+				}
+			}
+			if _, ok := resources.Requests[corev1.ResourceCPU]; !ok {
+				t.Error("expected the CPU request to be preserved")
+			}
+			changed, _ := deploymentConfigChanged(original, deployment)
+			if expectChanged := len(tc.expectRequest) != 0 || len(tc.expectLimit) != 0; changed != expectChanged {
+				t.Errorf("expected deploymentConfigChanged to return %t, got %t", expectChanged, changed)
+			}
+		})
+	}
+}
+
+// TestDesiredRouterDeploymentFSGroup verifies that desiredRouterDeployment
+// applies the fsGroup from the unsupported config override to the router pod's
+// security context, that it sets no fsGroup by default, and that changing the
+// override updates the deployment.
+func TestDesiredRouterDeploymentFSGroup(t *testing.T) {
+	fsGroup := func(id int64) *int64 { return &id }
+	testCases := []struct {
+		name          string
+		overrides     string
+		expectFSGroup *int64
+	}{
+		{
+			name:          "no override",
+			overrides:     "",
+			expectFSGroup: nil,
+		},
+		{
+			name:          "override",
+			overrides:     `{"fsGroup":1000}`,
+			expectFSGroup: fsGroup(1000),
+		},
+		{
+			name:          "override with the root group",
+			overrides:     `{"fsGroup":0}`,
+			expectFSGroup: fsGroup(0),
+		},
+	}
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	original, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			var actualFSGroup *int64
+			if sc := deployment.Spec.Template.Spec.SecurityContext; sc != nil {
+				actualFSGroup = sc.FSGroup
+			}
+			if !reflect.DeepEqual(actualFSGroup, tc.expectFSGroup) {
+				t.Fatalf("expected fsGroup %v, got %v", tc.expectFSGroup, actualFSGroup)
+			}
+			expectChanged := tc.expectFSGroup != nil
+			changed, updated := deploymentConfigChanged(original, deployment)
+			if changed != expectChanged {
+				t.Fatalf("expected deploymentConfigChanged to return %t, got %t", expectChanged, changed)
+			}
+			if changed && !reflect.DeepEqual(updated.Spec.Template.Spec.SecurityContext.FSGroup, tc.expectFSGroup) {
+				t.Errorf("expected updated fsGroup %v, got %v", tc.expectFSGroup, updated.Spec.Template.Spec.SecurityContext.FSGroup)
+			}
+
+			// Removing the override must remove the fsGroup again.
+			changed, updated = deploymentConfigChanged(deployment, original)
+			if changed != expectChanged {
+				t.Fatalf("expected deploymentConfigChanged to return %t when the override is removed, got %t", expectChanged, changed)
+			}
+			if changed && updated.Spec.Template.Spec.SecurityContext.FSGroup != nil {
+				t.Errorf("expected the fsGroup to be removed, got %d", *updated.Spec.Template.Spec.SecurityContext.FSGroup)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -17,6 +18,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // defaultRecordTTL is the TTL (in seconds) assigned to all new DNS records.
@@ -99,15 +103,19 @@ func desiredWildcardDNSRecord(ic *operatorv1.IngressController, service *corev1.
 	name := controller.WildcardDNSRecordName(ic)
 	// Use an absolute name to prevent any ambiguity.
 	domain := fmt.Sprintf("*.%s.", ic.Status.Domain)
-	var target string
+	var targets []string
 	var recordType iov1.DNSRecordType
 
 	if len(ingress.Hostname) > 0 {
 		recordType = iov1.CNAMERecordType
-		target = ingress.Hostname
+		targets = []string{ingress.Hostname}
 	} else {
+		// Publish every IP address of the load balancer so that
+		// clients round-robin among them.  Keep the order of the
+		// service's status so that the first target, which DNS
+		// providers that support only a single target use, is stable.
 		recordType = iov1.ARecordType
-		target = ingress.IP
+		targets = loadBalancerIngressIPs(service.Status.LoadBalancer.Ingress)
 	}
 
 	trueVar := true
@@ -132,13 +140,29 @@ func desiredWildcardDNSRecord(ic *operatorv1.IngressController, service *corev1.
 		},
 		Spec: iov1.DNSRecordSpec{
 			DNSName:    domain,
-			Targets:    []string{target},
+			Targets:    targets,
 			RecordType: recordType,
 			RecordTTL:  defaultRecordTTL,
 		},
 	}
 }
 
+// loadBalancerIngressIPs returns the distinct IP addresses of the given load
+// balancer ingress points in order, ignoring ingress points that have no IP
+// address.
+func loadBalancerIngressIPs(ingresses []corev1.LoadBalancerIngress) []string {
+	var ips []string
+	seen := sets.NewString()
+	for _, ingress := range ingresses {
+		if len(ingress.IP) == 0 || seen.Has(ingress.IP) {
+			continue
+		}
+		seen.Insert(ingress.IP)
+		ips = append(ips, ingress.IP)
+	}
+	return ips
+}
+
 func (r *reconciler) currentWildcardDNSRecord(ic *operatorv1.IngressController) (bool, *iov1.DNSRecord, error) {
 	current := &iov1.DNSRecord{}
 	err := r.client.Get(context.TODO(), controller.WildcardDNSRecordName(ic), current)
@@ -165,32 +189,81 @@ func (r *reconciler) deleteWildcardDNSRecord(ic *operatorv1.IngressController) e
 	return nil
 }
 
-// updateDNSRecord updates a DNSRecord. Returns a boolean indicating whether
-// the record was updated, and an error value.
-func (r *reconciler) updateDNSRecord(current, desired *iov1.DNSRecord) (bool, error) {
-	changed, updated := dnsRecordChanged(current, desired)
-	if !changed {
-		return false, nil
+// retainWildcardDNSRecord retains the wildcard DNS record of the given
+// ingresscontroller, which is being deleted, for the given grace period.  The
+// record's owner reference to the ingresscontroller is removed so that the
+// record is not garbage-collected along with the ingresscontroller, and the
+// record is annotated with the time until which it is retained.  The DNS
+// controller deletes the record after that time unless an ingresscontroller
+// with the same name has adopted it.  Returns a Boolean indicating whether the
+// record exists, and an error value.
+func (r *reconciler) retainWildcardDNSRecord(ic *operatorv1.IngressController, gracePeriod time.Duration, now time.Time) (bool, error) {
+	haveRec, current, err := r.currentWildcardDNSRecord(ic)
+	if err != nil || !haveRec {
+		return false, err
+	}
+	if _, ok := current.Annotations[manifests.DNSRecordRetainUntilAnnotation]; ok {
+		return true, nil
 	}
 
-	// Diff before updating because the client may mutate the object.
-	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+	updated := current.DeepCopy()
+	var ownerRefs []metav1.OwnerReference
+	for _, ref := range updated.OwnerReferences {
+		if ref.UID != ic.UID {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	updated.OwnerReferences = ownerRefs
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	retainUntil := now.Add(gracePeriod).UTC().Format(time.RFC3339)
+	updated.Annotations[manifests.DNSRecordRetainUntilAnnotation] = retainUntil
 	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return true, fmt.Errorf("failed to retain dnsrecord %s/%s: %w", updated.Namespace, updated.Name, err)
+	}
+	log.Info("retained dnsrecord", "namespace", updated.Namespace, "name", updated.Name, "until", retainUntil)
+	return true, nil
+}
+
+// updateDNSRecord updates a DNSRecord, retrying if the update conflicts with
+// another writer's. Returns a boolean indicating whether the record was
+// updated, and an error value.
+func (r *reconciler) updateDNSRecord(current, desired *iov1.DNSRecord) (bool, error) {
+	current = current.DeepCopy()
+	var diff string
+	updated, err := controller.UpdateWithRetryOnConflict(r.client, current, func() crclient.Object {
+		changed, updated := dnsRecordChanged(current, desired)
+		if !changed {
+			return nil
+		}
+		// Diff before updating because the client may mutate the object.
+		diff = cmp.Diff(current, updated, cmpopts.EquateEmpty())
+		return updated
+	})
+	if err != nil || !updated {
 		return false, err
 	}
-	log.Info("updated dnsrecord", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
+	log.Info("updated dnsrecord", "namespace", desired.Namespace, "name", desired.Name, "diff", diff)
 	return true, nil
 }
 
 // dnsRecordChanged checks if the current DNSRecord spec matches the expected spec and
-// if not returns an updated one.
+// if not returns an updated one.  If the current DNSRecord was retained after
+// its ingresscontroller was deleted, the updated one is adopted by the
+// expected one's owner.
 func dnsRecordChanged(current, expected *iov1.DNSRecord) (bool, *iov1.DNSRecord) {
-	if cmp.Equal(current.Spec, expected.Spec, cmpopts.EquateEmpty()) {
+	_, retained := current.Annotations[manifests.DNSRecordRetainUntilAnnotation]
+	if !retained && cmp.Equal(current.Spec, expected.Spec, cmpopts.EquateEmpty()) {
 		return false, nil
 	}
 
 	updated := current.DeepCopy()
 	updated.Spec = expected.Spec
+	if retained {
+		delete(updated.Annotations, manifests.DNSRecordRetainUntilAnnotation)
+		updated.OwnerReferences = expected.OwnerReferences
+	}
 	return true, updated
 }
 
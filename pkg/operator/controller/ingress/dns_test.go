@@ -1,7 +1,9 @@
 package ingress
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -10,10 +12,16 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	iov1 "github.com/openshift/api/operatoringress/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredWildcardDNSRecord(t *testing.T) {
@@ -74,6 +82,55 @@ func TestDesiredWildcardDNSRecord(t *testing.T) {
 				RecordTTL:  defaultRecordTTL,
 			},
 		},
+		{
+			description: "two IPs to A records",
+			publish:     operatorv1.LoadBalancerServiceStrategyType,
+			domain:      "apps.openshift.example.com",
+			ingresses: []corev1.LoadBalancerIngress{
+				{IP: "192.0.2.1"},
+				{IP: "192.0.2.2"},
+			},
+			expect: &iov1.DNSRecordSpec{
+				DNSName:    "*.apps.openshift.example.com.",
+				RecordType: iov1.ARecordType,
+				Targets:    []string{"192.0.2.1", "192.0.2.2"},
+				RecordTTL:  defaultRecordTTL,
+			},
+		},
+		{
+			description: "many IPs to A records in status order",
+			publish:     operatorv1.LoadBalancerServiceStrategyType,
+			domain:      "apps.openshift.example.com",
+			ingresses: []corev1.LoadBalancerIngress{
+				{IP: "192.0.2.4"},
+				{IP: "192.0.2.1"},
+				{IP: "192.0.2.3"},
+				{IP: "192.0.2.2"},
+			},
+			expect: &iov1.DNSRecordSpec{
+				DNSName:    "*.apps.openshift.example.com.",
+				RecordType: iov1.ARecordType,
+				Targets:    []string{"192.0.2.4", "192.0.2.1", "192.0.2.3", "192.0.2.2"},
+				RecordTTL:  defaultRecordTTL,
+			},
+		},
+		{
+			description: "duplicate IPs and ingresses without IPs are ignored",
+			publish:     operatorv1.LoadBalancerServiceStrategyType,
+			domain:      "apps.openshift.example.com",
+			ingresses: []corev1.LoadBalancerIngress{
+				{IP: "192.0.2.1"},
+				{Hostname: "lb.cloud.example.com"},
+				{IP: "192.0.2.2"},
+				{IP: "192.0.2.1"},
+			},
+			expect: &iov1.DNSRecordSpec{
+				DNSName:    "*.apps.openshift.example.com.",
+				RecordType: iov1.ARecordType,
+				Targets:    []string{"192.0.2.1", "192.0.2.2"},
+				RecordTTL:  defaultRecordTTL,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -181,3 +238,214 @@ func toYaml(obj interface{}) string {
 	yml, _ := yaml.Marshal(obj)
 	return string(yml)
 }
+
+// TestDNSRecordChangedLoadBalancerIPs verifies that the wildcard DNS record
+// is updated when the set of load balancer IP addresses changes.
+func TestDNSRecordChangedLoadBalancerIPs(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.openshift.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	serviceWithIPs := func(ips ...string) *corev1.Service {
+		service := &corev1.Service{}
+		for _, ip := range ips {
+			service.Status.LoadBalancer.Ingress = append(service.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+		}
+		return service
+	}
+	tests := []struct {
+		description   string
+		currentIPs    []string
+		desiredIPs    []string
+		expectChanged bool
+	}{
+		{
+			description:   "same single IP",
+			currentIPs:    []string{"192.0.2.1"},
+			desiredIPs:    []string{"192.0.2.1"},
+			expectChanged: false,
+		},
+		{
+			description:   "IP added",
+			currentIPs:    []string{"192.0.2.1"},
+			desiredIPs:    []string{"192.0.2.1", "192.0.2.2"},
+			expectChanged: true,
+		},
+		{
+			description:   "IP removed",
+			currentIPs:    []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+			desiredIPs:    []string{"192.0.2.1", "192.0.2.3"},
+			expectChanged: true,
+		},
+		{
+			description:   "same IPs",
+			currentIPs:    []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+			desiredIPs:    []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+			expectChanged: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			_, current := desiredWildcardDNSRecord(ic, serviceWithIPs(test.currentIPs...))
+			_, desired := desiredWildcardDNSRecord(ic, serviceWithIPs(test.desiredIPs...))
+			changed, updated := dnsRecordChanged(current, desired)
+			if changed != test.expectChanged {
+				t.Fatalf("expected changed to be %t, got %t", test.expectChanged, changed)
+			}
+			if changed && !cmp.Equal(updated.Spec.Targets, test.desiredIPs) {
+				t.Errorf("expected targets %v, got %v", test.desiredIPs, updated.Spec.Targets)
+			}
+		})
+	}
+}
+
+// TestRetainAndAdoptWildcardDNSRecord verifies that retainWildcardDNSRecord
+// orphans and annotates the wildcard dnsrecord of a deleted ingresscontroller
+// and that an ingresscontroller with the same name that is created within the
+// grace period adopts the record.
+func TestRetainAndAdoptWildcardDNSRecord(t *testing.T) {
+	newIC := func(uid types.UID) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      "default",
+				UID:       uid,
+			},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"dnsRecordDeletionGracePeriodSeconds":300}`)},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.openshift.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+	}
+	serviceWithHostname := func(hostname string) *corev1.Service {
+		service := &corev1.Service{}
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: hostname}}
+		return service
+	}
+	oldIC, recreatedIC := newIC("old"), newIC("new")
+	_, record := desiredWildcardDNSRecord(oldIC, serviceWithHostname("old-lb.example.com"))
+
+	scheme := runtime.NewScheme()
+	if err := iov1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(record).Build()
+	r := &reconciler{client: cl}
+	getRecord := func() *iov1.DNSRecord {
+		t.Helper()
+		current := &iov1.DNSRecord{}
+		if err := cl.Get(context.Background(), controller.WildcardDNSRecordName(oldIC), current); err != nil {
+			t.Fatalf("failed to get dnsrecord: %v", err)
+		}
+		return current
+	}
+
+	if gracePeriod := dnsRecordDeletionGracePeriod(oldIC); gracePeriod != 5*time.Minute {
+		t.Fatalf("expected a grace period of 5m, got %v", gracePeriod)
+	}
+	now := time.Date(2022, time.May, 1, 12, 0, 0, 0, time.UTC)
+	if have, err := r.retainWildcardDNSRecord(oldIC, 5*time.Minute, now); err != nil || !have {
+		t.Fatalf("expected the dnsrecord to be retained, got have=%t, err=%v", have, err)
+	}
+	retained := getRecord()
+	if len(retained.OwnerReferences) != 0 {
+		t.Errorf("expected the retained dnsrecord to have no owner references, got %v", retained.OwnerReferences)
+	}
+	if v := retained.Annotations[manifests.DNSRecordRetainUntilAnnotation]; v != "2022-05-01T12:05:00Z" {
+		t.Errorf("expected %s=2022-05-01T12:05:00Z, got %q", manifests.DNSRecordRetainUntilAnnotation, v)
+	}
+
+	// Reconciling the deletion again does not extend the grace period.
+	if _, err := r.retainWildcardDNSRecord(oldIC, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := getRecord().Annotations[manifests.DNSRecordRetainUntilAnnotation]; v != "2022-05-01T12:05:00Z" {
+		t.Errorf("expected the grace period not to be extended, got %q", v)
+	}
+
+	// An ingresscontroller that is recreated within the grace period
+	// adopts the record and points it at its own load balancer.
+	platformStatus := &configv1.PlatformStatus{Type: configv1.GCPPlatformType}
+	dnsConfig := &configv1.DNS{Spec: configv1.DNSSpec{BaseDomain: "openshift.example.com"}}
+	if _, _, err := r.ensureWildcardDNSRecord(recreatedIC, platformStatus, dnsConfig, serviceWithHostname("new-lb.example.com"), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	adopted := getRecord()
+	if _, ok := adopted.Annotations[manifests.DNSRecordRetainUntilAnnotation]; ok {
+		t.Errorf("expected the adopted dnsrecord not to have the %s annotation", manifests.DNSRecordRetainUntilAnnotation)
+	}
+	if len(adopted.OwnerReferences) != 1 || adopted.OwnerReferences[0].UID != recreatedIC.UID {
+		t.Errorf("expected the adopted dnsrecord to be owned by the recreated ingresscontroller, got %v", adopted.OwnerReferences)
+	}
+	if !cmp.Equal(adopted.Spec.Targets, []string{"new-lb.example.com"}) {
+		t.Errorf("expected the adopted dnsrecord to target the new load balancer, got %v", adopted.Spec.Targets)
+	}
+}
+
+// TestUpdateDNSRecordRetriesOnConflict verifies that updateDNSRecord retries
+// an update that conflicts with another writer's, such as another replica of
+// the operator, and preserves the other writer's change.
+func TestUpdateDNSRecordRetriesOnConflict(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.openshift.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "old-lb.example.com"}}
+	_, record := desiredWildcardDNSRecord(ic, service)
+
+	scheme := runtime.NewScheme()
+	if err := iov1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(record).Build()
+	r := &reconciler{client: cl}
+	name := controller.WildcardDNSRecordName(ic)
+
+	stale := &iov1.DNSRecord{}
+	if err := cl.Get(context.Background(), name, stale); err != nil {
+		t.Fatalf("failed to get dnsrecord: %v", err)
+	}
+	// Another writer modifies the record after it was read.
+	other := stale.DeepCopy()
+	other.Labels = map[string]string{"writer": "other"}
+	if err := cl.Update(context.Background(), other); err != nil {
+		t.Fatalf("failed to update dnsrecord: %v", err)
+	}
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "new-lb.example.com"}}
+	_, desired := desiredWildcardDNSRecord(ic, service)
+	if updated, err := r.updateDNSRecord(stale, desired); err != nil || !updated {
+		t.Fatalf("expected the dnsrecord to be updated, got updated=%t, err=%v", updated, err)
+	}
+	actual := &iov1.DNSRecord{}
+	if err := cl.Get(context.Background(), name, actual); err != nil {
+		t.Fatalf("failed to get dnsrecord: %v", err)
+	}
+	if !cmp.Equal(actual.Spec.Targets, []string{"new-lb.example.com"}) {
+		t.Errorf("expected the dnsrecord to target the new load balancer, got %v", actual.Spec.Targets)
+	}
+	if actual.Labels["writer"] != "other" {
+		t.Errorf("expected the other writer's change to be preserved, got labels %v", actual.Labels)
+	}
+}
@@ -0,0 +1,201 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// networkTypeOpenShiftSDN and networkTypeOVNKubernetes are the values
+	// of the cluster network config's networkType field for the network
+	// plugins that support egress IPs.
+	networkTypeOpenShiftSDN  = "OpenShiftSDN"
+	networkTypeOVNKubernetes = "OVNKubernetes"
+
+	// routerEgressIPName is the name of the egressip that the operator
+	// manages for the router namespace on OVN-Kubernetes.
+	routerEgressIPName = "openshift-ingress-router"
+)
+
+var (
+	// netNamespaceGVK is the kind of OpenShift SDN's per-namespace network
+	// configuration.  NetNamespaces only exist on OpenShift SDN, so they are
+	// accessed as unstructured objects rather than added to the operator's
+	// scheme.
+	netNamespaceGVK = schema.GroupVersionKind{Group: "network.openshift.io", Version: "v1", Kind: "NetNamespace"}
+	// egressIPGVK is the kind of OVN-Kubernetes's egress IP configuration,
+	// which only exists on OVN-Kubernetes.
+	egressIPGVK = schema.GroupVersionKind{Group: "k8s.ovn.org", Version: "v1", Kind: "EgressIP"}
+)
+
+// ensureRouterNamespaceEgressIPs ensures that the router namespace's egress
+// IPs match the routerNamespaceEgressIPs unsupported config override of the
+// given ingresscontroller, which must be the default ingresscontroller, using
+// the mechanism of the cluster's network plugin.
+func (r *reconciler) ensureRouterNamespaceEgressIPs(ci *operatorv1.IngressController, networkConfig *configv1.Network) error {
+	if ci.Name != manifests.DefaultIngressControllerName {
+		return nil
+	}
+	overrides, err := getUnsupportedConfigOverrides(ci)
+	if err != nil {
+		return err
+	}
+	ips := overrides.RouterNamespaceEgressIPs
+
+	networkType := networkConfig.Status.NetworkType
+	if len(networkType) == 0 {
+		networkType = networkConfig.Spec.NetworkType
+	}
+	switch networkType {
+	case networkTypeOpenShiftSDN:
+		return r.ensureRouterNetNamespaceEgressIPs(ips)
+	case networkTypeOVNKubernetes:
+		return r.ensureRouterEgressIP(ips)
+	default:
+		if len(ips) != 0 {
+			return fmt.Errorf("router namespace egress IPs are not supported with network type %q", networkType)
+		}
+		return nil
+	}
+}
+
+// ensureRouterNetNamespaceEgressIPs sets the egress IPs of the router
+// namespace's netnamespace to the given IPs.  If no IPs are given, it clears
+// the egress IPs only if the operator set them.
+func (r *reconciler) ensureRouterNetNamespaceEgressIPs(ips []string) error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(netNamespaceGVK)
+	name := types.NamespacedName{Name: operatorcontroller.DefaultOperandNamespace}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if errors.IsNotFound(err) && len(ips) == 0 {
+			return nil
+		}
+		return fmt.Errorf("failed to get netnamespace %s: %w", name.Name, err)
+	}
+
+	_, managed := current.GetAnnotations()[manifests.RouterNamespaceEgressIPsAnnotation]
+	if len(ips) == 0 && !managed {
+		return nil
+	}
+	currentIPs, _, err := unstructured.NestedStringSlice(current.Object, "egressIPs")
+	if err != nil {
+		return fmt.Errorf("failed to read egress IPs of netnamespace %s: %w", name.Name, err)
+	}
+	if managed == (len(ips) != 0) && stringSlicesEqual(currentIPs, ips) {
+		return nil
+	}
+
+	updated := current.DeepCopy()
+	annotations := updated.GetAnnotations()
+	if len(ips) == 0 {
+		unstructured.RemoveNestedField(updated.Object, "egressIPs")
+		delete(annotations, manifests.RouterNamespaceEgressIPsAnnotation)
+	} else {
+		if err := unstructured.SetNestedStringSlice(updated.Object, ips, "egressIPs"); err != nil {
+			return err
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[manifests.RouterNamespaceEgressIPsAnnotation] = ""
+	}
+	updated.SetAnnotations(annotations)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update egress IPs of netnamespace %s: %w", name.Name, err)
+	}
+	log.Info("updated egress IPs of netnamespace", "name", name.Name, "old", currentIPs, "new", ips)
+	return nil
+}
+
+// desiredRouterEgressIP returns the egressip that assigns the given IPs to the
+// router namespace.
+func desiredRouterEgressIP(ips []string) *unstructured.Unstructured {
+	egressIPs := make([]interface{}, len(ips))
+	for i := range ips {
+		egressIPs[i] = ips[i]
+	}
+	egressIP := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"egressIPs": egressIPs,
+			"namespaceSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{
+					"kubernetes.io/metadata.name": operatorcontroller.DefaultOperandNamespace,
+				},
+			},
+		},
+	}}
+	egressIP.SetGroupVersionKind(egressIPGVK)
+	egressIP.SetName(routerEgressIPName)
+	return egressIP
+}
+
+// ensureRouterEgressIP ensures that the egressip for the router namespace
+// exists and has the given IPs, or that it does not exist if no IPs are given.
+func (r *reconciler) ensureRouterEgressIP(ips []string) error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(egressIPGVK)
+	name := types.NamespacedName{Name: routerEgressIPName}
+	haveEgressIP := true
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get egressip %s: %w", name.Name, err)
+		}
+		haveEgressIP = false
+	}
+
+	switch {
+	case len(ips) == 0 && !haveEgressIP:
+		return nil
+	case len(ips) == 0 && haveEgressIP:
+		if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete egressip %s: %w", name.Name, err)
+		}
+		log.Info("deleted egressip", "name", name.Name)
+		return nil
+	}
+
+	desired := desiredRouterEgressIP(ips)
+	if !haveEgressIP {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create egressip %s: %w", name.Name, err)
+		}
+		log.Info("created egressip", "name", name.Name, "egressIPs", ips)
+		return nil
+	}
+	if reflect.DeepEqual(current.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Object["spec"] = desired.Object["spec"]
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update egressip %s: %w", name.Name, err)
+	}
+	log.Info("updated egressip", "name", name.Name, "egressIPs", ips)
+	return nil
+}
+
+// stringSlicesEqual returns true if a and b have the same elements in the same
+// order.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
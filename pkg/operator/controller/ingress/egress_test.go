@@ -0,0 +1,192 @@
+package ingress
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newEgressTestClient returns a fake client that knows the netnamespace and
+// egressip kinds, with the given objects.
+func newEgressTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	s := runtime.NewScheme()
+	for _, gvk := range []schema.GroupVersionKind{netNamespaceGVK, egressIPGVK} {
+		s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func egressTestIngressController(name, overrides string) *operatorv1.IngressController {
+	return &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(overrides)},
+		},
+	}
+}
+
+func networkConfigWithType(networkType string) *configv1.Network {
+	return &configv1.Network{Status: configv1.NetworkStatus{NetworkType: networkType}}
+}
+
+// TestEnsureRouterNetNamespaceEgressIPs verifies that on OpenShift SDN the
+// operator sets the egress IPs of the router namespace's netnamespace from the
+// default ingresscontroller's routerNamespaceEgressIPs override and clears
+// them when the override is removed, but does not clear egress IPs that it
+// did not set.
+func TestEnsureRouterNetNamespaceEgressIPs(t *testing.T) {
+	netNamespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"netname": "openshift-ingress",
+		"netid":   int64(42),
+	}}
+	netNamespace.SetGroupVersionKind(netNamespaceGVK)
+	netNamespace.SetName("openshift-ingress")
+	cl := newEgressTestClient(t, netNamespace)
+	r := &reconciler{client: cl}
+	sdn := networkConfigWithType(networkTypeOpenShiftSDN)
+
+	check := func(t *testing.T, expectIPs []string, expectManaged bool) {
+		t.Helper()
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(netNamespaceGVK)
+		if err := cl.Get(context.Background(), types.NamespacedName{Name: "openshift-ingress"}, current); err != nil {
+			t.Fatalf("failed to get netnamespace: %v", err)
+		}
+		ips, _, _ := unstructured.NestedStringSlice(current.Object, "egressIPs")
+		if !reflect.DeepEqual(ips, expectIPs) {
+			t.Errorf("expected egress IPs %v, got %v", expectIPs, ips)
+		}
+		if _, managed := current.GetAnnotations()[manifests.RouterNamespaceEgressIPsAnnotation]; managed != expectManaged {
+			t.Errorf("expected managed annotation to be present: %t, got %t", expectManaged, managed)
+		}
+		if netID, _, _ := unstructured.NestedInt64(current.Object, "netid"); netID != 42 {
+			t.Errorf("expected netid to be preserved, got %d", netID)
+		}
+	}
+
+	// An ingresscontroller other than the default does not affect the
+	// router namespace.
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("sharded", `{"routerNamespaceEgressIPs":["192.0.2.10"]}`), sdn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(t, nil, false)
+
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", `{"routerNamespaceEgressIPs":["192.0.2.10","192.0.2.11"]}`), sdn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(t, []string{"192.0.2.10", "192.0.2.11"}, true)
+
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", ""), sdn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(t, nil, false)
+
+	// Egress IPs that the operator did not set are preserved.
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(netNamespaceGVK)
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: "openshift-ingress"}, current); err != nil {
+		t.Fatalf("failed to get netnamespace: %v", err)
+	}
+	if err := unstructured.SetNestedStringSlice(current.Object, []string{"192.0.2.20"}, "egressIPs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Update(context.Background(), current); err != nil {
+		t.Fatalf("failed to update netnamespace: %v", err)
+	}
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", ""), sdn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(t, []string{"192.0.2.20"}, false)
+}
+
+// TestEnsureRouterEgressIP verifies that on OVN-Kubernetes the operator
+// creates, updates, and deletes an egressip that selects the router namespace
+// according to the default ingresscontroller's routerNamespaceEgressIPs
+// override.
+func TestEnsureRouterEgressIP(t *testing.T) {
+	cl := newEgressTestClient(t)
+	r := &reconciler{client: cl}
+	ovn := networkConfigWithType(networkTypeOVNKubernetes)
+
+	get := func(t *testing.T) (*unstructured.Unstructured, bool) {
+		t.Helper()
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(egressIPGVK)
+		if err := cl.Get(context.Background(), types.NamespacedName{Name: routerEgressIPName}, current); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, false
+			}
+			t.Fatalf("failed to get egressip: %v", err)
+		}
+		return current, true
+	}
+
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", ""), ovn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := get(t); ok {
+		t.Fatal("expected no egressip without the override")
+	}
+
+	for _, tc := range []struct {
+		overrides string
+		ips       []string
+	}{
+		{`{"routerNamespaceEgressIPs":["192.0.2.10"]}`, []string{"192.0.2.10"}},
+		{`{"routerNamespaceEgressIPs":["192.0.2.10","2001:db8::10"]}`, []string{"192.0.2.10", "2001:db8::10"}},
+	} {
+		ic := egressTestIngressController("default", tc.overrides)
+		if err := r.ensureRouterNamespaceEgressIPs(ic, ovn); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		egressIP, ok := get(t)
+		if !ok {
+			t.Fatal("expected egressip to exist")
+		}
+		actualIPs, _, _ := unstructured.NestedStringSlice(egressIP.Object, "spec", "egressIPs")
+		if !reflect.DeepEqual(actualIPs, tc.ips) {
+			t.Errorf("expected egress IPs %v, got %v", tc.ips, actualIPs)
+		}
+		selector, _, _ := unstructured.NestedStringMap(egressIP.Object, "spec", "namespaceSelector", "matchLabels")
+		if expected := map[string]string{"kubernetes.io/metadata.name": "openshift-ingress"}; !reflect.DeepEqual(selector, expected) {
+			t.Errorf("expected namespace selector %v, got %v", expected, selector)
+		}
+	}
+
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", ""), ovn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := get(t); ok {
+		t.Error("expected egressip to be deleted when the override is removed")
+	}
+}
+
+// TestEnsureRouterNamespaceEgressIPsUnsupportedNetwork verifies that the
+// override is reported as unsupported on network plugins without egress IPs.
+func TestEnsureRouterNamespaceEgressIPsUnsupportedNetwork(t *testing.T) {
+	r := &reconciler{client: newEgressTestClient(t)}
+	kuryr := networkConfigWithType("Kuryr")
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", ""), kuryr); err != nil {
+		t.Errorf("unexpected error without the override: %v", err)
+	}
+	if err := r.ensureRouterNamespaceEgressIPs(egressTestIngressController("default", `{"routerNamespaceEgressIPs":["192.0.2.10"]}`), kuryr); err == nil {
+		t.Error("expected an error for an unsupported network type")
+	}
+}
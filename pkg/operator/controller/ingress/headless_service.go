@@ -0,0 +1,179 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ensureHeadlessService ensures a headless service exists for a given
+// ingresscontroller, if and only if one is desired.  Returns a Boolean
+// indicating whether the headless service exists, the current headless service
+// if it does exist, and an error value.
+func (r *reconciler) ensureHeadlessService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (bool, *corev1.Service, error) {
+	haveService, current, err := r.currentHeadlessService(ic)
+	if err != nil {
+		return false, nil, err
+	}
+
+	wantService, desired := desiredHeadlessService(ic, deploymentRef)
+
+	// Don't modify or delete a service that is not directly owned by this
+	// controller.
+	if haveService && !isServiceOwnedByIngressController(current, ic) {
+		if !wantService {
+			return true, current, nil
+		}
+		return true, current, fmt.Errorf("a conflicting headless service exists that is not owned by the ingress controller: %s", controller.HeadlessIngressControllerServiceName(ic))
+	}
+
+	switch {
+	case !wantService && !haveService:
+		return false, nil, nil
+	case !wantService && haveService:
+		if err := r.client.Delete(context.TODO(), current); err != nil {
+			if !errors.IsNotFound(err) {
+				return true, current, fmt.Errorf("failed to delete headless service: %v", err)
+			}
+		} else {
+			log.Info("deleted headless service", "service", current)
+		}
+		return false, nil, nil
+	case wantService && !haveService:
+		if err := r.applyObject(desired); err != nil {
+			return false, nil, fmt.Errorf("failed to create headless service: %v", err)
+		}
+		log.Info("created headless service", "service", desired)
+		return r.currentHeadlessService(ic)
+	case wantService && haveService:
+		if updated, err := r.updateHeadlessService(current, desired); err != nil {
+			return true, current, fmt.Errorf("failed to update headless service: %v", err)
+		} else if updated {
+			return r.currentHeadlessService(ic)
+		}
+	}
+
+	return true, current, nil
+}
+
+// desiredHeadlessService returns a Boolean indicating whether a headless
+// service is desired, as well as the headless service if one is desired.  A
+// headless service is desired if the ingresscontroller has the headlessService
+// unsupported config override.
+func desiredHeadlessService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (bool, *corev1.Service) {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil || !overrides.HeadlessService {
+		return false, nil
+	}
+
+	name := controller.HeadlessIngressControllerServiceName(ic)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				"app":                                  "router",
+				"router":                               name.Name,
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{deploymentRef},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(80),
+					TargetPort: intstr.FromString("http"),
+				},
+				{
+					Name:       "https",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(443),
+					TargetPort: intstr.FromString("https"),
+				},
+			},
+			Selector: controller.IngressControllerDeploymentPodSelector(ic).MatchLabels,
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+
+	return true, service
+}
+
+// currentHeadlessService returns a Boolean indicating whether a headless
+// service exists for the given ingresscontroller, as well as the headless
+// service if it does exist and an error value.
+func (r *reconciler) currentHeadlessService(ic *operatorv1.IngressController) (bool, *corev1.Service, error) {
+	service := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), controller.HeadlessIngressControllerServiceName(ic), service); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, service, nil
+}
+
+// updateHeadlessService updates a headless service.  Returns a Boolean
+// indicating whether the service was updated, and an error value.
+func (r *reconciler) updateHeadlessService(current, desired *corev1.Service) (bool, error) {
+	changed, updated := headlessServiceChanged(current, desired)
+	if !changed {
+		return false, nil
+	}
+
+	// Diff before updating because the client may mutate the object.
+	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+	if err := r.applyUpdate(current.DeepCopy(), desired.DeepCopy()); err != nil {
+		return false, err
+	}
+	log.Info("updated headless service", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
+	return true, nil
+}
+
+// headlessServiceChanged checks if the current headless service spec matches
+// the expected spec and if not returns an updated one.  The cluster IP is
+// immutable, so a service whose cluster IP is not "None" cannot be updated to
+// be headless and is reported as unchanged.
+func headlessServiceChanged(current, expected *corev1.Service) (bool, *corev1.Service) {
+	serviceCmpOpts := []cmp.Option{
+		// Ignore fields that the API, other controllers, or user may
+		// have modified.
+		cmpopts.IgnoreFields(corev1.ServiceSpec{}, "ClusterIPs", "IPFamilies", "IPFamilyPolicy", "InternalTrafficPolicy"),
+		cmp.Comparer(cmpServiceAffinity),
+		cmpopts.EquateEmpty(),
+	}
+	if cmp.Equal(current.Spec, expected.Spec, serviceCmpOpts...) {
+		return false, nil
+	}
+	if current.Spec.ClusterIP != expected.Spec.ClusterIP {
+		log.Info("headless service has a cluster IP and must be recreated to become headless", "namespace", current.Namespace, "name", current.Name)
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Spec = expected.Spec
+
+	// Preserve fields that the API, other controllers, or user may have
+	// modified.
+	updated.Spec.ClusterIPs = current.Spec.ClusterIPs
+	updated.Spec.IPFamilies = current.Spec.IPFamilies
+	updated.Spec.IPFamilyPolicy = current.Spec.IPFamilyPolicy
+	updated.Spec.InternalTrafficPolicy = current.Spec.InternalTrafficPolicy
+
+	return true, updated
+}
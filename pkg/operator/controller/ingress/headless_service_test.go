@@ -0,0 +1,173 @@
+package ingress
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// TestDesiredHeadlessService verifies that desiredHeadlessService returns a
+// headless service that selects the ingresscontroller's router pods if and
+// only if the ingresscontroller has the headlessService unsupported config
+// override.
+func TestDesiredHeadlessService(t *testing.T) {
+	trueVar := true
+	deploymentRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "router-default",
+		UID:        "1",
+		Controller: &trueVar,
+	}
+	testCases := []struct {
+		name          string
+		overrides     string
+		expectService bool
+	}{
+		{
+			name:          "no overrides",
+			expectService: false,
+		},
+		{
+			name:          "headlessService false",
+			overrides:     `{"headlessService":false}`,
+			expectService: false,
+		},
+		{
+			name:          "headlessService true",
+			overrides:     `{"headlessService":true}`,
+			expectService: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			}
+			if len(tc.overrides) != 0 {
+				ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			}
+			want, svc := desiredHeadlessService(ic, deploymentRef)
+			if want != tc.expectService {
+				t.Fatalf("expected desiredHeadlessService to return %t, got %t", tc.expectService, want)
+			}
+			if !want {
+				return
+			}
+			if svc.Namespace != "openshift-ingress" || svc.Name != "router-headless-default" {
+				t.Errorf("unexpected service name: %s/%s", svc.Namespace, svc.Name)
+			}
+			if svc.Spec.Type != corev1.ServiceTypeClusterIP || svc.Spec.ClusterIP != corev1.ClusterIPNone {
+				t.Errorf("expected a headless ClusterIP service, got type %q and cluster IP %q", svc.Spec.Type, svc.Spec.ClusterIP)
+			}
+			expectSelector := map[string]string{
+				"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "default",
+			}
+			if len(svc.Spec.Selector) != len(expectSelector) {
+				t.Errorf("expected selector %v, got %v", expectSelector, svc.Spec.Selector)
+			}
+			for k, v := range expectSelector {
+				if svc.Spec.Selector[k] != v {
+					t.Errorf("expected selector %v, got %v", expectSelector, svc.Spec.Selector)
+				}
+			}
+			if svc.Labels[manifests.OwningIngressControllerLabel] != "default" {
+				t.Errorf("expected service to have label %s=default, got %v", manifests.OwningIngressControllerLabel, svc.Labels)
+			}
+			if len(svc.OwnerReferences) != 1 || svc.OwnerReferences[0] != deploymentRef {
+				t.Errorf("expected owner reference %v, got %v", deploymentRef, svc.OwnerReferences)
+			}
+			expectPorts := []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("http")},
+				{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromString("https")},
+			}
+			if len(svc.Spec.Ports) != len(expectPorts) {
+				t.Fatalf("expected ports %v, got %v", expectPorts, svc.Spec.Ports)
+			}
+			for i := range expectPorts {
+				if svc.Spec.Ports[i] != expectPorts[i] {
+					t.Errorf("expected port %v, got %v", expectPorts[i], svc.Spec.Ports[i])
+				}
+			}
+		})
+	}
+}
+
+// TestHeadlessServiceChanged verifies that headlessServiceChanged detects
+// changes to the headless service's spec, ignores fields that the API sets,
+// and does not try to change the immutable cluster IP.
+func TestHeadlessServiceChanged(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"headlessService":true}`)},
+		},
+	}
+	testCases := []struct {
+		description string
+		mutate      func(*corev1.Service)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *corev1.Service) {},
+			expect:      false,
+		},
+		{
+			description: "if .spec.clusterIPs is set",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.ClusterIPs = []string{corev1.ClusterIPNone}
+			},
+			expect: false,
+		},
+		{
+			description: "if .spec.ipFamilies is set",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+			},
+			expect: false,
+		},
+		{
+			description: "if .spec.selector changes",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Selector = map[string]string{"foo": "bar"}
+			},
+			expect: true,
+		},
+		{
+			description: "if a port is removed",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Ports = svc.Spec.Ports[:1]
+			},
+			expect: true,
+		},
+		{
+			description: "if .spec.clusterIP is not None",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.ClusterIP = "172.30.0.10"
+				svc.Spec.Selector = map[string]string{"foo": "bar"}
+			},
+			expect: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, original := desiredHeadlessService(ic, metav1.OwnerReference{})
+			mutated := original.DeepCopy()
+			tc.mutate(mutated)
+			if changed, updated := headlessServiceChanged(original, mutated); changed != tc.expect {
+				t.Errorf("expected headlessServiceChanged to be %t, got %t", tc.expect, changed)
+			} else if changed {
+				if changedAgain, _ := headlessServiceChanged(mutated, updated); changedAgain {
+					t.Error("headlessServiceChanged does not behave as a fixed point function")
+				}
+			}
+		})
+	}
+}
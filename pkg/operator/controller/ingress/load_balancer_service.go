@@ -20,6 +20,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -131,7 +132,8 @@ const (
 	// an IngressController to indicate that the operator should
 	// automatically delete any associated service load-balancer when its
 	// scope changes if changing scope requires deleting service
-	// load-balancers on the current platform.
+	// load-balancers on the current platform, or when its AWS load
+	// balancer type changes.
 	autoDeleteLoadBalancerAnnotation = "ingress.operator.openshift.io/auto-delete-load-balancer"
 )
 
@@ -213,6 +215,16 @@ var (
 	// <https://bugzilla.redhat.com/show_bug.cgi?id=1905490>).  In order to
 	// avoid problems, make sure the previous release blocks upgrades when
 	// the user has modified an annotation that the new release manages.
+	// awsLoadBalancerTypeAnnotations is the set of annotations whose
+	// values depend on the type of AWS load balancer.  The operator does
+	// not update these annotations while a change of the load balancer
+	// type is pending.
+	awsLoadBalancerTypeAnnotations = sets.NewString(
+		AWSLBTypeAnnotation,
+		awsLBHealthCheckIntervalAnnotation,
+		awsELBConnectionIdleTimeoutAnnotation,
+	)
+
 	managedLoadBalancerServiceAnnotations = func() sets.String {
 		result := sets.NewString(
 			// AWS LB health check interval annotation (see
@@ -257,6 +269,31 @@ func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController,
 		return false, nil, err
 	}
 
+	overrides, err := getUnsupportedConfigOverrides(ci)
+	if err != nil {
+		return false, nil, err
+	}
+	if name := overrides.SharedLoadBalancerService; len(name) != 0 {
+		if !wantLBS {
+			return false, nil, r.releaseSharedLoadBalancerServices(ci, "")
+		}
+		return r.ensureSharedLoadBalancerService(ci, name, desiredLBService, deploymentRef)
+	}
+	if err := r.releaseSharedLoadBalancerServices(ci, ""); err != nil {
+		return false, nil, err
+	}
+	if name := overrides.ExistingLoadBalancerService; len(name) != 0 {
+		// Never create or delete an adopted service; the tool that
+		// pre-provisioned it owns its life cycle.
+		if !wantLBS {
+			return false, nil, nil
+		}
+		return r.ensureAdoptedLoadBalancerService(types.NamespacedName{
+			Namespace: desiredLBService.Namespace,
+			Name:      name,
+		}, desiredLBService)
+	}
+
 	haveLBS, currentLBService, err := r.currentLoadBalancerService(ci)
 	if err != nil {
 		return false, nil, err
@@ -293,11 +330,11 @@ func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController,
 				return haveLBS, currentLBService, err
 			}
 		}
-		deleteIfScopeChanged := false
+		autoDeleteLoadBalancer := false
 		if _, ok := ci.Annotations[autoDeleteLoadBalancerAnnotation]; ok {
-			deleteIfScopeChanged = true
+			autoDeleteLoadBalancer = true
 		}
-		if updated, err := r.updateLoadBalancerService(currentLBService, desiredLBService, platformStatus, deleteIfScopeChanged); err != nil {
+		if updated, err := r.updateLoadBalancerService(currentLBService, desiredLBService, platformStatus, autoDeleteLoadBalancer); err != nil {
 			return true, currentLBService, fmt.Errorf("failed to update load balancer service: %v", err)
 		} else if updated {
 			return r.currentLoadBalancerService(ci)
@@ -306,6 +343,92 @@ func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController,
 	return true, currentLBService, nil
 }
 
+// ensureAdoptedLoadBalancerService ensures that the pre-created LB service with
+// the given name has the selector, ports, and managed annotations of the
+// desired service.  Returns a Boolean indicating whether the service exists,
+// the current service if it does exist, and an error value.  It is an error
+// for the service not to exist as the operator does not create adopted
+// services.  The tool that created the service owns its ports and selector
+// until the operator first changes them, so the operator takes ownership of
+// these fields; it leaves the rest of the service to the tool.
+func (r *reconciler) ensureAdoptedLoadBalancerService(name types.NamespacedName, desired *corev1.Service) (bool, *corev1.Service, error) {
+	current := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil, fmt.Errorf("load balancer service %s specified by spec.unsupportedConfigOverrides.existingLoadBalancerService does not exist", name)
+		}
+		return false, nil, err
+	}
+
+	changed, updated := adoptedLoadBalancerServiceChanged(current, desired)
+	if !changed {
+		return true, current, nil
+	}
+	// Diff before updating because the client may mutate the object.
+	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+	applied := adoptedLoadBalancerServiceApplyConfiguration(updated)
+	if err := r.applyObject(applied); err != nil {
+		return true, current, fmt.Errorf("failed to update adopted load balancer service %s: %w", name, err)
+	}
+	log.Info("updated adopted load balancer service", "namespace", name.Namespace, "name", name.Name, "diff", diff)
+	return true, updated, nil
+}
+
+// adoptedLoadBalancerServiceApplyConfiguration returns a service with only the
+// fields of the given adopted LB service that the operator manages, namely the
+// managed annotations, selector, and ports.  The rest of the adopted service
+// belongs to whoever created it.
+func adoptedLoadBalancerServiceApplyConfiguration(service *corev1.Service) *corev1.Service {
+	applied := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: service.Namespace,
+			Name:      service.Name,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports:    service.Spec.Ports,
+			Selector: service.Spec.Selector,
+		},
+	}
+	for key, value := range service.Annotations {
+		if managedLoadBalancerServiceAnnotations.Has(key) {
+			if applied.Annotations == nil {
+				applied.Annotations = map[string]string{}
+			}
+			applied.Annotations[key] = value
+		}
+	}
+	return applied
+}
+
+// adoptedLoadBalancerServiceChanged checks if the selector, ports, and managed
+// annotations of the current adopted LB service match the expected ones and
+// if not returns an updated service.  All other fields are preserved.  Node
+// ports that were allocated for ports with matching names are preserved too.
+func adoptedLoadBalancerServiceChanged(current, expected *corev1.Service) (bool, *corev1.Service) {
+	annotationsChanged, updated := loadBalancerServiceAnnotationsChanged(current, expected, managedLoadBalancerServiceAnnotations)
+	if !annotationsChanged {
+		updated = current.DeepCopy()
+	}
+
+	ports := make([]corev1.ServicePort, len(expected.Spec.Ports))
+	for i, port := range expected.Spec.Ports {
+		ports[i] = port
+		for _, currentPort := range current.Spec.Ports {
+			if currentPort.Name == port.Name && currentPort.Protocol == port.Protocol && currentPort.Port == port.Port {
+				ports[i].NodePort = currentPort.NodePort
+				break
+			}
+		}
+	}
+	updated.Spec.Ports = ports
+	updated.Spec.Selector = expected.Spec.Selector
+
+	if !annotationsChanged && cmp.Equal(current.Spec.Ports, updated.Spec.Ports, cmpopts.EquateEmpty()) && cmp.Equal(current.Spec.Selector, updated.Spec.Selector, cmpopts.EquateEmpty()) {
+		return false, nil
+	}
+	return true, updated
+}
+
 // isServiceOwnedByIngressController determines whether a service is owned by an ingress controller.
 func isServiceOwnedByIngressController(service *corev1.Service, ic *operatorv1.IngressController) bool {
 	if service != nil && service.Labels[manifests.OwningIngressControllerLabel] == ic.Name {
@@ -419,6 +542,15 @@ func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef
 				service.Annotations[alibabaCloudLBAddressTypeAnnotation] = alibabaCloudLBAddressTypeInternet
 			}
 		}
+		if overrides, err := getUnsupportedConfigOverrides(ci); err != nil {
+			return true, service, err
+		} else if annotations, err := loadBalancerZoneAnnotations(platform, overrides.LoadBalancerZones); err != nil {
+			return true, service, err
+		} else {
+			for name, value := range annotations {
+				service.Annotations[name] = value
+			}
+		}
 		// Azure load balancers are not customizable and are set to (2 fail @ 5s interval, 2 healthy)
 		// GCP load balancers are not customizable and are set to (3 fail @ 8s interval, 1 healthy)
 
@@ -433,6 +565,17 @@ func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef
 	return true, service, nil
 }
 
+// usesLocalExternalTrafficPolicy returns a Boolean value indicating whether the
+// given ingresscontroller's load balancer service uses the "Local" external
+// traffic policy on the given platform.
+func usesLocalExternalTrafficPolicy(ci *operatorv1.IngressController, platform *configv1.PlatformStatus) (bool, error) {
+	wantLB, service, err := desiredLoadBalancerService(ci, metav1.OwnerReference{}, platform)
+	if err != nil {
+		return false, err
+	}
+	return wantLB && service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal, nil
+}
+
 // shouldUseLocalWithFallback returns a Boolean value indicating whether the
 // local-with-fallback annotation should be set for the given service, and
 // returns an error if the given ingresscontroller has an invalid unsupported
@@ -508,7 +651,7 @@ func (r *reconciler) normalizeLoadBalancerServiceAnnotations(service *corev1.Ser
 
 // createLoadBalancerService creates a load balancer service.
 func (r *reconciler) createLoadBalancerService(service *corev1.Service) error {
-	if err := r.client.Create(context.TODO(), service); err != nil {
+	if err := r.applyObject(service); err != nil {
 		return fmt.Errorf("failed to create load balancer service %s/%s: %v", service.Namespace, service.Name, err)
 	}
 	log.Info("created load balancer service", "namespace", service.Namespace, "name", service.Name)
@@ -527,12 +670,23 @@ func (r *reconciler) deleteLoadBalancerService(service *corev1.Service, options
 	return nil
 }
 
-// updateLoadBalancerService updates a load balancer service.  Returns a Boolean
-// indicating whether the service was updated, and an error value.
-func (r *reconciler) updateLoadBalancerService(current, desired *corev1.Service, platform *configv1.PlatformStatus, deleteIfScopeChanged bool) (bool, error) {
+// updateLoadBalancerService updates a load balancer service.  If the service's
+// scope or AWS load balancer type changed and the change requires recreating
+// the service, the service is deleted and recreated if autoDeleteLoadBalancer
+// is true.  Otherwise, a pending AWS load balancer type change leaves the
+// annotations that are specific to the load balancer type unchanged, and the
+// rest of the service is updated as usual.  Returns a Boolean indicating
+// whether the service was updated, and an error value.
+func (r *reconciler) updateLoadBalancerService(current, desired *corev1.Service, platform *configv1.PlatformStatus, autoDeleteLoadBalancer bool) (bool, error) {
 	_, platformHasMutableScope := platformsWithMutableScope[platform.Type]
-	if !platformHasMutableScope && deleteIfScopeChanged && !scopeEqual(current, desired, platform) {
-		log.Info("deleting and recreating the load balancer because its scope changed", "namespace", desired.Namespace, "name", desired.Name)
+	scopeChanged := !platformHasMutableScope && !scopeEqual(current, desired, platform)
+	lbTypeChanged := platform.Type == configv1.AWSPlatformType && awsLoadBalancerType(current) != awsLoadBalancerType(desired)
+	if autoDeleteLoadBalancer && (scopeChanged || lbTypeChanged) {
+		reason := "scope"
+		if !scopeChanged {
+			reason = "AWS load balancer type"
+		}
+		log.Info(fmt.Sprintf("deleting and recreating the load balancer because its %s changed", reason), "namespace", desired.Namespace, "name", desired.Name)
 		foreground := metav1.DeletePropagationForeground
 		deleteOptions := crclient.DeleteOptions{PropagationPolicy: &foreground}
 		if err := r.deleteLoadBalancerService(current, &deleteOptions); err != nil {
@@ -543,6 +697,14 @@ func (r *reconciler) updateLoadBalancerService(current, desired *corev1.Service,
 		}
 		return true, nil
 	}
+	if lbTypeChanged {
+		// The annotations for one type of AWS load balancer may be
+		// invalid for the other type (for example, the health check
+		// interval), so keep the current ones until the service is
+		// recreated.  computeIngressProgressingCondition reports the
+		// pending change.
+		desired = withCurrentAnnotations(current, desired, awsLoadBalancerTypeAnnotations)
+	}
 
 	changed, updated := loadBalancerServiceChanged(current, desired)
 	if !changed {
@@ -550,13 +712,41 @@ func (r *reconciler) updateLoadBalancerService(current, desired *corev1.Service,
 	}
 	// Diff before updating because the client may mutate the object.
 	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
-	if err := r.client.Update(context.TODO(), updated); err != nil {
+	if err := r.applyUpdate(current.DeepCopy(), desired.DeepCopy()); err != nil {
 		return false, err
 	}
 	log.Info("updated load balancer service", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
 	return true, nil
 }
 
+// withCurrentAnnotations returns a copy of the desired service in which the
+// given annotations have their values from the current service, or are absent
+// if the current service does not have them.
+func withCurrentAnnotations(current, desired *corev1.Service, annotations sets.String) *corev1.Service {
+	updated := desired.DeepCopy()
+	for name := range annotations {
+		value, ok := current.Annotations[name]
+		if !ok {
+			delete(updated.Annotations, name)
+			continue
+		}
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[name] = value
+	}
+	return updated
+}
+
+// awsLoadBalancerType returns the type of AWS load balancer that the given
+// service requests.
+func awsLoadBalancerType(service *corev1.Service) operatorv1.AWSLoadBalancerType {
+	if service.Annotations[AWSLBTypeAnnotation] == AWSNLBAnnotation {
+		return operatorv1.AWSNetworkLoadBalancer
+	}
+	return operatorv1.AWSClassicLoadBalancer
+}
+
 // scopeEqual returns true if the scope is the same between the two given
 // services and false if the scope is different.
 func scopeEqual(a, b *corev1.Service, platform *configv1.PlatformStatus) bool {
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	corev1 "k8s.io/api/core/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -15,6 +17,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredLoadBalancerService(t *testing.T) {
@@ -646,6 +652,54 @@ func checkServiceHasAnnotation(svc *corev1.Service, name string, expectValue boo
 	}
 }
 
+// TestUsesLocalExternalTrafficPolicy verifies that
+// usesLocalExternalTrafficPolicy detects the external traffic policy of the
+// load balancer service that the operator would create.
+func TestUsesLocalExternalTrafficPolicy(t *testing.T) {
+	testCases := []struct {
+		description string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		platform    configv1.PlatformType
+		expect      bool
+	}{
+		{
+			description: "load balancer on AWS",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.AWSPlatformType,
+			expect:      true,
+		},
+		{
+			description: "load balancer on IBM Cloud",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			platform:    configv1.IBMCloudPlatformType,
+			expect:      false,
+		},
+		{
+			description: "no load balancer",
+			strategy:    operatorv1.NodePortServiceStrategyType,
+			platform:    configv1.AWSPlatformType,
+			expect:      false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Status: operatorv1.IngressControllerStatus{
+					EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+				},
+			}
+			actual, err := usesLocalExternalTrafficPolicy(ic, &configv1.PlatformStatus{Type: tc.platform})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tc.expect {
+				t.Errorf("expected %t, got %t", tc.expect, actual)
+			}
+		})
+	}
+}
+
 // TestShouldUseLocalWithFallback verifies that shouldUseLocalWithFallback
 // behaves as expected.
 func TestShouldUseLocalWithFallback(t *testing.T) {
@@ -1063,3 +1117,322 @@ func TestServiceIngressOwner(t *testing.T) {
 	}
 
 }
+
+// TestEnsureLoadBalancerServiceAdoptsExistingService verifies that
+// ensureLoadBalancerService adopts the pre-created service that the
+// existingLoadBalancerService unsupported config override specifies,
+// reconciling its selector and ports without creating or deleting services.
+func TestEnsureLoadBalancerServiceAdoptsExistingService(t *testing.T) {
+	platformStatus := &configv1.PlatformStatus{Type: configv1.AWSPlatformType}
+	deploymentRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "router-default",
+		UID:        "1",
+	}
+	newIngressController := func(strategy operatorv1.EndpointPublishingStrategyType) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{
+					Raw: []byte(`{"existingLoadBalancerService":"preprovisioned-lb"}`),
+				},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+	}
+	preprovisioned := func(selector map[string]string, ports ...corev1.ServicePort) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "openshift-ingress",
+				Name:        "preprovisioned-lb",
+				Annotations: map[string]string{"example.com/provisioned-by": "other-tool"},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeLoadBalancer,
+				Selector: selector,
+				Ports:    ports,
+			},
+		}
+	}
+	routerSelector := map[string]string{
+		"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "default",
+	}
+	httpPort := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("http"), NodePort: 30080}
+	httpsPort := corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromString("https"), NodePort: 30443}
+
+	testCases := []struct {
+		description string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		existing    *corev1.Service
+		// managedAnnotations specifies whether existing has the
+		// annotations that the operator manages.
+		managedAnnotations bool
+		// conflicts is the number of applies that conflict with the
+		// field manager of the tool that created the service.
+		conflicts     int
+		expectHave    bool
+		expectError   bool
+		expectApplied *corev1.Service
+	}{
+		{
+			description: "adopted service is missing",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expectError: true,
+		},
+		{
+			description:        "adopted service already matches",
+			strategy:           operatorv1.LoadBalancerServiceStrategyType,
+			existing:           preprovisioned(routerSelector, httpPort, httpsPort),
+			managedAnnotations: true,
+			expectHave:         true,
+		},
+		{
+			description: "adopted service has a stale selector and ports",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			existing: preprovisioned(map[string]string{"app": "other"},
+				httpPort,
+				corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 8443, TargetPort: intstr.FromInt(8443), NodePort: 30444},
+			),
+			expectHave: true,
+			expectApplied: preprovisioned(routerSelector,
+				httpPort,
+				corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromString("https")},
+			),
+		},
+		{
+			description: "adopted service with ports that another field manager owns",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			existing: preprovisioned(routerSelector,
+				httpPort,
+				corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 8443, TargetPort: intstr.FromInt(8443), NodePort: 30444},
+			),
+			managedAnnotations: true,
+			conflicts:          1,
+			expectHave:         true,
+			expectApplied: preprovisioned(routerSelector,
+				httpPort,
+				corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromString("https")},
+			),
+		},
+		{
+			description: "load balancer is not wanted",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			existing:    preprovisioned(map[string]string{"app": "other"}, httpPort),
+			expectHave:  false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ic := newIngressController(tc.strategy)
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tc.managedAnnotations {
+				_, desired, err := desiredLoadBalancerService(ic, deploymentRef, platformStatus)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for name := range managedLoadBalancerServiceAnnotations {
+					if v, ok := desired.Annotations[name]; ok {
+						tc.existing.Annotations[name] = v
+					}
+				}
+			}
+			if tc.existing != nil {
+				builder = builder.WithObjects(tc.existing)
+			}
+			cl := &applyRecordingClient{
+				Client:          builder.Build(),
+				conflicts:       tc.conflicts,
+				conflictManager: "other-tool",
+			}
+			r := &reconciler{client: cl}
+
+			have, service, err := r.ensureLoadBalancerService(ic, deploymentRef, platformStatus)
+			switch {
+			case tc.expectError && err == nil:
+				t.Fatal("expected an error, got nil")
+			case !tc.expectError && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if have != tc.expectHave {
+				t.Errorf("expected have to be %t, got %t", tc.expectHave, have)
+			}
+			if tc.expectHave && (service == nil || service.Name != "preprovisioned-lb") {
+				t.Errorf("expected the adopted service to be returned, got %v", service)
+			}
+			if tc.expectApplied == nil {
+				if len(cl.patches) != 0 {
+					t.Fatalf("expected no patches, got %d", len(cl.patches))
+				}
+				return
+			}
+			if len(cl.patches) != tc.conflicts+1 {
+				t.Fatalf("expected %d patches, got %d", tc.conflicts+1, len(cl.patches))
+			}
+			// A conflict with the tool that created the service must
+			// not keep the operator from reconciling the fields that
+			// it claims.
+			last := cl.patches[len(cl.patches)-1]
+			if forced := last.options.Force != nil && *last.options.Force; forced != (tc.conflicts > 0) {
+				t.Errorf("expected force to be %t, got %t", tc.conflicts > 0, forced)
+			}
+			applied := last.object.(*corev1.Service)
+			if applied.Name != tc.expectApplied.Name {
+				t.Errorf("expected patch to service %q, got %q", tc.expectApplied.Name, applied.Name)
+			}
+			if !cmp.Equal(applied.Spec.Selector, tc.expectApplied.Spec.Selector) {
+				t.Errorf("unexpected selector: %s", cmp.Diff(tc.expectApplied.Spec.Selector, applied.Spec.Selector))
+			}
+			if !cmp.Equal(applied.Spec.Ports, tc.expectApplied.Spec.Ports) {
+				t.Errorf("unexpected ports: %s", cmp.Diff(tc.expectApplied.Spec.Ports, applied.Spec.Ports))
+			}
+			// The unmanaged annotation belongs to whoever created the
+			// service, so the apply must leave it alone.
+			if _, ok := applied.Annotations["example.com/provisioned-by"]; ok {
+				t.Errorf("expected the apply not to specify the unmanaged annotation, got %v", applied.Annotations)
+			}
+		})
+	}
+}
+
+// TestDesiredLoadBalancerServiceAWSLoadBalancerType verifies that
+// desiredLoadBalancerService sets the annotations for the AWS load balancer
+// type that the ingresscontroller specifies.
+func TestDesiredLoadBalancerServiceAWSLoadBalancerType(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		awsType                operatorv1.AWSLoadBalancerType
+		expectTypeAnnotation   bool
+		expectHealthCheckValue string
+	}{
+		{
+			name:                   "unset",
+			expectTypeAnnotation:   false,
+			expectHealthCheckValue: awsLBHealthCheckIntervalDefault,
+		},
+		{
+			name:                   "Classic",
+			awsType:                operatorv1.AWSClassicLoadBalancer,
+			expectTypeAnnotation:   false,
+			expectHealthCheckValue: awsLBHealthCheckIntervalDefault,
+		},
+		{
+			name:                   "NLB",
+			awsType:                operatorv1.AWSNetworkLoadBalancer,
+			expectTypeAnnotation:   true,
+			expectHealthCheckValue: awsLBHealthCheckIntervalNLB,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lb := &operatorv1.LoadBalancerStrategy{Scope: operatorv1.ExternalLoadBalancer}
+			if len(tc.awsType) != 0 {
+				lb.ProviderParameters = &operatorv1.ProviderLoadBalancerParameters{
+					Type: operatorv1.AWSLoadBalancerProvider,
+					AWS:  &operatorv1.AWSLoadBalancerParameters{Type: tc.awsType},
+				}
+			}
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Status: operatorv1.IngressControllerStatus{
+					EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+						Type:         operatorv1.LoadBalancerServiceStrategyType,
+						LoadBalancer: lb,
+					},
+				},
+			}
+			platformStatus := &configv1.PlatformStatus{Type: configv1.AWSPlatformType}
+			_, svc, err := desiredLoadBalancerService(ic, metav1.OwnerReference{}, platformStatus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch v, ok := svc.Annotations[AWSLBTypeAnnotation]; {
+			case !tc.expectTypeAnnotation && ok:
+				t.Errorf("unexpected annotation: %s=%s", AWSLBTypeAnnotation, v)
+			case tc.expectTypeAnnotation && v != AWSNLBAnnotation:
+				t.Errorf("expected annotation %s=%s, found %q", AWSLBTypeAnnotation, AWSNLBAnnotation, v)
+			}
+			if err := checkServiceHasAnnotation(svc, awsLBHealthCheckIntervalAnnotation, true, tc.expectHealthCheckValue); err != nil {
+				t.Error(err)
+			}
+			if expected := tc.awsType; len(expected) != 0 && awsLoadBalancerType(svc) != expected {
+				t.Errorf("expected awsLoadBalancerType to return %q, got %q", expected, awsLoadBalancerType(svc))
+			}
+		})
+	}
+}
+
+// TestUpdateLoadBalancerServiceAWSLoadBalancerType verifies that
+// updateLoadBalancerService recreates the service when the AWS load balancer
+// type changes if the auto-delete annotation is set, and otherwise keeps the
+// annotations for the current load balancer type while still updating the
+// service's other managed annotations.
+func TestUpdateLoadBalancerServiceAWSLoadBalancerType(t *testing.T) {
+	platformStatus := &configv1.PlatformStatus{Type: configv1.AWSPlatformType}
+	newIC := func(awsType operatorv1.AWSLoadBalancerType) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+					LoadBalancer: &operatorv1.LoadBalancerStrategy{
+						Scope: operatorv1.ExternalLoadBalancer,
+						ProviderParameters: &operatorv1.ProviderLoadBalancerParameters{
+							Type: operatorv1.AWSLoadBalancerProvider,
+							AWS:  &operatorv1.AWSLoadBalancerParameters{Type: awsType},
+						},
+					},
+				},
+			},
+		}
+	}
+	_, current, err := desiredLoadBalancerService(newIC(operatorv1.AWSClassicLoadBalancer), metav1.OwnerReference{}, platformStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, desired, err := desiredLoadBalancerService(newIC(operatorv1.AWSNetworkLoadBalancer), metav1.OwnerReference{}, platformStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate an unrelated change to a managed annotation that must be
+	// reconciled even while the load balancer type change is pending.
+	if _, ok := desired.Annotations[localWithFallbackAnnotation]; !ok {
+		t.Fatalf("expected the desired service to have the %s annotation", localWithFallbackAnnotation)
+	}
+	delete(current.Annotations, localWithFallbackAnnotation)
+
+	for _, autoDelete := range []bool{false, true} {
+		t.Run(fmt.Sprintf("autoDelete=%t", autoDelete), func(t *testing.T) {
+			cl := &applyRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(current.DeepCopy()).Build()}
+			r := &reconciler{client: cl}
+			updated, err := r.updateLoadBalancerService(current.DeepCopy(), desired, platformStatus, autoDelete)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !updated {
+				t.Error("expected the service to be updated")
+			}
+			if len(cl.patches) != 1 {
+				t.Fatalf("expected 1 patch, got %d", len(cl.patches))
+			}
+			applied := cl.patches[0].object.(*corev1.Service)
+			if err := checkServiceHasAnnotation(applied, localWithFallbackAnnotation, true, ""); err != nil {
+				t.Error(err)
+			}
+			if autoDelete {
+				if awsType := awsLoadBalancerType(applied); awsType != operatorv1.AWSNetworkLoadBalancer {
+					t.Errorf("expected the service to be recreated as %q, got %q", operatorv1.AWSNetworkLoadBalancer, awsType)
+				}
+				return
+			}
+			if awsType := awsLoadBalancerType(applied); awsType != operatorv1.AWSClassicLoadBalancer {
+				t.Errorf("expected the load balancer type to remain %q until the service is recreated, got %q", operatorv1.AWSClassicLoadBalancer, awsType)
+			}
+			if err := checkServiceHasAnnotation(applied, awsLBHealthCheckIntervalAnnotation, true, awsLBHealthCheckIntervalDefault); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
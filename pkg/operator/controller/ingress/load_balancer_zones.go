@@ -0,0 +1,97 @@
+package ingress
+
+import (
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// alibabaCloudLBMasterZoneAnnotation and
+	// alibabaCloudLBSlaveZoneAnnotation are the annotations used on a
+	// service to specify the primary and backup zones of an Alibaba Cloud
+	// load balancer.
+	alibabaCloudLBMasterZoneAnnotation = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-master-zoneid"
+	alibabaCloudLBSlaveZoneAnnotation  = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-slave-zoneid"
+
+	// iksLBZoneAnnotation is the annotation used on a service to specify
+	// the zone of an IBM Cloud load balancer.
+	iksLBZoneAnnotation = "service.kubernetes.io/ibm-load-balancer-cloud-provider-zone"
+
+	// openstackLBAvailabilityZoneAnnotation is the annotation used on a
+	// service to specify the availability zone of an OpenStack load
+	// balancer.
+	openstackLBAvailabilityZoneAnnotation = "loadbalancer.openstack.org/availability-zone"
+)
+
+// loadBalancerZoneAnnotations returns the annotations that pin a load balancer
+// on the given platform to the given zones.  Returns an error if the platform
+// does not support pinning a load balancer to zones or does not support the
+// given number of zones.  Other platforms, such as AWS, select zones by
+// subnet, which the operator cannot derive from zone names.
+func loadBalancerZoneAnnotations(platform *configv1.PlatformStatus, zones []string) (map[string]string, error) {
+	if len(zones) == 0 {
+		return nil, nil
+	}
+	var platformType configv1.PlatformType
+	if platform != nil {
+		platformType = platform.Type
+	}
+	var maxZones int
+	annotations := map[string]string{}
+	switch platformType {
+	case configv1.AlibabaCloudPlatformType:
+		maxZones = 2
+		annotations[alibabaCloudLBMasterZoneAnnotation] = zones[0]
+		if len(zones) > 1 {
+			annotations[alibabaCloudLBSlaveZoneAnnotation] = zones[1]
+		}
+	case configv1.IBMCloudPlatformType:
+		maxZones = 1
+		annotations[iksLBZoneAnnotation] = zones[0]
+	case configv1.OpenStackPlatformType:
+		maxZones = 1
+		annotations[openstackLBAvailabilityZoneAnnotation] = zones[0]
+	default:
+		return nil, fmt.Errorf("spec.unsupportedConfigOverrides.loadBalancerZones is not supported on platform %q", platformType)
+	}
+	if len(zones) > maxZones {
+		return nil, fmt.Errorf("invalid spec.unsupportedConfigOverrides.loadBalancerZones %v: platform %q supports at most %d zones", zones, platformType, maxZones)
+	}
+	return annotations, nil
+}
+
+// validateLoadBalancerZones returns an error if the given ingresscontroller's
+// loadBalancerZones unsupported config override is not supported on the given
+// platform or specifies a zone that none of the given nodes is in.  The
+// infrastructure config does not list the cluster's zones, so the zones are
+// taken from the nodes' topology labels.
+func validateLoadBalancerZones(ic *operatorv1.IngressController, platform *configv1.PlatformStatus, nodes []corev1.Node) error {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil || len(overrides.LoadBalancerZones) == 0 {
+		// validateUnsupportedConfigOverrides reports the error.
+		return nil
+	}
+	zones := overrides.LoadBalancerZones
+	if eps := ic.Status.EndpointPublishingStrategy; eps == nil || eps.Type != operatorv1.LoadBalancerServiceStrategyType {
+		return fmt.Errorf("spec.unsupportedConfigOverrides.loadBalancerZones requires the %q endpoint publishing strategy", operatorv1.LoadBalancerServiceStrategyType)
+	}
+	if _, err := loadBalancerZoneAnnotations(platform, zones); err != nil {
+		return err
+	}
+
+	clusterZones := sets.NewString()
+	for _, node := range nodes {
+		if zone, ok := node.Labels[corev1.LabelTopologyZone]; ok {
+			clusterZones.Insert(zone)
+		}
+	}
+	if unknown := sets.NewString(zones...).Difference(clusterZones); unknown.Len() != 0 {
+		return fmt.Errorf("invalid spec.unsupportedConfigOverrides.loadBalancerZones: the cluster has no nodes in zones %v", unknown.List())
+	}
+	return nil
+}
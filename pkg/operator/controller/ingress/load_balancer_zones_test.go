@@ -0,0 +1,185 @@
+package ingress
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestLoadBalancerZoneAnnotations verifies that loadBalancerZoneAnnotations
+// returns the expected annotations for each platform and rejects platforms and
+// numbers of zones that are not supported.
+func TestLoadBalancerZoneAnnotations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		platform    configv1.PlatformType
+		zones       []string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name:     "no zones",
+			platform: configv1.AWSPlatformType,
+		},
+		{
+			name:     "Alibaba Cloud with one zone",
+			platform: configv1.AlibabaCloudPlatformType,
+			zones:    []string{"cn-hangzhou-a"},
+			expected: map[string]string{
+				alibabaCloudLBMasterZoneAnnotation: "cn-hangzhou-a",
+			},
+		},
+		{
+			name:     "Alibaba Cloud with two zones",
+			platform: configv1.AlibabaCloudPlatformType,
+			zones:    []string{"cn-hangzhou-a", "cn-hangzhou-b"},
+			expected: map[string]string{
+				alibabaCloudLBMasterZoneAnnotation: "cn-hangzhou-a",
+				alibabaCloudLBSlaveZoneAnnotation:  "cn-hangzhou-b",
+			},
+		},
+		{
+			name:        "Alibaba Cloud with three zones",
+			platform:    configv1.AlibabaCloudPlatformType,
+			zones:       []string{"cn-hangzhou-a", "cn-hangzhou-b", "cn-hangzhou-c"},
+			expectError: true,
+		},
+		{
+			name:     "IBM Cloud",
+			platform: configv1.IBMCloudPlatformType,
+			zones:    []string{"us-south-1"},
+			expected: map[string]string{
+				iksLBZoneAnnotation: "us-south-1",
+			},
+		},
+		{
+			name:        "IBM Cloud with two zones",
+			platform:    configv1.IBMCloudPlatformType,
+			zones:       []string{"us-south-1", "us-south-2"},
+			expectError: true,
+		},
+		{
+			name:     "OpenStack",
+			platform: configv1.OpenStackPlatformType,
+			zones:    []string{"az1"},
+			expected: map[string]string{
+				openstackLBAvailabilityZoneAnnotation: "az1",
+			},
+		},
+		{
+			name:        "AWS",
+			platform:    configv1.AWSPlatformType,
+			zones:       []string{"us-east-1a"},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotations, err := loadBalancerZoneAnnotations(&configv1.PlatformStatus{Type: tc.platform}, tc.zones)
+			switch {
+			case err != nil && !tc.expectError:
+				t.Fatalf("unexpected error: %v", err)
+			case err == nil && tc.expectError:
+				t.Fatal("expected an error")
+			}
+			if len(annotations) != 0 || len(tc.expected) != 0 {
+				if !reflect.DeepEqual(annotations, tc.expected) {
+					t.Errorf("expected annotations %v, got %v", tc.expected, annotations)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateLoadBalancerZones verifies that validateLoadBalancerZones
+// rejects zones that have no nodes and endpoint publishing strategies other
+// than LoadBalancerService.
+func TestValidateLoadBalancerZones(t *testing.T) {
+	node := func(zone string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: zone}}}
+	}
+	nodes := []corev1.Node{node("az1"), node("az2"), {}}
+	testCases := []struct {
+		name         string
+		endpointType operatorv1.EndpointPublishingStrategyType
+		overrides    string
+		expectError  bool
+	}{
+		{
+			name:         "no zones",
+			endpointType: operatorv1.HostNetworkStrategyType,
+		},
+		{
+			name:         "zone with nodes",
+			endpointType: operatorv1.LoadBalancerServiceStrategyType,
+			overrides:    `{"loadBalancerZones":["az2"]}`,
+		},
+		{
+			name:         "zone without nodes",
+			endpointType: operatorv1.LoadBalancerServiceStrategyType,
+			overrides:    `{"loadBalancerZones":["az3"]}`,
+			expectError:  true,
+		},
+		{
+			name:         "host network",
+			endpointType: operatorv1.HostNetworkStrategyType,
+			overrides:    `{"loadBalancerZones":["az1"]}`,
+			expectError:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				Spec: operatorv1.IngressControllerSpec{
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+				},
+				Status: operatorv1.IngressControllerStatus{
+					EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.endpointType},
+				},
+			}
+			platform := &configv1.PlatformStatus{Type: configv1.OpenStackPlatformType}
+			err := validateLoadBalancerZones(ic, platform, nodes)
+			switch {
+			case err != nil && !tc.expectError:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && tc.expectError:
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestDesiredLoadBalancerServiceZones verifies that desiredLoadBalancerService
+// sets the zone annotations for the loadBalancerZones unsupported config
+// override.
+func TestDesiredLoadBalancerServiceZones(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"loadBalancerZones":["cn-hangzhou-a","cn-hangzhou-b"]}`)},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type:         operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{Scope: operatorv1.ExternalLoadBalancer},
+			},
+		},
+	}
+	platform := &configv1.PlatformStatus{Type: configv1.AlibabaCloudPlatformType}
+	_, service, err := desiredLoadBalancerService(ic, metav1.OwnerReference{}, platform)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := service.Annotations[alibabaCloudLBMasterZoneAnnotation]; v != "cn-hangzhou-a" {
+		t.Errorf("expected annotation %s=cn-hangzhou-a, got %q", alibabaCloudLBMasterZoneAnnotation, v)
+	}
+	if v := service.Annotations[alibabaCloudLBSlaveZoneAnnotation]; v != "cn-hangzhou-b" {
+		t.Errorf("expected annotation %s=cn-hangzhou-b, got %q", alibabaCloudLBSlaveZoneAnnotation, v)
+	}
+}
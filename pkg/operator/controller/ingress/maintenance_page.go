@@ -0,0 +1,142 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// maintenancePageKey is the key in the maintenance page configmap whose
+	// value the router serves when a route has no available backends.
+	// Like the keys of the spec.httpErrorCodePages configmap, the value
+	// must be a complete HTTP response, including the status line and
+	// headers.
+	maintenancePageKey = "maintenance-page.http"
+
+	maintenancePageVolumeName      = "maintenance-page"
+	maintenancePageVolumeMountPath = "/var/lib/haproxy/conf/maintenance_page"
+
+	// MaintenancePageConfigMapHashAnnotation is the pod template
+	// annotation that records a hash of the maintenance page configmap's
+	// content so that the router is rolled out when the page changes.
+	MaintenancePageConfigMapHashAnnotation = "ingress.operator.openshift.io/maintenance-page-configmap-hash"
+)
+
+// maintenancePageConfigMapToIngressController maps a configmap to the
+// ingresscontrollers that use it as their maintenance page.
+func (r *reconciler) maintenancePageConfigMapToIngressController(o crclient.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	controllers := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.Background(), controllers, crclient.InNamespace(r.config.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for configmap", "namespace", o.GetNamespace(), "name", o.GetName())
+		return requests
+	}
+	for i := range controllers.Items {
+		ic := &controllers.Items[i]
+		overrides, err := getUnsupportedConfigOverrides(ic)
+		if err != nil || overrides.MaintenancePageConfigMap != o.GetName() {
+			continue
+		}
+		log.Info("queueing ingresscontroller", "name", ic.Name, "configmap", o.GetName())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: ic.Namespace,
+				Name:      ic.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// currentMaintenancePageConfigMap returns the maintenance page configmap that
+// the given ingresscontroller references, if any.  Returns a Boolean
+// indicating whether the configmap exists, the configmap if it does exist, and
+// an error value.
+func (r *reconciler) currentMaintenancePageConfigMap(ci *operatorv1.IngressController) (bool, *corev1.ConfigMap, error) {
+	overrides, err := getUnsupportedConfigOverrides(ci)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(overrides.MaintenancePageConfigMap) == 0 {
+		return false, nil, nil
+	}
+	name := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: overrides.MaintenancePageConfigMap}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), name, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, fmt.Errorf("failed to get maintenance page configmap %s: %w", name, err)
+	}
+	return true, cm, nil
+}
+
+// configureMaintenancePage configures the given router deployment to serve
+// the page in the given configmap when a route has no available backends.  The
+// page takes precedence over the 503 page from spec.httpErrorCodePages.  If the
+// configmap does not have the expected key, the deployment is left unchanged.
+func configureMaintenancePage(deployment *appsv1.Deployment, cm *corev1.ConfigMap) {
+	page, ok := cm.Data[maintenancePageKey]
+	if !ok {
+		log.Info("maintenance page configmap is missing the expected key; ignoring it", "namespace", cm.Namespace, "name", cm.Name, "key", maintenancePageKey)
+		return
+	}
+
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: maintenancePageVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: cm.Name,
+				},
+				Items: []corev1.KeyToPath{{
+					Key:  maintenancePageKey,
+					Path: maintenancePageKey,
+				}},
+			},
+		},
+	})
+
+	router := &podSpec.Containers[0]
+	router.VolumeMounts = append(router.VolumeMounts, corev1.VolumeMount{
+		Name:      maintenancePageVolumeName,
+		MountPath: maintenancePageVolumeMountPath,
+		ReadOnly:  true,
+	})
+	errorFile := corev1.EnvVar{
+		Name:  RouterErrorFile503EnvName,
+		Value: maintenancePageVolumeMountPath + "/" + maintenancePageKey,
+	}
+	replaced := false
+	for i := range router.Env {
+		if router.Env[i].Name == RouterErrorFile503EnvName {
+			router.Env[i] = errorFile
+			replaced = true
+		}
+	}
+	if !replaced {
+		router.Env = append(router.Env, errorFile)
+	}
+
+	// HAProxy only reads error files when it loads its configuration, so
+	// roll out the router when the page changes.  Only the page is hashed
+	// so that changes to other keys do not trigger a rollout.
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[MaintenancePageConfigMapHashAnnotation] = configMapContentHash(&corev1.ConfigMap{
+		Data: map[string]string{maintenancePageKey: page},
+	})
+}
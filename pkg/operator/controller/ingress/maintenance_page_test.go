@@ -0,0 +1,160 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestConfigureMaintenancePage verifies that configureMaintenancePage mounts
+// the maintenance page configmap, configures the router to serve the page in
+// place of the 503 response, and rolls out the router when the page changes.
+func TestConfigureMaintenancePage(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Spec.HttpErrorCodePages = configv1.ConfigMapNameReference{Name: "my-custom-error-code-pages"}
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"maintenancePageConfigMap":"maintenance"}`)}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.DefaultOperandNamespace,
+			Name:      "maintenance",
+		},
+		Data: map[string]string{
+			maintenancePageKey: "HTTP/1.0 503 Service Unavailable\r\n\r\nDown for maintenance\r\n",
+		},
+	}
+
+	deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	configureMaintenancePage(deployment, cm)
+
+	haveVolume := false
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == maintenancePageVolumeName {
+			if volume.ConfigMap == nil || volume.ConfigMap.Name != cm.Name {
+				t.Errorf("unexpected maintenance page volume: %v", volume)
+			}
+			haveVolume = true
+		}
+	}
+	if !haveVolume {
+		t.Errorf("expected a %s volume", maintenancePageVolumeName)
+	}
+	mounted := false
+	for _, mount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if mount.Name == maintenancePageVolumeName && mount.MountPath == maintenancePageVolumeMountPath {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("expected the %s volume to be mounted at %s", maintenancePageVolumeName, maintenancePageVolumeMountPath)
+	}
+	// The maintenance page takes precedence over the 503 page from
+	// spec.httpErrorCodePages, and the variable must not be duplicated.
+	if err := checkDeploymentEnvironment(t, deployment, []envData{
+		{RouterErrorFile503EnvName, true, maintenancePageVolumeMountPath + "/" + maintenancePageKey},
+		{RouterErrorFile404EnvName, true, "/var/lib/haproxy/conf/error_code_pages/error-page-404.http"},
+	}); err != nil {
+		t.Error(err)
+	}
+	count := 0
+	for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == RouterErrorFile503EnvName {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected %s to be set once, found %d", RouterErrorFile503EnvName, count)
+	}
+
+	// Changing the page triggers a rollout; changing another key does
+	// not.
+	cm.Data["notes"] = "unrelated"
+	unrelated, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	configureMaintenancePage(unrelated, cm)
+	if changed, _ := deploymentConfigChanged(deployment, unrelated); changed {
+		t.Error("expected a change to an unrelated key not to change the deployment")
+	}
+	cm.Data[maintenancePageKey] = "HTTP/1.0 503 Service Unavailable\r\n\r\nBack soon\r\n"
+	updated, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	configureMaintenancePage(updated, cm)
+	if changed, result := deploymentConfigChanged(deployment, updated); !changed {
+		t.Error("expected a change to the maintenance page to change the deployment")
+	} else if deploymentTemplateHash(result) == deploymentTemplateHash(deployment) {
+		t.Error("expected a change to the maintenance page to change the pod template")
+	}
+}
+
+// TestConfigureMaintenancePageMissingKey verifies that configureMaintenancePage
+// ignores a configmap that does not have the maintenance page key.
+func TestConfigureMaintenancePageMissingKey(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	expected := deployment.DeepCopy()
+	configureMaintenancePage(deployment, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "maintenance"},
+		Data:       map[string]string{"index.html": "Down for maintenance"},
+	})
+	if changed, _ := deploymentConfigChanged(expected, deployment); changed {
+		t.Error("expected the deployment to be unchanged")
+	}
+}
+
+// TestCurrentMaintenancePageConfigMap verifies that
+// currentMaintenancePageConfigMap gets the configmap that the
+// ingresscontroller references from the operand namespace.
+func TestCurrentMaintenancePageConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.DefaultOperandNamespace,
+			Name:      "maintenance",
+		},
+		Data: map[string]string{maintenancePageKey: "page"},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build()
+	r := &reconciler{client: cl}
+
+	testCases := []struct {
+		name       string
+		overrides  string
+		expectHave bool
+	}{
+		{"no override", "", false},
+		{"existing configmap", `{"maintenancePageConfigMap":"maintenance"}`, true},
+		{"missing configmap", `{"maintenancePageConfigMap":"missing"}`, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic, _, _, _, _, _ := getRouterDeploymentComponents(t)
+			ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			have, current, err := r.currentMaintenancePageConfigMap(ic)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if have != tc.expectHave {
+				t.Fatalf("expected have to be %t, got %t", tc.expectHave, have)
+			}
+			if have && current.Name != cm.Name {
+				t.Errorf("expected configmap %s, got %s", cm.Name, current.Name)
+			}
+		})
+	}
+}
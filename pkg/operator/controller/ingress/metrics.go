@@ -2,6 +2,8 @@ package ingress
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -31,10 +33,18 @@ var (
 		Help: "Report the number of active NLBs on AWS clusters.",
 	}, []string{"name"})
 
+	// defaultCertificateExpiry reports the expiry time of each
+	// IngressController's default certificate.
+	defaultCertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_default_certificate_expiry_timestamp_seconds",
+		Help: "Report the time at which the ingress controller's default certificate expires, in seconds since the Unix epoch.",
+	}, []string{"name"})
+
 	// metricsList is a list of metrics for this package.
 	metricsList = []prometheus.Collector{
 		ingressControllerConditions,
 		activeNLBs,
+		defaultCertificateExpiry,
 	}
 )
 
@@ -167,3 +177,29 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 
 	return nil
 }
+
+// SetDefaultCertificateExpiryMetric updates the
+// ingress_controller_default_certificate_expiry_timestamp_seconds metric value
+// for the given IngressController from the given default certificate secret.
+// The value is deleted if the secret does not have a valid PEM-encoded
+// certificate.
+func SetDefaultCertificateExpiryMetric(ic *operatorv1.IngressController, secret *corev1.Secret) {
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil || block.Type != "CERTIFICATE" {
+		DeleteDefaultCertificateExpiryMetric(ic)
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		DeleteDefaultCertificateExpiryMetric(ic)
+		return
+	}
+	defaultCertificateExpiry.WithLabelValues(ic.Name).Set(float64(cert.NotAfter.Unix()))
+}
+
+// DeleteDefaultCertificateExpiryMetric deletes the
+// ingress_controller_default_certificate_expiry_timestamp_seconds metric value
+// for the given IngressController.
+func DeleteDefaultCertificateExpiryMetric(ic *operatorv1.IngressController) {
+	defaultCertificateExpiry.DeleteLabelValues(ic.Name)
+}
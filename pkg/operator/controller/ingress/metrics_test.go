@@ -1,11 +1,20 @@
 package ingress
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -210,6 +219,46 @@ func TestIngressControllerNLBMetric(t *testing.T) {
 	}
 }
 
+// TestDefaultCertificateExpiryMetric verifies that
+// SetDefaultCertificateExpiryMetric reports the expiry time of the default
+// certificate and deletes the value if the secret has no valid certificate.
+func TestDefaultCertificateExpiryMetric(t *testing.T) {
+	defaultCertificateExpiry.Reset()
+	notAfter := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.apps.example.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to generate certificate: %v", err)
+	}
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+
+	SetDefaultCertificateExpiryMetric(ic, &corev1.Secret{
+		Data: map[string][]byte{"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})},
+	})
+	expected := fmt.Sprintf(`
+	# HELP ingress_controller_default_certificate_expiry_timestamp_seconds Report the time at which the ingress controller's default certificate expires, in seconds since the Unix epoch.
+	# TYPE ingress_controller_default_certificate_expiry_timestamp_seconds gauge
+	ingress_controller_default_certificate_expiry_timestamp_seconds{name="default"} %d
+	`, notAfter.Unix())
+	if err := testutil.CollectAndCompare(defaultCertificateExpiry, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	SetDefaultCertificateExpiryMetric(ic, &corev1.Secret{})
+	if err := testutil.CollectAndCompare(defaultCertificateExpiry, strings.NewReader("")); err != nil {
+		t.Error(err)
+	}
+}
+
 func testIngressControllerWithConditions(name string, conditions []operatorv1.OperatorCondition) *operatorv1.IngressController {
 	return &operatorv1.IngressController{
 		ObjectMeta: metav1.ObjectMeta{
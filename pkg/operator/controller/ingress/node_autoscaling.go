@@ -0,0 +1,62 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// clusterAutoscalerGVK is the kind of the clusterautoscaler that configures
+// the cluster autoscaler.
+var clusterAutoscalerGVK = schema.GroupVersionKind{
+	Group:   "autoscaling.openshift.io",
+	Kind:    "ClusterAutoscaler",
+	Version: "v1",
+}
+
+// clusterAutoscalerName is the name of the clusterautoscaler that enables the
+// cluster autoscaler.  The cluster autoscaler operator ignores any other name.
+const clusterAutoscalerName = "default"
+
+// nodeAutoscalingEnabled returns a Boolean indicating whether the cluster
+// autoscaler is enabled, meaning that nodes are added when pods cannot be
+// scheduled.  The cluster autoscaler is enabled if the default
+// clusterautoscaler exists.
+func (r *reconciler) nodeAutoscalingEnabled() (bool, error) {
+	autoscaler := &unstructured.Unstructured{}
+	autoscaler.SetGroupVersionKind(clusterAutoscalerGVK)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: clusterAutoscalerName}, autoscaler); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get clusterautoscaler %s: %w", clusterAutoscalerName, err)
+	}
+	return true, nil
+}
+
+// surgeForHostNetwork sets the given deployment's rolling update strategy to
+// create one surge pod at a time and to keep all replicas available, which
+// requires a free node for each surge pod, such as a node that the cluster
+// autoscaler adds.  Returns a Boolean indicating whether the deployment was
+// changed.
+func surgeForHostNetwork(deployment *appsv1.Deployment) bool {
+	rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+	if rollingUpdate == nil {
+		return false
+	}
+	maxSurge, maxUnavailable := intstr.FromInt(1), intstr.FromInt(0)
+	if rollingUpdate.MaxSurge != nil && *rollingUpdate.MaxSurge == maxSurge && rollingUpdate.MaxUnavailable != nil && *rollingUpdate.MaxUnavailable == maxUnavailable {
+		return false
+	}
+	rollingUpdate.MaxSurge = &maxSurge
+	rollingUpdate.MaxUnavailable = &maxUnavailable
+	return true
+}
@@ -0,0 +1,96 @@
+package ingress
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestNodeAutoscalingEnabled verifies that nodeAutoscalingEnabled detects the
+// cluster autoscaler by the default clusterautoscaler.
+func TestNodeAutoscalingEnabled(t *testing.T) {
+	autoscaler := func(name string) client.Object {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(clusterAutoscalerGVK)
+		u.SetName(name)
+		return u
+	}
+	testCases := []struct {
+		name     string
+		objects  []client.Object
+		expected bool
+	}{
+		{
+			name:     "no clusterautoscaler",
+			expected: false,
+		},
+		{
+			name:     "other clusterautoscaler",
+			objects:  []client.Object{autoscaler("other")},
+			expected: false,
+		},
+		{
+			name:     "default clusterautoscaler",
+			objects:  []client.Object{autoscaler("default")},
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			s.AddKnownTypeWithName(clusterAutoscalerGVK, &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(clusterAutoscalerGVK.GroupVersion().WithKind("ClusterAutoscalerList"), &unstructured.UnstructuredList{})
+			r := &reconciler{client: fake.NewClientBuilder().WithScheme(s).WithObjects(tc.objects...).Build()}
+			enabled, err := r.nodeAutoscalingEnabled()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if enabled != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, enabled)
+			}
+		})
+	}
+}
+
+// TestSurgeForHostNetwork verifies that surgeForHostNetwork sets maxSurge to 1
+// and maxUnavailable to 0 and leaves deployments without a rolling update
+// strategy unchanged.
+func TestSurgeForHostNetwork(t *testing.T) {
+	pointerTo := func(ios intstr.IntOrString) *intstr.IntOrString { return &ios }
+	deployment := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: pointerTo(intstr.FromString("25%")),
+					MaxSurge:       pointerTo(intstr.FromInt(0)),
+				},
+			},
+		},
+	}
+	if !surgeForHostNetwork(deployment) {
+		t.Fatal("expected the deployment to be changed")
+	}
+	rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+	if *rollingUpdate.MaxSurge != intstr.FromInt(1) || *rollingUpdate.MaxUnavailable != intstr.FromInt(0) {
+		t.Errorf("expected maxSurge 1 and maxUnavailable 0, got %s and %s", rollingUpdate.MaxSurge.String(), rollingUpdate.MaxUnavailable.String())
+	}
+	if surgeForHostNetwork(deployment) {
+		t.Error("expected no change for a deployment that already uses surge")
+	}
+
+	recreate := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		},
+	}
+	if surgeForHostNetwork(recreate) || recreate.Spec.Strategy.RollingUpdate != nil {
+		t.Error("expected no change for a deployment with the Recreate strategy")
+	}
+}
@@ -0,0 +1,170 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// nodeCountReplicasMaxGrowthPercent is the maximum percentage by which the
+// operator grows the number of replicas of a router deployment whose replicas
+// follow the node count in a single step.  The operator takes the next step
+// once the router pods from the previous step are available so that adding
+// many nodes at once, for example when the cluster scales up, does not cause
+// the deployment to thrash.
+const nodeCountReplicasMaxGrowthPercent = 25
+
+// replicasFromNodeCountEnabled returns whether the given ingresscontroller's
+// replicas follow the number of nodes on which its router pods can run, which
+// is the case if the ingresscontroller uses the HostNetwork endpoint
+// publishing strategy, does not specify spec.replicas, and has the
+// replicasFromNodeCount unsupported config override.
+func replicasFromNodeCountEnabled(ic *operatorv1.IngressController) bool {
+	if ic.Spec.Replicas != nil {
+		return false
+	}
+	if ic.Status.EndpointPublishingStrategy == nil || ic.Status.EndpointPublishingStrategy.Type != operatorv1.HostNetworkStrategyType {
+		return false
+	}
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	return err == nil && overrides.ReplicasFromNodeCount
+}
+
+// countEligibleRouterNodes returns the number of the given nodes on which a
+// router pod with the given pod spec can run.  A node is eligible if it is
+// ready and schedulable, matches the ingresscontroller's node placement
+// selector (or the pod's node selector if the ingresscontroller does not
+// specify one), and has no NoSchedule or NoExecute taint that the pod does not
+// tolerate.
+func countEligibleRouterNodes(ic *operatorv1.IngressController, podSpec *corev1.PodSpec, nodes []corev1.Node) (int32, error) {
+	selector := labels.SelectorFromSet(podSpec.NodeSelector)
+	if ic.Spec.NodePlacement != nil && ic.Spec.NodePlacement.NodeSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(ic.Spec.NodePlacement.NodeSelector); err != nil {
+			return 0, fmt.Errorf("ingresscontroller %q has invalid spec.nodePlacement.nodeSelector: %w", ic.Name, err)
+		}
+	}
+	count := int32(0)
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Spec.Unschedulable || !isNodeReady(node) || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if !toleratesNodeTaints(podSpec.Tolerations, node.Spec.Taints) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// isNodeReady returns whether the given node has the Ready condition.
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// toleratesNodeTaints returns whether the given tolerations tolerate all of the
+// given taints that prevent scheduling or execution.
+func toleratesNodeTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// capReplicasGrowth returns the number of replicas to set on the given current
+// router deployment, which may be nil, given the number of eligible nodes.
+// Shrinking takes effect immediately.  Growth is limited to
+// nodeCountReplicasMaxGrowthPercent of the current replicas (at least one
+// replica) per step, and the next step is not taken until the deployment has
+// observed its latest generation and all of its replicas are available.  At
+// least one replica is always returned.
+func capReplicasGrowth(current *appsv1.Deployment, eligibleNodes int32) int32 {
+	desired := eligibleNodes
+	if desired < 1 {
+		desired = 1
+	}
+	if current == nil || current.Spec.Replicas == nil {
+		return desired
+	}
+	currentReplicas := *current.Spec.Replicas
+	if desired <= currentReplicas {
+		return desired
+	}
+	if current.Status.ObservedGeneration < current.Generation || current.Status.AvailableReplicas < currentReplicas {
+		return currentReplicas
+	}
+	step := currentReplicas * nodeCountReplicasMaxGrowthPercent / 100
+	if step < 1 {
+		step = 1
+	}
+	if currentReplicas+step < desired {
+		return currentReplicas + step
+	}
+	return desired
+}
+
+// nodeCountReplicas returns the number of replicas for the given
+// ingresscontroller's router deployment, whose desired pod spec is given,
+// based on the number of nodes on which its router pods can run.  The current
+// deployment may be nil if it does not exist yet.
+func (r *reconciler) nodeCountReplicas(ic *operatorv1.IngressController, desired, current *appsv1.Deployment) (int32, error) {
+	nodes := &corev1.NodeList{}
+	if err := r.cache.List(context.TODO(), nodes); err != nil {
+		return 0, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	eligible, err := countEligibleRouterNodes(ic, &desired.Spec.Template.Spec, nodes.Items)
+	if err != nil {
+		return 0, err
+	}
+	return capReplicasGrowth(current, eligible), nil
+}
+
+// nodeToIngressControllers returns reconcile requests for the
+// ingresscontrollers whose replicas follow the node count.
+func (r *reconciler) nodeToIngressControllers(o client.Object) []reconcile.Request {
+	controllers := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.Background(), controllers, client.InNamespace(r.config.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for node", "node", o.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range controllers.Items {
+		ic := &controllers.Items[i]
+		if !replicasFromNodeCountEnabled(ic) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	return requests
+}
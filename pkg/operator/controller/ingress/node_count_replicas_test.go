@@ -0,0 +1,236 @@
+package ingress
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// routerNode returns a node with the given name and labels that has the given
+// readiness, schedulability, and taints.
+func routerNode(name string, labels map[string]string, ready, unschedulable bool, taints ...corev1.Taint) corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable, Taints: taints},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+// TestReplicasFromNodeCountEnabled verifies that replicas follow the node count
+// only for the HostNetwork endpoint publishing strategy, only with the
+// replicasFromNodeCount unsupported config override, and only if spec.replicas
+// is unset.
+func TestReplicasFromNodeCountEnabled(t *testing.T) {
+	three := int32(3)
+	testCases := []struct {
+		name         string
+		strategy     operatorv1.EndpointPublishingStrategyType
+		replicas     *int32
+		overrides    string
+		expectResult bool
+	}{
+		{
+			name:         "HostNetwork with the override",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			overrides:    `{"replicasFromNodeCount":true}`,
+			expectResult: true,
+		},
+		{
+			name:         "HostNetwork without the override",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			expectResult: false,
+		},
+		{
+			name:         "HostNetwork with the override and spec.replicas",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			replicas:     &three,
+			overrides:    `{"replicasFromNodeCount":true}`,
+			expectResult: false,
+		},
+		{
+			name:         "LoadBalancerService with the override",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			overrides:    `{"replicasFromNodeCount":true}`,
+			expectResult: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				Spec: operatorv1.IngressControllerSpec{Replicas: tc.replicas},
+				Status: operatorv1.IngressControllerStatus{
+					EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+				},
+			}
+			if len(tc.overrides) != 0 {
+				ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(tc.overrides)}
+			}
+			if actual := replicasFromNodeCountEnabled(ic); actual != tc.expectResult {
+				t.Errorf("expected %t, got %t", tc.expectResult, actual)
+			}
+		})
+	}
+}
+
+// TestCountEligibleRouterNodes verifies that countEligibleRouterNodes counts
+// only ready, schedulable nodes that match the node placement selector and
+// whose taints the router pods tolerate.
+func TestCountEligibleRouterNodes(t *testing.T) {
+	worker := map[string]string{"node-role.kubernetes.io/worker": ""}
+	infra := map[string]string{"node-role.kubernetes.io/worker": "", "node-role.kubernetes.io/infra": ""}
+	infraTaint := corev1.Taint{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}
+	preferTaint := corev1.Taint{Key: "example", Effect: corev1.TaintEffectPreferNoSchedule}
+	nodes := []corev1.Node{
+		routerNode("worker-1", worker, true, false),
+		routerNode("worker-2", worker, true, false, preferTaint),
+		routerNode("worker-not-ready", worker, false, false),
+		routerNode("worker-cordoned", worker, true, true),
+		routerNode("infra-1", infra, true, false, infraTaint),
+		routerNode("infra-2", infra, true, false, infraTaint),
+		routerNode("master", map[string]string{"node-role.kubernetes.io/master": ""}, true, false),
+	}
+	testCases := []struct {
+		name          string
+		nodePlacement *operatorv1.NodePlacement
+		podSpec       corev1.PodSpec
+		expectCount   int32
+	}{
+		{
+			name:        "default placement",
+			podSpec:     corev1.PodSpec{NodeSelector: worker},
+			expectCount: 2,
+		},
+		{
+			name: "infra placement without tolerations",
+			nodePlacement: &operatorv1.NodePlacement{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""}},
+			},
+			expectCount: 0,
+		},
+		{
+			name: "infra placement with tolerations",
+			nodePlacement: &operatorv1.NodePlacement{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""}},
+			},
+			podSpec: corev1.PodSpec{
+				Tolerations: []corev1.Toleration{{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists}},
+			},
+			expectCount: 2,
+		},
+		{
+			name: "placement with match expressions",
+			nodePlacement: &operatorv1.NodePlacement{
+				NodeSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "node-role.kubernetes.io/infra",
+						Operator: metav1.LabelSelectorOpDoesNotExist,
+					}},
+				},
+			},
+			expectCount: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       operatorv1.IngressControllerSpec{NodePlacement: tc.nodePlacement},
+			}
+			count, err := countEligibleRouterNodes(ic, &tc.podSpec, nodes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tc.expectCount {
+				t.Errorf("expected %d eligible nodes, got %d", tc.expectCount, count)
+			}
+		})
+	}
+}
+
+// TestCapReplicasGrowth verifies that capReplicasGrowth shrinks immediately,
+// grows in limited steps once the current deployment is available, and never
+// returns fewer than one replica.
+func TestCapReplicasGrowth(t *testing.T) {
+	deployment := func(replicas, available int32, generation, observedGeneration int64) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+	testCases := []struct {
+		name           string
+		current        *appsv1.Deployment
+		eligibleNodes  int32
+		expectReplicas int32
+	}{
+		{
+			name:           "no deployment yet",
+			eligibleNodes:  20,
+			expectReplicas: 20,
+		},
+		{
+			name:           "no eligible nodes",
+			current:        deployment(3, 3, 1, 1),
+			eligibleNodes:  0,
+			expectReplicas: 1,
+		},
+		{
+			name:           "shrink",
+			current:        deployment(10, 10, 1, 1),
+			eligibleNodes:  6,
+			expectReplicas: 6,
+		},
+		{
+			name:           "grow by one from a small deployment",
+			current:        deployment(2, 2, 1, 1),
+			eligibleNodes:  10,
+			expectReplicas: 3,
+		},
+		{
+			name:           "grow by a quarter",
+			current:        deployment(8, 8, 1, 1),
+			eligibleNodes:  20,
+			expectReplicas: 10,
+		},
+		{
+			name:           "grow to the node count",
+			current:        deployment(8, 8, 1, 1),
+			eligibleNodes:  9,
+			expectReplicas: 9,
+		},
+		{
+			name:           "hold while replicas are unavailable",
+			current:        deployment(8, 7, 1, 1),
+			eligibleNodes:  20,
+			expectReplicas: 8,
+		},
+		{
+			name:           "hold while the latest generation is not observed",
+			current:        deployment(8, 8, 2, 1),
+			eligibleNodes:  20,
+			expectReplicas: 8,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := capReplicasGrowth(tc.current, tc.eligibleNodes); actual != tc.expectReplicas {
+				t.Errorf("expected %d replicas, got %d", tc.expectReplicas, actual)
+			}
+		})
+	}
+}
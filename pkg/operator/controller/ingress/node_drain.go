@@ -0,0 +1,84 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// countRouterPodsOnDrainingNodes returns the number of the given
+// ingresscontroller's router pods that are on nodes that are being drained.  A
+// node is considered to be draining if it is marked unschedulable.
+func (r *reconciler) countRouterPodsOnDrainingNodes(ci *operatorv1.IngressController) (int, error) {
+	pods := &corev1.PodList{}
+	labels := controller.IngressControllerDeploymentPodSelector(ci).MatchLabels
+	if err := r.client.List(context.TODO(), pods, crclient.InNamespace(controller.DefaultOperandNamespace), crclient.MatchingLabels(labels)); err != nil {
+		return 0, fmt.Errorf("failed to list pods for ingresscontroller %s: %w", ci.Name, err)
+	}
+	if len(pods.Items) == 0 {
+		return 0, nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes); err != nil {
+		return 0, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	draining := map[string]bool{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			draining[node.Name] = true
+		}
+	}
+	count := 0
+	for _, pod := range pods.Items {
+		if draining[pod.Spec.NodeName] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// relaxMaxUnavailableForDrain raises the given deployment's maxUnavailable by
+// the given number of router pods that are on draining nodes.  Evictions count
+// against maxUnavailable, so without the extra allowance, a rollout that is
+// blocked by the pod anti-affinity policy cannot make progress until the drain
+// completes, and the drain cannot complete until the rollout does.  The result
+// is capped at one less than the number of replicas so that at least one
+// replica stays available.  Returns a Boolean indicating whether the
+// deployment was changed.
+func relaxMaxUnavailableForDrain(deployment *appsv1.Deployment, drainingPods int) bool {
+	if drainingPods <= 0 || deployment.Spec.Replicas == nil {
+		return false
+	}
+	rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+	if rollingUpdate == nil || rollingUpdate.MaxUnavailable == nil {
+		return false
+	}
+	replicas := int(*deployment.Spec.Replicas)
+	// The deployment controller rounds max unavailable down.
+	current, err := intstr.GetScaledValueFromIntOrPercent(rollingUpdate.MaxUnavailable, replicas, false)
+	if err != nil {
+		return false
+	}
+	limit := replicas - 1
+	if limit < 1 {
+		limit = 1
+	}
+	relaxed := current + drainingPods
+	if relaxed > limit {
+		relaxed = limit
+	}
+	if relaxed <= current {
+		return false
+	}
+	maxUnavailable := intstr.FromInt(relaxed)
+	rollingUpdate.MaxUnavailable = &maxUnavailable
+	return true
+}
@@ -0,0 +1,168 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestRelaxMaxUnavailableForDrain verifies that relaxMaxUnavailableForDrain
+// raises maxUnavailable by the number of router pods on draining nodes without
+// allowing every replica to be unavailable.
+func TestRelaxMaxUnavailableForDrain(t *testing.T) {
+	testCases := []struct {
+		description    string
+		replicas       int32
+		maxUnavailable intstr.IntOrString
+		drainingPods   int
+		expectChanged  bool
+		expect         intstr.IntOrString
+	}{
+		{
+			description:    "no drain",
+			replicas:       2,
+			maxUnavailable: intstr.FromString("50%"),
+			drainingPods:   0,
+			expectChanged:  false,
+			expect:         intstr.FromString("50%"),
+		},
+		{
+			description:    "one pod draining, 3 replicas",
+			replicas:       3,
+			maxUnavailable: intstr.FromString("50%"),
+			drainingPods:   1,
+			expectChanged:  true,
+			expect:         intstr.FromInt(2),
+		},
+		{
+			description:    "one pod draining, 8 replicas",
+			replicas:       8,
+			maxUnavailable: intstr.FromString("25%"),
+			drainingPods:   1,
+			expectChanged:  true,
+			expect:         intstr.FromInt(3),
+		},
+		{
+			description:    "capped to keep one replica available",
+			replicas:       4,
+			maxUnavailable: intstr.FromString("25%"),
+			drainingPods:   4,
+			expectChanged:  true,
+			expect:         intstr.FromInt(3),
+		},
+		{
+			description:    "already at the cap",
+			replicas:       2,
+			maxUnavailable: intstr.FromString("50%"),
+			drainingPods:   1,
+			expectChanged:  false,
+			expect:         intstr.FromString("50%"),
+		},
+		{
+			description:    "single replica",
+			replicas:       1,
+			maxUnavailable: intstr.FromString("50%"),
+			drainingPods:   1,
+			expectChanged:  true,
+			expect:         intstr.FromInt(1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			maxUnavailable := tc.maxUnavailable
+			deployment := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &tc.replicas,
+					Strategy: appsv1.DeploymentStrategy{
+						Type: appsv1.RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDeployment{
+							MaxUnavailable: &maxUnavailable,
+						},
+					},
+				},
+			}
+			if changed := relaxMaxUnavailableForDrain(deployment, tc.drainingPods); changed != tc.expectChanged {
+				t.Errorf("expected changed to be %t, got %t", tc.expectChanged, changed)
+			}
+			if actual := *deployment.Spec.Strategy.RollingUpdate.MaxUnavailable; actual != tc.expect {
+				t.Errorf("expected maxUnavailable %s, got %s", tc.expect.String(), actual.String())
+			}
+		})
+	}
+}
+
+// TestRouterDeploymentDuringNodeDrain simulates a drain of a node that has a
+// router pod and verifies that maxUnavailable is relaxed while the node is
+// draining and restored once the drain completes.
+func TestRouterDeploymentDuringNodeDrain(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Status.EndpointPublishingStrategy.Type = operatorv1.LoadBalancerServiceStrategyType
+	replicas := int32(3)
+	ic.Spec.Replicas = &replicas
+
+	labels := controller.IngressControllerDeploymentPodSelector(ic).MatchLabels
+	newPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: controller.DefaultOperandNamespace,
+				Name:      name,
+				Labels:    labels,
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-c"}},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		nodes[0], nodes[1], nodes[2],
+		newPod("router-a", "node-a"),
+		newPod("router-b", "node-b"),
+		newPod("router-c", "node-c"),
+	).Build()
+	r := &reconciler{client: cl}
+
+	expectMaxUnavailable := func(expect intstr.IntOrString) {
+		t.Helper()
+		deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		draining, err := r.countRouterPodsOnDrainingNodes(ic)
+		if err != nil {
+			t.Fatalf("failed to count router pods on draining nodes: %v", err)
+		}
+		relaxMaxUnavailableForDrain(deployment, draining)
+		if actual := *deployment.Spec.Strategy.RollingUpdate.MaxUnavailable; actual != expect {
+			t.Errorf("expected maxUnavailable %s, got %s", expect.String(), actual.String())
+		}
+	}
+
+	expectMaxUnavailable(intstr.FromString("50%"))
+
+	// Cordon node-b as "oc adm drain" does.
+	nodes[1].Spec.Unschedulable = true
+	if err := cl.Update(context.Background(), nodes[1]); err != nil {
+		t.Fatalf("failed to cordon node: %v", err)
+	}
+	expectMaxUnavailable(intstr.FromInt(2))
+
+	// Once the router pod has been evicted from the drained node, the
+	// usual value is restored.
+	if err := cl.Delete(context.Background(), newPod("router-b", "node-b")); err != nil {
+		t.Fatalf("failed to evict pod: %v", err)
+	}
+	expectMaxUnavailable(intstr.FromString("50%"))
+}
@@ -71,7 +71,7 @@ func (r *reconciler) ensureNodePortService(ic *operatorv1.IngressController, dep
 		}
 		return false, nil, nil
 	case wantService && !haveService:
-		if err := r.client.Create(context.TODO(), desired); err != nil {
+		if err := r.applyObject(desired); err != nil {
 			return false, nil, fmt.Errorf("failed to create NodePort service: %v", err)
 		}
 		log.Info("created NodePort service", "service", desired)
@@ -173,7 +173,7 @@ func (r *reconciler) updateNodePortService(current, desired *corev1.Service) (bo
 
 	// Diff before updating because the client may mutate the object.
 	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
-	if err := r.client.Update(context.TODO(), updated); err != nil {
+	if err := r.applyUpdate(current.DeepCopy(), desired.DeepCopy()); err != nil {
 		return false, err
 	}
 	log.Info("updated NodePort service", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
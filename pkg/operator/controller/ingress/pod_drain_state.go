@@ -0,0 +1,57 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// routerPodDrainState returns the drain state to report for the given router
+// pod: RouterPodDrainStateDraining if the pod is shutting down, during which
+// the router finishes its open connections before it exits, or the empty
+// string otherwise.
+func routerPodDrainState(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return manifests.RouterPodDrainStateDraining
+	}
+	return ""
+}
+
+// syncRouterPodDrainStates annotates each of the given ingresscontroller's
+// router pods with its drain state so that external orchestration can tell
+// which router pods are draining.
+func (r *reconciler) syncRouterPodDrainStates(ci *operatorv1.IngressController) error {
+	pods := &corev1.PodList{}
+	labels := controller.IngressControllerDeploymentPodSelector(ci).MatchLabels
+	if err := r.client.List(context.TODO(), pods, crclient.InNamespace(controller.DefaultOperandNamespace), crclient.MatchingLabels(labels)); err != nil {
+		return fmt.Errorf("failed to list pods for ingresscontroller %s: %w", ci.Name, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		state := routerPodDrainState(pod)
+		if pod.Annotations[manifests.RouterPodDrainStateAnnotation] == state {
+			continue
+		}
+		updated := pod.DeepCopy()
+		if len(state) == 0 {
+			delete(updated.Annotations, manifests.RouterPodDrainStateAnnotation)
+		} else {
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			updated.Annotations[manifests.RouterPodDrainStateAnnotation] = state
+		}
+		if err := r.client.Patch(context.TODO(), updated, crclient.MergeFrom(pod)); err != nil {
+			return fmt.Errorf("failed to annotate pod %s/%s with drain state %q: %w", pod.Namespace, pod.Name, state, err)
+		}
+		log.Info("updated router pod drain state", "namespace", pod.Namespace, "name", pod.Name, "state", state)
+	}
+	return nil
+}
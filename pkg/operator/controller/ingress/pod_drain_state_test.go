@@ -0,0 +1,76 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestSyncRouterPodDrainStates verifies that syncRouterPodDrainStates
+// annotates router pods that are shutting down as draining and removes the
+// annotation from router pods that are not.
+func TestSyncRouterPodDrainStates(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	labels := controller.IngressControllerDeploymentPodSelector(ic).MatchLabels
+	pod := func(name string, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "openshift-ingress",
+				Name:        name,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+		}
+	}
+	draining := map[string]string{manifests.RouterPodDrainStateAnnotation: manifests.RouterPodDrainStateDraining}
+	serving := pod("serving", nil)
+	stale := pod("stale", draining)
+	terminating := pod("terminating", nil)
+	// The fake client deletes an object without finalizers as soon as
+	// it has a deletion timestamp.
+	terminating.Finalizers = []string{"example.com/keep"}
+	r := &reconciler{client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(serving, stale, terminating).Build()}
+
+	// Simulate the start of a graceful shutdown.
+	current := &corev1.Pod{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: "openshift-ingress", Name: "terminating"}, current); err != nil {
+		t.Fatal(err)
+	}
+	now := metav1.NewTime(time.Now())
+	current.DeletionTimestamp = &now
+	if err := r.client.Update(context.TODO(), current); err != nil {
+		t.Fatal(err)
+	}
+	if routerPodDrainState(current) != manifests.RouterPodDrainStateDraining {
+		t.Fatalf("expected a terminating pod to be draining")
+	}
+
+	if err := r.syncRouterPodDrainStates(ic); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"serving":     "",
+		"stale":       "",
+		"terminating": manifests.RouterPodDrainStateDraining,
+	}
+	for name, state := range expected {
+		pod := &corev1.Pod{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: "openshift-ingress", Name: name}, pod); err != nil {
+			t.Fatal(err)
+		}
+		if actual := pod.Annotations[manifests.RouterPodDrainStateAnnotation]; actual != state {
+			t.Errorf("pod %s: expected drain state %q, got %q", name, state, actual)
+		}
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
+	appsv1 "k8s.io/api/apps/v1"
 	policyv1 "k8s.io/api/policy/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -18,10 +20,12 @@ import (
 )
 
 // ensureRouterPodDisruptionBudget ensures the pod disruption budget exists for
-// a given ingresscontroller.  Returns a Boolean indicating whether the PDB
-// exists, the PDB if it does exist, and an error value.
-func (r *reconciler) ensureRouterPodDisruptionBudget(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (bool, *policyv1.PodDisruptionBudget, error) {
-	wantPDB, desired, err := desiredRouterPodDisruptionBudget(ic, deploymentRef)
+// a given ingresscontroller if it has multiple replicas and does not exist
+// otherwise.  Returns a Boolean indicating whether the PDB exists, the PDB if
+// it does exist, and an error value.
+func (r *reconciler) ensureRouterPodDisruptionBudget(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure, deploymentRef metav1.OwnerReference) (bool, *policyv1.PodDisruptionBudget, error) {
+	replicas := determineDeploymentReplicas(ic, ingressConfig, infraConfig)
+	wantPDB, desired, err := desiredRouterPodDisruptionBudget(ic, replicas, deploymentRef)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to build pod disruption budget: %v", err)
 	}
@@ -61,15 +65,18 @@ func (r *reconciler) ensureRouterPodDisruptionBudget(ic *operatorv1.IngressContr
 }
 
 // desiredRouterPodDisruptionBudget returns the desired router pod disruption
-// budget.  Returns a Boolean indicating whether a PDB is desired, as well as
-// the PDB if one is desired.
-func desiredRouterPodDisruptionBudget(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (bool, *policyv1.PodDisruptionBudget, error) {
-	if ic.Spec.Replicas != nil && *ic.Spec.Replicas < int32(2) {
+// budget for the given number of replicas, which determineDeploymentReplicas
+// computes.  Returns a Boolean indicating whether a PDB is desired, as well as
+// the PDB if one is desired.  A PDB is not desired for a single replica, for
+// example with the SingleReplica infrastructure topology, because no budget can
+// keep a single replica available during a voluntary disruption.
+func desiredRouterPodDisruptionBudget(ic *operatorv1.IngressController, replicas int32, deploymentRef metav1.OwnerReference) (bool, *policyv1.PodDisruptionBudget, error) {
+	if replicas < int32(2) {
 		return false, nil, nil
 	}
 
 	maxUnavailable := "50%"
-	if ic.Spec.Replicas != nil && int(*ic.Spec.Replicas) >= 4 {
+	if replicas >= 4 {
 		maxUnavailable = "25%"
 	}
 
@@ -134,3 +141,62 @@ func podDisruptionBudgetChanged(current, expected *policyv1.PodDisruptionBudget)
 	updated.Spec = expected.Spec
 	return true, updated
 }
+
+// limitRolloutToPodDisruptionBudget sets the given deployment's rolling update
+// strategy to use no surge and to take down no more replicas at once than the
+// given pod disruption budget allows, but at least one replica so that the
+// rollout can make progress.  Returns a Boolean indicating whether the
+// deployment was changed.
+func limitRolloutToPodDisruptionBudget(deployment *appsv1.Deployment, pdb *policyv1.PodDisruptionBudget) bool {
+	rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+	if deployment.Spec.Replicas == nil || rollingUpdate == nil || rollingUpdate.MaxUnavailable == nil {
+		return false
+	}
+	replicas := int(*deployment.Spec.Replicas)
+	// The deployment controller rounds max unavailable down.
+	limit, err := intstr.GetScaledValueFromIntOrPercent(rollingUpdate.MaxUnavailable, replicas, false)
+	if err != nil {
+		return false
+	}
+	// The disruption controller rounds both max unavailable and min
+	// available up.
+	var allowed int
+	switch {
+	case pdb.Spec.MaxUnavailable != nil:
+		if allowed, err = intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, replicas, true); err != nil {
+			return false
+		}
+	case pdb.Spec.MinAvailable != nil:
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, replicas, true)
+		if err != nil {
+			return false
+		}
+		allowed = replicas - minAvailable
+	default:
+		return false
+	}
+	if allowed < limit {
+		limit = allowed
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	maxSurge, maxUnavailable := intstr.FromInt(0), intstr.FromInt(limit)
+	if rollingUpdate.MaxSurge != nil && *rollingUpdate.MaxSurge == maxSurge && *rollingUpdate.MaxUnavailable == maxUnavailable {
+		return false
+	}
+	rollingUpdate.MaxSurge = &maxSurge
+	rollingUpdate.MaxUnavailable = &maxUnavailable
+	return true
+}
+
+// isOwnedBy returns a Boolean indicating whether the given object has an owner
+// reference to the given owner.
+func isOwnedBy(object, owner metav1.Object) bool {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,22 @@
 package ingress
 
 import (
+	"context"
+	"reflect"
 	"testing"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredPodDisruptionBudget(t *testing.T) {
@@ -14,15 +24,23 @@ func TestDesiredPodDisruptionBudget(t *testing.T) {
 	testCases := []struct {
 		description          string
 		replicas             *int32
+		topology             configv1.TopologyMode
 		expectPDB            bool
 		expectMaxUnavailable intstr.IntOrString
 	}{
 		{
 			description:          "if replicas is not set, PDB should be 50%",
 			replicas:             nil,
+			topology:             configv1.HighlyAvailableTopologyMode,
 			expectPDB:            true,
 			expectMaxUnavailable: intstr.FromString("50%"),
 		},
+		{
+			description: "if replicas is not set and the topology is single-replica, PDB should be absent",
+			replicas:    nil,
+			topology:    configv1.SingleReplicaTopologyMode,
+			expectPDB:   false,
+		},
 		{
 			description:          "if replicas is 1, PDB should be absent",
 			replicas:             pointerTo(1),
@@ -71,13 +89,18 @@ func TestDesiredPodDisruptionBudget(t *testing.T) {
 			UID:        "1",
 			Controller: &trueVar,
 		}
-		wantPDB, pdb, err := desiredRouterPodDisruptionBudget(ic, deploymentRef)
+		ingressConfig := &configv1.Ingress{}
+		infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{InfrastructureTopology: tc.topology}}
+		replicas := determineDeploymentReplicas(ic, ingressConfig, infraConfig)
+		wantPDB, pdb, err := desiredRouterPodDisruptionBudget(ic, replicas, deploymentRef)
 		if err != nil {
 			t.Errorf("%q: unexpected error: %v", tc.description, err)
 		} else if !wantPDB {
 			if tc.expectPDB {
 				t.Errorf("%q: expected true, got false", tc.description)
 			}
+		} else if !tc.expectPDB {
+			t.Errorf("%q: expected false, got true", tc.description)
 		} else if pdb == nil {
 			t.Errorf("%q: expected pointer, got nil", tc.description)
 		} else if pdb.Spec.MaxUnavailable == nil {
@@ -87,3 +110,130 @@ func TestDesiredPodDisruptionBudget(t *testing.T) {
 		}
 	}
 }
+
+// TestLimitRolloutToPodDisruptionBudget verifies that
+// limitRolloutToPodDisruptionBudget removes surge from the router deployment's
+// strategy and limits maxUnavailable to what the pod disruption budget allows.
+func TestLimitRolloutToPodDisruptionBudget(t *testing.T) {
+	pointerTo := func(ios intstr.IntOrString) *intstr.IntOrString { return &ios }
+	testCases := []struct {
+		description          string
+		strategy             operatorv1.EndpointPublishingStrategyType
+		replicas             int32
+		pdbMinAvailable      *intstr.IntOrString
+		expectMaxUnavailable intstr.IntOrString
+	}{
+		{
+			description:          "2 replicas",
+			strategy:             operatorv1.LoadBalancerServiceStrategyType,
+			replicas:             2,
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+		{
+			description:          "3 replicas, limited by the deployment's maxUnavailable",
+			strategy:             operatorv1.LoadBalancerServiceStrategyType,
+			replicas:             3,
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+		{
+			description:          "8 replicas",
+			strategy:             operatorv1.LoadBalancerServiceStrategyType,
+			replicas:             8,
+			expectMaxUnavailable: intstr.FromInt(2),
+		},
+		{
+			description:          "8 replicas, limited by the PDB's minAvailable",
+			strategy:             operatorv1.LoadBalancerServiceStrategyType,
+			replicas:             8,
+			pdbMinAvailable:      pointerTo(intstr.FromInt(7)),
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+		{
+			description:          "PDB that allows no disruptions",
+			strategy:             operatorv1.LoadBalancerServiceStrategyType,
+			replicas:             2,
+			pdbMinAvailable:      pointerTo(intstr.FromString("100%")),
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+		{
+			description:          "host network with 3 replicas",
+			strategy:             operatorv1.HostNetworkStrategyType,
+			replicas:             3,
+			expectMaxUnavailable: intstr.FromInt(1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+			ic.Status.EndpointPublishingStrategy.Type = tc.strategy
+			ic.Spec.Replicas = &tc.replicas
+			deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+			if err != nil {
+				t.Fatalf("invalid router Deployment: %v", err)
+			}
+			deployment.UID = "1"
+			deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name, UID: deployment.UID}
+			_, pdb, err := desiredRouterPodDisruptionBudget(ic, tc.replicas, deploymentRef)
+			if err != nil {
+				t.Fatalf("invalid pod disruption budget: %v", err)
+			}
+			if tc.pdbMinAvailable != nil {
+				pdb.Spec.MaxUnavailable = nil
+				pdb.Spec.MinAvailable = tc.pdbMinAvailable
+			}
+			if !isOwnedBy(pdb, deployment) {
+				t.Error("expected the pod disruption budget to be owned by the deployment")
+			}
+
+			if !limitRolloutToPodDisruptionBudget(deployment, pdb) {
+				t.Error("expected the deployment to be changed")
+			}
+			rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+			if *rollingUpdate.MaxSurge != intstr.FromInt(0) {
+				t.Errorf("expected maxSurge 0, got %s", rollingUpdate.MaxSurge.String())
+			}
+			if *rollingUpdate.MaxUnavailable != tc.expectMaxUnavailable {
+				t.Errorf("expected maxUnavailable %s, got %s", tc.expectMaxUnavailable.String(), rollingUpdate.MaxUnavailable.String())
+			}
+			if limitRolloutToPodDisruptionBudget(deployment, pdb) {
+				t.Error("expected the deployment not to be changed again")
+			}
+		})
+	}
+}
+
+// TestEnsureRouterPodDisruptionBudget verifies that
+// ensureRouterPodDisruptionBudget creates a pod disruption budget for an
+// ingresscontroller with multiple replicas and deletes it when the
+// ingresscontroller is scaled down to a single replica.
+func TestEnsureRouterPodDisruptionBudget(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}
+	ingressConfig := &configv1.Ingress{}
+	infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{InfrastructureTopology: configv1.HighlyAvailableTopologyMode}}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	r := &reconciler{client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+
+	havePDB, pdb, err := r.ensureRouterPodDisruptionBudget(ic, ingressConfig, infraConfig, deploymentRef)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !havePDB || pdb == nil {
+		t.Fatal("expected a pod disruption budget for 2 replicas")
+	}
+	if !reflect.DeepEqual(pdb.Spec.Selector, controller.IngressControllerDeploymentPodSelector(ic)) {
+		t.Errorf("expected the pod disruption budget to select the router pods, got %v", pdb.Spec.Selector)
+	}
+
+	one := int32(1)
+	ic.Spec.Replicas = &one
+	if havePDB, _, err := r.ensureRouterPodDisruptionBudget(ic, ingressConfig, infraConfig, deploymentRef); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if havePDB {
+		t.Error("expected no pod disruption budget for 1 replica")
+	}
+	if err := r.client.Get(context.TODO(), controller.RouterPodDisruptionBudgetName(ic), &policyv1.PodDisruptionBudget{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the stale pod disruption budget to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// defaultAlertDegradedForSeconds is the default period for which an
+	// ingresscontroller must be degraded before the generated alert fires.
+	defaultAlertDegradedForSeconds = 300
+	// defaultAlertCertificateExpiryDays is the default number of days
+	// before the default certificate expires when the generated alert
+	// fires.
+	defaultAlertCertificateExpiryDays = 14
+)
+
+// prometheusRuleGVK is the kind of the prometheusrule that the operator
+// manages.
+var prometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Kind:    "PrometheusRule",
+	Version: "v1",
+}
+
+// ensurePrometheusRule ensures that the prometheusrule with the alerts that the
+// given ingresscontroller's alertingRules unsupported config override
+// configures exists, or that it does not exist if the override is not set.
+// Only the default ingresscontroller configures the prometheusrule.
+func (r *reconciler) ensurePrometheusRule(ic *operatorv1.IngressController) error {
+	if ic.Name != manifests.DefaultIngressControllerName {
+		return nil
+	}
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		return err
+	}
+
+	haveRule, current, err := r.currentPrometheusRule(ic)
+	if err != nil {
+		return err
+	}
+
+	if overrides.AlertingRules == nil {
+		if haveRule {
+			if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete prometheusrule %s/%s: %w", current.GetNamespace(), current.GetName(), err)
+			}
+			log.Info("deleted prometheusrule", "namespace", current.GetNamespace(), "name", current.GetName())
+		}
+		return nil
+	}
+
+	desired := desiredPrometheusRule(ic, overrides.AlertingRules)
+	if !haveRule {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create prometheusrule %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+		}
+		log.Info("created prometheusrule", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		return nil
+	}
+	if changed, updated := prometheusRuleChanged(current, desired); changed {
+		// Diff before updating because the client may mutate the object.
+		diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update prometheusrule %s/%s: %w", updated.GetNamespace(), updated.GetName(), err)
+		}
+		log.Info("updated prometheusrule", "namespace", updated.GetNamespace(), "name", updated.GetName(), "diff", diff)
+	}
+	return nil
+}
+
+// desiredPrometheusRule returns the desired prometheusrule with alerts for
+// degraded ingresscontrollers, expiring default certificates, and failing DNS
+// provider calls, using the given thresholds and the operator's defaults for
+// unset thresholds.
+func desiredPrometheusRule(ic *operatorv1.IngressController, thresholds *alertingRulesOverride) *unstructured.Unstructured {
+	degradedFor := thresholds.DegradedForSeconds
+	if degradedFor == 0 {
+		degradedFor = defaultAlertDegradedForSeconds
+	}
+	expiryDays := thresholds.CertificateExpiryDays
+	if expiryDays == 0 {
+		expiryDays = defaultAlertCertificateExpiryDays
+	}
+
+	// Use []interface{} and map[string]interface{} throughout so that
+	// the object can be deep-copied and compared with API objects.
+	alert := func(name, expr, forDuration, summary, message string) interface{} {
+		return map[string]interface{}{
+			"alert": name,
+			"expr":  expr,
+			"for":   forDuration,
+			"labels": map[string]interface{}{
+				"severity": "warning",
+			},
+			"annotations": map[string]interface{}{
+				"summary": summary,
+				"message": message,
+			},
+		}
+	}
+	name := controller.IngressControllerPrometheusRuleName(ic.Namespace)
+	rule := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"namespace": name.Namespace,
+				"name":      name.Name,
+				"labels": map[string]interface{}{
+					"role": "alert-rules",
+				},
+			},
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name": "openshift-ingress-operator-generated.rules",
+						"rules": []interface{}{
+							alert("IngressControllerDegradedTooLong",
+								`ingress_controller_conditions{condition="Degraded"} == 1`,
+								fmt.Sprintf("%ds", degradedFor),
+								"IngressController has been degraded for too long",
+								"The {{ $labels.name }} ingresscontroller has been degraded for longer than the configured period."),
+							alert("IngressControllerCertificateExpiringSoon",
+								fmt.Sprintf("ingress_controller_default_certificate_expiry_timestamp_seconds - time() < %d", int64(expiryDays)*24*60*60),
+								"10m",
+								"IngressController default certificate is expiring soon",
+								fmt.Sprintf("The default certificate of the {{ $labels.name }} ingresscontroller expires in less than %d days.", expiryDays)),
+							alert("IngressDNSPublishFailing",
+								fmt.Sprintf("increase(ingress_dns_publish_failures_total[15m]) > %d", thresholds.DNSFailureThreshold),
+								"5m",
+								"DNS provider calls are failing",
+								"{{ $labels.operation }} calls to the {{ $labels.provider }} DNS provider are failing."),
+						},
+					},
+				},
+			},
+		},
+	}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	trueVar := true
+	rule.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: operatorv1.GroupVersion.String(),
+		Kind:       "IngressController",
+		Name:       ic.Name,
+		UID:        ic.UID,
+		Controller: &trueVar,
+	}})
+	return rule
+}
+
+// currentPrometheusRule returns the current prometheusrule.  Returns a Boolean
+// indicating whether the prometheusrule existed, the prometheusrule if it did
+// exist, and an error value.
+func (r *reconciler) currentPrometheusRule(ic *operatorv1.IngressController) (bool, *unstructured.Unstructured, error) {
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	if err := r.client.Get(context.TODO(), controller.IngressControllerPrometheusRuleName(ic.Namespace), rule); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, rule, nil
+}
+
+// prometheusRuleChanged checks if the current prometheusrule spec matches the
+// expected spec and if not returns an updated one.
+func prometheusRuleChanged(current, expected *unstructured.Unstructured) (bool, *unstructured.Unstructured) {
+	if reflect.DeepEqual(current.Object["spec"], expected.Object["spec"]) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Object["spec"] = expected.Object["spec"]
+	return true, updated
+}
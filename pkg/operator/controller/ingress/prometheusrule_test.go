@@ -0,0 +1,163 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// prometheusRuleAlerts returns the given prometheusrule's alerts, keyed by
+// name.
+func prometheusRuleAlerts(t *testing.T, rule *unstructured.Unstructured) map[string]map[string]interface{} {
+	t.Helper()
+	groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("expected one rule group, got %v (error: %v)", groups, err)
+	}
+	rules, _, err := unstructured.NestedSlice(groups[0].(map[string]interface{}), "rules")
+	if err != nil {
+		t.Fatalf("failed to read rules: %v", err)
+	}
+	alerts := map[string]map[string]interface{}{}
+	for _, r := range rules {
+		alert := r.(map[string]interface{})
+		alerts[alert["alert"].(string)] = alert
+	}
+	return alerts
+}
+
+// TestDesiredPrometheusRule verifies that desiredPrometheusRule generates
+// alerts with the configured thresholds, or with the defaults for unset
+// thresholds.
+func TestDesiredPrometheusRule(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default", UID: "1"},
+	}
+	testCases := []struct {
+		name       string
+		thresholds alertingRulesOverride
+		expect     map[string][2]string
+	}{
+		{
+			name:       "defaults",
+			thresholds: alertingRulesOverride{},
+			expect: map[string][2]string{
+				"IngressControllerDegradedTooLong":         {`ingress_controller_conditions{condition="Degraded"} == 1`, "300s"},
+				"IngressControllerCertificateExpiringSoon": {"ingress_controller_default_certificate_expiry_timestamp_seconds - time() < 1209600", "10m"},
+				"IngressDNSPublishFailing":                 {"increase(ingress_dns_publish_failures_total[15m]) > 0", "5m"},
+			},
+		},
+		{
+			name: "configured thresholds",
+			thresholds: alertingRulesOverride{
+				DegradedForSeconds:    600,
+				CertificateExpiryDays: 30,
+				DNSFailureThreshold:   5,
+			},
+			expect: map[string][2]string{
+				"IngressControllerDegradedTooLong":         {`ingress_controller_conditions{condition="Degraded"} == 1`, "600s"},
+				"IngressControllerCertificateExpiringSoon": {"ingress_controller_default_certificate_expiry_timestamp_seconds - time() < 2592000", "10m"},
+				"IngressDNSPublishFailing":                 {"increase(ingress_dns_publish_failures_total[15m]) > 5", "5m"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := desiredPrometheusRule(ic, &tc.thresholds)
+			if rule.GetNamespace() != "openshift-ingress-operator" || rule.GetName() != "ingress-operator-generated" {
+				t.Errorf("unexpected prometheusrule name %s/%s", rule.GetNamespace(), rule.GetName())
+			}
+			if refs := rule.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != ic.UID {
+				t.Errorf("expected the prometheusrule to be owned by the ingresscontroller, got %v", refs)
+			}
+			alerts := prometheusRuleAlerts(t, rule)
+			if len(alerts) != len(tc.expect) {
+				t.Errorf("expected %d alerts, got %d", len(tc.expect), len(alerts))
+			}
+			for name, expected := range tc.expect {
+				alert, ok := alerts[name]
+				if !ok {
+					t.Errorf("expected alert %s", name)
+					continue
+				}
+				if alert["expr"] != expected[0] {
+					t.Errorf("expected alert %s to have expression %q, got %q", name, expected[0], alert["expr"])
+				}
+				if alert["for"] != expected[1] {
+					t.Errorf("expected alert %s to have duration %q, got %q", name, expected[1], alert["for"])
+				}
+			}
+		})
+	}
+}
+
+// TestEnsurePrometheusRule verifies that the operator creates, updates, and
+// deletes the prometheusrule according to the default ingresscontroller's
+// alertingRules unsupported config override, and ignores the override on
+// other ingresscontrollers.
+func TestEnsurePrometheusRule(t *testing.T) {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(prometheusRuleGVK, &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(prometheusRuleGVK.GroupVersion().WithKind("PrometheusRuleList"), &unstructured.UnstructuredList{})
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &reconciler{client: cl}
+	icWithOverrides := func(name, overrides string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(overrides)},
+			},
+		}
+	}
+	get := func(t *testing.T) (*unstructured.Unstructured, bool) {
+		t.Helper()
+		rule := &unstructured.Unstructured{}
+		rule.SetGroupVersionKind(prometheusRuleGVK)
+		if err := cl.Get(context.Background(), controller.IngressControllerPrometheusRuleName("openshift-ingress-operator"), rule); err != nil {
+			return nil, false
+		}
+		return rule, true
+	}
+
+	if err := r.ensurePrometheusRule(icWithOverrides("sharded", `{"alertingRules":{}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := get(t); ok {
+		t.Fatal("expected no prometheusrule for a non-default ingresscontroller")
+	}
+
+	if err := r.ensurePrometheusRule(icWithOverrides("default", `{"alertingRules":{}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rule, ok := get(t)
+	if !ok {
+		t.Fatal("expected prometheusrule to be created")
+	}
+	if expr := prometheusRuleAlerts(t, rule)["IngressDNSPublishFailing"]["expr"]; expr != "increase(ingress_dns_publish_failures_total[15m]) > 0" {
+		t.Errorf("unexpected DNS alert expression %q", expr)
+	}
+
+	if err := r.ensurePrometheusRule(icWithOverrides("default", `{"alertingRules":{"dnsFailureThreshold":3}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rule, _ = get(t)
+	if expr := prometheusRuleAlerts(t, rule)["IngressDNSPublishFailing"]["expr"]; expr != "increase(ingress_dns_publish_failures_total[15m]) > 3" {
+		t.Errorf("expected prometheusrule to be updated, got DNS alert expression %q", expr)
+	}
+
+	if err := r.ensurePrometheusRule(icWithOverrides("default", "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := get(t); ok {
+		t.Error("expected prometheusrule to be deleted when the override is removed")
+	}
+}
@@ -0,0 +1,67 @@
+package ingress
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcileBackoff tracks consecutive failures to reconcile each
+// ingresscontroller so that an ingresscontroller that fails persistently, for
+// example because of invalid cloud credentials, is requeued with exponentially
+// increasing delays rather than at a fixed interval.  A nil *reconcileBackoff
+// imposes no backoff.
+type reconcileBackoff struct {
+	max time.Duration
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// newReconcileBackoff returns a reconcileBackoff that caps the requeue delay
+// at max.  Returns nil if max is not positive.
+func newReconcileBackoff(max time.Duration) *reconcileBackoff {
+	if max <= 0 {
+		return nil
+	}
+	return &reconcileBackoff{
+		max:      max,
+		failures: map[types.NamespacedName]int{},
+	}
+}
+
+// failed records a failure to reconcile the ingresscontroller with the given
+// name and returns how long to wait before reconciling it again, given the
+// delay that the failure requested.  The delay doubles with each consecutive
+// failure, up to the maximum, and is never less than the requested delay.
+func (b *reconcileBackoff) failed(name types.NamespacedName, after time.Duration) time.Duration {
+	if b == nil {
+		return after
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	failures := b.failures[name]
+	b.failures[name] = failures + 1
+	delay := after
+	for i := 0; i < failures && delay < b.max; i++ {
+		delay *= 2
+	}
+	if delay > b.max {
+		delay = b.max
+	}
+	if delay < after {
+		delay = after
+	}
+	return delay
+}
+
+// succeeded resets the backoff for the ingresscontroller with the given name.
+func (b *reconcileBackoff) succeeded(name types.NamespacedName) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, name)
+}
@@ -0,0 +1,62 @@
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestReconcileBackoff verifies that reconcileBackoff doubles the requeue
+// delay with each consecutive failure to reconcile an ingresscontroller, caps
+// the delay at the maximum, tracks each ingresscontroller separately, and
+// resets the delay after a success.
+func TestReconcileBackoff(t *testing.T) {
+	b := newReconcileBackoff(time.Minute)
+	foo := types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "foo"}
+	bar := types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "bar"}
+
+	expected := []time.Duration{
+		5 * time.Second,
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		time.Minute,
+		time.Minute,
+	}
+	for i, expect := range expected {
+		if delay := b.failed(foo, 5*time.Second); delay != expect {
+			t.Errorf("failure %d: expected delay %v, got %v", i+1, expect, delay)
+		}
+	}
+
+	if delay := b.failed(bar, 5*time.Second); delay != 5*time.Second {
+		t.Errorf("expected failures of another ingresscontroller not to affect its delay, got %v", delay)
+	}
+
+	// A failure that requests a longer delay than the maximum gets it.
+	if delay := b.failed(foo, 2*time.Minute); delay != 2*time.Minute {
+		t.Errorf("expected the requested delay, got %v", delay)
+	}
+
+	b.succeeded(foo)
+	if delay := b.failed(foo, 5*time.Second); delay != 5*time.Second {
+		t.Errorf("expected the delay to be reset after a success, got %v", delay)
+	}
+}
+
+// TestReconcileBackoffDisabled verifies that a nil reconcileBackoff returns
+// the requested delay.
+func TestReconcileBackoffDisabled(t *testing.T) {
+	b := newReconcileBackoff(0)
+	if b != nil {
+		t.Fatalf("expected a zero maximum to disable the backoff")
+	}
+	name := types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}
+	for i := 0; i < 3; i++ {
+		if delay := b.failed(name, 15*time.Second); delay != 15*time.Second {
+			t.Errorf("failure %d: expected delay %v, got %v", i+1, 15*time.Second, delay)
+		}
+	}
+	b.succeeded(name)
+}
@@ -10,16 +10,21 @@ import (
 // determining the number of replicas in the Deployments corresponding to
 // IngressController resources in which the number of replicas is unset
 func DetermineReplicas(ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure) int32 {
-	// DefaultPlacement affects which topology field we're interested in
-	topology := infraConfig.Status.InfrastructureTopology
-	if ingressConfig.Status.DefaultPlacement == configv1.DefaultPlacementControlPlane {
-		topology = infraConfig.Status.ControlPlaneTopology
-	}
-
-	if topology == configv1.SingleReplicaTopologyMode {
+	if replicasTopology(ingressConfig, infraConfig) == configv1.SingleReplicaTopologyMode {
 		return 1
 	}
 
 	// TODO: Set the replicas value to the number of workers.
 	return 2
 }
+
+// replicasTopology returns the topology mode of the nodes on which router pods
+// are placed by default, which DetermineReplicas uses to choose the number of
+// replicas.
+func replicasTopology(ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure) configv1.TopologyMode {
+	// DefaultPlacement affects which topology field we're interested in
+	if ingressConfig.Status.DefaultPlacement == configv1.DefaultPlacementControlPlane {
+		return infraConfig.Status.ControlPlaneTopology
+	}
+	return infraConfig.Status.InfrastructureTopology
+}
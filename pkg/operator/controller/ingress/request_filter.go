@@ -0,0 +1,109 @@
+package ingress
+
+import (
+	"time"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// isDefaultIngressControllerRequest returns a function that returns true if
+// the given reconcile request is for the default ingresscontroller in the
+// given namespace.
+func isDefaultIngressControllerRequest(namespace string) func(request reconcile.Request) bool {
+	return func(request reconcile.Request) bool {
+		return request.Namespace == namespace && request.Name == manifests.DefaultIngressControllerName
+	}
+}
+
+// filterRequests returns an event handler that adds the reconcile requests
+// that the given handler adds to the queue only if keep returns true for them.
+// This allows the reconcile requests for a watch to be split between
+// controllers, each with its own queue.
+func filterRequests(h handler.EventHandler, keep func(request reconcile.Request) bool) handler.EventHandler {
+	return &requestFilteringHandler{handler: h, keep: keep}
+}
+
+// requestFilteringHandler is an event handler that wraps another event handler
+// and drops the reconcile requests for which keep returns false.
+type requestFilteringHandler struct {
+	handler handler.EventHandler
+	keep    func(request reconcile.Request) bool
+}
+
+var _ handler.EventHandler = &requestFilteringHandler{}
+
+// InjectFunc injects dependencies, such as the scheme and REST mapper that
+// handler.EnqueueRequestForOwner uses, into the wrapped handler.
+func (h *requestFilteringHandler) InjectFunc(f inject.Func) error {
+	return f(h.handler)
+}
+
+// Create implements handler.EventHandler.
+func (h *requestFilteringHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Create(e, h.filter(q))
+}
+
+// Update implements handler.EventHandler.
+func (h *requestFilteringHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Update(e, h.filter(q))
+}
+
+// Delete implements handler.EventHandler.
+func (h *requestFilteringHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Delete(e, h.filter(q))
+}
+
+// Generic implements handler.EventHandler.
+func (h *requestFilteringHandler) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Generic(e, h.filter(q))
+}
+
+// filter returns a queue that adds items to the given queue only if they are
+// reconcile requests for which keep returns true.
+func (h *requestFilteringHandler) filter(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	return &filteringQueue{RateLimitingInterface: q, keep: h.keep}
+}
+
+// filteringQueue is a work queue that drops the items that it is asked to add
+// unless they are reconcile requests for which keep returns true.  It passes
+// all other calls through to the wrapped queue.
+type filteringQueue struct {
+	workqueue.RateLimitingInterface
+	keep func(request reconcile.Request) bool
+}
+
+// kept returns true if the given item should be added to the queue.
+func (q *filteringQueue) kept(item interface{}) bool {
+	request, ok := item.(reconcile.Request)
+	return ok && q.keep(request)
+}
+
+// Add adds the given item to the wrapped queue if it is kept.
+func (q *filteringQueue) Add(item interface{}) {
+	if q.kept(item) {
+		q.RateLimitingInterface.Add(item)
+	}
+}
+
+// AddAfter adds the given item to the wrapped queue after the given duration
+// if it is kept.
+func (q *filteringQueue) AddAfter(item interface{}, duration time.Duration) {
+	if q.kept(item) {
+		q.RateLimitingInterface.AddAfter(item, duration)
+	}
+}
+
+// AddRateLimited adds the given item to the wrapped queue when the wrapped
+// queue's rate limiter allows it if it is kept.
+func (q *filteringQueue) AddRateLimited(item interface{}) {
+	if q.kept(item) {
+		q.RateLimitingInterface.AddRateLimited(item)
+	}
+}
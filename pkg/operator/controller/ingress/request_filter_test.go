@@ -0,0 +1,129 @@
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+func icRequest(name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: name}}
+}
+
+// TestIsDefaultIngressControllerRequest verifies that
+// isDefaultIngressControllerRequest matches only the default
+// ingresscontroller in the operator's namespace.
+func TestIsDefaultIngressControllerRequest(t *testing.T) {
+	isDefault := isDefaultIngressControllerRequest("openshift-ingress-operator")
+	testCases := []struct {
+		request reconcile.Request
+		expect  bool
+	}{
+		{icRequest("default"), true},
+		{icRequest("sharded"), false},
+		{reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "other", Name: "default"}}, false},
+	}
+	for _, tc := range testCases {
+		if actual := isDefault(tc.request); actual != tc.expect {
+			t.Errorf("expected %v to be %t, got %t", tc.request, tc.expect, actual)
+		}
+	}
+}
+
+// TestFilterRequestsDefaultIngressControllerFirst verifies that splitting the
+// reconcile requests for a watch between two queues, as New does, makes the
+// default ingresscontroller's requests available ahead of other requests that
+// were queued earlier, and that other requests stay in the order in which
+// they were added.
+func TestFilterRequestsDefaultIngressControllerFirst(t *testing.T) {
+	isDefault := isDefaultIngressControllerRequest("openshift-ingress-operator")
+	isNotDefault := func(request reconcile.Request) bool { return !isDefault(request) }
+	mainQueue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer mainQueue.ShutDown()
+	defaultQueue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer defaultQueue.ShutDown()
+	mainHandler := filterRequests(&handler.EnqueueRequestForObject{}, isNotDefault)
+	defaultHandler := filterRequests(&handler.EnqueueRequestForObject{}, isDefault)
+
+	// Simulate a backlog of events for other ingresscontrollers followed
+	// by an event for the default ingresscontroller.
+	for _, name := range []string{"a", "b", "c", "default", "d"} {
+		e := event.CreateEvent{Object: &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name},
+		}}
+		mainHandler.Create(e, mainQueue)
+		defaultHandler.Create(e, defaultQueue)
+	}
+
+	if n := defaultQueue.Len(); n != 1 {
+		t.Fatalf("expected 1 request in the default ingresscontroller's queue, got %d", n)
+	}
+	if item, _ := defaultQueue.Get(); item != icRequest("default") {
+		t.Errorf("expected the default ingresscontroller's request to be dequeued first, got %v", item)
+	}
+	for i, want := range []reconcile.Request{icRequest("a"), icRequest("b"), icRequest("c"), icRequest("d")} {
+		item, shutdown := mainQueue.Get()
+		if shutdown {
+			t.Fatalf("unexpected shutdown after %d items", i)
+		}
+		if item != want {
+			t.Errorf("expected item %d to be %v, got %v", i, want, item)
+		}
+		mainQueue.Done(item)
+	}
+	if n := mainQueue.Len(); n != 0 {
+		t.Errorf("expected the main queue to be empty, got %d items", n)
+	}
+}
+
+// TestFilteringQueue verifies that filteringQueue drops items that are not
+// kept from all of the ways of adding an item and that delayed additions of a
+// kept item are still coalesced by the wrapped queue.
+func TestFilteringQueue(t *testing.T) {
+	wrapped := workqueue.NewRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(time.Millisecond, time.Millisecond, 1))
+	defer wrapped.ShutDown()
+	q := &filteringQueue{RateLimitingInterface: wrapped, keep: isDefaultIngressControllerRequest("openshift-ingress-operator")}
+
+	q.Add(icRequest("a"))
+	q.AddAfter(icRequest("a"), time.Millisecond)
+	q.AddRateLimited(icRequest("a"))
+	q.Add("not a request")
+	q.AddAfter(icRequest("default"), time.Millisecond)
+	q.AddAfter(icRequest("default"), time.Millisecond)
+	q.AddRateLimited(icRequest("default"))
+
+	time.Sleep(50 * time.Millisecond)
+	if n := wrapped.Len(); n != 1 {
+		t.Fatalf("expected 1 item in the wrapped queue, got %d", n)
+	}
+	if item, _ := wrapped.Get(); item != icRequest("default") {
+		t.Errorf("expected %v, got %v", icRequest("default"), item)
+	}
+}
+
+// TestRequestFilteringHandlerInjection verifies that dependencies that a
+// controller injects into a requestFilteringHandler reach the wrapped handler.
+func TestRequestFilteringHandlerInjection(t *testing.T) {
+	wrapped := &handler.EnqueueRequestForOwner{OwnerType: &operatorv1.IngressController{}}
+	h := filterRequests(wrapped, isDefaultIngressControllerRequest("openshift-ingress-operator"))
+	var injected []interface{}
+	if _, err := inject.InjectorInto(func(i interface{}) error {
+		injected = append(injected, i)
+		return nil
+	}, h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(injected) != 1 || injected[0] != wrapped {
+		t.Errorf("expected dependencies to be injected into the wrapped handler, got %v", injected)
+	}
+}
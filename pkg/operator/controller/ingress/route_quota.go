@@ -0,0 +1,235 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// routeQuotaExceededLabelPrefix is the prefix of the label that the operator
+// puts on routes that exceed an ingresscontroller's route quota.  The
+// ingresscontroller's name is the label's name, and the ingresscontroller's
+// router does not select routes that have the label.
+const routeQuotaExceededLabelPrefix = "route-quota-exceeded.ingress.operator.openshift.io/"
+
+// routeQuotaOverride specifies the maximum number of routes per namespace that
+// an ingresscontroller admits.
+type routeQuotaOverride struct {
+	// Default is the maximum number of routes that the ingresscontroller
+	// admits in each namespace that Namespaces does not list.  If it is
+	// zero, the number of routes in those namespaces is not limited.
+	Default int32 `json:"default"`
+	// Namespaces maps namespace names to the maximum number of routes that
+	// the ingresscontroller admits in the namespace.  A value of zero
+	// means that the number of routes in the namespace is not limited.
+	Namespaces map[string]int32 `json:"namespaces"`
+}
+
+// quotaFor returns the given namespace's route quota, or zero if the number
+// of routes in the namespace is not limited.
+func (q *routeQuotaOverride) quotaFor(namespace string) int32 {
+	if v, ok := q.Namespaces[namespace]; ok {
+		return v
+	}
+	return q.Default
+}
+
+// routeQuotaExceededLabel returns the label that marks routes that exceed the
+// route quota of the ingresscontroller with the given name.
+func routeQuotaExceededLabel(icName string) string {
+	return routeQuotaExceededLabelPrefix + icName
+}
+
+// routeQuotaExceededSelector returns a selector that excludes routes that
+// exceed the given ingresscontroller's route quota, or nil if the
+// ingresscontroller has no route quota.
+func routeQuotaExceededSelector(ic *operatorv1.IngressController) (labels.Requirements, error) {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil || overrides.RouteQuota == nil {
+		return nil, nil
+	}
+	requirement, err := labels.NewRequirement(routeQuotaExceededLabel(ic.Name), "!", nil)
+	if err != nil {
+		return nil, err
+	}
+	return labels.Requirements{*requirement}, nil
+}
+
+// validateRouteQuota returns errors for negative route quotas, for namespace
+// names that are invalid, or for an ingresscontroller name that cannot be used
+// in the route quota label.
+func validateRouteQuota(ic *operatorv1.IngressController, quota *routeQuotaOverride) []error {
+	var errs []error
+	if quota.Default < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.routeQuota.default %d: must not be negative", quota.Default))
+	}
+	for _, namespace := range sets.StringKeySet(quota.Namespaces).List() {
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.routeQuota.namespaces key %q: %s", namespace, strings.Join(msgs, ", ")))
+		}
+		if v := quota.Namespaces[namespace]; v < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.routeQuota.namespaces[%q] %d: must not be negative", namespace, v))
+		}
+	}
+	if msgs := validation.IsQualifiedName(routeQuotaExceededLabel(ic.Name)); len(msgs) != 0 {
+		errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.routeQuota cannot be used with ingresscontroller name %q: %s", ic.Name, strings.Join(msgs, ", ")))
+	}
+	return errs
+}
+
+// routesOverQuota returns the keys ("namespace/name") of the given routes that
+// exceed the given route quota.  In each namespace, the oldest routes are
+// within the quota, and the rest exceed it.
+func routesOverQuota(routes []*routev1.Route, quota *routeQuotaOverride) sets.String {
+	byNamespace := map[string][]*routev1.Route{}
+	for _, route := range routes {
+		byNamespace[route.Namespace] = append(byNamespace[route.Namespace], route)
+	}
+	over := sets.NewString()
+	for namespace, routes := range byNamespace {
+		limit := int(quota.quotaFor(namespace))
+		if limit == 0 || len(routes) <= limit {
+			continue
+		}
+		sort.Slice(routes, func(i, j int) bool {
+			ti, tj := routes[i].CreationTimestamp, routes[j].CreationTimestamp
+			if !ti.Equal(&tj) {
+				return ti.Before(&tj)
+			}
+			return routes[i].Name < routes[j].Name
+		})
+		for _, route := range routes[limit:] {
+			over.Insert(route.Namespace + "/" + route.Name)
+		}
+	}
+	return over
+}
+
+// shardSelector returns a selector for the given ingresscontroller's route or
+// namespace selector, which selects everything if it is nil.
+func shardSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// syncRouteQuota ensures that the routes in the given ingresscontroller's shard
+// that exceed its route quota, and only those routes, have the route quota
+// label, which makes the ingresscontroller's router stop selecting them.  The
+// operator clears the ingresscontroller's status from each route that it
+// labels, because the router does not clear the status of routes that it no
+// longer selects, and emits a warning event on the route.  If the
+// ingresscontroller has no route quota, the label is removed from all routes,
+// which are listed only if the route cache has a route that still has the
+// label.
+func (r *reconciler) syncRouteQuota(ic *operatorv1.IngressController) []error {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		// validateUnsupportedConfigOverrides reports the error.
+		return nil
+	}
+	quota := overrides.RouteQuota
+	if quota == nil {
+		requirement, err := labels.NewRequirement(routeQuotaExceededLabel(ic.Name), selection.Exists, nil)
+		if err != nil {
+			// No route can have an invalid label.
+			return nil
+		}
+		labeled := &routev1.RouteList{}
+		if err := r.routeCache.List(context.TODO(), labeled, client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*requirement)}); err != nil {
+			return []error{fmt.Errorf("failed to list routes with the route quota label for %s: %w", ic.Name, err)}
+		}
+		if len(labeled.Items) == 0 {
+			return nil
+		}
+	}
+
+	routeList := &routev1.RouteList{}
+	if err := r.client.List(context.TODO(), routeList); err != nil {
+		return []error{fmt.Errorf("failed to list all routes in order to enforce the route quota for %s: %w", ic.Name, err)}
+	}
+
+	over := sets.NewString()
+	if quota != nil {
+		routeSelector, err := shardSelector(ic.Spec.RouteSelector)
+		if err != nil {
+			return []error{fmt.Errorf("ingresscontroller %s has an invalid route selector: %w", ic.Name, err)}
+		}
+		namespaceSelector, err := shardSelector(ic.Spec.NamespaceSelector)
+		if err != nil {
+			return []error{fmt.Errorf("ingresscontroller %s has an invalid namespace selector: %w", ic.Name, err)}
+		}
+		namespaceList := &corev1.NamespaceList{}
+		if err := r.client.List(context.TODO(), namespaceList, client.MatchingLabelsSelector{Selector: namespaceSelector}); err != nil {
+			return []error{fmt.Errorf("failed to list namespaces in order to enforce the route quota for %s: %w", ic.Name, err)}
+		}
+		namespacesInShard := sets.NewString()
+		for i := range namespaceList.Items {
+			namespacesInShard.Insert(namespaceList.Items[i].Name)
+		}
+		var routesInShard []*routev1.Route
+		for i := range routeList.Items {
+			route := &routeList.Items[i]
+			if route.DeletionTimestamp != nil || !namespacesInShard.Has(route.Namespace) || !routeSelector.Matches(labels.Set(route.Labels)) {
+				continue
+			}
+			routesInShard = append(routesInShard, route)
+		}
+		over = routesOverQuota(routesInShard, quota)
+	}
+
+	var errs []error
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		exceeds := over.Has(route.Namespace + "/" + route.Name)
+		if changed, err := r.setRouteQuotaExceededLabel(route, ic.Name, exceeds); err != nil {
+			errs = append(errs, err)
+			continue
+		} else if !changed || !exceeds {
+			continue
+		}
+		r.recorder.Eventf(route, "Warning", "RouteQuotaExceeded", "Route is not admitted by ingresscontroller %s because namespace %s has more than %d routes, which is the ingresscontroller's route quota for the namespace.", ic.Name, route.Namespace, quota.quotaFor(route.Namespace))
+		if _, err := r.clearRouteStatus(route, ic.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// setRouteQuotaExceededLabel adds the route quota label of the ingresscontroller
+// with the given name to the given route if exceeds is true, or removes it
+// otherwise, and updates the route if the label changed.  Returns a Boolean
+// indicating whether the route was updated, and an error value.
+func (r *reconciler) setRouteQuotaExceededLabel(route *routev1.Route, icName string, exceeds bool) (bool, error) {
+	label := routeQuotaExceededLabel(icName)
+	if _, labeled := route.Labels[label]; labeled == exceeds {
+		return false, nil
+	}
+	if exceeds {
+		if route.Labels == nil {
+			route.Labels = map[string]string{}
+		}
+		route.Labels[label] = ""
+	} else {
+		delete(route.Labels, label)
+	}
+	if err := r.client.Update(context.TODO(), route); err != nil {
+		return false, fmt.Errorf("failed to update route quota label of route %s/%s for ingresscontroller %s: %w", route.Namespace, route.Name, icName, err)
+	}
+	log.Info("updated route quota label for route", "Route", route.Namespace+"/"+route.Name, "Ingress Controller", icName, "exceeds quota", exceeds)
+	return true, nil
+}
@@ -0,0 +1,224 @@
+package ingress
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// quotaRoute returns a route with the given namespace and name that was
+// created the given number of minutes after an arbitrary time and that the
+// "default" ingresscontroller has admitted.
+func quotaRoute(namespace, name string, minutes int) *routev1.Route {
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(time.Date(2022, 1, 1, 0, minutes, 0, 0, time.UTC)),
+		},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{{
+				RouterName: "default",
+				Conditions: []routev1.RouteIngressCondition{{
+					Type:   routev1.RouteAdmitted,
+					Status: corev1.ConditionTrue,
+				}},
+			}},
+		},
+	}
+}
+
+// TestRoutesOverQuota verifies that routesOverQuota keeps the oldest routes in
+// each namespace within the namespace's quota and reports the rest.
+func TestRoutesOverQuota(t *testing.T) {
+	routes := []*routev1.Route{
+		quotaRoute("app", "c", 3),
+		quotaRoute("app", "a", 1),
+		quotaRoute("app", "b", 2),
+		quotaRoute("app", "d", 2),
+		quotaRoute("big", "a", 1),
+		quotaRoute("big", "b", 2),
+		quotaRoute("big", "c", 3),
+		quotaRoute("small", "a", 1),
+		quotaRoute("small", "b", 2),
+	}
+	testCases := []struct {
+		name   string
+		quota  routeQuotaOverride
+		expect []string
+	}{
+		{
+			name:   "no quota",
+			quota:  routeQuotaOverride{},
+			expect: []string{},
+		},
+		{
+			name:   "all namespaces under quota",
+			quota:  routeQuotaOverride{Default: 4},
+			expect: []string{},
+		},
+		{
+			name:   "default quota",
+			quota:  routeQuotaOverride{Default: 2},
+			expect: []string{"app/c", "app/d", "big/c"},
+		},
+		{
+			name: "per-namespace quotas",
+			quota: routeQuotaOverride{
+				Default:    2,
+				Namespaces: map[string]int32{"big": 0, "small": 1},
+			},
+			expect: []string{"app/c", "app/d", "small/b"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := routesOverQuota(routes, &tc.quota)
+			if !actual.Equal(sets.NewString(tc.expect...)) {
+				t.Errorf("expected %v, got %v", tc.expect, actual.List())
+			}
+		})
+	}
+}
+
+// TestSyncRouteQuota verifies that syncRouteQuota labels the routes in the
+// ingresscontroller's shard that exceed its route quota, clears their status
+// and reports them, and removes the label from routes that no longer exceed
+// the quota.
+func TestSyncRouteQuota(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := routev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	shard := map[string]string{"shard": "default"}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: shard}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unsharded"}},
+		quotaRoute("app", "first", 1),
+		quotaRoute("app", "second", 2),
+		quotaRoute("app", "third", 3),
+		quotaRoute("unsharded", "first", 1),
+		quotaRoute("unsharded", "second", 2),
+		quotaRoute("unsharded", "third", 3),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	counting := &listCountingClient{Client: cl}
+	r := &reconciler{client: counting, routeCache: &clientCache{client: cl}, recorder: recorder}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			NamespaceSelector:          &metav1.LabelSelector{MatchLabels: shard},
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"routeQuota":{"default":2}}`)},
+		},
+	}
+	label := routeQuotaExceededLabel("default")
+	getRoute := func(namespace, name string) *routev1.Route {
+		route := &routev1.Route{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, route); err != nil {
+			t.Fatal(err)
+		}
+		return route
+	}
+	labeledRoutes := func() []string {
+		routes := &routev1.RouteList{}
+		if err := cl.List(context.TODO(), routes); err != nil {
+			t.Fatal(err)
+		}
+		var labeled []string
+		for _, route := range routes.Items {
+			if _, ok := route.Labels[label]; ok {
+				labeled = append(labeled, route.Namespace+"/"+route.Name)
+			}
+		}
+		return labeled
+	}
+	drain := func() []string {
+		var events []string
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	// Over quota: the newest route in the sharded namespace is labeled,
+	// its status is cleared, and it is reported once.
+	if errs := r.syncRouteQuota(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if labeled := labeledRoutes(); len(labeled) != 1 || labeled[0] != "app/third" {
+		t.Errorf("expected only app/third to be labeled, got %v", labeled)
+	}
+	if route := getRoute("app", "third"); len(route.Status.Ingress) != 0 {
+		t.Errorf("expected the status of app/third to be cleared, got %+v", route.Status.Ingress)
+	}
+	if route := getRoute("app", "second"); len(route.Status.Ingress) != 1 {
+		t.Errorf("expected the status of app/second to be kept, got %+v", route.Status.Ingress)
+	}
+	events := drain()
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning RouteQuotaExceeded") || !strings.Contains(events[0], "more than 2 routes") {
+		t.Errorf("expected one RouteQuotaExceeded event, got %v", events)
+	}
+	if errs := r.syncRouteQuota(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if events := drain(); len(events) != 0 {
+		t.Errorf("expected no repeated events, got %v", events)
+	}
+
+	// Under quota: deleting an older route frees quota for the labeled
+	// route.
+	if err := cl.Delete(context.TODO(), getRoute("app", "first")); err != nil {
+		t.Fatal(err)
+	}
+	if errs := r.syncRouteQuota(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if labeled := labeledRoutes(); len(labeled) != 0 {
+		t.Errorf("expected no labeled routes, got %v", labeled)
+	}
+
+	// Removing the quota removes the label.
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"routeQuota":{"default":1}}`)}
+	if errs := r.syncRouteQuota(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if labeled := labeledRoutes(); len(labeled) != 1 || labeled[0] != "app/third" {
+		t.Errorf("expected only app/third to be labeled, got %v", labeled)
+	}
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{}
+	if errs := r.syncRouteQuota(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if labeled := labeledRoutes(); len(labeled) != 0 {
+		t.Errorf("expected no labeled routes after removing the quota, got %v", labeled)
+	}
+
+	// Without a quota, routes are not listed once no route has the label.
+	counting.lists = 0
+	if errs := r.syncRouteQuota(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if counting.lists != 0 {
+		t.Errorf("expected routes not to be listed, got %d lists", counting.lists)
+	}
+}
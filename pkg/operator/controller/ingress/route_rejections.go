@@ -0,0 +1,105 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// routeRejectionReasonHostAlreadyClaimed is the reason that the router gives
+// for rejecting a route whose host another route in a different namespace has
+// already claimed.
+const routeRejectionReasonHostAlreadyClaimed = "HostAlreadyClaimed"
+
+// reportRouteRejectionsEnabled returns whether the given ingresscontroller has
+// the reportRouteRejections unsupported config override.
+func reportRouteRejectionsEnabled(ic *operatorv1.IngressController) bool {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	return err == nil && overrides.ReportRouteRejections
+}
+
+// routeRejection returns the given ingresscontroller's Admitted=False
+// condition on the given route, or nil if the ingresscontroller has not
+// rejected the route.
+func routeRejection(route *routev1.Route, icName string) *routev1.RouteIngressCondition {
+	for i := range route.Status.Ingress {
+		if route.Status.Ingress[i].RouterName != icName {
+			continue
+		}
+		if condition := findCondition(&route.Status.Ingress[i], routev1.RouteAdmitted); condition != nil && condition.Status == corev1.ConditionFalse {
+			return condition
+		}
+	}
+	return nil
+}
+
+// routeRejectionMessage returns a message that explains why the given
+// ingresscontroller rejected the given route with the given condition,
+// relating the rejection to the ingresscontroller's route admission policy
+// where it is the cause.
+func routeRejectionMessage(ic *operatorv1.IngressController, route *routev1.Route, condition *routev1.RouteIngressCondition) string {
+	message := fmt.Sprintf("Route was rejected by ingresscontroller %s (reason: %s): %s", ic.Name, condition.Reason, condition.Message)
+	admission := effectiveRouteAdmissionPolicy(ic)
+	switch {
+	case route.Spec.WildcardPolicy == routev1.WildcardPolicySubdomain && admission.WildcardPolicy != operatorv1.WildcardPolicyAllowed:
+		message += fmt.Sprintf(" The ingresscontroller's spec.routeAdmission.wildcardPolicy is %s, which rejects routes with wildcard policy %s.", admission.WildcardPolicy, routev1.WildcardPolicySubdomain)
+	case condition.Reason == routeRejectionReasonHostAlreadyClaimed && admission.NamespaceOwnership == operatorv1.StrictNamespaceOwnershipCheck:
+		message += fmt.Sprintf(" The ingresscontroller's spec.routeAdmission.namespaceOwnership is %s, which rejects routes that claim a host that a route in another namespace has claimed.", admission.NamespaceOwnership)
+	case condition.Reason == routeRejectionReasonHostAlreadyClaimed:
+		message += fmt.Sprintf(" The ingresscontroller's spec.routeAdmission.namespaceOwnership is %s, which rejects routes that claim the same host and path as a route in another namespace.", admission.NamespaceOwnership)
+	}
+	return message
+}
+
+// reportRouteRejections emits a warning event on each route that the given
+// ingresscontroller has rejected if the ingresscontroller has the
+// reportRouteRejections unsupported config override.  Each rejection is
+// reported once.
+func (r *reconciler) reportRouteRejections(ic *operatorv1.IngressController) []error {
+	if !reportRouteRejectionsEnabled(ic) {
+		return nil
+	}
+	routeList := &routev1.RouteList{}
+	if err := r.client.List(context.TODO(), routeList); err != nil {
+		return []error{fmt.Errorf("failed to list all routes in order to report route rejections for %s: %w", ic.Name, err)}
+	}
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		key := ic.Name + "/" + route.Namespace + "/" + route.Name
+		condition := routeRejection(route, ic.Name)
+		if condition == nil {
+			r.reportedRouteRejections.Delete(key)
+			continue
+		}
+		rejection := condition.Reason + "/" + condition.Message
+		if condition.LastTransitionTime != nil {
+			rejection += "/" + condition.LastTransitionTime.String()
+		}
+		if reported, ok := r.reportedRouteRejections.Load(key); ok && reported == rejection {
+			continue
+		}
+		r.recorder.Event(route, "Warning", "RouteRejected", routeRejectionMessage(ic, route, condition))
+		r.reportedRouteRejections.Store(key, rejection)
+	}
+	return nil
+}
+
+// reportRouteUnselected emits a warning event on the given route, which the
+// given ingresscontroller no longer admits because its route selector (if
+// routeInShard is false) or namespace selector no longer selects the route, if
+// the ingresscontroller has the reportRouteRejections unsupported config
+// override.
+func (r *reconciler) reportRouteUnselected(ic *operatorv1.IngressController, route *routev1.Route, routeInShard bool) {
+	if !reportRouteRejectionsEnabled(ic) {
+		return
+	}
+	selector := "spec.namespaceSelector"
+	if !routeInShard {
+		selector = "spec.routeSelector"
+	}
+	r.recorder.Eventf(route, "Warning", "RouteNotSelected", "Route is no longer admitted by ingresscontroller %s because the ingresscontroller's %s does not select it.", ic.Name, selector)
+}
@@ -0,0 +1,157 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// rejectedRoute returns a route that the ingresscontroller with the given name
+// has rejected with the given reason and message.
+func rejectedRoute(name, icName, reason, message string, wildcardPolicy routev1.WildcardPolicyType) *routev1.Route {
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: name},
+		Spec:       routev1.RouteSpec{WildcardPolicy: wildcardPolicy},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{{
+				RouterName: icName,
+				Conditions: []routev1.RouteIngressCondition{{
+					Type:    routev1.RouteAdmitted,
+					Status:  corev1.ConditionFalse,
+					Reason:  reason,
+					Message: message,
+				}},
+			}},
+		},
+	}
+}
+
+// TestRouteRejectionMessage verifies that routeRejectionMessage explains each
+// rejection reason in terms of the ingresscontroller's route admission policy.
+func TestRouteRejectionMessage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		routeAdmission *operatorv1.RouteAdmissionPolicy
+		route          *routev1.Route
+		expectContains []string
+	}{
+		{
+			name:           "wildcard route, default policy",
+			route:          rejectedRoute("wildcard", "default", "RouteNotAdmitted", "wildcard routes are not allowed", routev1.WildcardPolicySubdomain),
+			expectContains: []string{"RouteNotAdmitted", "wildcard routes are not allowed", "spec.routeAdmission.wildcardPolicy is WildcardsDisallowed"},
+		},
+		{
+			name:           "host already claimed, strict namespace ownership",
+			route:          rejectedRoute("claimed", "default", "HostAlreadyClaimed", "route other already exposes foo.example.com and is older", routev1.WildcardPolicyNone),
+			expectContains: []string{"HostAlreadyClaimed", "spec.routeAdmission.namespaceOwnership is Strict", "a route in another namespace has claimed"},
+		},
+		{
+			name:           "host already claimed, inter-namespace ownership allowed",
+			routeAdmission: &operatorv1.RouteAdmissionPolicy{NamespaceOwnership: operatorv1.InterNamespaceAllowedOwnershipCheck},
+			route:          rejectedRoute("claimed", "default", "HostAlreadyClaimed", "route other already exposes foo.example.com/bar and is older", routev1.WildcardPolicyNone),
+			expectContains: []string{"HostAlreadyClaimed", "spec.routeAdmission.namespaceOwnership is InterNamespaceAllowed", "same host and path"},
+		},
+		{
+			name:           "other reason",
+			route:          rejectedRoute("invalid", "default", "ExtendedValidationFailed", "spec.tls.certificate: Invalid value", routev1.WildcardPolicyNone),
+			expectContains: []string{"ExtendedValidationFailed", "spec.tls.certificate: Invalid value"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       operatorv1.IngressControllerSpec{RouteAdmission: tc.routeAdmission},
+			}
+			message := routeRejectionMessage(ic, tc.route, routeRejection(tc.route, ic.Name))
+			for _, s := range tc.expectContains {
+				if !strings.Contains(message, s) {
+					t.Errorf("expected message to contain %q, got %q", s, message)
+				}
+			}
+			if tc.route.Spec.WildcardPolicy != routev1.WildcardPolicySubdomain && strings.Contains(message, "wildcardPolicy") {
+				t.Errorf("expected message not to mention the wildcard policy, got %q", message)
+			}
+		})
+	}
+}
+
+// TestReportRouteRejections verifies that reportRouteRejections emits one
+// event for each route that the ingresscontroller has rejected, only if the
+// reportRouteRejections unsupported config override is set, and that
+// reportRouteUnselected names the selector that no longer selects a route.
+func TestReportRouteRejections(t *testing.T) {
+	admitted := rejectedRoute("admitted", "default", "", "", routev1.WildcardPolicyNone)
+	admitted.Status.Ingress[0].Conditions[0].Status = corev1.ConditionTrue
+	s := runtime.NewScheme()
+	if err := routev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		admitted,
+		rejectedRoute("wildcard", "default", "RouteNotAdmitted", "wildcard routes are not allowed", routev1.WildcardPolicySubdomain),
+		rejectedRoute("claimed", "default", "HostAlreadyClaimed", "route other already exposes foo.example.com and is older", routev1.WildcardPolicyNone),
+		rejectedRoute("sharded", "sharded", "HostAlreadyClaimed", "route other already exposes bar.example.com and is older", routev1.WildcardPolicyNone),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &reconciler{client: cl, recorder: recorder}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+	}
+	drain := func() []string {
+		var events []string
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	if errs := r.reportRouteRejections(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if events := drain(); len(events) != 0 {
+		t.Errorf("expected no events without the override, got %v", events)
+	}
+
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"reportRouteRejections":true}`)}
+	if errs := r.reportRouteRejections(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	events := drain()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", events)
+	}
+	for _, event := range events {
+		if !strings.HasPrefix(event, "Warning RouteRejected Route was rejected by ingresscontroller default") {
+			t.Errorf("unexpected event %q", event)
+		}
+	}
+
+	// Each rejection is reported once.
+	if errs := r.reportRouteRejections(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if events := drain(); len(events) != 0 {
+		t.Errorf("expected no repeated events, got %v", events)
+	}
+
+	r.reportRouteUnselected(ic, admitted, false)
+	r.reportRouteUnselected(ic, admitted, true)
+	events = drain()
+	if len(events) != 2 || !strings.Contains(events[0], "spec.routeSelector") || !strings.Contains(events[1], "spec.namespaceSelector") {
+		t.Errorf("expected events naming the route selector and the namespace selector, got %v", events)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"k8s.io/apimachinery/pkg/labels"
 	"reflect"
+	"strings"
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -53,9 +55,109 @@ func (r *reconciler) syncRouteStatus(ic *operatorv1.IngressController) []error {
 			}
 		}
 	}
+	errs := r.syncRouteQuota(ic)
+	errs = append(errs, r.syncRouteAdmittedByAnnotations(ic)...)
+	return append(errs, r.reportRouteRejections(ic)...)
+}
+
+// syncRouteAdmittedByAnnotations ensures that, if the ingresscontroller has the
+// annotateAdmittedRoutes unsupported config override, the admitted-by
+// annotation on each route that the ingresscontroller has admitted lists the
+// ingresscontroller's name, and that no other route's annotation lists it.  If
+// the override is not set, all routes are listed only if the route cache has a
+// route whose annotation still lists the ingresscontroller's name.
+func (r *reconciler) syncRouteAdmittedByAnnotations(ic *operatorv1.IngressController) []error {
+	enabled := false
+	if overrides, err := getUnsupportedConfigOverrides(ic); err == nil {
+		enabled = overrides.AnnotateAdmittedRoutes
+	}
+	if !enabled {
+		annotated := &routev1.RouteList{}
+		if err := r.routeCache.List(context.TODO(), annotated, client.MatchingFields{routeAdmittedByIndex: ic.Name}); err != nil {
+			return []error{fmt.Errorf("failed to list routes with admitted-by annotations for %s: %w", ic.Name, err)}
+		}
+		if len(annotated.Items) == 0 {
+			return nil
+		}
+	}
+	routeList := &routev1.RouteList{}
+	if err := r.client.List(context.TODO(), routeList); err != nil {
+		return []error{fmt.Errorf("failed to list all routes in order to sync admitted-by annotations for %s: %w", ic.Name, err)}
+	}
+	var errs []error
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		admitted := enabled && isRouteAdmittedBy(route, ic.Name)
+		if err := r.setRouteAdmittedByAnnotation(route, ic.Name, admitted); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// setRouteAdmittedByAnnotation adds the given ingresscontroller's name to the
+// route's admitted-by annotation if admitted is true, or removes it otherwise,
+// and updates the route if the annotation changed.
+func (r *reconciler) setRouteAdmittedByAnnotation(route *routev1.Route, icName string, admitted bool) error {
+	current := route.Annotations[manifests.RouteAdmittedByAnnotation]
+	desired := routeAdmittedByAnnotationValue(current, icName, admitted)
+	if desired == current {
+		return nil
+	}
+	if len(desired) == 0 {
+		delete(route.Annotations, manifests.RouteAdmittedByAnnotation)
+	} else {
+		if route.Annotations == nil {
+			route.Annotations = map[string]string{}
+		}
+		route.Annotations[manifests.RouteAdmittedByAnnotation] = desired
+	}
+	if err := r.client.Update(context.TODO(), route); err != nil {
+		return fmt.Errorf("failed to update admitted-by annotation of route %s/%s for ingresscontroller %s: %w", route.Namespace, route.Name, icName, err)
+	}
+	log.Info("updated admitted-by annotation for route", "Route", route.Namespace+"/"+route.Name, "Ingress Controller", icName, "admitted-by", desired)
 	return nil
 }
 
+// routeAdmittedByAnnotationValue returns the value of the admitted-by
+// annotation that results from adding the given ingresscontroller's name to, or
+// removing it from, the given annotation value.
+func routeAdmittedByAnnotationValue(current, icName string, admitted bool) string {
+	names := routeAdmittedByNames(current)
+	if admitted {
+		names.Insert(icName)
+	} else {
+		names.Delete(icName)
+	}
+	return strings.Join(names.List(), ",")
+}
+
+// routeAdmittedByNames returns the ingresscontroller names in the given value
+// of the admitted-by annotation.
+func routeAdmittedByNames(value string) sets.String {
+	names := sets.NewString()
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); len(name) != 0 {
+			names.Insert(name)
+		}
+	}
+	return names
+}
+
+// isRouteAdmittedBy returns whether the given route has been admitted by the
+// ingresscontroller with the given name.
+func isRouteAdmittedBy(route *routev1.Route, icName string) bool {
+	for i := range route.Status.Ingress {
+		if route.Status.Ingress[i].RouterName != icName {
+			continue
+		}
+		if condition := findCondition(&route.Status.Ingress[i], routev1.RouteAdmitted); condition != nil && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 // isRouterDeploymentRolloutComplete determines whether the rollout of the ingress router deployment is complete.
 func (r *reconciler) isRouterDeploymentRolloutComplete(ic *operatorv1.IngressController) (bool, error) {
 	deployment := appsv1.Deployment{}
@@ -87,6 +189,14 @@ func (r *reconciler) clearAllRoutesStatusForIngressController(icName string) []e
 	}
 	// Clear status on the routes that belonged to icName.
 	for i := range routeList.Items {
+		if err := r.setRouteAdmittedByAnnotation(&routeList.Items[i], icName, false); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := r.setRouteQuotaExceededLabel(&routeList.Items[i], icName, false); err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		if cleared, err := r.clearRouteStatus(&routeList.Items[i], icName); err != nil {
 			errs = append(errs, err)
 		} else if cleared {
@@ -181,6 +291,7 @@ func (r *reconciler) clearRoutesNotAdmittedByIngress(ingress *operatorv1.Ingress
 				errs = append(errs, err)
 			} else if cleared {
 				routesCleared++
+				r.reportRouteUnselected(ingress, route, routeInShard)
 			}
 		}
 
@@ -0,0 +1,260 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// clientCache is a cache that reads objects using a client.  The fake client
+// ignores field selectors, so List applies the route cache's admitted-by index
+// itself.
+type clientCache struct {
+	informertest.FakeInformers
+	client client.Reader
+}
+
+// Get implements cache.Cache.
+func (c *clientCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.client.Get(ctx, key, obj)
+}
+
+// List implements cache.Cache.
+func (c *clientCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	fieldSelector := listOpts.FieldSelector
+	listOpts.FieldSelector = nil
+	if err := c.client.List(ctx, list, listOpts); err != nil {
+		return err
+	}
+	if fieldSelector == nil {
+		return nil
+	}
+	name, _ := fieldSelector.RequiresExactMatch(routeAdmittedByIndex)
+	routeList := list.(*routev1.RouteList)
+	var items []routev1.Route
+	for i := range routeList.Items {
+		if sets.NewString(routeAdmittedByIndexFunc(&routeList.Items[i])...).Has(name) {
+			items = append(items, routeList.Items[i])
+		}
+	}
+	routeList.Items = items
+	return nil
+}
+
+// listCountingClient is a client that counts the lists that it does.
+type listCountingClient struct {
+	client.Client
+	lists int
+}
+
+// List implements client.Client.
+func (c *listCountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.lists++
+	return c.Client.List(ctx, list, opts...)
+}
+
+// TestRouteAdmittedByAnnotationValue verifies that
+// routeAdmittedByAnnotationValue adds and removes an ingresscontroller's name
+// without affecting other names.
+func TestRouteAdmittedByAnnotationValue(t *testing.T) {
+	testCases := []struct {
+		current  string
+		admitted bool
+		expected string
+	}{
+		{"", true, "default"},
+		{"", false, ""},
+		{"default", true, "default"},
+		{"default", false, ""},
+		{"sharded", true, "default,sharded"},
+		{"default,sharded", false, "sharded"},
+		{"sharded, default", false, "sharded"},
+	}
+	for _, tc := range testCases {
+		if actual := routeAdmittedByAnnotationValue(tc.current, "default", tc.admitted); actual != tc.expected {
+			t.Errorf("expected %q with current value %q and admitted %t, got %q", tc.expected, tc.current, tc.admitted, actual)
+		}
+	}
+}
+
+// TestSyncRouteAdmittedByAnnotations verifies that the operator adds the
+// ingresscontroller's name to the admitted-by annotation of routes that it
+// has admitted when the annotateAdmittedRoutes override is set, and removes it
+// when the route is no longer admitted, when the override is removed, and when
+// the ingresscontroller is deleted.
+func TestSyncRouteAdmittedByAnnotations(t *testing.T) {
+	admittedBy := func(condition corev1.ConditionStatus, routerNames ...string) routev1.RouteStatus {
+		var status routev1.RouteStatus
+		for _, name := range routerNames {
+			status.Ingress = append(status.Ingress, routev1.RouteIngress{
+				RouterName: name,
+				Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: condition}},
+			})
+		}
+		return status
+	}
+	route := func(name, annotation string, status routev1.RouteStatus) *routev1.Route {
+		r := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: name},
+			Status:     status,
+		}
+		if len(annotation) != 0 {
+			r.Annotations = map[string]string{manifests.RouteAdmittedByAnnotation: annotation}
+		}
+		return r
+	}
+	s := runtime.NewScheme()
+	if err := routev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		route("admitted", "", admittedBy(corev1.ConditionTrue, "default")),
+		route("admitted-by-both", "sharded", admittedBy(corev1.ConditionTrue, "sharded", "default")),
+		route("rejected", "", admittedBy(corev1.ConditionFalse, "default")),
+		route("no-longer-admitted", "default,sharded", admittedBy(corev1.ConditionTrue, "sharded")),
+		route("other", "", admittedBy(corev1.ConditionTrue, "sharded")),
+	).Build()
+	counting := &listCountingClient{Client: cl}
+	r := &reconciler{client: counting, routeCache: &clientCache{client: cl}}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"annotateAdmittedRoutes":true}`)},
+		},
+	}
+	checkAnnotations := func(t *testing.T, expected map[string]string) {
+		t.Helper()
+		for name, value := range expected {
+			route := &routev1.Route{}
+			if err := cl.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: name}, route); err != nil {
+				t.Fatalf("failed to get route %s: %v", name, err)
+			}
+			actual, ok := route.Annotations[manifests.RouteAdmittedByAnnotation]
+			switch {
+			case len(value) == 0 && ok:
+				t.Errorf("expected route %s not to have the admitted-by annotation, got %q", name, actual)
+			case actual != value:
+				t.Errorf("expected route %s to have admitted-by annotation %q, got %q", name, value, actual)
+			}
+		}
+	}
+
+	if errs := r.syncRouteAdmittedByAnnotations(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	checkAnnotations(t, map[string]string{
+		"admitted":           "default",
+		"admitted-by-both":   "default,sharded",
+		"rejected":           "",
+		"no-longer-admitted": "sharded",
+		"other":              "",
+	})
+
+	// Removing the override removes the ingresscontroller's name.
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{}
+	if errs := r.syncRouteAdmittedByAnnotations(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	checkAnnotations(t, map[string]string{
+		"admitted":         "",
+		"admitted-by-both": "sharded",
+	})
+
+	// Without the override, routes are not listed once no route's
+	// annotation lists the ingresscontroller's name.
+	counting.lists = 0
+	if errs := r.syncRouteAdmittedByAnnotations(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if counting.lists != 0 {
+		t.Errorf("expected routes not to be listed, got %d lists", counting.lists)
+	}
+
+	// Deleting the ingresscontroller removes its name.
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"annotateAdmittedRoutes":true}`)}
+	if errs := r.syncRouteAdmittedByAnnotations(ic); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	checkAnnotations(t, map[string]string{"admitted": "default"})
+	if errs := r.clearAllRoutesStatusForIngressController(ic.Name); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	checkAnnotations(t, map[string]string{
+		"admitted":         "",
+		"admitted-by-both": "sharded",
+	})
+}
+
+// TestRouteSourceEnqueuesIngressController verifies that an event for a route
+// in a user's namespace enqueues a reconcile request for an ingresscontroller
+// that has admitted the route.
+func TestRouteSourceEnqueuesIngressController(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := routev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := operatorv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"annotateAdmittedRoutes":true}`)},
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()
+	r := &reconciler{
+		config: Config{Namespace: "openshift-ingress-operator"},
+		cache:  &clientCache{client: cl},
+	}
+	routeCache := &informertest.FakeInformers{Scheme: s}
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	src := newRouteSource(routeCache)
+	if err := src.Start(ctx, handler.EnqueueRequestsFromMapFunc(r.routeToIngressControllers), queue, routePredicate); err != nil {
+		t.Fatalf("failed to start route source: %v", err)
+	}
+	if err := src.(source.SyncingSource).WaitForSync(ctx); err != nil {
+		t.Fatalf("failed to sync route source: %v", err)
+	}
+	informer, err := routeCache.FakeInformerFor(&routev1.Route{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unadmitted := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: "user-namespace", Name: "app"}}
+	admitted := unadmitted.DeepCopy()
+	admitted.Status.Ingress = []routev1.RouteIngress{{RouterName: ic.Name}}
+	informer.Update(unadmitted, admitted)
+
+	if queue.Len() != 1 {
+		t.Fatalf("expected 1 reconcile request, got %d", queue.Len())
+	}
+	item, _ := queue.Get()
+	expected := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}}
+	if item != expected {
+		t.Errorf("expected reconcile request %v, got %v", expected, item)
+	}
+}
@@ -22,9 +22,33 @@ const (
 $SystemLogSocketName /var/lib/rsyslog/rsyslog.sock
 $ModLoad omstdout.so
 *.* :omstdout:
+`
+
+	// rsyslogRateLimitedConfigurationFormat is the format for the contents
+	// for rsyslog.conf when access logs are rate limited.  The format
+	// takes the maximum number of messages per second.
+	rsyslogRateLimitedConfigurationFormat = `$ModLoad imuxsock
+$SystemLogRateLimitInterval 1
+$SystemLogRateLimitBurst %d
+$SystemLogSocketName /var/lib/rsyslog/rsyslog.sock
+$ModLoad omstdout.so
+*.* :omstdout:
 `
 )
 
+// rsyslogConfigurationForIngressController returns the contents for
+// rsyslog.conf for the given ingresscontroller.
+func rsyslogConfigurationForIngressController(ic *operatorv1.IngressController) (string, error) {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		return "", err
+	}
+	if overrides.AccessLogRateLimit > 0 {
+		return fmt.Sprintf(rsyslogRateLimitedConfigurationFormat, overrides.AccessLogRateLimit), nil
+	}
+	return rsyslogConfiguration, nil
+}
+
 // ensureRsyslogConfigMap ensures the rsyslog configmap exists for a given
 // ingresscontroller if the access logging is enabled.  Returns a Boolean
 // indicating whether the configmap exists, the configmap if it does exist, and
@@ -78,6 +102,11 @@ func desiredRsyslogConfigMap(ic *operatorv1.IngressController, deploymentRef met
 		return false, nil, nil
 	}
 
+	configuration, err := rsyslogConfigurationForIngressController(ic)
+	if err != nil {
+		return false, nil, err
+	}
+
 	name := controller.RsyslogConfigMapName(ic)
 	cm := corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -85,7 +114,7 @@ func desiredRsyslogConfigMap(ic *operatorv1.IngressController, deploymentRef met
 			Namespace: name.Namespace,
 		},
 		Data: map[string]string{
-			"rsyslog.conf": rsyslogConfiguration,
+			"rsyslog.conf": configuration,
 		},
 	}
 	cm.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
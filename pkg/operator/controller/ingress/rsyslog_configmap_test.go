@@ -0,0 +1,109 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestDesiredRsyslogConfigMap verifies that desiredRsyslogConfigMap returns a
+// configmap only when access logs are sent to a sidecar and configures rate
+// limiting only when the accessLogRateLimit unsupported config override is
+// set.
+func TestDesiredRsyslogConfigMap(t *testing.T) {
+	containerLogging := &operatorv1.IngressControllerLogging{
+		Access: &operatorv1.AccessLogging{
+			Destination: operatorv1.LoggingDestination{
+				Type:      operatorv1.ContainerLoggingDestinationType,
+				Container: &operatorv1.ContainerLoggingDestinationParameters{},
+			},
+		},
+	}
+	syslogLogging := &operatorv1.IngressControllerLogging{
+		Access: &operatorv1.AccessLogging{
+			Destination: operatorv1.LoggingDestination{
+				Type: operatorv1.SyslogLoggingDestinationType,
+				Syslog: &operatorv1.SyslogLoggingDestinationParameters{
+					Address: "1.2.3.4",
+					Port:    514,
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		description     string
+		logging         *operatorv1.IngressControllerLogging
+		overrides       string
+		expectConfigMap bool
+		expectConfig    []string
+		unexpectConfig  []string
+	}{
+		{
+			description:     "no access logging",
+			logging:         nil,
+			overrides:       `{"accessLogRateLimit":100}`,
+			expectConfigMap: false,
+		},
+		{
+			description:     "syslog access logging with rate limit",
+			logging:         syslogLogging,
+			overrides:       `{"accessLogRateLimit":100}`,
+			expectConfigMap: false,
+		},
+		{
+			description:     "container access logging without rate limit",
+			logging:         containerLogging,
+			overrides:       "",
+			expectConfigMap: true,
+			expectConfig:    []string{"$SystemLogSocketName /var/lib/rsyslog/rsyslog.sock"},
+			unexpectConfig:  []string{"$SystemLogRateLimitInterval", "$SystemLogRateLimitBurst"},
+		},
+		{
+			description:     "container access logging with rate limit",
+			logging:         containerLogging,
+			overrides:       `{"accessLogRateLimit":100}`,
+			expectConfigMap: true,
+			expectConfig: []string{
+				"$SystemLogRateLimitInterval 1\n",
+				"$SystemLogRateLimitBurst 100\n",
+				"$SystemLogSocketName /var/lib/rsyslog/rsyslog.sock",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec: operatorv1.IngressControllerSpec{
+					Logging:                    tc.logging,
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+				},
+			}
+			want, cm, err := desiredRsyslogConfigMap(ic, metav1.OwnerReference{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want != tc.expectConfigMap {
+				t.Fatalf("expected desiredRsyslogConfigMap to return %t, got %t", tc.expectConfigMap, want)
+			}
+			if !want {
+				return
+			}
+			config := cm.Data["rsyslog.conf"]
+			for _, s := range tc.expectConfig {
+				if !strings.Contains(config, s) {
+					t.Errorf("expected rsyslog.conf to contain %q, got:\n%s", s, config)
+				}
+			}
+			for _, s := range tc.unexpectConfig {
+				if strings.Contains(config, s) {
+					t.Errorf("expected rsyslog.conf not to contain %q, got:\n%s", s, config)
+				}
+			}
+		})
+	}
+}
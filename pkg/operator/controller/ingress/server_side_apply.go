@@ -0,0 +1,270 @@
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// defaultFieldManager is the field manager name that the operator uses for
+// server-side apply if none is configured.  It is also the field manager name
+// that the API server infers for updates from older releases of the operator,
+// which did not use server-side apply.
+const defaultFieldManager = "ingress-operator"
+
+// applyConfigurationMetadataFields is the set of metadata fields that the
+// operator specifies when it applies an object.  Other metadata fields are
+// set by the API or by other actors.
+var applyConfigurationMetadataFields = sets.NewString(
+	"name",
+	"namespace",
+	"labels",
+	"annotations",
+	"ownerReferences",
+	"finalizers",
+)
+
+// conflictingManagerRE matches the field manager name in the message of a
+// server-side apply conflict cause, such as `conflict with "kubectl-edit"
+// using apps/v1`.
+var conflictingManagerRE = regexp.MustCompile(`conflict with "([^"]*)"`)
+
+// fieldManager returns the field manager name that the operator uses for
+// server-side apply.
+func (r *reconciler) fieldManager() string {
+	if len(r.config.FieldManager) != 0 {
+		return r.config.FieldManager
+	}
+	return defaultFieldManager
+}
+
+// applyObject creates or updates the given object using server-side apply
+// with the operator's field manager.  The object must be built from the
+// desired state and specify only the fields that the operator manages: the
+// operator gives up any field that it applied before and that the object now
+// omits, and it never claims fields that the object omits, so fields that
+// other actors set are left alone.
+//
+// The operator owns the fields that it applies, so if another field manager
+// has changed any of them, for example using "oc edit" or "oc set env", the
+// apply is retried with forced ownership, which reverts the change.  The
+// conflict is logged so that it is clear why the change did not stick.
+func (r *reconciler) applyObject(obj crclient.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.client.Scheme())
+	if err != nil {
+		return fmt.Errorf("failed to determine kind of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	applyConfig, err := applyConfiguration(obj, gvk)
+	if err != nil {
+		return fmt.Errorf("failed to build apply configuration for %s %s/%s: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	fieldOwner := crclient.FieldOwner(r.fieldManager())
+	err = r.client.Patch(context.TODO(), applyConfig, crclient.Apply, fieldOwner)
+	if err != nil && errors.IsConflict(err) {
+		managers := conflictingFieldManagers(err)
+		if len(managers) == 0 {
+			return err
+		}
+		log.Info("server-side apply conflicted with changes that other field managers made to fields that the operator manages; forcing ownership", "kind", gvk.Kind, "namespace", obj.GetNamespace(), "name", obj.GetName(), "fieldManager", fieldOwner, "conflictingFieldManagers", managers)
+		err = r.client.Patch(context.TODO(), applyConfig, crclient.Apply, fieldOwner, crclient.ForceOwnership)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Return the object that the API server returned to the caller.
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applyConfig.UnstructuredContent(), obj)
+}
+
+// applyUpdate updates the given current object to the given desired object
+// using server-side apply.  Before the first apply, the fields that earlier
+// releases of the operator set with updates are moved to the operator's
+// server-side apply field manager so that the apply removes the ones that the
+// desired object no longer specifies.
+func (r *reconciler) applyUpdate(current, desired crclient.Object) error {
+	if err := r.upgradeManagedFields(current); err != nil {
+		return err
+	}
+	return r.applyObject(desired)
+}
+
+// upgradeManagedFields moves the ownership of the fields that the operator
+// owns as the manager of update operations, which earlier releases of the
+// operator used, to the operator's server-side apply field manager.  Server-
+// side apply only removes fields that the applying field manager owns, so
+// without this, fields that an earlier release of the operator set and that
+// the operator no longer specifies would never be removed.  This is the
+// approach of k8s.io/client-go/util/csaupgrade, which is not available in the
+// version of client-go that the operator uses.
+//
+// The managed fields of the given object are rewritten at most once: the
+// update operation entries are removed, so later calls do nothing.
+func (r *reconciler) upgradeManagedFields(obj crclient.Object) error {
+	managedFields, changed, err := upgradedManagedFields(obj.GetManagedFields(), r.fieldManager(), sets.NewString(defaultFieldManager, r.fieldManager()))
+	if err != nil {
+		return fmt.Errorf("failed to upgrade managed fields of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	if !changed {
+		return nil
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": obj.GetResourceVersion()},
+		{"op": "replace", "path": "/metadata/managedFields", "value": managedFields},
+	})
+	if err != nil {
+		return err
+	}
+	if err := r.client.Patch(context.TODO(), obj, crclient.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return fmt.Errorf("failed to upgrade managed fields of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	log.Info("moved fields that an earlier release of the operator set to the server-side apply field manager", "namespace", obj.GetNamespace(), "name", obj.GetName(), "fieldManager", r.fieldManager())
+	return nil
+}
+
+// upgradedManagedFields returns the given managed fields with the fields of
+// the update operation entries of the given update managers merged into the
+// apply operation entry of the given apply manager, and a Boolean value
+// indicating whether anything changed.  Entries for subresources, such as
+// status, are left alone.
+func upgradedManagedFields(managedFields []metav1.ManagedFieldsEntry, applyManager string, updateManagers sets.String) ([]metav1.ManagedFieldsEntry, bool, error) {
+	applyIndex := -1
+	var updateIndexes []int
+	for i, entry := range managedFields {
+		if len(entry.Subresource) != 0 {
+			continue
+		}
+		switch {
+		case entry.Operation == metav1.ManagedFieldsOperationApply && entry.Manager == applyManager:
+			applyIndex = i
+		case entry.Operation == metav1.ManagedFieldsOperationUpdate && updateManagers.Has(entry.Manager):
+			updateIndexes = append(updateIndexes, i)
+		}
+	}
+	if len(updateIndexes) == 0 {
+		return managedFields, false, nil
+	}
+
+	fields := &fieldpath.Set{}
+	var applyEntry metav1.ManagedFieldsEntry
+	if applyIndex >= 0 {
+		applyEntry = managedFields[applyIndex]
+	} else {
+		// Turn the first update entry into the apply entry.
+		applyEntry = managedFields[updateIndexes[0]]
+		applyEntry.Manager = applyManager
+		applyEntry.Operation = metav1.ManagedFieldsOperationApply
+	}
+	for _, i := range append([]int{applyIndex}, updateIndexes...) {
+		if i < 0 || managedFields[i].FieldsV1 == nil {
+			continue
+		}
+		entryFields := &fieldpath.Set{}
+		if err := entryFields.FromJSON(bytes.NewReader(managedFields[i].FieldsV1.Raw)); err != nil {
+			return nil, false, err
+		}
+		fields = fields.Union(entryFields)
+	}
+	raw, err := fields.ToJSON()
+	if err != nil {
+		return nil, false, err
+	}
+	applyEntry.FieldsType = "FieldsV1"
+	applyEntry.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+
+	// Replace the apply entry, or the first update entry if there is no
+	// apply entry, with the merged apply entry, and drop the update entries,
+	// which the apply entry now covers.
+	replaceIndex := applyIndex
+	if replaceIndex < 0 {
+		replaceIndex = updateIndexes[0]
+	}
+	dropIndexes := sets.NewInt(updateIndexes...)
+	upgraded := []metav1.ManagedFieldsEntry{}
+	for i, entry := range managedFields {
+		switch {
+		case i == replaceIndex:
+			upgraded = append(upgraded, applyEntry)
+		case !dropIndexes.Has(i):
+			upgraded = append(upgraded, entry)
+		}
+	}
+	return upgraded, true, nil
+}
+
+// applyConfiguration converts the given object to an apply configuration that
+// has only the object's kind, identifying metadata, and spec.  Null values,
+// which result from unset fields of the typed object, are pruned so that the
+// operator does not claim fields that it does not set.
+func applyConfiguration(obj crclient.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for field := range metadata {
+			if !applyConfigurationMetadataFields.Has(field) {
+				delete(metadata, field)
+			}
+		}
+	}
+	pruneNullValues(content)
+
+	applyConfig := &unstructured.Unstructured{Object: content}
+	applyConfig.SetGroupVersionKind(gvk)
+	return applyConfig, nil
+}
+
+// pruneNullValues recursively deletes fields with null values from the given
+// value.
+func pruneNullValues(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field == nil {
+				delete(v, key)
+				continue
+			}
+			pruneNullValues(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			pruneNullValues(item)
+		}
+	}
+}
+
+// conflictingFieldManagers returns the names of the field managers with which
+// the given server-side apply error conflicts.
+func conflictingFieldManagers(err error) []string {
+	status, ok := err.(errors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+	managers := sets.NewString()
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		if match := conflictingManagerRE.FindStringSubmatch(cause.Message); match != nil {
+			managers.Insert(match[1])
+		}
+	}
+	return managers.List()
+}
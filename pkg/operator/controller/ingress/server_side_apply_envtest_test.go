@@ -0,0 +1,209 @@
+package ingress
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"k8s.io/client-go/kubernetes/scheme"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// newTestAPIServerClient starts an API server using envtest and returns a
+// client for it.  Server-side apply is implemented by the API server, so tests
+// of how the operator's field ownership interacts with other field managers
+// need a real one.  The test is skipped unless KUBEBUILDER_ASSETS is set to the
+// directory with the kube-apiserver and etcd binaries, for example using
+// setup-envtest.
+func newTestAPIServerClient(t *testing.T) crclient.Client {
+	t.Helper()
+
+	if len(os.Getenv("KUBEBUILDER_ASSETS")) == 0 {
+		t.Skip("KUBEBUILDER_ASSETS is not set; skipping test that needs an API server")
+	}
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start API server: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("failed to stop API server: %v", err)
+		}
+	})
+	cl, err := crclient.New(cfg, crclient.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}}
+	if err := cl.Create(context.Background(), ns); err != nil {
+		t.Fatalf("failed to create namespace %s: %v", ns.Name, err)
+	}
+	return cl
+}
+
+// TestApplyUpdateWithAPIServer verifies against an API server that applyUpdate
+// takes over the deployment that an earlier release of the operator created
+// with an update: fields that the operator no longer specifies are removed,
+// fields of the operator's that another field manager changed are reverted, and
+// fields that only another field manager set are kept.
+func TestApplyUpdateWithAPIServer(t *testing.T) {
+	cl := newTestAPIServerClient(t)
+	r := &reconciler{client: cl}
+	name := types.NamespacedName{Namespace: "openshift-ingress", Name: "router-default"}
+
+	deployment := func(annotations map[string]string, env ...corev1.EnvVar) *appsv1.Deployment {
+		labels := map[string]string{"app": "router"}
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   name.Namespace,
+				Name:        name.Name,
+				Annotations: annotations,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "router",
+							Image: "router:v1",
+							Env:   env,
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	// Create the deployment the way that an earlier release of the
+	// operator did, with an annotation and an environment variable that
+	// the operator no longer sets.
+	old := deployment(map[string]string{"example.com/removed": "true"},
+		corev1.EnvVar{Name: "ROUTER_KEPT", Value: "1"},
+		corev1.EnvVar{Name: "ROUTER_REMOVED", Value: "1"},
+	)
+	if err := cl.Create(context.Background(), old, crclient.FieldOwner(defaultFieldManager)); err != nil {
+		t.Fatalf("failed to create deployment: %v", err)
+	}
+
+	// Have another field manager change one of the operator's fields and
+	// add a label of its own, as "oc edit" would.
+	edited := &appsv1.Deployment{}
+	if err := cl.Get(context.Background(), name, edited); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	edited.Labels = map[string]string{"example.com/edited": "true"}
+	edited.Spec.Template.Spec.Containers[0].Env[0].Value = "2"
+	if err := cl.Update(context.Background(), edited, crclient.FieldOwner("kubectl-edit")); err != nil {
+		t.Fatalf("failed to update deployment: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		current := &appsv1.Deployment{}
+		if err := cl.Get(context.Background(), name, current); err != nil {
+			t.Fatalf("failed to get deployment: %v", err)
+		}
+		desired := deployment(nil, corev1.EnvVar{Name: "ROUTER_KEPT", Value: "1"})
+		if err := r.applyUpdate(current, desired); err != nil {
+			t.Fatalf("apply %d failed: %v", i, err)
+		}
+	}
+
+	actual := &appsv1.Deployment{}
+	if err := cl.Get(context.Background(), name, actual); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if _, ok := actual.Annotations["example.com/removed"]; ok {
+		t.Error("expected the annotation that the operator no longer sets to be removed")
+	}
+	if actual.Labels["example.com/edited"] != "true" {
+		t.Error("expected the label of the other field manager to be kept")
+	}
+	env := actual.Spec.Template.Spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "ROUTER_KEPT" || env[0].Value != "1" {
+		t.Errorf("expected only ROUTER_KEPT=1 in the environment, got %v", env)
+	}
+	for _, entry := range actual.ManagedFields {
+		if entry.Manager == defaultFieldManager && entry.Operation == metav1.ManagedFieldsOperationUpdate {
+			t.Errorf("expected the operator's update entry to be upgraded, got %+v", entry)
+		}
+	}
+}
+
+// TestEnsureAdoptedLoadBalancerServiceWithAPIServer verifies against an API
+// server that ensureAdoptedLoadBalancerService reconciles the ports and
+// selector of an adopted service that the tool that created it owns, and that
+// it leaves the tool's other fields alone.
+func TestEnsureAdoptedLoadBalancerServiceWithAPIServer(t *testing.T) {
+	cl := newTestAPIServerClient(t)
+	r := &reconciler{client: cl}
+	name := types.NamespacedName{Namespace: "openshift-ingress", Name: "preprovisioned-lb"}
+
+	existing := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   name.Namespace,
+			Name:        name.Name,
+			Annotations: map[string]string{"example.com/provisioned-by": "other-tool"},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{"app": "other"},
+			Ports: []corev1.ServicePort{{
+				Name:       "https",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       8443,
+				TargetPort: intstr.FromInt(8443),
+			}},
+		},
+	}
+	if err := cl.Create(context.Background(), existing, crclient.FieldOwner("other-tool")); err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	selector := map[string]string{"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "default"}
+	ports := []corev1.ServicePort{
+		{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("http")},
+		{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromString("https")},
+	}
+	desired := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: selector,
+			Ports:    ports,
+		},
+	}
+	if _, _, err := r.ensureAdoptedLoadBalancerService(name, desired); err != nil {
+		t.Fatalf("failed to reconcile adopted service: %v", err)
+	}
+
+	actual := &corev1.Service{}
+	if err := cl.Get(context.Background(), name, actual); err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if !reflect.DeepEqual(actual.Spec.Selector, selector) {
+		t.Errorf("expected selector %v, got %v", selector, actual.Spec.Selector)
+	}
+	if len(actual.Spec.Ports) != len(ports) {
+		t.Fatalf("expected ports %v, got %v", ports, actual.Spec.Ports)
+	}
+	for i := range ports {
+		if actual.Spec.Ports[i].Name != ports[i].Name || actual.Spec.Ports[i].Port != ports[i].Port {
+			t.Errorf("expected port %d to be %s/%d, got %s/%d", i, ports[i].Name, ports[i].Port, actual.Spec.Ports[i].Name, actual.Spec.Ports[i].Port)
+		}
+	}
+	if actual.Annotations["example.com/provisioned-by"] != "other-tool" {
+		t.Error("expected the annotation of the tool that created the service to be kept")
+	}
+}
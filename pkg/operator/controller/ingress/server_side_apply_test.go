@@ -0,0 +1,419 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/client-go/kubernetes/scheme"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordedPatch is a patch request that applyRecordingClient has received.
+type recordedPatch struct {
+	patchType types.PatchType
+	options   crclient.PatchOptions
+	// content is the apply configuration as it was sent.
+	content map[string]interface{}
+	// object is the apply configuration converted to its typed object.
+	object crclient.Object
+}
+
+// applyRecordingClient is a client that records patch requests instead of
+// sending them.  It rejects unforced patches with an apply conflict with the
+// field manager conflictManager while conflicts is positive, and it rejects
+// any other patches with err if err is non-nil.
+type applyRecordingClient struct {
+	crclient.Client
+
+	conflicts       int
+	conflictManager string
+	err             error
+	patches         []recordedPatch
+}
+
+func (c *applyRecordingClient) Patch(_ context.Context, obj crclient.Object, patch crclient.Patch, opts ...crclient.PatchOption) error {
+	options := crclient.PatchOptions{}
+	options.ApplyOptions(opts)
+	recorded := recordedPatch{
+		patchType: patch.Type(),
+		options:   options,
+		object:    obj.DeepCopyObject().(crclient.Object),
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		recorded.content = u.DeepCopy().UnstructuredContent()
+		typed, err := c.Scheme().New(u.GroupVersionKind())
+		if err != nil {
+			return err
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(recorded.content, typed); err != nil {
+			return err
+		}
+		typed.GetObjectKind().SetGroupVersionKind(u.GroupVersionKind())
+		recorded.object = typed.(crclient.Object)
+	}
+	c.patches = append(c.patches, recorded)
+	if c.conflicts > 0 && (options.Force == nil || !*options.Force) {
+		c.conflicts--
+		manager := c.conflictManager
+		if len(manager) == 0 {
+			manager = "kubectl-edit"
+		}
+		return errors.NewApplyConflict([]metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: fmt.Sprintf("conflict with %q using apps/v1", manager),
+			Field:   ".spec.replicas",
+		}}, "Apply failed with 1 conflict")
+	}
+	return c.err
+}
+
+// TestApplyObject verifies that applyObject uses server-side apply with the
+// configured field manager and forces ownership of the operator's fields after
+// a conflict with another field manager.
+func TestApplyObject(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "openshift-ingress",
+			Name:            "router-default",
+			ResourceVersion: "42",
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:   "kubectl-edit",
+				Operation: metav1.ManagedFieldsOperationUpdate,
+			}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-default",
+		},
+	}
+	otherErr := fmt.Errorf("connection refused")
+	testCases := []struct {
+		description     string
+		object          crclient.Object
+		fieldManager    string
+		conflicts       int
+		conflictManager string
+		err             error
+		expectKind      string
+		expectManager   string
+		expectForced    []bool
+		expectErr       bool
+		expectConflict  bool
+	}{
+		{
+			description:   "deployment with default field manager",
+			object:        deployment,
+			expectKind:    "Deployment",
+			expectManager: "ingress-operator",
+			expectForced:  []bool{false},
+		},
+		{
+			description:   "service with custom field manager",
+			object:        service,
+			fieldManager:  "custom-manager",
+			expectKind:    "Service",
+			expectManager: "custom-manager",
+			expectForced:  []bool{false},
+		},
+		{
+			description:     "conflict with an earlier release of the operator is resolved by forcing ownership",
+			object:          deployment,
+			conflicts:       1,
+			conflictManager: "ingress-operator",
+			expectKind:      "Deployment",
+			expectManager:   "ingress-operator",
+			expectForced:    []bool{false, true},
+		},
+		{
+			description:     "conflict with the default field manager is forced when a custom one is configured",
+			object:          deployment,
+			fieldManager:    "custom-manager",
+			conflicts:       1,
+			conflictManager: "ingress-operator",
+			expectKind:      "Deployment",
+			expectManager:   "custom-manager",
+			expectForced:    []bool{false, true},
+		},
+		{
+			description:   "conflict with another field manager is resolved by forcing ownership",
+			object:        deployment,
+			conflicts:     1,
+			expectKind:    "Deployment",
+			expectManager: "ingress-operator",
+			expectForced:  []bool{false, true},
+		},
+		{
+			description:     "conflict that persists is returned",
+			object:          deployment,
+			conflicts:       1,
+			conflictManager: "ingress-operator",
+			err:             errors.NewConflict(appsv1.Resource("deployments"), "router-default", fmt.Errorf("still conflicting")),
+			expectKind:      "Deployment",
+			expectManager:   "ingress-operator",
+			expectForced:    []bool{false, true},
+			expectErr:       true,
+			expectConflict:  true,
+		},
+		{
+			description:   "other errors are returned without retrying",
+			object:        service,
+			err:           otherErr,
+			expectKind:    "Service",
+			expectManager: "ingress-operator",
+			expectForced:  []bool{false},
+			expectErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cl := &applyRecordingClient{
+				Client:          fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+				conflicts:       tc.conflicts,
+				conflictManager: tc.conflictManager,
+				err:             tc.err,
+			}
+			r := &reconciler{
+				config: Config{FieldManager: tc.fieldManager},
+				client: cl,
+			}
+			err := r.applyObject(tc.object.DeepCopyObject().(crclient.Object))
+			switch {
+			case tc.expectErr && err == nil:
+				t.Fatal("expected an error, got nil")
+			case !tc.expectErr && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tc.expectErr && errors.IsConflict(err) != tc.expectConflict:
+				t.Fatalf("expected conflict error to be %t, got %v", tc.expectConflict, err)
+			}
+			if len(cl.patches) != len(tc.expectForced) {
+				t.Fatalf("expected %d patches, got %d", len(tc.expectForced), len(cl.patches))
+			}
+			for i, patch := range cl.patches {
+				if patch.patchType != types.ApplyPatchType {
+					t.Errorf("patch %d: expected patch type %q, got %q", i, types.ApplyPatchType, patch.patchType)
+				}
+				if patch.options.FieldManager != tc.expectManager {
+					t.Errorf("patch %d: expected field manager %q, got %q", i, tc.expectManager, patch.options.FieldManager)
+				}
+				forced := patch.options.Force != nil && *patch.options.Force
+				if forced != tc.expectForced[i] {
+					t.Errorf("patch %d: expected force to be %t, got %t", i, tc.expectForced[i], forced)
+				}
+				if kind := patch.object.GetObjectKind().GroupVersionKind().Kind; kind != tc.expectKind {
+					t.Errorf("patch %d: expected kind %q, got %q", i, tc.expectKind, kind)
+				}
+				if len(patch.object.GetResourceVersion()) != 0 {
+					t.Errorf("patch %d: expected empty resource version, got %q", i, patch.object.GetResourceVersion())
+				}
+				if len(patch.object.GetManagedFields()) != 0 {
+					t.Errorf("patch %d: expected no managed fields, got %v", i, patch.object.GetManagedFields())
+				}
+			}
+		})
+	}
+}
+
+// TestUpdateRouterDeploymentPreservesFieldsOfOtherManagers verifies that
+// updateRouterDeployment applies only the fields of the desired deployment so
+// that fields that another field manager set on the current deployment, and
+// that the operator does not manage, are left to that field manager.
+func TestUpdateRouterDeploymentPreservesFieldsOfOtherManagers(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, _ := getRouterDeploymentComponents(t)
+	desired, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a deployment that has an older image and on which another
+	// field manager has set an annotation and the revision history limit.
+	revisionHistoryLimit := int32(3)
+	current := desired.DeepCopy()
+	current.ResourceVersion = "42"
+	current.Annotations = map[string]string{"example.com/owner": "other"}
+	current.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+	current.Spec.Template.Spec.Containers[0].Image = "older-image"
+	current.ManagedFields = []metav1.ManagedFieldsEntry{{
+		Manager:   "other-manager",
+		Operation: metav1.ManagedFieldsOperationUpdate,
+	}}
+
+	cl := &applyRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(current.DeepCopy()).Build()}
+	r := &reconciler{client: cl}
+	updated, err := r.updateRouterDeployment(ic, current, desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated {
+		t.Fatal("expected the deployment to be updated")
+	}
+	if len(cl.patches) != 1 {
+		t.Fatalf("expected 1 patch, got %d", len(cl.patches))
+	}
+	patch := cl.patches[0]
+	if patch.options.Force != nil && *patch.options.Force {
+		t.Error("expected the apply not to force ownership")
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(patch.content, "metadata", "annotations", "example.com/owner"); found {
+		t.Error("expected the apply configuration not to specify the other field manager's annotation")
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(patch.content, "spec", "revisionHistoryLimit"); found {
+		t.Error("expected the apply configuration not to specify the revision history limit")
+	}
+	for _, field := range []string{"resourceVersion", "managedFields", "creationTimestamp"} {
+		if _, found, _ := unstructured.NestedFieldNoCopy(patch.content, "metadata", field); found {
+			t.Errorf("expected the apply configuration not to specify metadata.%s", field)
+		}
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(patch.content, "status"); found {
+		t.Error("expected the apply configuration not to specify status")
+	}
+	if image := patch.object.(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image; image != ingressControllerImage {
+		t.Errorf("expected the apply configuration to specify image %q, got %q", ingressControllerImage, image)
+	}
+}
+
+// TestUpgradedManagedFields verifies that upgradedManagedFields merges the
+// fields of the operator's update operation entries into its apply operation
+// entry and leaves the entries of other field managers alone.
+func TestUpgradedManagedFields(t *testing.T) {
+	fields := func(raw string) *metav1.FieldsV1 { return &metav1.FieldsV1{Raw: []byte(raw)} }
+	entry := func(manager string, operation metav1.ManagedFieldsOperationType, subresource, raw string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:     manager,
+			Operation:   operation,
+			APIVersion:  "apps/v1",
+			FieldsType:  "FieldsV1",
+			FieldsV1:    fields(raw),
+			Subresource: subresource,
+		}
+	}
+	const (
+		envFields      = `{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"router\"}":{"f:env":{}}}}}}}`
+		replicasFields = `{"f:spec":{"f:replicas":{}}}`
+		mergedFields   = `{"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"router\"}":{"f:env":{}}}}}}}`
+		labelFields    = `{"f:metadata":{"f:labels":{"f:example":{}}}}`
+		statusFields   = `{"f:status":{"f:replicas":{}}}`
+	)
+	update := metav1.ManagedFieldsOperationUpdate
+	apply := metav1.ManagedFieldsOperationApply
+	testCases := []struct {
+		description   string
+		managedFields []metav1.ManagedFieldsEntry
+		expectChanged bool
+		expect        []metav1.ManagedFieldsEntry
+	}{
+		{
+			description: "no update entries",
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("ingress-operator", apply, "", envFields),
+				entry("kubectl-edit", update, "", labelFields),
+			},
+			expectChanged: false,
+		},
+		{
+			description: "update entry is converted to an apply entry",
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl-edit", update, "", labelFields),
+				entry("ingress-operator", update, "", envFields),
+				entry("ingress-operator", update, "status", statusFields),
+			},
+			expectChanged: true,
+			expect: []metav1.ManagedFieldsEntry{
+				entry("kubectl-edit", update, "", labelFields),
+				entry("ingress-operator", apply, "", envFields),
+				entry("ingress-operator", update, "status", statusFields),
+			},
+		},
+		{
+			description: "update entry is merged into the existing apply entry",
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("ingress-operator", update, "", envFields),
+				entry("kubectl-edit", update, "", labelFields),
+				entry("ingress-operator", apply, "", replicasFields),
+			},
+			expectChanged: true,
+			expect: []metav1.ManagedFieldsEntry{
+				entry("kubectl-edit", update, "", labelFields),
+				entry("ingress-operator", apply, "", mergedFields),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, changed, err := upgradedManagedFields(tc.managedFields, "ingress-operator", sets.NewString("ingress-operator"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if changed != tc.expectChanged {
+				t.Fatalf("expected changed to be %t, got %t", tc.expectChanged, changed)
+			}
+			if !changed {
+				return
+			}
+			if !reflect.DeepEqual(actual, tc.expect) {
+				t.Errorf("expected managed fields:\n%s\ngot:\n%s", managedFieldsString(tc.expect), managedFieldsString(actual))
+			}
+		})
+	}
+}
+
+// managedFieldsString returns a readable representation of the given managed
+// fields for test failure messages.
+func managedFieldsString(managedFields []metav1.ManagedFieldsEntry) string {
+	var lines []string
+	for _, entry := range managedFields {
+		lines = append(lines, fmt.Sprintf("%s %s %q %s", entry.Manager, entry.Operation, entry.Subresource, string(entry.FieldsV1.Raw)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestApplyUpdateUpgradesManagedFields verifies that applyUpdate moves the
+// fields that an earlier release of the operator set with an update to the
+// operator's apply field manager before it applies the desired object.
+func TestApplyUpdateUpgradesManagedFields(t *testing.T) {
+	current := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "openshift-ingress",
+			Name:            "router-default",
+			ResourceVersion: "42",
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:    "ingress-operator",
+				Operation:  metav1.ManagedFieldsOperationUpdate,
+				APIVersion: "apps/v1",
+				FieldsType: "FieldsV1",
+				FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+			}},
+		},
+	}
+	cl := &applyRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(current.DeepCopy()).Build()}
+	r := &reconciler{client: cl}
+	if err := r.applyUpdate(current.DeepCopy(), current.DeepCopy()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cl.patches) != 2 {
+		t.Fatalf("expected 2 patches, got %d", len(cl.patches))
+	}
+	if cl.patches[0].patchType != types.JSONPatchType {
+		t.Errorf("expected the managed fields to be upgraded with a JSON patch, got patch type %q", cl.patches[0].patchType)
+	}
+	if cl.patches[1].patchType != types.ApplyPatchType {
+		t.Errorf("expected the desired object to be applied, got patch type %q", cl.patches[1].patchType)
+	}
+}
@@ -0,0 +1,285 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// sharedLoadBalancerServiceSlotsAnnotation records the port slot that
+	// is allocated to each ingresscontroller that shares a load balancer
+	// service.  The value is a JSON object that maps ingresscontroller
+	// names to slot numbers.
+	sharedLoadBalancerServiceSlotsAnnotation = "ingress.operator.openshift.io/shared-load-balancer-slots"
+
+	// sharedLoadBalancerServicePortStride is the distance between the
+	// ports of consecutive slots.  Slot 0 gets ports 80 and 443, slot 1
+	// gets ports 1080 and 1443, and so on.
+	sharedLoadBalancerServicePortStride = 1000
+	// maxSharedLoadBalancerServiceSlots is the number of slots that fit in
+	// the port range.
+	maxSharedLoadBalancerServiceSlots = 65
+)
+
+// sharedPortNameSuffix returns a suffix for port names that is unique to the
+// named ingresscontroller.  Port names are limited to 15 characters, so the
+// suffix is a hash of the name.
+func sharedPortNameSuffix(icName string) string {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(icName))
+	return fmt.Sprintf("%08x", hasher.Sum32())
+}
+
+// sharedHTTPPortName returns the name of the router container port that a
+// shared load balancer service targets for the given ingresscontroller's HTTP
+// traffic.
+func sharedHTTPPortName(ic *operatorv1.IngressController) string {
+	return "http-" + sharedPortNameSuffix(ic.Name)
+}
+
+// sharedHTTPSPortName returns the name of the router container port that a
+// shared load balancer service targets for the given ingresscontroller's
+// HTTPS traffic.
+func sharedHTTPSPortName(ic *operatorv1.IngressController) string {
+	return "https-" + sharedPortNameSuffix(ic.Name)
+}
+
+// sharedLoadBalancerServicePorts returns the HTTP and HTTPS ports for the
+// given slot.
+func sharedLoadBalancerServicePorts(slot int) (int32, int32) {
+	offset := int32(slot * sharedLoadBalancerServicePortStride)
+	return 80 + offset, 443 + offset
+}
+
+// sharedLoadBalancerServiceSlots returns the slot allocation that is recorded
+// on the given shared load balancer service.
+func sharedLoadBalancerServiceSlots(service *corev1.Service) (map[string]int, error) {
+	slots := map[string]int{}
+	if v, ok := service.Annotations[sharedLoadBalancerServiceSlotsAnnotation]; ok && len(v) != 0 {
+		if err := json.Unmarshal([]byte(v), &slots); err != nil {
+			return nil, fmt.Errorf("service %s/%s has an invalid %s annotation: %w", service.Namespace, service.Name, sharedLoadBalancerServiceSlotsAnnotation, err)
+		}
+	}
+	return slots, nil
+}
+
+// setSharedLoadBalancerServiceSlots records the given slot allocation on the
+// given shared load balancer service and sets the service's ports to match.
+// Node ports that were allocated for ports with matching names are preserved.
+func setSharedLoadBalancerServiceSlots(service *corev1.Service, slots map[string]int) error {
+	data, err := json.Marshal(slots)
+	if err != nil {
+		return err
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[sharedLoadBalancerServiceSlotsAnnotation] = string(data)
+
+	names := make([]string, 0, len(slots))
+	for name := range slots {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return slots[names[i]] < slots[names[j]]
+	})
+	nodePorts := map[string]int32{}
+	for _, port := range service.Spec.Ports {
+		nodePorts[port.Name] = port.NodePort
+	}
+	ports := []corev1.ServicePort{}
+	for _, name := range names {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		httpPort, httpsPort := sharedLoadBalancerServicePorts(slots[name])
+		httpName, httpsName := sharedHTTPPortName(ic), sharedHTTPSPortName(ic)
+		ports = append(ports,
+			corev1.ServicePort{
+				Name:       httpName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       httpPort,
+				TargetPort: intstr.FromString(httpName),
+				NodePort:   nodePorts[httpName],
+			},
+			corev1.ServicePort{
+				Name:       httpsName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       httpsPort,
+				TargetPort: intstr.FromString(httpsName),
+				NodePort:   nodePorts[httpsName],
+			},
+		)
+	}
+	service.Spec.Ports = ports
+	return nil
+}
+
+// ensureSharedLoadBalancerService ensures that the shared LB service with the
+// given name exists and has a slot, ports, and an owner reference for the
+// given ingresscontroller.  The service is created from the desired LB service
+// for the first ingresscontroller that uses it; after that, only the
+// selector, ports, and owner references are reconciled.  Any dedicated LB
+// service of the ingresscontroller and its membership in any other shared LB
+// service are removed.  Returns a Boolean indicating whether the service
+// exists, the current service if it does exist, and an error value.
+func (r *reconciler) ensureSharedLoadBalancerService(ci *operatorv1.IngressController, name string, desired *corev1.Service, deploymentRef metav1.OwnerReference) (bool, *corev1.Service, error) {
+	if err := r.releaseSharedLoadBalancerServices(ci, name); err != nil {
+		return false, nil, err
+	}
+	if haveLBS, dedicated, err := r.currentLoadBalancerService(ci); err != nil {
+		return false, nil, err
+	} else if haveLBS && isServiceOwnedByIngressController(dedicated, ci) {
+		if err := r.deleteLoadBalancerService(dedicated, &crclient.DeleteOptions{}); err != nil {
+			return false, nil, err
+		}
+	}
+
+	key := types.NamespacedName{Namespace: desired.Namespace, Name: name}
+	current := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), key, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, nil, err
+		}
+		service := desired.DeepCopy()
+		service.Name = name
+		service.Labels = map[string]string{controller.SharedLoadBalancerServiceLabel: name}
+		service.Spec.Selector = map[string]string{controller.SharedLoadBalancerServiceLabel: name}
+		service.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+		if err := setSharedLoadBalancerServiceSlots(service, map[string]int{ci.Name: 0}); err != nil {
+			return false, nil, err
+		}
+		if err := r.client.Create(context.TODO(), service); err != nil {
+			return false, nil, fmt.Errorf("failed to create shared load balancer service %s: %w", key, err)
+		}
+		log.Info("created shared load balancer service", "namespace", key.Namespace, "name", key.Name)
+		return true, service, nil
+	}
+
+	changed, updated, err := sharedLoadBalancerServiceMemberAdded(current, ci, deploymentRef)
+	if err != nil {
+		return true, current, err
+	}
+	if !changed {
+		return true, current, nil
+	}
+	// Use update rather than apply so that concurrent changes to the slot
+	// allocation conflict rather than overwrite each other.
+	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return true, current, fmt.Errorf("failed to update shared load balancer service %s: %w", key, err)
+	}
+	log.Info("updated shared load balancer service", "namespace", key.Namespace, "name", key.Name, "diff", diff)
+	return true, updated, nil
+}
+
+// sharedLoadBalancerServiceMemberAdded checks whether the current shared LB
+// service has a slot and an owner reference for the given ingresscontroller
+// and the expected selector, and if not, returns an updated service.
+func sharedLoadBalancerServiceMemberAdded(current *corev1.Service, ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (bool, *corev1.Service, error) {
+	slots, err := sharedLoadBalancerServiceSlots(current)
+	if err != nil {
+		return false, nil, err
+	}
+	if _, ok := slots[ci.Name]; !ok {
+		used := map[int]bool{}
+		for _, slot := range slots {
+			used[slot] = true
+		}
+		slot := 0
+		for used[slot] {
+			slot++
+		}
+		if slot >= maxSharedLoadBalancerServiceSlots {
+			return false, nil, fmt.Errorf("shared load balancer service %s/%s has no free ports", current.Namespace, current.Name)
+		}
+		slots[ci.Name] = slot
+	}
+
+	updated := current.DeepCopy()
+	if err := setSharedLoadBalancerServiceSlots(updated, slots); err != nil {
+		return false, nil, err
+	}
+	updated.Spec.Selector = map[string]string{controller.SharedLoadBalancerServiceLabel: current.Name}
+	haveOwner := false
+	for _, ref := range updated.OwnerReferences {
+		if ref.UID == deploymentRef.UID {
+			haveOwner = true
+			break
+		}
+	}
+	if !haveOwner {
+		updated.OwnerReferences = append(updated.OwnerReferences, deploymentRef)
+	}
+
+	if cmp.Equal(current.Annotations, updated.Annotations, cmpopts.EquateEmpty()) &&
+		cmp.Equal(current.Spec.Ports, updated.Spec.Ports, cmpopts.EquateEmpty()) &&
+		cmp.Equal(current.Spec.Selector, updated.Spec.Selector, cmpopts.EquateEmpty()) &&
+		haveOwner {
+		return false, nil, nil
+	}
+	return true, updated, nil
+}
+
+// releaseSharedLoadBalancerServices removes the given ingresscontroller's
+// slot, ports, and owner reference from every shared LB service other than
+// the one with the given name.  A shared LB service that no longer has any
+// ingresscontrollers is deleted.
+func (r *reconciler) releaseSharedLoadBalancerServices(ci *operatorv1.IngressController, except string) error {
+	services := &corev1.ServiceList{}
+	if err := r.client.List(context.TODO(), services, crclient.InNamespace(controller.DefaultOperandNamespace), crclient.HasLabels{controller.SharedLoadBalancerServiceLabel}); err != nil {
+		return fmt.Errorf("failed to list shared load balancer services: %w", err)
+	}
+	deploymentName := controller.RouterDeploymentName(ci).Name
+	for i := range services.Items {
+		current := &services.Items[i]
+		if current.Name == except {
+			continue
+		}
+		slots, err := sharedLoadBalancerServiceSlots(current)
+		if err != nil {
+			return err
+		}
+		if _, ok := slots[ci.Name]; !ok {
+			continue
+		}
+		delete(slots, ci.Name)
+		if len(slots) == 0 {
+			if err := r.deleteLoadBalancerService(current, &crclient.DeleteOptions{}); err != nil {
+				return err
+			}
+			continue
+		}
+		updated := current.DeepCopy()
+		if err := setSharedLoadBalancerServiceSlots(updated, slots); err != nil {
+			return err
+		}
+		var owners []metav1.OwnerReference
+		for _, ref := range updated.OwnerReferences {
+			if ref.Kind != "Deployment" || ref.Name != deploymentName {
+				owners = append(owners, ref)
+			}
+		}
+		updated.OwnerReferences = owners
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update shared load balancer service %s/%s: %w", current.Namespace, current.Name, err)
+		}
+		log.Info("released shared load balancer service", "namespace", current.Namespace, "name", current.Name, "ingresscontroller", ci.Name)
+	}
+	return nil
+}
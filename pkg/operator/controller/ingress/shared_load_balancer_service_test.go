@@ -0,0 +1,173 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestSharedLoadBalancerService verifies that two ingresscontrollers can share
+// one load balancer service, each with its own ports, and that the service is
+// cleaned up as the ingresscontrollers stop using it.
+func TestSharedLoadBalancerService(t *testing.T) {
+	platformStatus := &configv1.PlatformStatus{Type: configv1.AWSPlatformType}
+	newIngressController := func(name string) (*operatorv1.IngressController, metav1.OwnerReference) {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{
+					Raw: []byte(`{"sharedLoadBalancerService":"shared-lb"}`),
+				},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+		deploymentRef := metav1.OwnerReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       controller.RouterDeploymentName(ic).Name,
+			UID:        types.UID(name),
+		}
+		return ic, deploymentRef
+	}
+	first, firstRef := newIngressController("first")
+	second, secondRef := newIngressController("second")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	r := &reconciler{client: cl}
+	name := types.NamespacedName{Namespace: "openshift-ingress", Name: "shared-lb"}
+	getService := func() *corev1.Service {
+		t.Helper()
+		service := &corev1.Service{}
+		if err := cl.Get(context.Background(), name, service); err != nil {
+			t.Fatalf("failed to get shared service: %v", err)
+		}
+		return service
+	}
+	expectPorts := func(service *corev1.Service, expected map[string]int32) {
+		t.Helper()
+		if len(service.Spec.Ports) != len(expected) {
+			t.Fatalf("expected %d ports, got %v", len(expected), service.Spec.Ports)
+		}
+		for _, port := range service.Spec.Ports {
+			if want, ok := expected[port.Name]; !ok || port.Port != want {
+				t.Errorf("unexpected port %s: %d", port.Name, port.Port)
+			}
+			if port.TargetPort.StrVal != port.Name {
+				t.Errorf("expected port %s to target the container port with the same name, got %s", port.Name, port.TargetPort.String())
+			}
+		}
+	}
+
+	for _, ic := range []struct {
+		ic  *operatorv1.IngressController
+		ref metav1.OwnerReference
+	}{{first, firstRef}, {second, secondRef}} {
+		have, service, err := r.ensureLoadBalancerService(ic.ic, ic.ref, platformStatus)
+		if err != nil {
+			t.Fatalf("unexpected error ensuring the service for %s: %v", ic.ic.Name, err)
+		}
+		if !have || service.Name != name.Name {
+			t.Fatalf("expected the shared service for %s, got %v", ic.ic.Name, service)
+		}
+	}
+
+	service := getService()
+	expectPorts(service, map[string]int32{
+		sharedHTTPPortName(first):   80,
+		sharedHTTPSPortName(first):  443,
+		sharedHTTPPortName(second):  1080,
+		sharedHTTPSPortName(second): 1443,
+	})
+	if service.Spec.Selector[controller.SharedLoadBalancerServiceLabel] != name.Name || len(service.Spec.Selector) != 1 {
+		t.Errorf("unexpected selector: %v", service.Spec.Selector)
+	}
+	if len(service.OwnerReferences) != 2 {
+		t.Errorf("expected owner references for both deployments, got %v", service.OwnerReferences)
+	}
+
+	// Reconciling again must not change the allocation.
+	if _, _, err := r.ensureLoadBalancerService(first, firstRef, platformStatus); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := getService().Annotations[sharedLoadBalancerServiceSlotsAnnotation]; v != `{"first":0,"second":1}` {
+		t.Errorf("unexpected slot allocation: %s", v)
+	}
+
+	// Deleting the first ingresscontroller releases its ports, and the
+	// second keeps its own.
+	if err := r.releaseSharedLoadBalancerServices(first, ""); err != nil {
+		t.Fatalf("unexpected error releasing the service: %v", err)
+	}
+	service = getService()
+	expectPorts(service, map[string]int32{
+		sharedHTTPPortName(second):  1080,
+		sharedHTTPSPortName(second): 1443,
+	})
+	if len(service.OwnerReferences) != 1 || service.OwnerReferences[0].UID != secondRef.UID {
+		t.Errorf("expected only the second deployment to own the service, got %v", service.OwnerReferences)
+	}
+
+	// A new member gets the lowest free slot.
+	third, thirdRef := newIngressController("third")
+	if _, _, err := r.ensureLoadBalancerService(third, thirdRef, platformStatus); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectPorts(getService(), map[string]int32{
+		sharedHTTPPortName(second):  1080,
+		sharedHTTPSPortName(second): 1443,
+		sharedHTTPPortName(third):   80,
+		sharedHTTPSPortName(third):  443,
+	})
+
+	// The service is deleted once no ingresscontroller uses it.
+	for _, ic := range []*operatorv1.IngressController{second, third} {
+		if err := r.releaseSharedLoadBalancerServices(ic, ""); err != nil {
+			t.Fatalf("unexpected error releasing the service: %v", err)
+		}
+	}
+	if err := cl.Get(context.Background(), name, &corev1.Service{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the shared service to be deleted, got %v", err)
+	}
+}
+
+// TestDesiredRouterDeploymentSharedLoadBalancerService verifies that the router
+// pods of an ingresscontroller that shares a load balancer service are
+// labeled for the shared service and expose uniquely named ports.
+func TestDesiredRouterDeploymentSharedLoadBalancerService(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	ic.Status.EndpointPublishingStrategy.Type = operatorv1.LoadBalancerServiceStrategyType
+	ic.Spec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"sharedLoadBalancerService":"shared-lb"}`)}
+	deployment, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if v := deployment.Spec.Template.Labels[controller.SharedLoadBalancerServiceLabel]; v != "shared-lb" {
+		t.Errorf("expected pod label %s=shared-lb, got %q", controller.SharedLoadBalancerServiceLabel, v)
+	}
+	ports := map[string]int32{}
+	for _, port := range deployment.Spec.Template.Spec.Containers[0].Ports {
+		if len(port.Name) > 15 {
+			t.Errorf("port name %q is longer than 15 characters", port.Name)
+		}
+		ports[port.Name] = port.ContainerPort
+	}
+	if ports[sharedHTTPPortName(ic)] != ports[HTTPPortName] || ports[sharedHTTPSPortName(ic)] != ports[HTTPSPortName] {
+		t.Errorf("expected shared port names to alias the HTTP and HTTPS ports, got %v", ports)
+	}
+}
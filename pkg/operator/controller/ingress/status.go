@@ -6,8 +6,10 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -29,14 +31,37 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	utilclock "k8s.io/apimachinery/pkg/util/clock"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // clock is to enable unit testing
 var clock utilclock.Clock = utilclock.RealClock{}
 
+// dnsResolver is to enable unit testing
+var dnsResolver resolver = net.DefaultResolver
+
+// resolver looks up DNS names.  It is satisfied by *net.Resolver.
+type resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+const (
+	// dnsVerificationTimeout is the time allowed for resolving the wildcard
+	// DNS record when verifying it.
+	dnsVerificationTimeout = 5 * time.Second
+	// dnsVerificationRetryInterval is the interval after which an
+	// unverified wildcard DNS record is checked again.
+	dnsVerificationRetryInterval = 30 * time.Second
+	// dnsVerificationHostLabel is the label that is substituted for the
+	// wildcard when resolving the wildcard DNS record.
+	dnsVerificationHostLabel = "dns-verification"
+)
+
 // expectedCondition contains a condition that is expected to be checked when
 // determining Available or Degraded status of the ingress controller
 type expectedCondition struct {
@@ -50,7 +75,7 @@ type expectedCondition struct {
 
 // syncIngressControllerStatus computes the current status of ic and
 // updates status upon any changes since last sync.
-func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressController, deployment *appsv1.Deployment, deploymentRef metav1.OwnerReference, pods []corev1.Pod, service *corev1.Service, operandEvents []corev1.Event, wildcardRecord *iov1.DNSRecord, dnsConfig *configv1.DNS, platformStatus *configv1.PlatformStatus) (error, bool) {
+func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressController, deployment *appsv1.Deployment, deploymentRef metav1.OwnerReference, pods []corev1.Pod, service, nodePortService *corev1.Service, operandEvents []corev1.Event, wildcardRecord *iov1.DNSRecord, dnsConfig *configv1.DNS, platformStatus *configv1.PlatformStatus, defaultBackendService *corev1.Service, ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure) (error, bool) {
 	updatedIc := false
 	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
@@ -70,12 +95,42 @@ func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressControlle
 	updated.Status.Selector = selector.String()
 	updated.Status.TLSProfile = computeIngressTLSProfile(ic.Status.TLSProfile, deployment)
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDeploymentPodsScheduledCondition(deployment, pods))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeInsufficientNodesForAntiAffinityCondition(deployment, pods))
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDeploymentAvailableCondition(deployment))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDeploymentProgressingCondition(deployment))
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDeploymentReplicasMinAvailableCondition(deployment))
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDeploymentReplicasAllAvailableCondition(deployment))
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeLoadBalancerStatus(ic, service, operandEvents)...)
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeServiceProvisionedCondition(ic, service, nodePortService))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeCertificateValidCondition(secretName, secret, clock.Now()))
+	SetDefaultCertificateExpiryMetric(ic, secret)
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDNSStatus(ic, wildcardRecord, platformStatus, dnsConfig)...)
-	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeIngressAvailableCondition(updated.Status.Conditions))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDNSProviderCondition(platformStatus, dnsConfig, infraConfig))
+	replicasBelowRecommendedCondition := computeReplicasBelowRecommendedCondition(ic, ingressConfig, infraConfig)
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, replicasBelowRecommendedCondition)
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeEffectiveReplicasCondition(ic, deployment, ingressConfig, infraConfig))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeHostNetworkNodeIPsAvailableCondition(ic, selector, pods))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeRouterPodsReadyCondition(selector, pods))
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		// validateUnsupportedConfigOverrides reports the error.
+		overrides = &unsupportedConfigOverrides{}
+	}
+	if overrides.RequireVerifiedDNS {
+		dnsVerifiedCondition, verifyErr := computeDNSVerifiedCondition(updated.Status.Conditions, wildcardRecord)
+		if verifyErr != nil {
+			errs = append(errs, retryableerror.New(verifyErr, dnsVerificationRetryInterval))
+		}
+		updated.Status.Conditions = MergeConditions(updated.Status.Conditions, dnsVerifiedCondition)
+	} else {
+		updated.Status.Conditions = removeCondition(updated.Status.Conditions, IngressControllerDNSVerifiedConditionType)
+	}
+	if overrides.DefaultBackend != nil {
+		updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDefaultBackendServiceMissingCondition(overrides.DefaultBackend, defaultBackendService))
+	} else {
+		updated.Status.Conditions = removeCondition(updated.Status.Conditions, IngressControllerDefaultBackendServiceMissingConditionType)
+	}
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeIngressAvailableCondition(updated.Status.Conditions, overrides))
 	degradedCondition, err := computeIngressDegradedCondition(updated.Status.Conditions, updated.Name)
 	errs = append(errs, err)
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeIngressProgressingCondition(updated.Status.Conditions, ic, service, platformStatus))
@@ -90,12 +145,26 @@ func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressControlle
 		} else {
 			updatedIc = true
 			SetIngressControllerConditionsMetric(updated)
+			r.reportReplicasBelowRecommended(ic, updated, replicasBelowRecommendedCondition)
+			r.notifyStatusWebhooks(ic, updated)
 		}
 	}
 
 	return retryableerror.NewMaybeRetryableAggregate(errs), updatedIc
 }
 
+// reportReplicasBelowRecommended emits a warning event on the given
+// ingresscontroller if the given "ReplicasBelowRecommended" condition is true
+// and was not true in the ingresscontroller's previous status, so that the
+// risk is reported once, when it arises, rather than on every sync.  The
+// deployment is not blocked.
+func (r *reconciler) reportReplicasBelowRecommended(previous, updated *operatorv1.IngressController, condition operatorv1.OperatorCondition) {
+	if condition.Status != operatorv1.ConditionTrue || conditionIsTrue(previous.Status.Conditions, IngressControllerReplicasBelowRecommendedConditionType) {
+		return
+	}
+	r.recorder.Event(updated, "Warning", "ReplicasBelowRecommended", condition.Message)
+}
+
 // syncIngressControllerSelectorStatus syncs the routeSelector and namespaceSelector
 // from the spec to the status for tracking selector state.
 func (r *reconciler) syncIngressControllerSelectorStatus(ic *operatorv1.IngressController) error {
@@ -153,6 +222,29 @@ func PruneConditions(conditions []operatorv1.OperatorCondition) []operatorv1.Ope
 	return conditions
 }
 
+// conditionIsTrue returns whether the given conditions include a condition of
+// the given type with status true.
+func conditionIsTrue(conditions []operatorv1.OperatorCondition, conditionType string) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status == operatorv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// removeCondition returns the given conditions without any condition of the
+// given type.
+func removeCondition(conditions []operatorv1.OperatorCondition, conditionType string) []operatorv1.OperatorCondition {
+	var result []operatorv1.OperatorCondition
+	for _, condition := range conditions {
+		if condition.Type != conditionType {
+			result = append(result, condition)
+		}
+	}
+	return result
+}
+
 // computeIngressTLSProfile computes the ingresscontroller's current TLS
 // profile.  If the deployment is ready, then the TLS profile is inferred from
 // deployment's pod template spec.  Otherwise the previous TLS profile is used.
@@ -230,13 +322,275 @@ func computeDeploymentPodsScheduledCondition(deployment *appsv1.Deployment, pods
 	}
 }
 
+// computeInsufficientNodesForAntiAffinityCondition computes the
+// ingresscontroller's "InsufficientNodesForAntiAffinity" status condition.  The
+// deployment strategy requires that router pods be scheduled on different
+// nodes, so the deployment can be stuck below its desired number of ready
+// replicas if there are not enough eligible nodes.  The condition is true if
+// the deployment requires pod anti-affinity, fewer replicas are ready than
+// desired, and some of the deployment's pods cannot be scheduled because of
+// anti-affinity.
+func computeInsufficientNodesForAntiAffinityCondition(deployment *appsv1.Deployment, pods []corev1.Pod) operatorv1.OperatorCondition {
+	if !hasRequiredPodAntiAffinity(deployment) {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerInsufficientNodesForAntiAffinityConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "AntiAffinityNotRequired",
+			Message: "The deployment does not require pod anti-affinity.",
+		}
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.ReadyReplicas >= replicas {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerInsufficientNodesForAntiAffinityConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "DesiredReplicasReady",
+			Message: fmt.Sprintf("%d/%d of replicas are ready.", deployment.Status.ReadyReplicas, replicas),
+		}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil || selector.Empty() {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerInsufficientNodesForAntiAffinityConditionType,
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "InvalidLabelSelector",
+			Message: "Deployment has an invalid label selector.",
+		}
+	}
+	var blocked []string
+	for _, pod := range pods {
+		if !selector.Matches(labels.Set(pod.Labels)) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status != corev1.ConditionTrue && cond.Reason == corev1.PodReasonUnschedulable && strings.Contains(cond.Message, "anti-affinity") {
+				blocked = append(blocked, pod.Name)
+			}
+		}
+	}
+	if len(blocked) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerInsufficientNodesForAntiAffinityConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoPodsBlockedByAntiAffinity",
+			Message: fmt.Sprintf("%d/%d of replicas are ready, and no pods are unschedulable because of anti-affinity.", deployment.Status.ReadyReplicas, replicas),
+		}
+	}
+	sort.Strings(blocked)
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerInsufficientNodesForAntiAffinityConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "PodsBlockedByAntiAffinity",
+		Message: fmt.Sprintf("%d/%d of replicas are ready, and some pods cannot be scheduled because router pods must run on different nodes: %s.  Make sure that enough nodes match the ingresscontroller's node placement.", deployment.Status.ReadyReplicas, replicas, strings.Join(blocked, ", ")),
+	}
+}
+
+// computeDefaultBackendServiceMissingCondition computes the ingresscontroller's
+// "DefaultBackendServiceMissing" status condition for the given default backend
+// service.  The condition is true if the service does not exist, in which case
+// the router cannot serve unmatched requests.
+func computeDefaultBackendServiceMissingCondition(backend *defaultBackendOverride, service *corev1.Service) operatorv1.OperatorCondition {
+	if service == nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDefaultBackendServiceMissingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "ServiceNotFound",
+			Message: fmt.Sprintf("The default backend service %s/%s does not exist; unmatched requests will fail.", controller.DefaultOperandNamespace, backend.Name),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerDefaultBackendServiceMissingConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "ServiceFound",
+		Message: fmt.Sprintf("The default backend service %s/%s exists.", service.Namespace, service.Name),
+	}
+}
+
+// computeReplicasBelowRecommendedCondition computes the ingresscontroller's
+// "ReplicasBelowRecommended" status condition.  The condition is true if
+// spec.replicas is set to a value that is lower than the number of replicas
+// that DetermineReplicas recommends for the cluster topology, in which case
+// the ingresscontroller may not be highly available.  The explicit value is
+// still used.  The condition's message includes the topology mode and the
+// number of replicas so that the risk is visible.
+func computeReplicasBelowRecommendedCondition(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure) operatorv1.OperatorCondition {
+	if ic.Spec.Replicas == nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerReplicasBelowRecommendedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "DefaultReplicas",
+			Message: "The number of replicas is determined by the cluster topology.",
+		}
+	}
+	replicas := *ic.Spec.Replicas
+	recommended := DetermineReplicas(ingressConfig, infraConfig)
+	if replicas < recommended {
+		risk := "the ingresscontroller may not be highly available"
+		if replicas == 1 {
+			risk = "the router deployment has 1 replica, which is a single point of failure"
+		}
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerReplicasBelowRecommendedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "ReplicasBelowRecommended",
+			Message: fmt.Sprintf("spec.replicas is %d, which is below the recommended minimum of %d for the %s topology; %s.", replicas, recommended, replicasTopology(ingressConfig, infraConfig), risk),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerReplicasBelowRecommendedConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "ReplicasMeetRecommendation",
+		Message: fmt.Sprintf("spec.replicas is %d, which meets the recommended minimum of %d for the cluster topology.", replicas, recommended),
+	}
+}
+
+// computeEffectiveReplicasCondition computes the ingresscontroller's
+// "EffectiveReplicas" status condition.  The condition is always true; its
+// message reports the number of replicas that determineDeploymentReplicas
+// chooses for the router deployment, and its reason reports which rule chose
+// it: an explicit spec.replicas value, the number of eligible nodes, the
+// single-replica topology, or the default for highly available topologies.
+func computeEffectiveReplicasCondition(ic *operatorv1.IngressController, deployment *appsv1.Deployment, ingressConfig *configv1.Ingress, infraConfig *configv1.Infrastructure) operatorv1.OperatorCondition {
+	replicas := determineDeploymentReplicas(ic, ingressConfig, infraConfig)
+	nodeCount := replicasFromNodeCountEnabled(ic) && deployment != nil && deployment.Spec.Replicas != nil
+	if nodeCount {
+		replicas = *deployment.Spec.Replicas
+	}
+	noun := "replicas"
+	if replicas == 1 {
+		noun = "replica"
+	}
+	var reason, message string
+	switch {
+	case ic.Spec.Replicas != nil:
+		reason = "SpecReplicas"
+		message = fmt.Sprintf("The router deployment has %d %s as set in spec.replicas.", replicas, noun)
+	case nodeCount:
+		reason = "NodeCount"
+		message = fmt.Sprintf("The router deployment has %d %s based on the number of nodes on which router pods can run because spec.replicas is unset and the replicasFromNodeCount unsupported config override is set.", replicas, noun)
+	case replicasTopology(ingressConfig, infraConfig) == configv1.SingleReplicaTopologyMode:
+		reason = "SingleReplicaTopology"
+		message = fmt.Sprintf("The router deployment has %d %s because spec.replicas is unset and the cluster topology is %s.", replicas, noun, configv1.SingleReplicaTopologyMode)
+	default:
+		reason = "DefaultReplicas"
+		message = fmt.Sprintf("The router deployment has the default of %d %s because spec.replicas is unset.", replicas, noun)
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerEffectiveReplicasConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// computeHostNetworkNodeIPsAvailableCondition computes the ingresscontroller's
+// "HostNetworkNodeIPsAvailable" status condition.  For the HostNetwork
+// endpoint publishing strategy, the condition's message lists the IP addresses
+// of the nodes on which the ingresscontroller's router pods are running so
+// that an external load balancer can be configured to target them.
+func computeHostNetworkNodeIPsAvailableCondition(ic *operatorv1.IngressController, selector labels.Selector, pods []corev1.Pod) operatorv1.OperatorCondition {
+	if ic.Status.EndpointPublishingStrategy == nil ||
+		ic.Status.EndpointPublishingStrategy.Type != operatorv1.HostNetworkStrategyType {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerHostNetworkNodeIPsAvailableConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "EndpointPublishingStrategyExcludesHostNetwork",
+			Message: "The configured endpoint publishing strategy does not use the host network.",
+		}
+	}
+
+	nodeIPs := sets.NewString()
+	for _, pod := range pods {
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pod.Status.Phase != corev1.PodRunning || len(pod.Status.HostIP) == 0 {
+			continue
+		}
+		nodeIPs.Insert(pod.Status.HostIP)
+	}
+	if nodeIPs.Len() == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerHostNetworkNodeIPsAvailableConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoRouterPodsRunning",
+			Message: "No router pods are running.",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerHostNetworkNodeIPsAvailableConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "RouterPodsRunning",
+		Message: fmt.Sprintf("Router pods are running on nodes with the following IP addresses: %s", strings.Join(nodeIPs.List(), ", ")),
+	}
+}
+
+// computeRouterPodsReadyCondition computes the ingresscontroller's
+// "RouterPodsReady" status condition, which reports how many of the
+// ingresscontroller's router pods are ready and names the pods that are not.
+// Pods that are being deleted are ignored.
+func computeRouterPodsReadyCondition(selector labels.Selector, pods []corev1.Pod) operatorv1.OperatorCondition {
+	var total int
+	var unready []string
+	for _, pod := range pods {
+		if !selector.Matches(labels.Set(pod.Labels)) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		total++
+		if !isPodReady(&pod) {
+			unready = append(unready, pod.Name)
+		}
+	}
+	if total == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerRouterPodsReadyConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoRouterPods",
+			Message: "No router pods exist.",
+		}
+	}
+	message := fmt.Sprintf("%d of %d router pods are ready.", total-len(unready), total)
+	if len(unready) != 0 {
+		sort.Strings(unready)
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerRouterPodsReadyConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "RouterPodsNotReady",
+			Message: fmt.Sprintf("%s  Unready pods: %s", message, strings.Join(unready, ", ")),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerRouterPodsReadyConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "AllRouterPodsReady",
+		Message: message,
+	}
+}
+
+// isPodReady returns a Boolean value indicating whether the given pod has a
+// true Ready condition.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 // computeIngressAvailableCondition computes the ingress controller's current Available status state
 // by inspecting the following:
 // 1) the Available condition of Deployment,
-// 2) the DNSReady condition of the IngressController, and
-// 3) the LoadBalancerReady condition of the IngressController.
-// The ingresscontroller is judged Available only if all 3 conditions are true
-func computeIngressAvailableCondition(conditions []operatorv1.OperatorCondition) operatorv1.OperatorCondition {
+// 2) the DNSReady condition of the IngressController,
+// 3) the LoadBalancerReady condition of the IngressController,
+// 4) the ServiceProvisioned condition of the IngressController, and
+// 5) if requireVerifiedDNS is true, the DNSVerified condition of the
+// IngressController.
+// The ingresscontroller is judged Available only if all of these conditions are true
+func computeIngressAvailableCondition(conditions []operatorv1.OperatorCondition, overrides *unsupportedConfigOverrides) operatorv1.OperatorCondition {
 	expected := []expectedCondition{
 		{
 			condition: IngressControllerDeploymentAvailableConditionType,
@@ -256,6 +610,29 @@ func computeIngressAvailableCondition(conditions []operatorv1.OperatorCondition)
 			status:           operatorv1.ConditionTrue,
 			ifConditionsTrue: []string{operatorv1.LoadBalancerManagedIngressConditionType},
 		},
+		{
+			condition: IngressControllerServiceProvisionedConditionType,
+			status:    operatorv1.ConditionTrue,
+		},
+	}
+	if overrides.RequireVerifiedDNS {
+		expected = append(expected, expectedCondition{
+			condition: IngressControllerDNSVerifiedConditionType,
+			status:    operatorv1.ConditionTrue,
+			ifConditionsTrue: []string{
+				operatorv1.LoadBalancerManagedIngressConditionType,
+				operatorv1.LoadBalancerReadyIngressConditionType,
+				operatorv1.DNSManagedIngressConditionType,
+			},
+		})
+	}
+	// In fail-closed mode, the router rejects TLS connections if the
+	// default certificate is invalid.
+	if overrides.CertificateFailurePolicy == certificateFailurePolicyFailClosed {
+		expected = append(expected, expectedCondition{
+			condition: IngressControllerCertificateValidConditionType,
+			status:    operatorv1.ConditionTrue,
+		})
 	}
 
 	// Cover the rare case of no conditions
@@ -366,6 +743,143 @@ func computeDeploymentAvailableCondition(deployment *appsv1.Deployment) operator
 	}
 }
 
+// computeDeploymentProgressingCondition computes the ingresscontroller's
+// "DeploymentProgressing" status condition by examining the status conditions
+// of the deployment.  The "DeploymentProgressing" condition is false if the
+// deployment's "Progressing" condition is false with reason
+// "ProgressDeadlineExceeded", which means that a rolling update has made no
+// progress within the deployment's progress deadline, and true otherwise.
+func computeDeploymentProgressingCondition(deployment *appsv1.Deployment) operatorv1.OperatorCondition {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse && cond.Reason == "ProgressDeadlineExceeded" {
+			return operatorv1.OperatorCondition{
+				Type:    IngressControllerDeploymentProgressingConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: fmt.Sprintf("The deployment has exceeded its progress deadline: %s", cond.Message),
+			}
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerDeploymentProgressingConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "DeploymentProgressing",
+		Message: "The deployment has not exceeded its progress deadline",
+	}
+}
+
+// computeServiceProvisionedCondition computes the ingresscontroller's
+// "ServiceProvisioned" status condition by checking that the service that the
+// ingresscontroller's endpoint publishing strategy requires exists and, for a
+// load balancer service, that the load balancer has been provisioned.
+func computeServiceProvisionedCondition(ic *operatorv1.IngressController, lbService, nodePortService *corev1.Service) operatorv1.OperatorCondition {
+	var strategyType operatorv1.EndpointPublishingStrategyType
+	if ic.Status.EndpointPublishingStrategy != nil {
+		strategyType = ic.Status.EndpointPublishingStrategy.Type
+	}
+	switch strategyType {
+	case operatorv1.LoadBalancerServiceStrategyType:
+		switch {
+		case lbService == nil:
+			return operatorv1.OperatorCondition{
+				Type:    IngressControllerServiceProvisionedConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "ServiceNotFound",
+				Message: "The load balancer service does not exist.",
+			}
+		case len(lbService.Status.LoadBalancer.Ingress) == 0:
+			return operatorv1.OperatorCondition{
+				Type:    IngressControllerServiceProvisionedConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "LoadBalancerPending",
+				Message: fmt.Sprintf("The load balancer for service %s/%s is pending.", lbService.Namespace, lbService.Name),
+			}
+		}
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerServiceProvisionedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "LoadBalancerProvisioned",
+			Message: fmt.Sprintf("The load balancer for service %s/%s is provisioned.", lbService.Namespace, lbService.Name),
+		}
+	case operatorv1.NodePortServiceStrategyType:
+		if nodePortService == nil {
+			return operatorv1.OperatorCondition{
+				Type:    IngressControllerServiceProvisionedConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "ServiceNotFound",
+				Message: "The NodePort service does not exist.",
+			}
+		}
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerServiceProvisionedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NodePortServiceProvisioned",
+			Message: fmt.Sprintf("The NodePort service %s/%s exists.", nodePortService.Namespace, nodePortService.Name),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerServiceProvisionedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "NoServiceRequired",
+		Message: "The endpoint publishing strategy does not require a service.",
+	}
+}
+
+// computeCertificateValidCondition computes the ingresscontroller's
+// "CertificateValid" status condition by checking that the given default
+// certificate secret has a certificate that is valid at the given time.
+func computeCertificateValidCondition(secretName types.NamespacedName, secret *corev1.Secret, now time.Time) operatorv1.OperatorCondition {
+	data := secret.Data["tls.crt"]
+	if len(data) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerCertificateValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "CertificateNotFound",
+			Message: fmt.Sprintf("The default certificate secret %s has no certificate.", secretName),
+		}
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerCertificateValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "InvalidCertificate",
+			Message: fmt.Sprintf("The default certificate secret %s does not have a PEM-encoded certificate.", secretName),
+		}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerCertificateValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "InvalidCertificate",
+			Message: fmt.Sprintf("The default certificate secret %s has an invalid certificate: %v", secretName, err),
+		}
+	}
+	switch {
+	case now.Before(cert.NotBefore):
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerCertificateValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "CertificateNotYetValid",
+			Message: fmt.Sprintf("The default certificate in secret %s is not valid before %s.", secretName, cert.NotBefore.UTC().Format(time.RFC3339)),
+		}
+	case now.After(cert.NotAfter):
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerCertificateValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "CertificateExpired",
+			Message: fmt.Sprintf("The default certificate in secret %s expired at %s.", secretName, cert.NotAfter.UTC().Format(time.RFC3339)),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerCertificateValidConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "CertificateValid",
+		Message: fmt.Sprintf("The default certificate in secret %s is valid until %s.", secretName, cert.NotAfter.UTC().Format(time.RFC3339)),
+	}
+}
+
 // computeDeploymentReplicasMinAvailableCondition computes the
 // ingresscontroller's "DeploymentReplicasMinAvailable" status condition by
 // examining the number of available replicas reported in the deployment's
@@ -484,6 +998,11 @@ func computeIngressDegradedCondition(conditions []operatorv1.OperatorCondition,
 			status:      operatorv1.ConditionTrue,
 			gracePeriod: time.Second * 60,
 		},
+		{
+			// The progress deadline already serves as a grace period.
+			condition: IngressControllerDeploymentProgressingConditionType,
+			status:    operatorv1.ConditionTrue,
+		},
 		{
 			condition:   IngressControllerDeploymentReplicasAllAvailableConditionType,
 			status:      operatorv1.ConditionTrue,
@@ -505,6 +1024,15 @@ func computeIngressDegradedCondition(conditions []operatorv1.OperatorCondition,
 			},
 			gracePeriod: time.Second * 30,
 		},
+		{
+			condition:   IngressControllerServiceProvisionedConditionType,
+			status:      operatorv1.ConditionTrue,
+			gracePeriod: time.Second * 90,
+		},
+		{
+			condition: IngressControllerCertificateValidConditionType,
+			status:    operatorv1.ConditionTrue,
+		},
 	}
 
 	// Only check the default ingress controller for the canary
@@ -674,6 +1202,16 @@ func computeIngressProgressingCondition(conditions []operatorv1.OperatorConditio
 				condition.Reason = "ScopeChanged"
 				condition.Message = message
 				condition.Status = operatorv1.ConditionTrue
+			} else if platform != nil && platform.Type == configv1.AWSPlatformType {
+				wantType := operatorv1.AWSClassicLoadBalancer
+				if params := ic.Status.EndpointPublishingStrategy.LoadBalancer.ProviderParameters; params != nil && params.AWS != nil && len(params.AWS.Type) != 0 {
+					wantType = params.AWS.Type
+				}
+				if haveType := awsLoadBalancerType(service); wantType != haveType {
+					condition.Reason = "LoadBalancerTypeChanged"
+					condition.Message = fmt.Sprintf("The IngressController AWS load balancer type was changed from %[1]q to %[2]q.  To effectuate this change, you must delete the service: `oc -n %[3]s delete svc/%[4]s`; the service load-balancer will then be deprovisioned and a new one created.  This will most likely cause the new load-balancer to have a different host name and IP address from the old one's.  Alternatively, you can set the %[5]s annotation on the IngressController to have the operator recreate the service, or revert the change to the IngressController.", haveType, wantType, service.Namespace, service.Name, autoDeleteLoadBalancerAnnotation)
+					condition.Status = operatorv1.ConditionTrue
+				}
 			}
 		}
 	}
@@ -892,6 +1430,148 @@ func computeDNSStatus(ic *operatorv1.IngressController, wildcardRecord *iov1.DNS
 	return conditions
 }
 
+// dnsProviderNames maps platform types to the names of the DNS providers that
+// the DNS controller uses on those platforms.
+var dnsProviderNames = map[configv1.PlatformType]string{
+	configv1.AWSPlatformType:          "AWS Route 53",
+	configv1.AzurePlatformType:        "Azure DNS",
+	configv1.GCPPlatformType:          "Google Cloud DNS",
+	configv1.IBMCloudPlatformType:     "IBM Cloud Internet Services",
+	configv1.PowerVSPlatformType:      "IBM Cloud Internet Services",
+	configv1.AlibabaCloudPlatformType: "Alibaba Cloud DNS",
+}
+
+// computeDNSProviderCondition computes the ingresscontroller's "DNSProvider"
+// status condition, which reports the DNS provider and the zones that the DNS
+// controller uses to publish DNS records, to help troubleshoot DNS
+// configuration.  The condition is false if DNS records are not published to
+// any provider.  The provider is chosen the same way that the DNS controller
+// chooses it, so the condition is updated when the cluster DNS or
+// infrastructure config changes.
+func computeDNSProviderCondition(platformStatus *configv1.PlatformStatus, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) operatorv1.OperatorCondition {
+	if dnsConfig.Spec.PublicZone == nil && dnsConfig.Spec.PrivateZone == nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSProviderConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoDNSZones",
+			Message: "No DNS zones are defined in the cluster dns config.",
+		}
+	}
+	name, ok := dnsProviderNames[platformStatus.Type]
+	if platformStatus.Type == configv1.IBMCloudPlatformType && infraConfig.Status.ControlPlaneTopology == configv1.ExternalTopologyMode {
+		ok = false
+	}
+	if !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSProviderConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "UnsupportedPlatform",
+			Message: fmt.Sprintf("DNS records are not published on platform %q.", platformStatus.Type),
+		}
+	}
+	var zones []string
+	if zone := dnsConfig.Spec.PrivateZone; zone != nil {
+		zones = append(zones, "private zone "+describeDNSZone(*zone))
+	}
+	if zone := dnsConfig.Spec.PublicZone; zone != nil {
+		zones = append(zones, "public zone "+describeDNSZone(*zone))
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerDNSProviderConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  string(platformStatus.Type),
+		Message: fmt.Sprintf("DNS records are published using %s to %s.", name, strings.Join(zones, " and ")),
+	}
+}
+
+// describeDNSZone returns a description of the given zone that identifies it
+// either by ID or by tags.
+func describeDNSZone(zone configv1.DNSZone) string {
+	if len(zone.ID) != 0 {
+		return fmt.Sprintf("with ID %q", zone.ID)
+	}
+	keys := make([]string, 0, len(zone.Tags))
+	for k := range zone.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]string, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, fmt.Sprintf("%s=%s", k, zone.Tags[k]))
+	}
+	return fmt.Sprintf("with tags %q", strings.Join(tags, ","))
+}
+
+// computeDNSVerifiedCondition computes the ingresscontroller's "DNSVerified"
+// status condition by resolving a name under the wildcard DNS record and
+// checking that it resolves to the record's targets.  Resolution is attempted
+// only once the DNSReady condition is true.  Returns an error if resolution
+// was attempted and did not verify the record, in which case the caller
+// should check again later.
+func computeDNSVerifiedCondition(conditions []operatorv1.OperatorCondition, wildcardRecord *iov1.DNSRecord) (operatorv1.OperatorCondition, error) {
+	dnsReady := false
+	for _, cond := range conditions {
+		if cond.Type == operatorv1.DNSReadyIngressConditionType && cond.Status == operatorv1.ConditionTrue {
+			dnsReady = true
+		}
+	}
+	if !dnsReady || wildcardRecord == nil || len(wildcardRecord.Spec.Targets) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSVerifiedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "RecordNotReady",
+			Message: "The wildcard DNS record is not yet provisioned.",
+		}, nil
+	}
+
+	host := wildcardRecord.Spec.DNSName
+	if strings.HasPrefix(host, "*.") {
+		host = dnsVerificationHostLabel + strings.TrimPrefix(host, "*")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dnsVerificationTimeout)
+	defer cancel()
+
+	var resolved []string
+	var err error
+	switch wildcardRecord.Spec.RecordType {
+	case iov1.CNAMERecordType:
+		var cname string
+		if cname, err = dnsResolver.LookupCNAME(ctx, host); err == nil {
+			resolved = []string{strings.ToLower(strings.TrimSuffix(cname, "."))}
+		}
+	default:
+		resolved, err = dnsResolver.LookupHost(ctx, host)
+	}
+	if err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSVerifiedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "LookupFailed",
+			Message: fmt.Sprintf("Failed to resolve %s: %v", host, err),
+		}, fmt.Errorf("failed to verify DNS record %s: %w", host, err)
+	}
+
+	expected := sets.NewString()
+	for _, target := range wildcardRecord.Spec.Targets {
+		expected.Insert(strings.ToLower(strings.TrimSuffix(target, ".")))
+	}
+	if missing := expected.Difference(sets.NewString(resolved...)); missing.Len() != 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSVerifiedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "TargetsNotResolved",
+			Message: fmt.Sprintf("%s does not yet resolve to the following targets: %s", host, strings.Join(missing.List(), ", ")),
+		}, fmt.Errorf("DNS record %s does not yet resolve to the following targets: %s", host, strings.Join(missing.List(), ", "))
+	}
+
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerDNSVerifiedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "TargetsResolved",
+		Message: fmt.Sprintf("%s resolves to the expected targets.", host),
+	}, nil
+}
+
 // checkZoneInConfig - private utility to check for a zone in the current config
 func checkZoneInConfig(dnsConfig *configv1.DNS, zone configv1.DNSZone) bool {
 	// check PrivateZone settings only
@@ -1,12 +1,14 @@
 package ingress
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"math/big"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -20,14 +22,19 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilclock "k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"k8s.io/client-go/tools/record"
 )
 
 func ingressController(name string, t operatorv1.EndpointPublishingStrategyType) *operatorv1.IngressController {
@@ -315,6 +322,16 @@ func TestComputeIngressDegradedCondition(t *testing.T) {
 			// Exceeded grace period, just use the one minute for this degraded condition
 			expectAfter: time.Minute,
 		},
+		{
+			name: "deployment progress deadline exceeded",
+			conditions: []operatorv1.OperatorCondition{
+				cond(IngressControllerDeploymentProgressingConditionType, operatorv1.ConditionFalse, "ProgressDeadlineExceeded", clock.Now()),
+			},
+			expectIngressDegradedStatus: operatorv1.ConditionTrue,
+			expectRequeue:               true,
+			// The progress deadline is the grace period, so use the one minute retry duration
+			expectAfter: time.Minute,
+		},
 		{
 			name: "deployment minimum replicas unavailable for <60s",
 			conditions: []operatorv1.OperatorCondition{
@@ -452,6 +469,36 @@ func TestComputeIngressDegradedCondition(t *testing.T) {
 			expectIngressDegradedStatus: operatorv1.ConditionFalse,
 			expectRequeue:               false,
 		},
+		{
+			name: "service not provisioned <90s",
+			conditions: []operatorv1.OperatorCondition{
+				cond(IngressControllerServiceProvisionedConditionType, operatorv1.ConditionFalse, "", clock.Now().Add(time.Second*-30)),
+				cond(IngressControllerCertificateValidConditionType, operatorv1.ConditionTrue, "", clock.Now().Add(time.Hour*-1)),
+			},
+			expectIngressDegradedStatus: operatorv1.ConditionFalse,
+			expectRequeue:               true,
+			expectAfter:                 time.Second * 60,
+		},
+		{
+			name: "service not provisioned >90s",
+			conditions: []operatorv1.OperatorCondition{
+				cond(IngressControllerServiceProvisionedConditionType, operatorv1.ConditionFalse, "", clock.Now().Add(time.Second*-120)),
+				cond(IngressControllerCertificateValidConditionType, operatorv1.ConditionTrue, "", clock.Now().Add(time.Hour*-1)),
+			},
+			expectIngressDegradedStatus: operatorv1.ConditionTrue,
+			expectRequeue:               true,
+			expectAfter:                 time.Minute,
+		},
+		{
+			name: "certificate invalid",
+			conditions: []operatorv1.OperatorCondition{
+				cond(IngressControllerServiceProvisionedConditionType, operatorv1.ConditionTrue, "", clock.Now().Add(time.Hour*-1)),
+				cond(IngressControllerCertificateValidConditionType, operatorv1.ConditionFalse, "", clock.Now().Add(time.Second*-1)),
+			},
+			expectIngressDegradedStatus: operatorv1.ConditionTrue,
+			expectRequeue:               true,
+			expectAfter:                 time.Minute,
+		},
 		{
 			name: "default ingress controller, canary check failing",
 			conditions: []operatorv1.OperatorCondition{
@@ -556,6 +603,29 @@ func TestComputeIngressProgressCondition(t *testing.T) {
 			},
 		},
 	}
+	loadBalancerIngressControllerWithNLB := operatorv1.IngressController{
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+				LoadBalancer: &operatorv1.LoadBalancerStrategy{
+					Scope: operatorv1.ExternalLoadBalancer,
+					ProviderParameters: &operatorv1.ProviderLoadBalancerParameters{
+						Type: operatorv1.AWSLoadBalancerProvider,
+						AWS: &operatorv1.AWSLoadBalancerParameters{
+							Type: operatorv1.AWSNetworkLoadBalancer,
+						},
+					},
+				},
+			},
+		},
+	}
+	lbServiceWithNLBOnAWS := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				AWSLBTypeAnnotation: AWSNLBAnnotation,
+			},
+		},
+	}
 	awsPlatformStatus := &configv1.PlatformStatus{
 		Type: configv1.AWSPlatformType,
 	}
@@ -627,6 +697,29 @@ func TestComputeIngressProgressCondition(t *testing.T) {
 			platformStatus: awsPlatformStatus,
 			expectStatus:   operatorv1.ConditionFalse,
 		},
+		{
+			name:                  "LoadBalancerService, type changed from Classic to NLB on AWS",
+			ic:                    &loadBalancerIngressControllerWithNLB,
+			service:               lbService,
+			platformStatus:        awsPlatformStatus,
+			expectStatus:          operatorv1.ConditionTrue,
+			expectMessageContains: "delete",
+		},
+		{
+			name:                  "LoadBalancerService, type changed from NLB to Classic on AWS",
+			ic:                    &loadBalancerIngressControllerWithExternalScope,
+			service:               lbServiceWithNLBOnAWS,
+			platformStatus:        awsPlatformStatus,
+			expectStatus:          operatorv1.ConditionTrue,
+			expectMessageContains: autoDeleteLoadBalancerAnnotation,
+		},
+		{
+			name:           "LoadBalancerService, NLB on AWS",
+			ic:             &loadBalancerIngressControllerWithNLB,
+			service:        lbServiceWithNLBOnAWS,
+			platformStatus: awsPlatformStatus,
+			expectStatus:   operatorv1.ConditionFalse,
+		},
 	}
 	for _, test := range tests {
 		actual := computeIngressProgressingCondition(test.conditions, test.ic, test.service, test.platformStatus)
@@ -689,6 +782,614 @@ func TestComputeDeploymentAvailableCondition(t *testing.T) {
 	}
 }
 
+// TestComputeDeploymentProgressingCondition verifies that
+// computeDeploymentProgressingCondition reports false only if the deployment
+// has exceeded its progress deadline.
+func TestComputeDeploymentProgressingCondition(t *testing.T) {
+	tests := []struct {
+		name                 string
+		deploymentConditions []appsv1.DeploymentCondition
+		expectStatus         operatorv1.ConditionStatus
+	}{
+		{
+			name:                 "progressing absent",
+			deploymentConditions: []appsv1.DeploymentCondition{},
+			expectStatus:         operatorv1.ConditionTrue,
+		},
+		{
+			name: "progressing true",
+			deploymentConditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentProgressing,
+					Status: corev1.ConditionTrue,
+					Reason: "NewReplicaSetAvailable",
+				},
+			},
+			expectStatus: operatorv1.ConditionTrue,
+		},
+		{
+			name: "progress deadline exceeded",
+			deploymentConditions: []appsv1.DeploymentCondition{
+				{
+					Type:    appsv1.DeploymentProgressing,
+					Status:  corev1.ConditionFalse,
+					Reason:  "ProgressDeadlineExceeded",
+					Message: `ReplicaSet "router-default-5d8f9c7b6" has timed out progressing.`,
+				},
+			},
+			expectStatus: operatorv1.ConditionFalse,
+		},
+	}
+
+	for _, test := range tests {
+		deploy := &appsv1.Deployment{
+			Status: appsv1.DeploymentStatus{
+				Conditions: test.deploymentConditions,
+			},
+		}
+
+		actual := computeDeploymentProgressingCondition(deploy)
+		if actual.Status != test.expectStatus {
+			t.Errorf("%q: expected %v, got %v", test.name, test.expectStatus, actual.Status)
+		}
+	}
+}
+
+// TestComputeDefaultBackendServiceMissingCondition verifies that
+// computeDefaultBackendServiceMissingCondition reports a missing default
+// backend service.
+func TestComputeDefaultBackendServiceMissingCondition(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "fallback",
+		},
+	}
+	backend := &defaultBackendOverride{Name: "fallback"}
+	tests := []struct {
+		name         string
+		service      *corev1.Service
+		expectStatus operatorv1.ConditionStatus
+		expectReason string
+	}{
+		{
+			name:         "default backend service missing",
+			service:      nil,
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "ServiceNotFound",
+		},
+		{
+			name:         "default backend service present",
+			service:      service,
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "ServiceFound",
+		},
+	}
+
+	for _, test := range tests {
+		actual := computeDefaultBackendServiceMissingCondition(backend, test.service)
+		if actual.Status != test.expectStatus || actual.Reason != test.expectReason {
+			t.Errorf("%q: expected status %v and reason %q, got %v and %q", test.name, test.expectStatus, test.expectReason, actual.Status, actual.Reason)
+		}
+	}
+}
+
+// TestComputeReplicasBelowRecommendedCondition verifies that
+// computeReplicasBelowRecommendedCondition compares explicit replicas with the
+// number that DetermineReplicas recommends for the cluster topology.
+func TestComputeReplicasBelowRecommendedCondition(t *testing.T) {
+	one, two, three := int32(1), int32(2), int32(3)
+	tests := []struct {
+		name                 string
+		replicas             *int32
+		defaultPlacement     configv1.DefaultPlacement
+		infraTopology        configv1.TopologyMode
+		controlPlaneTopology configv1.TopologyMode
+		expectStatus         operatorv1.ConditionStatus
+		expectReason         string
+		expectMessage        string
+	}{
+		{
+			name:          "default replicas, highly available workers",
+			replicas:      nil,
+			infraTopology: configv1.HighlyAvailableTopologyMode,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "DefaultReplicas",
+		},
+		{
+			name:          "1 replica, highly available workers",
+			replicas:      &one,
+			infraTopology: configv1.HighlyAvailableTopologyMode,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "ReplicasBelowRecommended",
+			expectMessage: "spec.replicas is 1, which is below the recommended minimum of 2 for the HighlyAvailable topology; the router deployment has 1 replica, which is a single point of failure.",
+		},
+		{
+			name:          "2 replicas, highly available workers",
+			replicas:      &two,
+			infraTopology: configv1.HighlyAvailableTopologyMode,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "ReplicasMeetRecommendation",
+		},
+		{
+			name:          "3 replicas, highly available workers",
+			replicas:      &three,
+			infraTopology: configv1.HighlyAvailableTopologyMode,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "ReplicasMeetRecommendation",
+		},
+		{
+			name:          "1 replica, single-replica workers",
+			replicas:      &one,
+			infraTopology: configv1.SingleReplicaTopologyMode,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "ReplicasMeetRecommendation",
+		},
+		{
+			name:                 "1 replica, control-plane placement, single-replica control plane",
+			replicas:             &one,
+			defaultPlacement:     configv1.DefaultPlacementControlPlane,
+			infraTopology:        configv1.HighlyAvailableTopologyMode,
+			controlPlaneTopology: configv1.SingleReplicaTopologyMode,
+			expectStatus:         operatorv1.ConditionFalse,
+			expectReason:         "ReplicasMeetRecommendation",
+		},
+		{
+			name:                 "1 replica, control-plane placement, highly available control plane",
+			replicas:             &one,
+			defaultPlacement:     configv1.DefaultPlacementControlPlane,
+			infraTopology:        configv1.SingleReplicaTopologyMode,
+			controlPlaneTopology: configv1.HighlyAvailableTopologyMode,
+			expectStatus:         operatorv1.ConditionTrue,
+			expectReason:         "ReplicasBelowRecommended",
+		},
+	}
+
+	for _, test := range tests {
+		ic := &operatorv1.IngressController{
+			Spec: operatorv1.IngressControllerSpec{Replicas: test.replicas},
+		}
+		ingressConfig := &configv1.Ingress{
+			Status: configv1.IngressStatus{DefaultPlacement: test.defaultPlacement},
+		}
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{
+				InfrastructureTopology: test.infraTopology,
+				ControlPlaneTopology:   test.controlPlaneTopology,
+			},
+		}
+		actual := computeReplicasBelowRecommendedCondition(ic, ingressConfig, infraConfig)
+		if actual.Status != test.expectStatus || actual.Reason != test.expectReason {
+			t.Errorf("%q: expected status %v and reason %q, got %v and %q", test.name, test.expectStatus, test.expectReason, actual.Status, actual.Reason)
+		}
+		if len(test.expectMessage) != 0 && actual.Message != test.expectMessage {
+			t.Errorf("%q: expected message %q, got %q", test.name, test.expectMessage, actual.Message)
+		}
+	}
+}
+
+// TestReportReplicasBelowRecommended verifies that
+// reportReplicasBelowRecommended emits a warning event only when the
+// "ReplicasBelowRecommended" condition becomes true.
+func TestReportReplicasBelowRecommended(t *testing.T) {
+	below := operatorv1.OperatorCondition{
+		Type:    IngressControllerReplicasBelowRecommendedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ReplicasBelowRecommended",
+		Message: "spec.replicas is 1, which is below the recommended minimum of 2 for the HighlyAvailable topology; the router deployment has 1 replica, which is a single point of failure.",
+	}
+	meets := operatorv1.OperatorCondition{
+		Type:   IngressControllerReplicasBelowRecommendedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "ReplicasMeetRecommendation",
+	}
+	tests := []struct {
+		name        string
+		previous    []operatorv1.OperatorCondition
+		condition   operatorv1.OperatorCondition
+		expectEvent bool
+	}{
+		{
+			name:        "condition becomes true",
+			previous:    []operatorv1.OperatorCondition{meets},
+			condition:   below,
+			expectEvent: true,
+		},
+		{
+			name:        "condition is new and true",
+			condition:   below,
+			expectEvent: true,
+		},
+		{
+			name:        "condition stays true",
+			previous:    []operatorv1.OperatorCondition{below},
+			condition:   below,
+			expectEvent: false,
+		},
+		{
+			name:        "condition is false",
+			previous:    []operatorv1.OperatorCondition{below},
+			condition:   meets,
+			expectEvent: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			r := &reconciler{recorder: recorder}
+			previous := &operatorv1.IngressController{
+				Status: operatorv1.IngressControllerStatus{Conditions: test.previous},
+			}
+			r.reportReplicasBelowRecommended(previous, previous.DeepCopy(), test.condition)
+			select {
+			case event := <-recorder.Events:
+				if !test.expectEvent {
+					t.Errorf("expected no event, got %q", event)
+				} else if expect := "Warning ReplicasBelowRecommended " + below.Message; event != expect {
+					t.Errorf("expected event %q, got %q", expect, event)
+				}
+			default:
+				if test.expectEvent {
+					t.Error("expected an event")
+				}
+			}
+		})
+	}
+}
+
+// TestComputeEffectiveReplicasCondition verifies that
+// computeEffectiveReplicasCondition reports the number of replicas that
+// determineDeploymentReplicas chooses and the rule that chose it.
+func TestComputeEffectiveReplicasCondition(t *testing.T) {
+	one, three := int32(1), int32(3)
+	tests := []struct {
+		name                 string
+		replicas             *int32
+		defaultPlacement     configv1.DefaultPlacement
+		infraTopology        configv1.TopologyMode
+		controlPlaneTopology configv1.TopologyMode
+		expectReason         string
+		expectReplicas       string
+	}{
+		{
+			name:           "explicit replicas, single-replica workers",
+			replicas:       &three,
+			infraTopology:  configv1.SingleReplicaTopologyMode,
+			expectReason:   "SpecReplicas",
+			expectReplicas: "3 replicas",
+		},
+		{
+			name:           "explicit 1 replica, highly available workers",
+			replicas:       &one,
+			infraTopology:  configv1.HighlyAvailableTopologyMode,
+			expectReason:   "SpecReplicas",
+			expectReplicas: "1 replica ",
+		},
+		{
+			name:           "default replicas, single-replica workers",
+			infraTopology:  configv1.SingleReplicaTopologyMode,
+			expectReason:   "SingleReplicaTopology",
+			expectReplicas: "1 replica",
+		},
+		{
+			name:                 "default replicas, control-plane placement, single-replica control plane",
+			defaultPlacement:     configv1.DefaultPlacementControlPlane,
+			infraTopology:        configv1.HighlyAvailableTopologyMode,
+			controlPlaneTopology: configv1.SingleReplicaTopologyMode,
+			expectReason:         "SingleReplicaTopology",
+			expectReplicas:       "1 replica",
+		},
+		{
+			name:           "default replicas, highly available workers",
+			infraTopology:  configv1.HighlyAvailableTopologyMode,
+			expectReason:   "DefaultReplicas",
+			expectReplicas: "2 replicas",
+		},
+	}
+
+	for _, test := range tests {
+		ic := &operatorv1.IngressController{
+			Spec: operatorv1.IngressControllerSpec{Replicas: test.replicas},
+		}
+		ingressConfig := &configv1.Ingress{
+			Status: configv1.IngressStatus{DefaultPlacement: test.defaultPlacement},
+		}
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{
+				InfrastructureTopology: test.infraTopology,
+				ControlPlaneTopology:   test.controlPlaneTopology,
+			},
+		}
+		actual := computeEffectiveReplicasCondition(ic, nil, ingressConfig, infraConfig)
+		if actual.Status != operatorv1.ConditionTrue || actual.Reason != test.expectReason {
+			t.Errorf("%q: expected status True and reason %q, got %v and %q", test.name, test.expectReason, actual.Status, actual.Reason)
+		}
+		if !strings.Contains(actual.Message, test.expectReplicas) {
+			t.Errorf("%q: expected message to contain %q, got %q", test.name, test.expectReplicas, actual.Message)
+		}
+	}
+}
+
+// TestComputeEffectiveReplicasConditionNodeCount verifies that
+// computeEffectiveReplicasCondition reports the router deployment's replicas
+// if they follow the node count.
+func TestComputeEffectiveReplicasConditionNodeCount(t *testing.T) {
+	five := int32(5)
+	ic := &operatorv1.IngressController{
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"replicasFromNodeCount":true}`)},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: operatorv1.HostNetworkStrategyType},
+		},
+	}
+	deployment := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &five}}
+	ingressConfig := &configv1.Ingress{}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{InfrastructureTopology: configv1.HighlyAvailableTopologyMode},
+	}
+	actual := computeEffectiveReplicasCondition(ic, deployment, ingressConfig, infraConfig)
+	if actual.Reason != "NodeCount" || !strings.Contains(actual.Message, "5 replicas") {
+		t.Errorf("expected reason NodeCount and a message reporting 5 replicas, got %q and %q", actual.Reason, actual.Message)
+	}
+}
+
+// TestComputeHostNetworkNodeIPsAvailableCondition verifies that
+// computeHostNetworkNodeIPsAvailableCondition reports the IP addresses of the
+// nodes that are running the ingresscontroller's router pods.
+func TestComputeHostNetworkNodeIPsAvailableCondition(t *testing.T) {
+	routerLabels := map[string]string{
+		"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "default",
+	}
+	otherLabels := map[string]string{
+		"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "other",
+	}
+	pod := func(name string, podLabels map[string]string, phase corev1.PodPhase, hostIP string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: podLabels},
+			Status:     corev1.PodStatus{Phase: phase, HostIP: hostIP},
+		}
+	}
+	tests := []struct {
+		name          string
+		strategy      operatorv1.EndpointPublishingStrategyType
+		pods          []corev1.Pod
+		expectStatus  operatorv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			name:         "load balancer strategy",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			pods:         []corev1.Pod{pod("router-1", routerLabels, corev1.PodRunning, "10.0.0.1")},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "EndpointPublishingStrategyExcludesHostNetwork",
+		},
+		{
+			name:         "no pods",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "NoRouterPodsRunning",
+		},
+		{
+			name:     "only pending and unrelated pods",
+			strategy: operatorv1.HostNetworkStrategyType,
+			pods: []corev1.Pod{
+				pod("router-1", routerLabels, corev1.PodPending, ""),
+				pod("other-1", otherLabels, corev1.PodRunning, "10.0.0.9"),
+			},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "NoRouterPodsRunning",
+		},
+		{
+			name:     "one running pod",
+			strategy: operatorv1.HostNetworkStrategyType,
+			pods: []corev1.Pod{
+				pod("router-1", routerLabels, corev1.PodRunning, "10.0.0.1"),
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "RouterPodsRunning",
+			expectMessage: "Router pods are running on nodes with the following IP addresses: 10.0.0.1",
+		},
+		{
+			name:     "several running pods",
+			strategy: operatorv1.HostNetworkStrategyType,
+			pods: []corev1.Pod{
+				pod("router-3", routerLabels, corev1.PodRunning, "10.0.0.3"),
+				pod("router-1", routerLabels, corev1.PodRunning, "10.0.0.1"),
+				pod("router-2", routerLabels, corev1.PodPending, "10.0.0.2"),
+				pod("router-4", routerLabels, corev1.PodRunning, "10.0.0.1"),
+				pod("other-1", otherLabels, corev1.PodRunning, "10.0.0.9"),
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "RouterPodsRunning",
+			expectMessage: "Router pods are running on nodes with the following IP addresses: 10.0.0.1, 10.0.0.3",
+		},
+	}
+
+	selector := labels.SelectorFromSet(routerLabels)
+	for _, test := range tests {
+		ic := &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: test.strategy},
+			},
+		}
+		actual := computeHostNetworkNodeIPsAvailableCondition(ic, selector, test.pods)
+		if actual.Status != test.expectStatus || actual.Reason != test.expectReason {
+			t.Errorf("%q: expected status %v and reason %q, got %v and %q", test.name, test.expectStatus, test.expectReason, actual.Status, actual.Reason)
+		}
+		if len(test.expectMessage) != 0 && actual.Message != test.expectMessage {
+			t.Errorf("%q: expected message %q, got %q", test.name, test.expectMessage, actual.Message)
+		}
+	}
+}
+
+// TestComputeRouterPodsReadyCondition verifies that
+// computeRouterPodsReadyCondition reports the number of ready router pods and
+// the names of unready router pods.
+func TestComputeRouterPodsReadyCondition(t *testing.T) {
+	routerLabels := map[string]string{
+		"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "default",
+	}
+	otherLabels := map[string]string{
+		"ingresscontroller.operator.openshift.io/deployment-ingresscontroller": "other",
+	}
+	pod := func(name string, podLabels map[string]string, ready corev1.ConditionStatus) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: podLabels}}
+		if len(ready) != 0 {
+			p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+		}
+		return p
+	}
+	terminating := pod("router-5", routerLabels, corev1.ConditionFalse)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	tests := []struct {
+		name          string
+		pods          []corev1.Pod
+		expectStatus  operatorv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			name:          "no pods",
+			pods:          []corev1.Pod{pod("other-1", otherLabels, corev1.ConditionTrue)},
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "NoRouterPods",
+			expectMessage: "No router pods exist.",
+		},
+		{
+			name: "all ready",
+			pods: []corev1.Pod{
+				pod("router-1", routerLabels, corev1.ConditionTrue),
+				pod("router-2", routerLabels, corev1.ConditionTrue),
+				terminating,
+			},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "AllRouterPodsReady",
+			expectMessage: "2 of 2 router pods are ready.",
+		},
+		{
+			name: "mix of ready and unready pods",
+			pods: []corev1.Pod{
+				pod("router-3", routerLabels, corev1.ConditionFalse),
+				pod("router-1", routerLabels, corev1.ConditionTrue),
+				pod("router-2", routerLabels, ""),
+				pod("router-4", routerLabels, corev1.ConditionTrue),
+				pod("other-1", otherLabels, corev1.ConditionFalse),
+				terminating,
+			},
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "RouterPodsNotReady",
+			expectMessage: "2 of 4 router pods are ready.  Unready pods: router-2, router-3",
+		},
+	}
+
+	selector := labels.SelectorFromSet(routerLabels)
+	for _, test := range tests {
+		actual := computeRouterPodsReadyCondition(selector, test.pods)
+		if actual.Status != test.expectStatus || actual.Reason != test.expectReason {
+			t.Errorf("%q: expected status %v and reason %q, got %v and %q", test.name, test.expectStatus, test.expectReason, actual.Status, actual.Reason)
+		}
+		if actual.Message != test.expectMessage {
+			t.Errorf("%q: expected message %q, got %q", test.name, test.expectMessage, actual.Message)
+		}
+	}
+}
+
+// TestComputeInsufficientNodesForAntiAffinityCondition verifies that
+// computeInsufficientNodesForAntiAffinityCondition reports when a router
+// deployment that requires pod anti-affinity is stuck below its desired number
+// of ready replicas because pods cannot be scheduled on separate nodes.
+func TestComputeInsufficientNodesForAntiAffinityCondition(t *testing.T) {
+	ic, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded := getRouterDeploymentComponents(t)
+	replicas := int32(3)
+	ic.Spec.Replicas = &replicas
+	desired, err := desiredRouterDeployment(ic, ingressControllerImage, ingressConfig, infraConfig, apiConfig, networkConfig, proxyNeeded, false, nil)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if !hasRequiredPodAntiAffinity(desired) {
+		t.Fatal("expected the desired router deployment to require pod anti-affinity")
+	}
+	withoutAntiAffinity := desired.DeepCopy()
+	withoutAntiAffinity.Spec.Template.Spec.Affinity = nil
+
+	withReadyReplicas := func(deployment *appsv1.Deployment, ready int32) *appsv1.Deployment {
+		deployment = deployment.DeepCopy()
+		deployment.Status.ReadyReplicas = ready
+		return deployment
+	}
+	pod := func(name string, scheduled corev1.PodCondition) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: desired.Spec.Selector.MatchLabels},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{scheduled}},
+		}
+	}
+	scheduled := corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}
+	blockedByAntiAffinity := corev1.PodCondition{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/3 nodes are available: 2 node(s) didn't match pod anti-affinity rules, 1 node(s) had taint {node-role.kubernetes.io/master: }, that the pod didn't tolerate.",
+	}
+	blockedByResources := corev1.PodCondition{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+	}
+	tests := []struct {
+		name         string
+		deployment   *appsv1.Deployment
+		pods         []corev1.Pod
+		expectStatus operatorv1.ConditionStatus
+		expectReason string
+	}{
+		{
+			name:         "anti-affinity not required",
+			deployment:   withReadyReplicas(withoutAntiAffinity, 2),
+			pods:         []corev1.Pod{pod("router-1", scheduled), pod("router-2", scheduled), pod("router-3", blockedByAntiAffinity)},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "AntiAffinityNotRequired",
+		},
+		{
+			name:         "all replicas ready",
+			deployment:   withReadyReplicas(desired, 3),
+			pods:         []corev1.Pod{pod("router-1", scheduled), pod("router-2", scheduled), pod("router-3", scheduled)},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "DesiredReplicasReady",
+		},
+		{
+			name:         "unschedulable for another reason",
+			deployment:   withReadyReplicas(desired, 2),
+			pods:         []corev1.Pod{pod("router-1", scheduled), pod("router-2", scheduled), pod("router-3", blockedByResources)},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "NoPodsBlockedByAntiAffinity",
+		},
+		{
+			name:         "stuck because of anti-affinity",
+			deployment:   withReadyReplicas(desired, 2),
+			pods:         []corev1.Pod{pod("router-1", scheduled), pod("router-2", scheduled), pod("router-3", blockedByAntiAffinity)},
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "PodsBlockedByAntiAffinity",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := computeInsufficientNodesForAntiAffinityCondition(test.deployment, test.pods)
+			if actual.Type != IngressControllerInsufficientNodesForAntiAffinityConditionType {
+				t.Errorf("expected condition type %q, got %q", IngressControllerInsufficientNodesForAntiAffinityConditionType, actual.Type)
+			}
+			if actual.Status != test.expectStatus || actual.Reason != test.expectReason {
+				t.Errorf("expected status %v and reason %q, got %v and %q: %s", test.expectStatus, test.expectReason, actual.Status, actual.Reason, actual.Message)
+			}
+			if actual.Status == operatorv1.ConditionTrue && !strings.Contains(actual.Message, "router-3") {
+				t.Errorf("expected message to name the blocked pod, got %q", actual.Message)
+			}
+		})
+	}
+}
+
 func TestComputeDeploymentReplicasMinAvailableCondition(t *testing.T) {
 	pointerToInt32 := func(i int32) *int32 { return &i }
 	pointerToIntVal := func(val intstr.IntOrString) *intstr.IntOrString { return &val }
@@ -1081,6 +1782,31 @@ func TestComputeIngressAvailableCondition(t *testing.T) {
 			},
 			expect: operatorv1.OperatorCondition{Type: operatorv1.OperatorStatusTypeAvailable, Status: operatorv1.ConditionFalse},
 		},
+		{
+			description: "service not provisioned, but deployment, dns, and lb available",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: IngressControllerDeploymentAvailableConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.DNSManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.DNSReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.LoadBalancerManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.LoadBalancerReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: IngressControllerServiceProvisionedConditionType, Status: operatorv1.ConditionFalse},
+			},
+			expect: operatorv1.OperatorCondition{Type: operatorv1.OperatorStatusTypeAvailable, Status: operatorv1.ConditionFalse},
+		},
+		{
+			description: "certificate invalid, but everything else available",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: IngressControllerDeploymentAvailableConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.DNSManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.DNSReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.LoadBalancerManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: operatorv1.LoadBalancerReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+				{Type: IngressControllerServiceProvisionedConditionType, Status: operatorv1.ConditionTrue},
+				{Type: IngressControllerCertificateValidConditionType, Status: operatorv1.ConditionFalse},
+			},
+			expect: operatorv1.OperatorCondition{Type: operatorv1.OperatorStatusTypeAvailable, Status: operatorv1.ConditionTrue},
+		},
 		{
 			description: "all availability unknown",
 			conditions: []operatorv1.OperatorCondition{
@@ -1100,7 +1826,7 @@ func TestComputeIngressAvailableCondition(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		actual := computeIngressAvailableCondition(tc.conditions)
+		actual := computeIngressAvailableCondition(tc.conditions, &unsupportedConfigOverrides{})
 		conditionsCmpOpts := []cmp.Option{
 			cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime", "Reason", "Message"),
 			cmpopts.EquateEmpty(),
@@ -1488,3 +2214,554 @@ func TestComputeIngressUpgradeableCondition(t *testing.T) {
 		})
 	}
 }
+
+// fakeResolver is a resolver that returns fixed results.
+type fakeResolver struct {
+	hosts map[string][]string
+	cname map[string]string
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	if cname, ok := r.cname[host]; ok {
+		return cname, nil
+	}
+	return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// TestComputeDNSVerifiedCondition verifies that computeDNSVerifiedCondition
+// reports the wildcard DNS record as verified only once it resolves to the
+// record's targets.
+func TestComputeDNSVerifiedCondition(t *testing.T) {
+	defer func(old resolver) { dnsResolver = old }(dnsResolver)
+
+	dnsReady := []operatorv1.OperatorCondition{{Type: operatorv1.DNSReadyIngressConditionType, Status: operatorv1.ConditionTrue}}
+	dnsNotReady := []operatorv1.OperatorCondition{{Type: operatorv1.DNSReadyIngressConditionType, Status: operatorv1.ConditionFalse}}
+	aRecord := &iov1.DNSRecord{
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com.",
+			RecordType: iov1.ARecordType,
+			Targets:    []string{"192.0.2.1", "192.0.2.2"},
+		},
+	}
+	cnameRecord := &iov1.DNSRecord{
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com.",
+			RecordType: iov1.CNAMERecordType,
+			Targets:    []string{"lb.example.com"},
+		},
+	}
+	const host = "dns-verification.apps.example.com."
+
+	testCases := []struct {
+		description  string
+		conditions   []operatorv1.OperatorCondition
+		record       *iov1.DNSRecord
+		resolver     *fakeResolver
+		expectStatus operatorv1.ConditionStatus
+		expectError  bool
+	}{
+		{
+			description:  "record not ready",
+			conditions:   dnsNotReady,
+			record:       aRecord,
+			resolver:     &fakeResolver{hosts: map[string][]string{host: {"192.0.2.1", "192.0.2.2"}}},
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:  "record not found",
+			conditions:   dnsReady,
+			record:       nil,
+			resolver:     &fakeResolver{},
+			expectStatus: operatorv1.ConditionFalse,
+		},
+		{
+			description:  "name does not resolve",
+			conditions:   dnsReady,
+			record:       aRecord,
+			resolver:     &fakeResolver{},
+			expectStatus: operatorv1.ConditionFalse,
+			expectError:  true,
+		},
+		{
+			description:  "name resolves to some of the targets",
+			conditions:   dnsReady,
+			record:       aRecord,
+			resolver:     &fakeResolver{hosts: map[string][]string{host: {"192.0.2.1"}}},
+			expectStatus: operatorv1.ConditionFalse,
+			expectError:  true,
+		},
+		{
+			description:  "name resolves to all of the targets",
+			conditions:   dnsReady,
+			record:       aRecord,
+			resolver:     &fakeResolver{hosts: map[string][]string{host: {"192.0.2.2", "192.0.2.1"}}},
+			expectStatus: operatorv1.ConditionTrue,
+		},
+		{
+			description:  "cname resolves to another name",
+			conditions:   dnsReady,
+			record:       cnameRecord,
+			resolver:     &fakeResolver{cname: map[string]string{host: "old-lb.example.com."}},
+			expectStatus: operatorv1.ConditionFalse,
+			expectError:  true,
+		},
+		{
+			description:  "cname resolves to the target",
+			conditions:   dnsReady,
+			record:       cnameRecord,
+			resolver:     &fakeResolver{cname: map[string]string{host: "LB.example.com."}},
+			expectStatus: operatorv1.ConditionTrue,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			dnsResolver = tc.resolver
+			actual, err := computeDNSVerifiedCondition(tc.conditions, tc.record)
+			if actual.Type != IngressControllerDNSVerifiedConditionType {
+				t.Errorf("expected condition type %s, got %s", IngressControllerDNSVerifiedConditionType, actual.Type)
+			}
+			if actual.Status != tc.expectStatus {
+				t.Errorf("expected status %s, got %s (reason: %s, message: %s)", tc.expectStatus, actual.Status, actual.Reason, actual.Message)
+			}
+			switch {
+			case tc.expectError && err == nil:
+				t.Error("expected an error, got nil")
+			case !tc.expectError && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestComputeIngressAvailableConditionRequireVerifiedDNS verifies that
+// computeIngressAvailableCondition withholds the Available condition until
+// DNS is verified if verified DNS is required.
+func TestComputeIngressAvailableConditionRequireVerifiedDNS(t *testing.T) {
+	available := []operatorv1.OperatorCondition{
+		{Type: IngressControllerDeploymentAvailableConditionType, Status: operatorv1.ConditionTrue},
+		{Type: operatorv1.DNSManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+		{Type: operatorv1.DNSReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+		{Type: operatorv1.LoadBalancerManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+		{Type: operatorv1.LoadBalancerReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+	}
+	withDNSVerified := func(status operatorv1.ConditionStatus) []operatorv1.OperatorCondition {
+		return append(append([]operatorv1.OperatorCondition{}, available...), operatorv1.OperatorCondition{
+			Type:   IngressControllerDNSVerifiedConditionType,
+			Status: status,
+		})
+	}
+	unmanagedDNS := []operatorv1.OperatorCondition{
+		{Type: IngressControllerDeploymentAvailableConditionType, Status: operatorv1.ConditionTrue},
+		{Type: operatorv1.DNSManagedIngressConditionType, Status: operatorv1.ConditionFalse},
+		{Type: operatorv1.LoadBalancerManagedIngressConditionType, Status: operatorv1.ConditionTrue},
+		{Type: operatorv1.LoadBalancerReadyIngressConditionType, Status: operatorv1.ConditionTrue},
+		{Type: IngressControllerDNSVerifiedConditionType, Status: operatorv1.ConditionFalse},
+	}
+
+	testCases := []struct {
+		description        string
+		conditions         []operatorv1.OperatorCondition
+		requireVerifiedDNS bool
+		expect             operatorv1.ConditionStatus
+	}{
+		{
+			description:        "not required, not verified",
+			conditions:         withDNSVerified(operatorv1.ConditionFalse),
+			requireVerifiedDNS: false,
+			expect:             operatorv1.ConditionTrue,
+		},
+		{
+			description:        "required, not verified",
+			conditions:         withDNSVerified(operatorv1.ConditionFalse),
+			requireVerifiedDNS: true,
+			expect:             operatorv1.ConditionFalse,
+		},
+		{
+			description:        "required, verified",
+			conditions:         withDNSVerified(operatorv1.ConditionTrue),
+			requireVerifiedDNS: true,
+			expect:             operatorv1.ConditionTrue,
+		},
+		{
+			description:        "required, DNS not managed",
+			conditions:         unmanagedDNS,
+			requireVerifiedDNS: true,
+			expect:             operatorv1.ConditionTrue,
+		},
+	}
+	for _, tc := range testCases {
+		actual := computeIngressAvailableCondition(tc.conditions, &unsupportedConfigOverrides{RequireVerifiedDNS: tc.requireVerifiedDNS})
+		if actual.Status != tc.expect {
+			t.Errorf("%q: expected Available=%s, got %s (message: %s)", tc.description, tc.expect, actual.Status, actual.Message)
+		}
+	}
+}
+
+// TestComputeServiceProvisionedCondition verifies that
+// computeServiceProvisionedCondition reports whether the service that the
+// endpoint publishing strategy requires is provisioned.
+func TestComputeServiceProvisionedCondition(t *testing.T) {
+	newIngressController := func(strategy operatorv1.EndpointPublishingStrategyType) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+	}
+	pendingLB := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"}}
+	provisionedLB := pendingLB.DeepCopy()
+	provisionedLB.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}
+	nodePort := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-nodeport-default"}}
+
+	testCases := []struct {
+		description     string
+		strategy        operatorv1.EndpointPublishingStrategyType
+		lbService       *corev1.Service
+		nodePortService *corev1.Service
+		expectStatus    operatorv1.ConditionStatus
+		expectReason    string
+	}{
+		{
+			description:  "load balancer service missing",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "ServiceNotFound",
+		},
+		{
+			description:  "load balancer pending",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			lbService:    pendingLB,
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "LoadBalancerPending",
+		},
+		{
+			description:  "load balancer provisioned",
+			strategy:     operatorv1.LoadBalancerServiceStrategyType,
+			lbService:    provisionedLB,
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "LoadBalancerProvisioned",
+		},
+		{
+			description:  "nodeport service missing",
+			strategy:     operatorv1.NodePortServiceStrategyType,
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "ServiceNotFound",
+		},
+		{
+			description:     "nodeport service exists",
+			strategy:        operatorv1.NodePortServiceStrategyType,
+			nodePortService: nodePort,
+			expectStatus:    operatorv1.ConditionTrue,
+			expectReason:    "NodePortServiceProvisioned",
+		},
+		{
+			description:  "host network",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "NoServiceRequired",
+		},
+	}
+	for _, tc := range testCases {
+		actual := computeServiceProvisionedCondition(newIngressController(tc.strategy), tc.lbService, tc.nodePortService)
+		if actual.Type != IngressControllerServiceProvisionedConditionType || actual.Status != tc.expectStatus || actual.Reason != tc.expectReason {
+			t.Errorf("%q: expected %s=%s with reason %s, got %s=%s with reason %s", tc.description, IngressControllerServiceProvisionedConditionType, tc.expectStatus, tc.expectReason, actual.Type, actual.Status, actual.Reason)
+		}
+	}
+}
+
+// TestComputeCertificateValidCondition verifies that
+// computeCertificateValidCondition reports whether the default certificate is
+// present, parsable, and currently valid.
+func TestComputeCertificateValidCondition(t *testing.T) {
+	now := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	makeSecret := func(notBefore, notAfter time.Time) *corev1.Secret {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		certTemplate := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "*.apps.example.com"},
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+		}
+		cert, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, &key.PublicKey, key)
+		if err != nil {
+			t.Fatalf("failed to generate certificate: %v", err)
+		}
+		return &corev1.Secret{
+			Data: map[string][]byte{"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})},
+		}
+	}
+	secretName := types.NamespacedName{Namespace: "openshift-ingress", Name: "router-certs-default"}
+
+	testCases := []struct {
+		description  string
+		secret       *corev1.Secret
+		expectStatus operatorv1.ConditionStatus
+		expectReason string
+	}{
+		{
+			description:  "secret not found",
+			secret:       &corev1.Secret{},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "CertificateNotFound",
+		},
+		{
+			description:  "not PEM-encoded",
+			secret:       &corev1.Secret{Data: map[string][]byte{"tls.crt": []byte("garbage")}},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "InvalidCertificate",
+		},
+		{
+			description:  "invalid certificate",
+			secret:       &corev1.Secret{Data: map[string][]byte{"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})}},
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "InvalidCertificate",
+		},
+		{
+			description:  "not yet valid",
+			secret:       makeSecret(now.Add(time.Hour), now.Add(48*time.Hour)),
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "CertificateNotYetValid",
+		},
+		{
+			description:  "expired",
+			secret:       makeSecret(now.Add(-48*time.Hour), now.Add(-time.Hour)),
+			expectStatus: operatorv1.ConditionFalse,
+			expectReason: "CertificateExpired",
+		},
+		{
+			description:  "valid",
+			secret:       makeSecret(now.Add(-time.Hour), now.Add(48*time.Hour)),
+			expectStatus: operatorv1.ConditionTrue,
+			expectReason: "CertificateValid",
+		},
+	}
+	for _, tc := range testCases {
+		actual := computeCertificateValidCondition(secretName, tc.secret, now)
+		if actual.Type != IngressControllerCertificateValidConditionType || actual.Status != tc.expectStatus || actual.Reason != tc.expectReason {
+			t.Errorf("%q: expected %s=%s with reason %s, got %s=%s with reason %s (message: %s)", tc.description, IngressControllerCertificateValidConditionType, tc.expectStatus, tc.expectReason, actual.Type, actual.Status, actual.Reason, actual.Message)
+		}
+	}
+}
+
+// TestCertificateFailurePolicy verifies that an invalid default certificate
+// makes the ingresscontroller degraded in both fail-open and fail-closed modes
+// and also makes it unavailable in fail-closed mode.
+func TestCertificateFailurePolicy(t *testing.T) {
+	now := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.apps.example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(48 * time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to generate certificate: %v", err)
+	}
+	validSecret := &corev1.Secret{
+		Data: map[string][]byte{"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})},
+	}
+	expiredSecret := validSecret.DeepCopy()
+	secretName := types.NamespacedName{Namespace: "openshift-ingress", Name: "router-certs-default"}
+
+	testCases := []struct {
+		description     string
+		policy          string
+		secret          *corev1.Secret
+		now             time.Time
+		expectAvailable operatorv1.ConditionStatus
+		expectDegraded  operatorv1.ConditionStatus
+	}{
+		{
+			description:     "fail open, valid certificate",
+			policy:          certificateFailurePolicyFailOpen,
+			secret:          validSecret,
+			now:             now,
+			expectAvailable: operatorv1.ConditionTrue,
+			expectDegraded:  operatorv1.ConditionFalse,
+		},
+		{
+			description:     "fail open, expired certificate",
+			policy:          certificateFailurePolicyFailOpen,
+			secret:          expiredSecret,
+			now:             now.Add(72 * time.Hour),
+			expectAvailable: operatorv1.ConditionTrue,
+			expectDegraded:  operatorv1.ConditionTrue,
+		},
+		{
+			description:     "fail open, invalid certificate",
+			policy:          "",
+			secret:          &corev1.Secret{Data: map[string][]byte{"tls.crt": []byte("garbage")}},
+			now:             now,
+			expectAvailable: operatorv1.ConditionTrue,
+			expectDegraded:  operatorv1.ConditionTrue,
+		},
+		{
+			description:     "fail closed, valid certificate",
+			policy:          certificateFailurePolicyFailClosed,
+			secret:          validSecret,
+			now:             now,
+			expectAvailable: operatorv1.ConditionTrue,
+			expectDegraded:  operatorv1.ConditionFalse,
+		},
+		{
+			description:     "fail closed, expired certificate",
+			policy:          certificateFailurePolicyFailClosed,
+			secret:          expiredSecret,
+			now:             now.Add(72 * time.Hour),
+			expectAvailable: operatorv1.ConditionFalse,
+			expectDegraded:  operatorv1.ConditionTrue,
+		},
+		{
+			description:     "fail closed, invalid certificate",
+			policy:          certificateFailurePolicyFailClosed,
+			secret:          &corev1.Secret{Data: map[string][]byte{"tls.crt": []byte("garbage")}},
+			now:             now,
+			expectAvailable: operatorv1.ConditionFalse,
+			expectDegraded:  operatorv1.ConditionTrue,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			conditions := []operatorv1.OperatorCondition{
+				cond(IngressControllerAdmittedConditionType, operatorv1.ConditionTrue, "", clock.Now()),
+				cond(IngressControllerDeploymentAvailableConditionType, operatorv1.ConditionTrue, "", clock.Now()),
+				cond(IngressControllerServiceProvisionedConditionType, operatorv1.ConditionTrue, "", clock.Now()),
+				computeCertificateValidCondition(secretName, tc.secret, tc.now),
+			}
+			overrides := &unsupportedConfigOverrides{CertificateFailurePolicy: tc.policy}
+			if actual := computeIngressAvailableCondition(conditions, overrides); actual.Status != tc.expectAvailable {
+				t.Errorf("expected Available=%s, got %s: %s", tc.expectAvailable, actual.Status, actual.Message)
+			}
+			degraded, _ := computeIngressDegradedCondition(conditions, "custom")
+			if degraded.Status != tc.expectDegraded {
+				t.Errorf("expected Degraded=%s, got %s: %s", tc.expectDegraded, degraded.Status, degraded.Message)
+			}
+		})
+	}
+}
+
+// TestComputeDNSProviderCondition verifies that computeDNSProviderCondition
+// reports the DNS provider and zones for each platform.
+func TestComputeDNSProviderCondition(t *testing.T) {
+	zones := configv1.DNSSpec{
+		PrivateZone: &configv1.DNSZone{Tags: map[string]string{"Name": "infra-int", "kubernetes.io/cluster/infra": "owned"}},
+		PublicZone:  &configv1.DNSZone{ID: "Z3URY6TWQ91KVV"},
+	}
+	idZones := configv1.DNSSpec{
+		PrivateZone: &configv1.DNSZone{ID: "private-zone-id"},
+		PublicZone:  &configv1.DNSZone{ID: "public-zone-id"},
+	}
+	testCases := []struct {
+		description   string
+		platform      configv1.PlatformType
+		topology      configv1.TopologyMode
+		dnsSpec       configv1.DNSSpec
+		expectStatus  operatorv1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			description:   "no zones",
+			platform:      configv1.AWSPlatformType,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "NoDNSZones",
+			expectMessage: "No DNS zones are defined in the cluster dns config.",
+		},
+		{
+			description:   "AWS with tagged private zone",
+			platform:      configv1.AWSPlatformType,
+			dnsSpec:       zones,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "AWS",
+			expectMessage: `DNS records are published using AWS Route 53 to private zone with tags "Name=infra-int,kubernetes.io/cluster/infra=owned" and public zone with ID "Z3URY6TWQ91KVV".`,
+		},
+		{
+			description:   "Azure",
+			platform:      configv1.AzurePlatformType,
+			dnsSpec:       idZones,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "Azure",
+			expectMessage: `DNS records are published using Azure DNS to private zone with ID "private-zone-id" and public zone with ID "public-zone-id".`,
+		},
+		{
+			description:   "GCP public zone only",
+			platform:      configv1.GCPPlatformType,
+			dnsSpec:       configv1.DNSSpec{PublicZone: idZones.PublicZone},
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "GCP",
+			expectMessage: `DNS records are published using Google Cloud DNS to public zone with ID "public-zone-id".`,
+		},
+		{
+			description:   "IBM Cloud",
+			platform:      configv1.IBMCloudPlatformType,
+			dnsSpec:       idZones,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "IBMCloud",
+			expectMessage: `DNS records are published using IBM Cloud Internet Services to private zone with ID "private-zone-id" and public zone with ID "public-zone-id".`,
+		},
+		{
+			description:   "IBM Cloud with external control plane",
+			platform:      configv1.IBMCloudPlatformType,
+			topology:      configv1.ExternalTopologyMode,
+			dnsSpec:       idZones,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "UnsupportedPlatform",
+			expectMessage: `DNS records are not published on platform "IBMCloud".`,
+		},
+		{
+			description:   "Power VS",
+			platform:      configv1.PowerVSPlatformType,
+			dnsSpec:       idZones,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "PowerVS",
+			expectMessage: `DNS records are published using IBM Cloud Internet Services to private zone with ID "private-zone-id" and public zone with ID "public-zone-id".`,
+		},
+		{
+			description:   "Alibaba Cloud",
+			platform:      configv1.AlibabaCloudPlatformType,
+			dnsSpec:       idZones,
+			expectStatus:  operatorv1.ConditionTrue,
+			expectReason:  "AlibabaCloud",
+			expectMessage: `DNS records are published using Alibaba Cloud DNS to private zone with ID "private-zone-id" and public zone with ID "public-zone-id".`,
+		},
+		{
+			description:   "bare metal",
+			platform:      configv1.BareMetalPlatformType,
+			dnsSpec:       idZones,
+			expectStatus:  operatorv1.ConditionFalse,
+			expectReason:  "UnsupportedPlatform",
+			expectMessage: `DNS records are not published on platform "BareMetal".`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			platformStatus := &configv1.PlatformStatus{Type: tc.platform}
+			dnsConfig := &configv1.DNS{Spec: tc.dnsSpec}
+			infraConfig := &configv1.Infrastructure{
+				Status: configv1.InfrastructureStatus{ControlPlaneTopology: tc.topology},
+			}
+			actual := computeDNSProviderCondition(platformStatus, dnsConfig, infraConfig)
+			if actual.Type != IngressControllerDNSProviderConditionType {
+				t.Errorf("unexpected condition type: %s", actual.Type)
+			}
+			if actual.Status != tc.expectStatus || actual.Reason != tc.expectReason || actual.Message != tc.expectMessage {
+				t.Errorf("expected status %s, reason %s, message %q; got status %s, reason %s, message %q", tc.expectStatus, tc.expectReason, tc.expectMessage, actual.Status, actual.Reason, actual.Message)
+			}
+		})
+	}
+}
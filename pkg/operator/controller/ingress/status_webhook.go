@@ -0,0 +1,256 @@
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// defaultStatusWebhookTimeout is the time that the operator waits for
+	// a status webhook endpoint to respond if the statusWebhook
+	// unsupported config override does not specify a timeout.
+	defaultStatusWebhookTimeout = 5 * time.Second
+	// defaultStatusWebhookRetries is the number of times that the
+	// operator retries a failed status webhook notification if the
+	// statusWebhook unsupported config override does not specify a number
+	// of retries.
+	defaultStatusWebhookRetries = 3
+	// maxStatusWebhookTimeoutSeconds is the maximum value of the
+	// statusWebhook unsupported config override's timeoutSeconds field.
+	maxStatusWebhookTimeoutSeconds = 30
+	// maxStatusWebhookRetries is the maximum value of the statusWebhook
+	// unsupported config override's retries field.
+	maxStatusWebhookRetries = 10
+	// statusWebhookQueueSize is the number of status webhook notifications
+	// that may wait to be sent.  Notifications are dropped when the queue
+	// is full so that slow endpoints cannot block reconciliation.
+	statusWebhookQueueSize = 100
+)
+
+// statusWebhookRetryInterval is the time that the operator waits before
+// retrying a failed status webhook notification, multiplied by the number of
+// the attempt.  It is a variable so that tests can shorten it.
+var statusWebhookRetryInterval = time.Second
+
+// statusWebhookOverride specifies HTTP endpoints that the operator notifies
+// when an ingresscontroller becomes degraded or recovers.
+type statusWebhookOverride struct {
+	// Endpoints are the http or https URLs to which the operator POSTs a
+	// statusWebhookPayload.
+	Endpoints []string `json:"endpoints"`
+	// TimeoutSeconds is the number of seconds that the operator waits for
+	// an endpoint to respond, at most 30.  If it is zero, 5 seconds is
+	// used.
+	TimeoutSeconds int32 `json:"timeoutSeconds"`
+	// Retries is the number of times that the operator retries a
+	// notification that fails with a connection error or a 429 or 5xx
+	// response, at most 10.  If it is nil, 3 is used.
+	Retries *int32 `json:"retries"`
+}
+
+// timeout returns the time that the operator waits for an endpoint to respond.
+func (w *statusWebhookOverride) timeout() time.Duration {
+	if w.TimeoutSeconds > 0 {
+		return time.Duration(w.TimeoutSeconds) * time.Second
+	}
+	return defaultStatusWebhookTimeout
+}
+
+// retries returns the number of times that the operator retries a failed
+// notification.
+func (w *statusWebhookOverride) retries() int {
+	if w.Retries != nil {
+		return int(*w.Retries)
+	}
+	return defaultStatusWebhookRetries
+}
+
+// statusWebhookPayload is the body of a status webhook notification.
+type statusWebhookPayload struct {
+	Namespace          string                     `json:"namespace"`
+	IngressController  string                     `json:"ingressController"`
+	ConditionType      string                     `json:"conditionType"`
+	Status             operatorv1.ConditionStatus `json:"status"`
+	PreviousStatus     operatorv1.ConditionStatus `json:"previousStatus,omitempty"`
+	Reason             string                     `json:"reason,omitempty"`
+	Message            string                     `json:"message,omitempty"`
+	LastTransitionTime time.Time                  `json:"lastTransitionTime"`
+}
+
+// validateStatusWebhook returns errors for endpoints that are not absolute
+// http or https URLs, and for a timeout or number of retries that is negative
+// or exceeds its maximum.
+func validateStatusWebhook(webhook *statusWebhookOverride) []error {
+	var errs []error
+	if len(webhook.Endpoints) == 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.statusWebhook.endpoints: must not be empty"))
+	}
+	for _, endpoint := range webhook.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.statusWebhook.endpoints entry %q: %v", endpoint, err))
+			continue
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.statusWebhook.endpoints entry %q: must be an http or https URL with a host", endpoint))
+		}
+	}
+	if webhook.TimeoutSeconds < 0 || webhook.TimeoutSeconds > maxStatusWebhookTimeoutSeconds {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.statusWebhook.timeoutSeconds %d: must be between 0 and %d", webhook.TimeoutSeconds, maxStatusWebhookTimeoutSeconds))
+	}
+	if webhook.Retries != nil && (*webhook.Retries < 0 || *webhook.Retries > maxStatusWebhookRetries) {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.statusWebhook.retries %d: must be between 0 and %d", *webhook.Retries, maxStatusWebhookRetries))
+	}
+	return errs
+}
+
+// degradedTransition returns the "Degraded" condition in the given updated
+// conditions, along with the status of the condition in the given previous
+// conditions, if the ingresscontroller has become degraded or has recovered.
+// Returns nil if it has done neither.
+func degradedTransition(previous, updated []operatorv1.OperatorCondition) (*operatorv1.OperatorCondition, operatorv1.ConditionStatus) {
+	var previousStatus operatorv1.ConditionStatus
+	for _, cond := range previous {
+		if cond.Type == operatorv1.OperatorStatusTypeDegraded {
+			previousStatus = cond.Status
+		}
+	}
+	for i := range updated {
+		cond := &updated[i]
+		if cond.Type != operatorv1.OperatorStatusTypeDegraded {
+			continue
+		}
+		wasDegraded := previousStatus == operatorv1.ConditionTrue
+		isDegraded := cond.Status == operatorv1.ConditionTrue
+		if wasDegraded != isDegraded {
+			return cond, previousStatus
+		}
+	}
+	return nil, ""
+}
+
+// statusWebhookNotification is a status webhook notification that is waiting
+// to be sent.
+type statusWebhookNotification struct {
+	// ingressController is the ingresscontroller on which failures to
+	// notify are reported.
+	ingressController *operatorv1.IngressController
+	webhook           statusWebhookOverride
+	payload           statusWebhookPayload
+}
+
+// notifyStatusWebhooks queues a notification for the given ingresscontroller's
+// status webhook endpoints if the ingresscontroller has become degraded or has
+// recovered since its previous status.  The notification is sent by
+// runStatusWebhookNotifier so that slow or unreachable endpoints do not block
+// reconciliation.  If the queue is full, the notification is dropped and
+// reported with a warning event on the ingresscontroller rather than failing
+// the sync, because the transition is not detected again once the status is
+// updated.
+func (r *reconciler) notifyStatusWebhooks(previous, updated *operatorv1.IngressController) {
+	overrides, err := getUnsupportedConfigOverrides(updated)
+	if err != nil || overrides.StatusWebhook == nil {
+		return
+	}
+	cond, previousStatus := degradedTransition(previous.Status.Conditions, updated.Status.Conditions)
+	if cond == nil {
+		return
+	}
+	notification := statusWebhookNotification{
+		ingressController: updated.DeepCopy(),
+		webhook:           *overrides.StatusWebhook,
+		payload: statusWebhookPayload{
+			Namespace:          updated.Namespace,
+			IngressController:  updated.Name,
+			ConditionType:      cond.Type,
+			Status:             cond.Status,
+			PreviousStatus:     previousStatus,
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: cond.LastTransitionTime.Time,
+		},
+	}
+	select {
+	case r.statusWebhookQueue <- notification:
+	default:
+		log.Info("status webhook queue is full; dropping notification", "ingresscontroller", updated.Name, "condition", cond.Type, "status", cond.Status)
+		r.recorder.Eventf(updated, "Warning", "StatusWebhookFailed", "Dropped status webhook notification that condition %s changed to %s because too many notifications are pending", cond.Type, cond.Status)
+	}
+}
+
+// runStatusWebhookNotifier sends queued status webhook notifications until the
+// given context is done.
+func (r *reconciler) runStatusWebhookNotifier(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-r.statusWebhookQueue:
+			r.sendStatusWebhookNotification(ctx, notification)
+		}
+	}
+}
+
+// sendStatusWebhookNotification POSTs the given notification to each of its
+// endpoints.  Failed notifications are reported with a warning event on the
+// ingresscontroller.
+func (r *reconciler) sendStatusWebhookNotification(ctx context.Context, notification statusWebhookNotification) {
+	ic, payload, webhook := notification.ingressController, notification.payload, notification.webhook
+	client := &http.Client{Timeout: webhook.timeout()}
+	defer client.CloseIdleConnections()
+	for _, endpoint := range webhook.Endpoints {
+		if err := postStatusWebhook(ctx, client, endpoint, payload, webhook.retries()); err != nil {
+			log.Error(err, "failed to notify status webhook", "ingresscontroller", ic.Name, "endpoint", endpoint)
+			r.recorder.Eventf(ic, "Warning", "StatusWebhookFailed", "Failed to notify status webhook %s that condition %s changed to %s: %v", endpoint, payload.ConditionType, payload.Status, err)
+			continue
+		}
+		log.Info("notified status webhook", "ingresscontroller", ic.Name, "endpoint", endpoint, "condition", payload.ConditionType, "status", payload.Status)
+	}
+}
+
+// postStatusWebhook POSTs the given payload to the given endpoint, retrying up
+// to the given number of times if the request fails with a connection error or
+// a 429 or 5xx response.  It gives up early if the given context is done.
+func postStatusWebhook(ctx context.Context, client *http.Client, endpoint string, payload statusWebhookPayload, retries int) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("giving up after %d attempts: %w", attempt, ctx.Err())
+			case <-time.After(time.Duration(attempt) * statusWebhookRetryInterval):
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("endpoint responded with %s", resp.Status)
+		default:
+			return fmt.Errorf("endpoint responded with %s", resp.Status)
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", retries+1, lastErr)
+}
@@ -0,0 +1,326 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakeStatusWebhook is an HTTP server that records the notifications that it
+// receives and responds with the given status codes in order, followed by 200.
+type fakeStatusWebhook struct {
+	*httptest.Server
+
+	lock         sync.Mutex
+	responses    []int
+	contentTypes []string
+	payloads     []statusWebhookPayload
+}
+
+func newFakeStatusWebhook(t *testing.T, responses ...int) *fakeStatusWebhook {
+	w := &fakeStatusWebhook{responses: responses}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		var payload statusWebhookPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		w.contentTypes = append(w.contentTypes, req.Header.Get("Content-Type"))
+		w.payloads = append(w.payloads, payload)
+		code := http.StatusOK
+		if len(w.responses) > 0 {
+			code, w.responses = w.responses[0], w.responses[1:]
+		}
+		rw.WriteHeader(code)
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+func (w *fakeStatusWebhook) received() []statusWebhookPayload {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]statusWebhookPayload(nil), w.payloads...)
+}
+
+// TestDegradedTransition verifies that degradedTransition reports only when
+// the Degraded condition becomes true or stops being true.
+func TestDegradedTransition(t *testing.T) {
+	degraded := func(status operatorv1.ConditionStatus) []operatorv1.OperatorCondition {
+		return []operatorv1.OperatorCondition{
+			{Type: operatorv1.OperatorStatusTypeAvailable, Status: operatorv1.ConditionTrue},
+			{Type: operatorv1.OperatorStatusTypeDegraded, Status: status},
+		}
+	}
+	tests := []struct {
+		name           string
+		previous       []operatorv1.OperatorCondition
+		updated        []operatorv1.OperatorCondition
+		expectStatus   operatorv1.ConditionStatus
+		expectPrevious operatorv1.ConditionStatus
+	}{
+		{
+			name:    "new ingresscontroller that is not degraded",
+			updated: degraded(operatorv1.ConditionFalse),
+		},
+		{
+			name:         "new ingresscontroller that is degraded",
+			updated:      degraded(operatorv1.ConditionTrue),
+			expectStatus: operatorv1.ConditionTrue,
+		},
+		{
+			name:           "becomes degraded",
+			previous:       degraded(operatorv1.ConditionFalse),
+			updated:        degraded(operatorv1.ConditionTrue),
+			expectStatus:   operatorv1.ConditionTrue,
+			expectPrevious: operatorv1.ConditionFalse,
+		},
+		{
+			name:           "recovers",
+			previous:       degraded(operatorv1.ConditionTrue),
+			updated:        degraded(operatorv1.ConditionFalse),
+			expectStatus:   operatorv1.ConditionFalse,
+			expectPrevious: operatorv1.ConditionTrue,
+		},
+		{
+			name:     "remains degraded",
+			previous: degraded(operatorv1.ConditionTrue),
+			updated:  degraded(operatorv1.ConditionTrue),
+		},
+		{
+			name:     "unknown to not degraded",
+			previous: degraded(operatorv1.ConditionUnknown),
+			updated:  degraded(operatorv1.ConditionFalse),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cond, previous := degradedTransition(test.previous, test.updated)
+			switch {
+			case cond == nil && len(test.expectStatus) != 0:
+				t.Errorf("expected a transition to %s, got none", test.expectStatus)
+			case cond != nil && len(test.expectStatus) == 0:
+				t.Errorf("expected no transition, got a transition to %s", cond.Status)
+			case cond != nil && (cond.Status != test.expectStatus || previous != test.expectPrevious):
+				t.Errorf("expected a transition from %q to %q, got from %q to %q", test.expectPrevious, test.expectStatus, previous, cond.Status)
+			}
+		})
+	}
+}
+
+// TestPostStatusWebhook verifies that postStatusWebhook retries connection
+// errors and 429 and 5xx responses up to the given number of times and does
+// not retry other errors.
+func TestPostStatusWebhook(t *testing.T) {
+	interval := statusWebhookRetryInterval
+	statusWebhookRetryInterval = time.Millisecond
+	defer func() { statusWebhookRetryInterval = interval }()
+
+	tests := []struct {
+		name           string
+		responses      []int
+		retries        int
+		expectAttempts int
+		expectError    bool
+	}{
+		{
+			name:           "success",
+			retries:        3,
+			expectAttempts: 1,
+		},
+		{
+			name:           "success after retries",
+			responses:      []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			retries:        3,
+			expectAttempts: 3,
+		},
+		{
+			name:           "retries exhausted",
+			responses:      []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable},
+			retries:        2,
+			expectAttempts: 3,
+			expectError:    true,
+		},
+		{
+			name:           "no retries",
+			responses:      []int{http.StatusServiceUnavailable},
+			retries:        0,
+			expectAttempts: 1,
+			expectError:    true,
+		},
+		{
+			name:           "client error is not retried",
+			responses:      []int{http.StatusBadRequest},
+			retries:        3,
+			expectAttempts: 1,
+			expectError:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			webhook := newFakeStatusWebhook(t, test.responses...)
+			payload := statusWebhookPayload{IngressController: "default"}
+			err := postStatusWebhook(context.Background(), webhook.Client(), webhook.URL, payload, test.retries)
+			if test.expectError && err == nil {
+				t.Error("expected an error")
+			} else if !test.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if n := len(webhook.received()); n != test.expectAttempts {
+				t.Errorf("expected %d attempts, got %d", test.expectAttempts, n)
+			}
+		})
+	}
+
+	t.Run("connection error", func(t *testing.T) {
+		webhook := newFakeStatusWebhook(t)
+		url := webhook.URL
+		webhook.Close()
+		if err := postStatusWebhook(context.Background(), http.DefaultClient, url, statusWebhookPayload{}, 1); err == nil {
+			t.Error("expected an error")
+		} else if !strings.Contains(err.Error(), "after 2 attempts") {
+			t.Errorf("expected error to report 2 attempts, got %v", err)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		webhook := newFakeStatusWebhook(t, http.StatusServiceUnavailable)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := postStatusWebhook(ctx, webhook.Client(), webhook.URL, statusWebhookPayload{}, 3); err == nil {
+			t.Error("expected an error")
+		}
+		if n := len(webhook.received()); n != 0 {
+			t.Errorf("expected no attempts after the context is done, got %d", n)
+		}
+	})
+}
+
+// TestNotifyStatusWebhooks verifies that notifyStatusWebhooks queues a
+// notification when the ingresscontroller becomes degraded and that sending it
+// delivers the expected payload to every configured endpoint and reports failed
+// notifications with an event.
+func TestNotifyStatusWebhooks(t *testing.T) {
+	interval := statusWebhookRetryInterval
+	statusWebhookRetryInterval = time.Millisecond
+	defer func() { statusWebhookRetryInterval = interval }()
+
+	good := newFakeStatusWebhook(t)
+	bad := newFakeStatusWebhook(t, http.StatusNotFound)
+	overrides, err := json.Marshal(unsupportedConfigOverrides{
+		StatusWebhook: &statusWebhookOverride{Endpoints: []string{good.URL, bad.URL}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transitionTime := metav1.NewTime(time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC))
+	previous := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+		Spec: operatorv1.IngressControllerSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: overrides},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Conditions: []operatorv1.OperatorCondition{{
+				Type:   operatorv1.OperatorStatusTypeDegraded,
+				Status: operatorv1.ConditionFalse,
+			}},
+		},
+	}
+	updated := previous.DeepCopy()
+	updated.Status.Conditions = []operatorv1.OperatorCondition{{
+		Type:               operatorv1.OperatorStatusTypeDegraded,
+		Status:             operatorv1.ConditionTrue,
+		Reason:             "DegradedConditions",
+		Message:            "One or more other status conditions indicate a degraded state.",
+		LastTransitionTime: transitionTime,
+	}}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &reconciler{
+		recorder:           recorder,
+		statusWebhookQueue: make(chan statusWebhookNotification, 1),
+	}
+	r.notifyStatusWebhooks(previous, updated)
+	if n := len(good.received()) + len(bad.received()); n != 0 {
+		t.Fatalf("expected notifications to be queued rather than sent, got %d sent", n)
+	}
+	if n := len(r.statusWebhookQueue); n != 1 {
+		t.Fatalf("expected 1 queued notification, got %d", n)
+	}
+	r.sendStatusWebhookNotification(context.Background(), <-r.statusWebhookQueue)
+
+	expect := statusWebhookPayload{
+		Namespace:          "openshift-ingress-operator",
+		IngressController:  "default",
+		ConditionType:      operatorv1.OperatorStatusTypeDegraded,
+		Status:             operatorv1.ConditionTrue,
+		PreviousStatus:     operatorv1.ConditionFalse,
+		Reason:             "DegradedConditions",
+		Message:            "One or more other status conditions indicate a degraded state.",
+		LastTransitionTime: transitionTime.Time,
+	}
+	for _, webhook := range []*fakeStatusWebhook{good, bad} {
+		payloads := webhook.received()
+		if len(payloads) != 1 {
+			t.Fatalf("expected 1 notification, got %d", len(payloads))
+		}
+		if !payloads[0].LastTransitionTime.Equal(expect.LastTransitionTime) {
+			t.Errorf("expected lastTransitionTime %v, got %v", expect.LastTransitionTime, payloads[0].LastTransitionTime)
+		}
+		payloads[0].LastTransitionTime = expect.LastTransitionTime
+		if payloads[0] != expect {
+			t.Errorf("expected payload %+v, got %+v", expect, payloads[0])
+		}
+		if contentType := webhook.contentTypes[0]; contentType != "application/json" {
+			t.Errorf("expected content type application/json, got %q", contentType)
+		}
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, "Warning StatusWebhookFailed ") || !strings.Contains(event, bad.URL) {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("expected an event for the failed notification")
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("unexpected event %q", event)
+	default:
+	}
+
+	// The status is unchanged, so there is nothing to notify.
+	r.notifyStatusWebhooks(updated, updated.DeepCopy())
+	if n := len(r.statusWebhookQueue); n != 0 {
+		t.Errorf("expected no further notifications, got %d queued", n)
+	}
+
+	// A notification that does not fit in the queue is dropped and
+	// reported with an event instead of blocking.
+	r.statusWebhookQueue <- statusWebhookNotification{}
+	r.notifyStatusWebhooks(previous, updated)
+	if n := len(r.statusWebhookQueue); n != 1 {
+		t.Errorf("expected the queue to remain full, got %d queued", n)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, "Warning StatusWebhookFailed Dropped ") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("expected an event for the dropped notification")
+	}
+}
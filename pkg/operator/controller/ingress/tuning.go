@@ -0,0 +1,324 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The following are the values that the router uses for tuning
+	// options that the operator does not set.
+	routerDefaultMaxConnections      = 50000
+	routerDefaultClientFinTimeout    = 1 * time.Second
+	routerDefaultServerFinTimeout    = 1 * time.Second
+	routerDefaultTunnelTimeout       = 1 * time.Hour
+	routerDefaultTLSInspectDelay     = 5 * time.Second
+	routerDefaultHealthCheckInterval = 5 * time.Second
+)
+
+const (
+	// minHealthCheckInterval and maxHealthCheckInterval are the bounds of
+	// spec.tuningOptions.healthCheckInterval.  Shorter intervals load
+	// backends with health checks, and longer intervals leave failed
+	// backends in rotation for too long.
+	minHealthCheckInterval = 1 * time.Second
+	maxHealthCheckInterval = 2 * time.Minute
+)
+
+// routerMaxThreads is the largest number of threads that HAProxy supports.
+const routerMaxThreads = 64
+
+// routerThreadCount returns the number of HAProxy threads for the given
+// ingresscontroller's router, whose container has the given resource
+// requirements.  spec.tuningOptions.threadCount takes precedence.  Otherwise,
+// the router uses one thread per CPU of the container's CPU limit, if it has
+// one, because threads beyond the limit are throttled; without a limit, it
+// uses one thread per CPU of the container's CPU request, but no fewer than
+// RouterHAProxyThreadsDefaultValue.  The result is at most routerMaxThreads.
+func routerThreadCount(ic *operatorv1.IngressController, resources corev1.ResourceRequirements) int32 {
+	var threads int64
+	if v := ic.Spec.TuningOptions.ThreadCount; v > 0 {
+		threads = int64(v)
+	} else if limit, ok := resources.Limits[corev1.ResourceCPU]; ok && limit.Sign() > 0 {
+		// Round fractional CPUs up.
+		threads = (limit.MilliValue() + 999) / 1000
+	} else {
+		threads = RouterHAProxyThreadsDefaultValue
+		if request, ok := resources.Requests[corev1.ResourceCPU]; ok {
+			if v := (request.MilliValue() + 999) / 1000; v > threads {
+				threads = v
+			}
+		}
+	}
+	if threads > routerMaxThreads {
+		threads = routerMaxThreads
+	}
+	return int32(threads)
+}
+
+// effectiveRouterThreadCount returns the number of HAProxy threads that
+// desiredRouterDeployment configures for the given ingresscontroller's
+// router.
+func effectiveRouterThreadCount(ic *operatorv1.IngressController) int32 {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		overrides = &unsupportedConfigOverrides{}
+	}
+	defaults := manifests.RouterDeployment().Spec.Template.Spec.Containers[0].Resources
+	return routerThreadCount(ic, desiredRouterResources(defaults, overrides))
+}
+
+// validateThreadCount returns an error if the given ingresscontroller
+// specifies a negative spec.tuningOptions.threadCount.  A value of zero cannot
+// be distinguished from an unset value, which makes the router use a thread
+// count based on its CPU resources.
+func validateThreadCount(ic *operatorv1.IngressController) error {
+	if v := ic.Spec.TuningOptions.ThreadCount; v < 0 {
+		return fmt.Errorf("invalid spec.tuningOptions.threadCount %d: must not be negative", v)
+	}
+	return nil
+}
+
+// tuningTimeouts returns the timeouts in the given tuning options, keyed by
+// field name.  Timeouts that are nil are omitted.
+func tuningTimeouts(spec operatorv1.IngressControllerTuningOptions) map[string]*metav1.Duration {
+	timeouts := map[string]*metav1.Duration{}
+	for name, d := range map[string]*metav1.Duration{
+		"clientTimeout":    spec.ClientTimeout,
+		"clientFinTimeout": spec.ClientFinTimeout,
+		"serverTimeout":    spec.ServerTimeout,
+		"serverFinTimeout": spec.ServerFinTimeout,
+		"tunnelTimeout":    spec.TunnelTimeout,
+		"tlsInspectDelay":  spec.TLSInspectDelay,
+	} {
+		if d != nil {
+			timeouts[name] = d
+		}
+	}
+	return timeouts
+}
+
+// validateTuningTimeouts returns an error for each of the given
+// ingresscontroller's tuning timeouts that is negative.  A timeout of zero
+// makes the router use its default.
+func validateTuningTimeouts(ic *operatorv1.IngressController) error {
+	timeouts := tuningTimeouts(ic.Spec.TuningOptions)
+	var errs []error
+	for _, name := range sets.StringKeySet(timeouts).List() {
+		if d := timeouts[name].Duration; d < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.tuningOptions.%s %s: must not be negative", name, d))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateHealthCheckInterval returns an error if the given ingresscontroller
+// specifies a spec.tuningOptions.healthCheckInterval outside the range from
+// minHealthCheckInterval to maxHealthCheckInterval.  A value of zero makes the
+// router use its default.
+func validateHealthCheckInterval(ic *operatorv1.IngressController) error {
+	interval := ic.Spec.TuningOptions.HealthCheckInterval
+	if interval == nil || interval.Duration == 0 {
+		return nil
+	}
+	if d := interval.Duration; d < minHealthCheckInterval || d > maxHealthCheckInterval {
+		return fmt.Errorf("invalid spec.tuningOptions.healthCheckInterval %s: must be between %s and %s", d, minHealthCheckInterval, maxHealthCheckInterval)
+	}
+	return nil
+}
+
+// effectiveTuningOptions returns the tuning options that the given
+// ingresscontroller's router uses: the values from spec.tuningOptions that
+// desiredRouterDeployment applies, and the router's defaults for the rest.
+// A maxConnections value of -1 means that the router computes the limit
+// dynamically.
+func effectiveTuningOptions(ic *operatorv1.IngressController) operatorv1.IngressControllerTuningOptions {
+	spec := ic.Spec.TuningOptions
+	effective := operatorv1.IngressControllerTuningOptions{
+		HeaderBufferBytes:           spec.HeaderBufferBytes,
+		HeaderBufferMaxRewriteBytes: spec.HeaderBufferMaxRewriteBytes,
+		ThreadCount:                 spec.ThreadCount,
+		MaxConnections:              spec.MaxConnections,
+	}
+	if effective.HeaderBufferBytes == 0 {
+		effective.HeaderBufferBytes = routerDefaultHeaderBufferSize
+	}
+	if effective.HeaderBufferMaxRewriteBytes == 0 {
+		effective.HeaderBufferMaxRewriteBytes = routerDefaultHeaderBufferMaxRewriteSize
+	}
+	effective.ThreadCount = effectiveRouterThreadCount(ic)
+	if effective.MaxConnections == 0 {
+		effective.MaxConnections = routerDefaultMaxConnections
+	}
+
+	// effectiveDuration returns the given duration if desiredRouterDeployment
+	// applies it and otherwise the given default.
+	effectiveDuration := func(d *metav1.Duration, defaultDuration time.Duration) *metav1.Duration {
+		if d != nil && d.Duration > 0 {
+			return &metav1.Duration{Duration: d.Duration}
+		}
+		return &metav1.Duration{Duration: defaultDuration}
+	}
+	clientTimeout, serverTimeout := routerDefaultClientTimeout, routerDefaultServerTimeout
+	if overrides, err := getUnsupportedConfigOverrides(ic); err == nil && overrides.GRPCMode {
+		clientTimeout, serverTimeout = grpcDefaultTimeout, grpcDefaultTimeout
+	}
+	effective.ClientTimeout = effectiveDuration(spec.ClientTimeout, clientTimeout)
+	effective.ClientFinTimeout = effectiveDuration(spec.ClientFinTimeout, routerDefaultClientFinTimeout)
+	effective.ServerTimeout = effectiveDuration(spec.ServerTimeout, serverTimeout)
+	effective.ServerFinTimeout = effectiveDuration(spec.ServerFinTimeout, routerDefaultServerFinTimeout)
+	effective.TunnelTimeout = effectiveDuration(spec.TunnelTimeout, routerDefaultTunnelTimeout)
+	effective.TLSInspectDelay = effectiveDuration(spec.TLSInspectDelay, routerDefaultTLSInspectDelay)
+	// Health check intervals of less than 1s are ignored.
+	effective.HealthCheckInterval = &metav1.Duration{Duration: routerDefaultHealthCheckInterval}
+	if d := spec.HealthCheckInterval; d != nil && d.Duration >= time.Second {
+		effective.HealthCheckInterval = &metav1.Duration{Duration: d.Duration}
+	}
+
+	return effective
+}
+
+// tuningConflict describes a combination of tuning options that contradict
+// each other.
+type tuningConflict struct {
+	// reject indicates whether the conflict makes the ingresscontroller
+	// invalid.  Conflicts that are not rejected are only reported as
+	// warnings.
+	reject bool
+	// message describes the conflict.
+	message string
+}
+
+// tuningConflicts returns the known conflicts between the given
+// ingresscontroller's effective tuning options and the connection handling
+// that its unsupported config overrides specify.
+func tuningConflicts(ic *operatorv1.IngressController) []tuningConflict {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		// validateUnsupportedConfigOverrides reports the error.
+		return nil
+	}
+	effective := effectiveTuningOptions(ic)
+	var conflicts []tuningConflict
+
+	// Close and ServerClose close server connections after each response,
+	// so there are no idle server connections to pool.
+	switch mode := overrides.HTTPConnectionMode; mode {
+	case httpConnectionModeClose, httpConnectionModeServerClose:
+		if overrides.BackendPoolMaxConnections > 0 {
+			conflicts = append(conflicts, tuningConflict{
+				reject:  true,
+				message: fmt.Sprintf("spec.unsupportedConfigOverrides.backendPoolMaxConnections (%d) conflicts with spec.unsupportedConfigOverrides.httpConnectionMode %q, which closes server connections after each response", overrides.BackendPoolMaxConnections, mode),
+			})
+		}
+		if mode == httpConnectionModeClose && overrides.TCPKeepalive != nil {
+			conflicts = append(conflicts, tuningConflict{
+				message: fmt.Sprintf("spec.unsupportedConfigOverrides.tcpKeepalive has little effect with spec.unsupportedConfigOverrides.httpConnectionMode %q, which closes connections after each response", mode),
+			})
+		}
+	}
+
+	// HAProxy uses the fin timeouts instead of the client and server
+	// timeouts once a connection is half-closed, so a longer fin timeout
+	// keeps half-closed connections open longer than idle ones.
+	if effective.ClientFinTimeout.Duration > effective.ClientTimeout.Duration {
+		conflicts = append(conflicts, tuningConflict{
+			message: fmt.Sprintf("spec.tuningOptions.clientFinTimeout (%s) is longer than clientTimeout (%s)", effective.ClientFinTimeout.Duration, effective.ClientTimeout.Duration),
+		})
+	}
+	if effective.ServerFinTimeout.Duration > effective.ServerTimeout.Duration {
+		conflicts = append(conflicts, tuningConflict{
+			message: fmt.Sprintf("spec.tuningOptions.serverFinTimeout (%s) is longer than serverTimeout (%s)", effective.ServerFinTimeout.Duration, effective.ServerTimeout.Duration),
+		})
+	}
+	// A client that is slow to send its TLS client hello is disconnected
+	// by the client timeout before the inspect delay expires.
+	if effective.TLSInspectDelay.Duration > effective.ClientTimeout.Duration {
+		conflicts = append(conflicts, tuningConflict{
+			message: fmt.Sprintf("spec.tuningOptions.tlsInspectDelay (%s) is longer than clientTimeout (%s), which limits it", effective.TLSInspectDelay.Duration, effective.ClientTimeout.Duration),
+		})
+	}
+
+	// desiredRouterDeployment truncates timeouts to HAProxy's maximum.
+	timeouts := tuningTimeouts(ic.Spec.TuningOptions)
+	for _, name := range sets.StringKeySet(timeouts).List() {
+		if d := timeouts[name].Duration; d > haproxyMaxTimeoutMilliseconds {
+			conflicts = append(conflicts, tuningConflict{
+				message: fmt.Sprintf("spec.tuningOptions.%s (%s) exceeds HAProxy's maximum timeout of %s, which the router uses instead", name, d, haproxyMaxTimeoutMilliseconds),
+			})
+		}
+	}
+
+	// HAProxy does not support more threads than routerMaxThreads, so the
+	// router uses that many.
+	if v := ic.Spec.TuningOptions.ThreadCount; v > routerMaxThreads {
+		conflicts = append(conflicts, tuningConflict{
+			message: fmt.Sprintf("spec.tuningOptions.threadCount (%d) exceeds HAProxy's maximum of %d threads, which the router uses instead", v, routerMaxThreads),
+		})
+	}
+
+	return conflicts
+}
+
+// validateTuningOptionConflicts returns an error for each conflict between the
+// given ingresscontroller's tuning options that makes it invalid.
+func validateTuningOptionConflicts(ic *operatorv1.IngressController) error {
+	var errs []error
+	for _, conflict := range tuningConflicts(ic) {
+		if conflict.reject {
+			errs = append(errs, fmt.Errorf("invalid tuning options: %s", conflict.message))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// tuningOptionWarnings returns a message for each conflict between the given
+// ingresscontroller's tuning options that does not make it invalid.
+func tuningOptionWarnings(ic *operatorv1.IngressController) []string {
+	var warnings []string
+	for _, conflict := range tuningConflicts(ic) {
+		if !conflict.reject {
+			warnings = append(warnings, conflict.message)
+		}
+	}
+	return warnings
+}
+
+// syncEffectiveTuningOptions updates the given ingresscontroller's effective
+// tuning options annotation if it does not match the ingresscontroller's
+// effective tuning options, and returns the current ingresscontroller.  The
+// annotation is set using a merge patch so that concurrent changes to the
+// ingresscontroller do not cause conflicts.
+func (r *reconciler) syncEffectiveTuningOptions(ic *operatorv1.IngressController) (*operatorv1.IngressController, error) {
+	value, err := json.Marshal(effectiveTuningOptions(ic))
+	if err != nil {
+		return ic, err
+	}
+	if current, ok := ic.Annotations[manifests.EffectiveTuningOptionsAnnotation]; ok && current == string(value) {
+		return ic, nil
+	}
+
+	updated := ic.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	updated.Annotations[manifests.EffectiveTuningOptionsAnnotation] = string(value)
+	if err := r.client.Patch(context.TODO(), updated, client.MergeFrom(ic)); err != nil {
+		return ic, fmt.Errorf("failed to patch ingresscontroller %s/%s: %w", ic.Namespace, ic.Name, err)
+	}
+	log.Info("updated effective tuning options", "namespace", ic.Namespace, "name", ic.Name, "value", string(value))
+	return updated, nil
+}
@@ -0,0 +1,499 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestEffectiveTuningOptions verifies that effectiveTuningOptions merges the
+// ingresscontroller's tuning options with the router's defaults.
+func TestEffectiveTuningOptions(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	defaults := operatorv1.IngressControllerTuningOptions{
+		HeaderBufferBytes:           32768,
+		HeaderBufferMaxRewriteBytes: 8192,
+		ThreadCount:                 4,
+		MaxConnections:              50000,
+		ClientTimeout:               duration(30 * time.Second),
+		ClientFinTimeout:            duration(1 * time.Second),
+		ServerTimeout:               duration(30 * time.Second),
+		ServerFinTimeout:            duration(1 * time.Second),
+		TunnelTimeout:               duration(1 * time.Hour),
+		TLSInspectDelay:             duration(5 * time.Second),
+		HealthCheckInterval:         duration(5 * time.Second),
+	}
+	testCases := []struct {
+		name      string
+		spec      operatorv1.IngressControllerTuningOptions
+		overrides string
+		expected  func(*operatorv1.IngressControllerTuningOptions)
+	}{
+		{
+			name:     "defaults",
+			expected: func(*operatorv1.IngressControllerTuningOptions) {},
+		},
+		{
+			name: "overrides",
+			spec: operatorv1.IngressControllerTuningOptions{
+				HeaderBufferBytes: 16384,
+				ThreadCount:       8,
+				MaxConnections:    -1,
+				ClientTimeout:     duration(time.Minute),
+				TunnelTimeout:     duration(2 * time.Hour),
+			},
+			expected: func(o *operatorv1.IngressControllerTuningOptions) {
+				o.HeaderBufferBytes = 16384
+				o.ThreadCount = 8
+				o.MaxConnections = -1
+				o.ClientTimeout = duration(time.Minute)
+				o.TunnelTimeout = duration(2 * time.Hour)
+			},
+		},
+		{
+			name:      "gRPC mode",
+			spec:      operatorv1.IngressControllerTuningOptions{ServerTimeout: duration(time.Minute)},
+			overrides: `{"grpcMode":true}`,
+			expected: func(o *operatorv1.IngressControllerTuningOptions) {
+				o.ClientTimeout = duration(time.Hour)
+				o.ServerTimeout = duration(time.Minute)
+			},
+		},
+		{
+			name:      "CPU limit",
+			overrides: `{"cpu":{"limit":"2"}}`,
+			expected: func(o *operatorv1.IngressControllerTuningOptions) {
+				o.ThreadCount = 2
+			},
+		},
+		{
+			name: "ignored values",
+			spec: operatorv1.IngressControllerTuningOptions{
+				ServerTimeout:       duration(0),
+				HealthCheckInterval: duration(500 * time.Millisecond),
+			},
+			expected: func(*operatorv1.IngressControllerTuningOptions) {},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{Spec: operatorv1.IngressControllerSpec{
+				TuningOptions:              tc.spec,
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+			}}
+			expected := *defaults.DeepCopy()
+			tc.expected(&expected)
+			if actual := effectiveTuningOptions(ic); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %+v, got %+v", expected, actual)
+			}
+		})
+	}
+}
+
+// TestSyncEffectiveTuningOptions verifies that syncEffectiveTuningOptions
+// publishes the effective tuning options in an annotation, updates the
+// annotation when the tuning options change, and does not conflict with
+// concurrent changes.
+func TestSyncEffectiveTuningOptions(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := operatorv1.Install(s); err != nil {
+		t.Fatal(err)
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+	}
+	r := &reconciler{client: fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()}
+
+	annotatedThreadCount := func(t *testing.T, ic *operatorv1.IngressController) int32 {
+		t.Helper()
+		var options operatorv1.IngressControllerTuningOptions
+		if err := json.Unmarshal([]byte(ic.Annotations[manifests.EffectiveTuningOptionsAnnotation]), &options); err != nil {
+			t.Fatalf("failed to decode annotation: %v", err)
+		}
+		return options.ThreadCount
+	}
+
+	updated, err := r.syncEffectiveTuningOptions(ic)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if threads := annotatedThreadCount(t, updated); threads != 4 {
+		t.Errorf("expected default thread count 4, got %d", threads)
+	}
+
+	// Syncing again without changes does not update the ingresscontroller.
+	again, err := r.syncEffectiveTuningOptions(updated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.ResourceVersion != updated.ResourceVersion {
+		t.Errorf("expected no update, resource version changed from %s to %s", updated.ResourceVersion, again.ResourceVersion)
+	}
+
+	changed := again.DeepCopy()
+	changed.Spec.TuningOptions.ThreadCount = 2
+	if err := r.client.Update(context.Background(), changed); err != nil {
+		t.Fatalf("failed to update ingresscontroller: %v", err)
+	}
+	updated, err = r.syncEffectiveTuningOptions(changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if threads := annotatedThreadCount(t, updated); threads != 2 {
+		t.Errorf("expected thread count 2, got %d", threads)
+	}
+
+	// A concurrent change to the ingresscontroller does not cause a
+	// conflict, and the change is kept.
+	stale := updated.DeepCopy()
+	delete(stale.Annotations, manifests.EffectiveTuningOptionsAnnotation)
+	edited := updated.DeepCopy()
+	edited.Labels = map[string]string{"example.com/edited": "true"}
+	if err := r.client.Update(context.Background(), edited); err != nil {
+		t.Fatalf("failed to update ingresscontroller: %v", err)
+	}
+	updated, err = r.syncEffectiveTuningOptions(stale)
+	if err != nil {
+		t.Fatalf("unexpected error with a stale ingresscontroller: %v", err)
+	}
+	if threads := annotatedThreadCount(t, updated); threads != 2 {
+		t.Errorf("expected thread count 2, got %d", threads)
+	}
+	if updated.Labels["example.com/edited"] != "true" {
+		t.Errorf("expected the concurrent change to be kept, got labels %v", updated.Labels)
+	}
+}
+
+// TestTuningConflicts verifies that tuningConflicts rejects or warns about
+// conflicting combinations of tuning options and accepts compatible ones.
+func TestTuningConflicts(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	testCases := []struct {
+		name           string
+		spec           operatorv1.IngressControllerTuningOptions
+		overrides      string
+		expectRejected int
+		expectWarnings int
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:      "keep-alive with backend connection pool",
+			overrides: `{"httpConnectionMode":"KeepAlive","backendPoolMaxConnections":100}`,
+		},
+		{
+			name:           "close with backend connection pool",
+			overrides:      `{"httpConnectionMode":"Close","backendPoolMaxConnections":100}`,
+			expectRejected: 1,
+		},
+		{
+			name:           "server-close with backend connection pool",
+			overrides:      `{"httpConnectionMode":"ServerClose","backendPoolMaxConnections":100}`,
+			expectRejected: 1,
+		},
+		{
+			name:           "close with TCP keepalive",
+			overrides:      `{"httpConnectionMode":"Close","tcpKeepalive":{"idleSeconds":60}}`,
+			expectWarnings: 1,
+		},
+		{
+			name:      "server-close with TCP keepalive",
+			overrides: `{"httpConnectionMode":"ServerClose","tcpKeepalive":{"idleSeconds":60}}`,
+		},
+		{
+			name: "fin timeouts shorter than timeouts",
+			spec: operatorv1.IngressControllerTuningOptions{
+				ClientFinTimeout: duration(10 * time.Second),
+				ServerFinTimeout: duration(10 * time.Second),
+			},
+		},
+		{
+			name: "fin timeouts longer than timeouts",
+			spec: operatorv1.IngressControllerTuningOptions{
+				ClientTimeout:    duration(5 * time.Second),
+				ClientFinTimeout: duration(10 * time.Second),
+				ServerTimeout:    duration(5 * time.Second),
+				ServerFinTimeout: duration(10 * time.Second),
+			},
+			expectWarnings: 2,
+		},
+		{
+			name: "TLS inspect delay longer than client timeout",
+			spec: operatorv1.IngressControllerTuningOptions{
+				ClientTimeout:   duration(3 * time.Second),
+				TLSInspectDelay: duration(4 * time.Second),
+			},
+			expectWarnings: 1,
+		},
+		{
+			name: "TLS inspect delay longer than default client timeout",
+			spec: operatorv1.IngressControllerTuningOptions{
+				TLSInspectDelay: duration(time.Minute),
+			},
+			expectWarnings: 1,
+		},
+		{
+			name: "TLS inspect delay within gRPC mode client timeout",
+			spec: operatorv1.IngressControllerTuningOptions{
+				TLSInspectDelay: duration(time.Minute),
+			},
+			overrides: `{"grpcMode":true}`,
+		},
+		{
+			name: "thread count at HAProxy's maximum",
+			spec: operatorv1.IngressControllerTuningOptions{ThreadCount: 64},
+		},
+		{
+			name:           "thread count above HAProxy's maximum",
+			spec:           operatorv1.IngressControllerTuningOptions{ThreadCount: 65},
+			expectWarnings: 1,
+		},
+		{
+			name: "tunnel timeout at HAProxy's maximum",
+			spec: operatorv1.IngressControllerTuningOptions{
+				TunnelTimeout: duration(2147483647 * time.Millisecond),
+			},
+		},
+		{
+			name: "tunnel timeout above HAProxy's maximum",
+			spec: operatorv1.IngressControllerTuningOptions{
+				TunnelTimeout: duration(1000 * time.Hour),
+			},
+			expectWarnings: 1,
+		},
+		{
+			name:      "invalid overrides",
+			overrides: `{"httpConnectionMode":`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				Spec: operatorv1.IngressControllerSpec{
+					TuningOptions:              tc.spec,
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+				},
+			}
+			var rejected int
+			for _, conflict := range tuningConflicts(ic) {
+				if conflict.reject {
+					rejected++
+				}
+			}
+			warnings := tuningOptionWarnings(ic)
+			if rejected != tc.expectRejected || len(warnings) != tc.expectWarnings {
+				t.Errorf("expected %d rejected conflicts and %d warnings, got %d and %v", tc.expectRejected, tc.expectWarnings, rejected, warnings)
+			}
+			if err := validateTuningOptionConflicts(ic); (err != nil) != (tc.expectRejected != 0) {
+				t.Errorf("expected rejection %t, got error %v", tc.expectRejected != 0, err)
+			}
+		})
+	}
+}
+
+// TestRouterThreadCount verifies that routerThreadCount uses
+// spec.tuningOptions.threadCount if it is set, and otherwise derives the
+// thread count from the CPU limit or request, and that it never exceeds
+// HAProxy's maximum.
+func TestRouterThreadCount(t *testing.T) {
+	cpu := func(request, limit string) corev1.ResourceRequirements {
+		var resources corev1.ResourceRequirements
+		if len(request) != 0 {
+			resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(request)}
+		}
+		if len(limit) != 0 {
+			resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(limit)}
+		}
+		return resources
+	}
+	testCases := []struct {
+		name          string
+		threadCount   int32
+		resources     corev1.ResourceRequirements
+		expectThreads int32
+	}{
+		{
+			name:          "no CPU resources",
+			expectThreads: 4,
+		},
+		{
+			name:          "default CPU request",
+			resources:     cpu("100m", ""),
+			expectThreads: 4,
+		},
+		{
+			name:          "CPU request above the default thread count",
+			resources:     cpu("6", ""),
+			expectThreads: 6,
+		},
+		{
+			name:          "fractional CPU request",
+			resources:     cpu("5500m", ""),
+			expectThreads: 6,
+		},
+		{
+			name:          "CPU limit below the default thread count",
+			resources:     cpu("100m", "2"),
+			expectThreads: 2,
+		},
+		{
+			name:          "fractional CPU limit",
+			resources:     cpu("100m", "500m"),
+			expectThreads: 1,
+		},
+		{
+			name:          "CPU limit above HAProxy's maximum",
+			resources:     cpu("100m", "100"),
+			expectThreads: 64,
+		},
+		{
+			name:          "thread count takes precedence",
+			threadCount:   8,
+			resources:     cpu("100m", "2"),
+			expectThreads: 8,
+		},
+		{
+			name:          "thread count above HAProxy's maximum",
+			threadCount:   128,
+			expectThreads: 64,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{
+				Spec: operatorv1.IngressControllerSpec{
+					TuningOptions: operatorv1.IngressControllerTuningOptions{ThreadCount: tc.threadCount},
+				},
+			}
+			if actual := routerThreadCount(ic, tc.resources); actual != tc.expectThreads {
+				t.Errorf("expected %d threads, got %d", tc.expectThreads, actual)
+			}
+		})
+	}
+}
+
+// TestValidateThreadCount verifies that validateThreadCount rejects a negative
+// spec.tuningOptions.threadCount.
+func TestValidateThreadCount(t *testing.T) {
+	for _, tc := range []struct {
+		threadCount int32
+		expectError bool
+	}{
+		{0, false},
+		{1, false},
+		{64, false},
+		{-1, true},
+	} {
+		ic := &operatorv1.IngressController{
+			Spec: operatorv1.IngressControllerSpec{
+				TuningOptions: operatorv1.IngressControllerTuningOptions{ThreadCount: tc.threadCount},
+			},
+		}
+		if err := validateThreadCount(ic); (err != nil) != tc.expectError {
+			t.Errorf("threadCount %d: expected error %t, got %v", tc.threadCount, tc.expectError, err)
+		}
+	}
+}
+
+// TestValidateTuningTimeouts verifies that validateTuningTimeouts rejects
+// negative tuning timeouts and accepts unset, zero, and positive ones.
+func TestValidateTuningTimeouts(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	testCases := []struct {
+		name        string
+		spec        operatorv1.IngressControllerTuningOptions
+		expectError []string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name: "zero and positive",
+			spec: operatorv1.IngressControllerTuningOptions{
+				ClientTimeout:    duration(0),
+				ClientFinTimeout: duration(time.Second),
+				ServerTimeout:    duration(time.Minute),
+				ServerFinTimeout: duration(time.Second),
+				TunnelTimeout:    duration(24 * time.Hour),
+				TLSInspectDelay:  duration(10 * time.Second),
+			},
+		},
+		{
+			name: "negative",
+			spec: operatorv1.IngressControllerTuningOptions{
+				ClientTimeout: duration(-time.Second),
+				TunnelTimeout: duration(-time.Hour),
+			},
+			expectError: []string{"spec.tuningOptions.clientTimeout -1s", "spec.tuningOptions.tunnelTimeout -1h0m0s"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{Spec: operatorv1.IngressControllerSpec{TuningOptions: tc.spec}}
+			err := validateTuningTimeouts(ic)
+			if len(tc.expectError) == 0 {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, s := range tc.expectError {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("expected error to contain %q, got %v", s, err)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateHealthCheckInterval verifies that validateHealthCheckInterval
+// accepts health check intervals from 1s to 2m and rejects the rest, so that
+// invalid values are reported rather than ignored.
+func TestValidateHealthCheckInterval(t *testing.T) {
+	testCases := []struct {
+		name        string
+		interval    *metav1.Duration
+		expectError bool
+	}{
+		{name: "unset"},
+		{name: "zero", interval: &metav1.Duration{}},
+		{name: "minimum", interval: &metav1.Duration{Duration: time.Second}},
+		{name: "maximum", interval: &metav1.Duration{Duration: 2 * time.Minute}},
+		{name: "too short", interval: &metav1.Duration{Duration: 500 * time.Millisecond}, expectError: true},
+		{name: "too long", interval: &metav1.Duration{Duration: 5 * time.Minute}, expectError: true},
+		{name: "negative", interval: &metav1.Duration{Duration: -time.Second}, expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ic := &operatorv1.IngressController{}
+			ic.Spec.TuningOptions.HealthCheckInterval = tc.interval
+			err := validateHealthCheckInterval(ic)
+			switch {
+			case tc.expectError && err == nil:
+				t.Error("expected an error")
+			case !tc.expectError && err != nil:
+				t.Errorf("expected no error, got %v", err)
+			case err != nil && !strings.Contains(err.Error(), "must be between 1s and 2m0s"):
+				t.Errorf("expected the error to state the valid range, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,1374 @@
+package ingress
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxHeaderCountLimit is the largest value that HAProxy accepts for
+// tune.http.maxhdr.
+const maxHeaderCountLimit = 32767
+
+const (
+	// minSSLMaxRecordSize is the smallest maximum TLS record size that the
+	// TLS maximum fragment length extension (RFC 6066) allows.
+	minSSLMaxRecordSize = 512
+	// maxSSLMaxRecordSize is the largest TLS record size that TLS allows.
+	maxSSLMaxRecordSize = 16384
+)
+
+// httpMethods is the set of HTTP methods that method-based routing accepts.
+var httpMethods = sets.NewString(
+	http.MethodConnect,
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodTrace,
+)
+
+const (
+	// alpnProtocolHTTP2, alpnProtocolHTTP11, and alpnProtocolHTTP10 are the
+	// ALPN protocol identifiers that the router can advertise.
+	alpnProtocolHTTP2  = "h2"
+	alpnProtocolHTTP11 = "http/1.1"
+	alpnProtocolHTTP10 = "http/1.0"
+)
+
+// alpnProtocols is the set of ALPN protocol identifiers that the
+// alpnProtocols unsupported config override accepts.
+var alpnProtocols = sets.NewString(alpnProtocolHTTP2, alpnProtocolHTTP11, alpnProtocolHTTP10)
+
+const (
+	// roundingFloor and roundingCeil are the values of the
+	// rollingUpdate.maxUnavailableRounding unsupported config override.
+	roundingFloor = "Floor"
+	roundingCeil  = "Ceil"
+)
+
+const (
+	// minCompressionLevel and maxCompressionLevel are the bounds of the
+	// gzip compression level.
+	minCompressionLevel = 1
+	maxCompressionLevel = 9
+)
+
+const (
+	// cipherPreferenceServer makes the router choose the cipher from the
+	// client's list according to the router's cipher order.
+	cipherPreferenceServer = "Server"
+	// cipherPreferenceClient makes the router choose the cipher according
+	// to the client's cipher order.
+	cipherPreferenceClient = "Client"
+)
+
+const (
+	// certificateFailurePolicyFailOpen makes the router serve the default
+	// certificate even if it is invalid.
+	certificateFailurePolicyFailOpen = "FailOpen"
+	// certificateFailurePolicyFailClosed makes the router reject TLS
+	// connections that would use the default certificate if it is
+	// invalid.
+	certificateFailurePolicyFailClosed = "FailClosed"
+)
+
+const (
+	// httpConnectionModeKeepAlive makes the router keep client and
+	// server connections open between requests (HAProxy's
+	// http-keep-alive).
+	httpConnectionModeKeepAlive = "KeepAlive"
+	// httpConnectionModeServerClose makes the router keep client
+	// connections open but close server connections after each response
+	// (HAProxy's http-server-close).
+	httpConnectionModeServerClose = "ServerClose"
+	// httpConnectionModeClose makes the router close both client and
+	// server connections after each response (HAProxy's httpclose).
+	httpConnectionModeClose = "Close"
+)
+
+const (
+	// requestSmugglingProtectionStrict makes the router reject HTTP/1
+	// requests with ambiguous framing.
+	requestSmugglingProtectionStrict = "Strict"
+	// requestSmugglingProtectionLenient makes the router accept HTTP/1
+	// requests with ambiguous framing.
+	requestSmugglingProtectionLenient = "Lenient"
+)
+
+const (
+	// sourceHashSourceIP makes the router choose a backend server by
+	// hashing the client's source IP address.
+	sourceHashSourceIP = "SourceIP"
+	// sourceHashHeader makes the router choose a backend server by
+	// hashing the value of an HTTP request header.
+	sourceHashHeader = "Header"
+)
+
+const (
+	// syslogProtocolUDP and syslogProtocolTCP are the values of the
+	// syslogProtocol unsupported config override.
+	syslogProtocolUDP = "UDP"
+	syslogProtocolTCP = "TCP"
+)
+
+const (
+	// deploymentStrategyRollingUpdate makes the operator roll out the
+	// router deployment using the RollingUpdate strategy.
+	deploymentStrategyRollingUpdate = "RollingUpdate"
+	// deploymentStrategyRecreate makes the operator roll out the router
+	// deployment using the Recreate strategy.
+	deploymentStrategyRecreate = "Recreate"
+)
+
+const (
+	// podAntiAffinityRequired makes the scheduler place router pods of
+	// the same generation on different nodes.
+	podAntiAffinityRequired = "Required"
+	// podAntiAffinityPreferred makes the scheduler prefer to place router
+	// pods of the same generation on different nodes.
+	podAntiAffinityPreferred = "Preferred"
+)
+
+// httpConnectionModeOptions maps each HTTP connection mode to the HAProxy
+// option that implements it.
+var httpConnectionModeOptions = map[string]string{
+	httpConnectionModeKeepAlive:   "http-keep-alive",
+	httpConnectionModeServerClose: "http-server-close",
+	httpConnectionModeClose:       "httpclose",
+}
+
+// unsupportedConfigOverrides holds the values from an ingresscontroller's
+// spec.unsupportedConfigOverrides field that the operator recognizes.
+type unsupportedConfigOverrides struct {
+	LoadBalancingAlgorithm string `json:"loadBalancingAlgorithm"`
+	// DynamicConfigManager specifies whether the router should apply
+	// endpoint changes to HAProxy's running configuration through the
+	// HAProxy runtime API instead of reloading HAProxy.  The runtime API
+	// is served on a Unix socket inside the router container that only
+	// the router's user can access, and it is not exposed on any port.
+	// It is enabled if the value parses as a true Boolean.
+	DynamicConfigManager string `json:"dynamicConfigManager"`
+	ReloadInterval       int32  `json:"reloadInterval"`
+
+	// DefaultBackend specifies a service in the operand namespace to which
+	// the router should send requests that do not match any route.  If
+	// it is nil, the router responds to such requests with a 503 error.
+	DefaultBackend *defaultBackendOverride `json:"defaultBackend"`
+
+	// PerRouteClientCertificatePolicy specifies whether the router should
+	// enforce spec.clientTLS.clientCertificatePolicy per route, allowing
+	// routes to override the policy using an annotation.
+	PerRouteClientCertificatePolicy bool `json:"perRouteClientCertificatePolicy"`
+
+	// CompressionLevel specifies the gzip compression level, from 1
+	// (fastest) to 9 (smallest output), that the router uses when
+	// spec.httpCompression is enabled, trading CPU usage against
+	// bandwidth.  If it is zero, the router's default level is used.
+	CompressionLevel int32 `json:"compressionLevel"`
+
+	// MaxHeaderCount specifies the maximum number of headers that the
+	// router accepts in a request.  Requests with more headers are
+	// rejected.  If it is zero, HAProxy's default of 101 is used.
+	MaxHeaderCount int32 `json:"maxHeaderCount"`
+
+	// SSLCacheSize specifies the number of entries in the router's TLS
+	// session cache (HAProxy's tune.ssl.cachesize).  Larger caches allow
+	// more clients to resume TLS sessions.  If it is zero, HAProxy's
+	// default of 20000 is used.
+	SSLCacheSize int32 `json:"sslCacheSize"`
+
+	// SSLMaxRecordSize specifies the maximum size in bytes of the TLS
+	// records that the router sends (HAProxy's tune.ssl.maxrecord).
+	// Sending records of a fixed, smaller size masks the sizes of the
+	// responses from traffic analysis, at the cost of some throughput.
+	// HAProxy does not support padding records to a minimum size, so this
+	// is the only record size tuning that the router offers.  If it is
+	// zero, HAProxy's default, which does not limit the record size, is
+	// used.
+	SSLMaxRecordSize int32 `json:"sslMaxRecordSize"`
+
+	// MaxSSLHandshakeRate specifies the maximum number of TLS handshakes
+	// per second that the router performs (HAProxy's maxsslrate).  Limiting
+	// the handshake rate protects the router's CPU from floods of new TLS
+	// connections.  If it is zero, the handshake rate is not limited.
+	MaxSSLHandshakeRate int32 `json:"maxSSLHandshakeRate"`
+
+	// BackendPoolMaxConnections specifies the maximum number of idle
+	// connections to each backend server that the router keeps for reuse
+	// (HAProxy's pool-max-conn).  Larger pools allow more connection reuse
+	// at the cost of memory.  If it is zero, HAProxy's default is used.
+	BackendPoolMaxConnections int32 `json:"backendPoolMaxConnections"`
+
+	// ServerMaxConnections specifies the maximum number of concurrent
+	// connections, and thus requests, that the router sends to each
+	// backend server (HAProxy's per-server maxconn).  Further requests
+	// queue in the router until a connection becomes available, which
+	// protects backends from overload.  If it is zero, the number of
+	// concurrent connections per server is not limited.
+	ServerMaxConnections int32 `json:"serverMaxConnections"`
+
+	// Backlog specifies the maximum number of pending connections that
+	// may queue on each of the router's listening sockets while waiting to
+	// be accepted (HAProxy's backlog).  A larger backlog absorbs bursts of
+	// new connections that would otherwise be dropped.  If it is zero,
+	// HAProxy's default is used.
+	Backlog int32 `json:"backlog"`
+
+	// MinReadySeconds specifies the number of seconds for which a new
+	// router pod must be ready before the deployment considers it
+	// available and continues the rolling update, which gives external
+	// load balancers time to bring the pod into rotation.  If it is nil,
+	// 30 seconds is used.
+	MinReadySeconds *int32 `json:"minReadySeconds"`
+
+	// ProgressDeadlineSeconds specifies the number of seconds that a
+	// rolling update of the router deployment may make no progress before
+	// the ingresscontroller reports that it is degraded.  It must be
+	// greater than minReadySeconds.  If it is nil, 600 seconds is used.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds"`
+
+	// TCPKeepalive, if set, enables TCP keepalive on the router's client
+	// and server sockets so that the router detects dead peers, using the
+	// given parameters.
+	TCPKeepalive *tcpKeepaliveOverride `json:"tcpKeepalive"`
+
+	// Tracing, if set, configures the router to emit OpenTelemetry spans
+	// for the requests that it handles to the given collector.  This
+	// requires a router image that supports OpenTelemetry; other images
+	// ignore it.
+	Tracing *tracingOverride `json:"tracing"`
+
+	// HSTS, if set, makes the router add a Strict-Transport-Security
+	// header with the given policy to responses for routes that do not
+	// specify their own policy using the
+	// haproxy.router.openshift.io/hsts_header annotation.  The router adds
+	// the header only to responses that it sends over TLS, because clients
+	// ignore it on cleartext responses.  A route's annotation, which the
+	// ingress config's required HSTS policies govern, takes precedence,
+	// so the router never sets the header twice.
+	HSTS *hstsOverride `json:"hsts"`
+
+	// SourceHashBalancing, if set, makes the router balance requests
+	// across backend servers by hashing the given client attribute, so
+	// that requests from the same client consistently reach the same
+	// server while the set of servers is unchanged.  Routes that specify
+	// the haproxy.router.openshift.io/balance annotation are unaffected.
+	// It may not be specified with LoadBalancingAlgorithm.
+	SourceHashBalancing *sourceHashBalancingOverride `json:"sourceHashBalancing"`
+
+	// StatusWebhook, if set, makes the operator POST a notification to
+	// the given HTTP endpoints when the ingresscontroller becomes
+	// degraded or recovers.
+	StatusWebhook *statusWebhookOverride `json:"statusWebhook"`
+
+	// LoadBalancerZones specifies the zones in which the cloud provider
+	// provisions the ingresscontroller's load balancer, which must be zones
+	// that have nodes.  Pinning a load balancer to zones is supported on
+	// Alibaba Cloud, with up to 2 zones (primary and backup), and on IBM
+	// Cloud and OpenStack, with 1 zone.  The zones apply when the load
+	// balancer service is created.
+	LoadBalancerZones []string `json:"loadBalancerZones"`
+
+	// CanaryRouter, if set, makes the operator run a second router
+	// deployment with a candidate image alongside the stable router
+	// deployment and split the replicas between them so that the canary
+	// receives approximately the given share of traffic.
+	CanaryRouter *canaryRouterOverride `json:"canaryRouter"`
+
+	// StatsTimeoutSeconds specifies how long the router's stats socket
+	// waits on a slow client, such as a slow metrics scrape, before closing
+	// the connection so that the client does not block the router's stats
+	// processing (the "timeout" parameter of HAProxy's "stats socket").  If
+	// it is zero, HAProxy's default is used.
+	StatsTimeoutSeconds int32 `json:"statsTimeoutSeconds"`
+
+	// HTTP2 specifies whether the router negotiates HTTP/2 with clients
+	// using TLS ALPN.  It takes precedence over the
+	// ingress.operator.openshift.io/default-enable-http2 annotation on the
+	// ingresscontroller and on the cluster ingress config.  HTTP/2 over
+	// cleartext (h2c) remains disabled either way.  If it is nil, the
+	// annotations determine whether HTTP/2 is enabled, and it is disabled
+	// if neither is set.
+	HTTP2 *bool `json:"http2"`
+
+	// HTTP2MaxConcurrentStreams specifies the maximum number of concurrent
+	// streams per HTTP/2 connection (HAProxy's
+	// tune.h2.max-concurrent-streams).  It may only be set when HTTP/2 is
+	// enabled.  If it is zero, HAProxy's default of 100 is used.
+	HTTP2MaxConcurrentStreams int32 `json:"http2MaxConcurrentStreams"`
+
+	// ALPNProtocols specifies the protocols, in order of preference, that
+	// the router advertises using TLS ALPN on its frontends (HAProxy's
+	// "alpn" bind option).  Valid protocols are "h2", "http/1.1", and
+	// "http/1.0".  The list must include "h2" if and only if HTTP/2 is
+	// enabled.  If it is empty, the router's default list is used.
+	ALPNProtocols []string `json:"alpnProtocols"`
+
+	// DefaultRouteRequestRateLimit specifies the default maximum number of
+	// HTTP requests per second that the router accepts from a single client
+	// IP address for each route.  A route can override the default using
+	// the haproxy.router.openshift.io/rate-limit-connections.rate-http
+	// annotation.  If it is zero, requests are not rate limited by
+	// default.
+	DefaultRouteRequestRateLimit int32 `json:"defaultRouteRequestRateLimit"`
+
+	// DefaultHostHeader specifies a hostname to which the router rewrites
+	// the Host header of requests before forwarding them to backends that
+	// require a specific Host header, such as the backend service's name.
+	// A route can override the default using the
+	// haproxy.router.openshift.io/host-header annotation.  If it is empty,
+	// the Host header is forwarded unchanged by default.
+	DefaultHostHeader string `json:"defaultHostHeader"`
+
+	// ReadMethods enables method-based routing and specifies the HTTP
+	// methods of read requests, for example GET and HEAD.  Read requests
+	// are sent to a route's backends as usual, and requests with other
+	// methods are sent to the service that the route's
+	// haproxy.router.openshift.io/write-backend annotation names, which
+	// allows splitting reads and writes between services.  Routes
+	// without the annotation are not affected.  If it is empty,
+	// method-based routing is disabled.
+	ReadMethods []string `json:"readMethods"`
+
+	// TrustForwardedProto specifies whether the router uses the
+	// X-Forwarded-Proto header of incoming requests, rather than the
+	// scheme of the connection to the router, to decide whether to
+	// redirect insecure requests for edge-terminated routes.  This is
+	// needed behind an external load balancer that terminates TLS and
+	// connects to the router using HTTP.  The header can only be trusted
+	// if the router keeps incoming forwarded headers, so this has no
+	// effect if spec.httpHeaders.forwardedHeaderPolicy is "Replace".
+	TrustForwardedProto bool `json:"trustForwardedProto"`
+
+	// MaintenancePageConfigMap specifies the name of a configmap in the
+	// operand namespace with a page that the router serves instead of its
+	// default 503 response when a route has no available backends.  The
+	// page is read from the configmap's "maintenance-page.http" key.
+	MaintenancePageConfigMap string `json:"maintenancePageConfigMap"`
+
+	// CipherPreference specifies whether the router enforces its own
+	// cipher order ("Server") or honors the client's ("Client") during the
+	// TLS handshake.  If it is empty, the router's default is used.
+	CipherPreference string `json:"cipherPreference"`
+
+	// CertificateFailurePolicy specifies what the router does if the
+	// default certificate is expired or otherwise invalid.  With
+	// "FailOpen", the router serves the certificate anyway.  With
+	// "FailClosed", the router rejects TLS connections that would use the
+	// certificate, and the ingresscontroller is reported as unavailable
+	// until the certificate is replaced.  If it is empty, "FailOpen" is
+	// used.
+	CertificateFailurePolicy string `json:"certificateFailurePolicy"`
+
+	// HTTPConnectionMode specifies whether the router keeps connections
+	// open after responding to a request.  With "KeepAlive", client and
+	// server connections are kept open.  With "ServerClose", client
+	// connections are kept open but server connections are closed after
+	// each response.  With "Close", the router closes the connection
+	// after each response, which works around legacy HTTP/1.0 clients
+	// that mishandle keep-alive.  If it is empty, the router's default is
+	// used.
+	HTTPConnectionMode string `json:"httpConnectionMode"`
+
+	// RequestSmugglingProtection specifies how the router handles HTTP/1
+	// requests with ambiguous framing, such as requests with both
+	// Content-Length and Transfer-Encoding headers or with duplicate
+	// Content-Length headers, which can be used to smuggle requests past
+	// the router.  With "Strict", the router rejects such requests.  With
+	// "Lenient", the router accepts them and normalizes their framing,
+	// which some legacy clients need.  If it is empty, "Strict" is used.
+	RequestSmugglingProtection string `json:"requestSmugglingProtection"`
+
+	// SyslogProtocol specifies the transport protocol, "UDP" or "TCP",
+	// that the router uses to send access logs to the syslog endpoint in
+	// spec.logging.access.destination.syslog.  TCP delivers logs reliably
+	// at the cost of a connection to the endpoint.  If it is empty, "UDP"
+	// is used.
+	SyslogProtocol string `json:"syslogProtocol"`
+
+	// GRPCMode specifies that the router should by default proxy routes
+	// in a way that suits gRPC backends: using HTTP/2 end-to-end, without
+	// buffering requests or responses, and with client and server
+	// timeouts that allow long-lived streams unless spec.tuningOptions
+	// sets them.  A route can opt out using the
+	// haproxy.router.openshift.io/grpc-mode=false annotation and can
+	// override the timeouts using the haproxy.router.openshift.io/timeout
+	// annotation.  HTTP/2 must be enabled, and httpConnectionMode must
+	// keep connections alive.
+	GRPCMode bool `json:"grpcMode"`
+
+	// ExistingLoadBalancerService specifies the name of a pre-created
+	// LoadBalancer-type service in the operand namespace that the operator
+	// should adopt instead of creating its own.  The operator reconciles
+	// the selector, ports, and managed annotations of the adopted service
+	// but never creates, deletes, or recreates it.
+	ExistingLoadBalancerService string `json:"existingLoadBalancerService"`
+
+	// RequireVerifiedDNS specifies that the ingresscontroller must not be
+	// reported as Available until the wildcard DNS record has been
+	// verified to resolve to the expected targets.
+	RequireVerifiedDNS bool `json:"requireVerifiedDNS"`
+
+	// AccessLogRateLimit specifies the maximum number of access log
+	// messages per second that the logging sidecar accepts from the
+	// router; excess messages are dropped.  It applies only if access
+	// logs are sent to a sidecar container.  If it is zero, access logs
+	// are not rate limited.
+	AccessLogRateLimit int32 `json:"accessLogRateLimit"`
+
+	// SharedLoadBalancerService specifies the name of a LoadBalancer-type
+	// service in the operand namespace that the ingresscontroller shares
+	// with any other ingresscontrollers that specify the same name.  The
+	// operator creates the shared service if necessary, allocates a
+	// distinct pair of ports on it to each ingresscontroller, and deletes
+	// it once no ingresscontroller uses it.
+	SharedLoadBalancerService string `json:"sharedLoadBalancerService"`
+
+	// EphemeralStorage specifies the ephemeral-storage request and limit
+	// for the router container.
+	EphemeralStorage *resourceOverride `json:"ephemeralStorage"`
+
+	// CPU specifies the CPU request and limit for the router container.
+	// If spec.tuningOptions.threadCount is unset, the number of HAProxy
+	// threads follows the CPU limit, or the CPU request if it is larger
+	// than the default thread count.
+	CPU *resourceOverride `json:"cpu"`
+
+	// AdditionalWildcardDomains specifies domains other than the
+	// ingresscontroller's domain for which the router serves wildcard
+	// certificates.  The operator generates and rotates a wildcard
+	// certificate for each domain, and the router selects the certificate
+	// using SNI.
+	AdditionalWildcardDomains []string `json:"additionalWildcardDomains"`
+
+	// DeploymentStrategy specifies the strategy with which the router
+	// deployment is rolled out: "RollingUpdate" or "Recreate".  With
+	// "Recreate", all router pods are terminated before new ones are
+	// created, which trades per-node downtime during rolling updates for a
+	// predictable replacement that can be coordinated with an external
+	// drain.  It may only be set to "Recreate" with the HostNetwork
+	// endpoint publishing strategy.  If it is empty, "RollingUpdate" is
+	// used.
+	DeploymentStrategy string `json:"deploymentStrategy"`
+
+	// PodAntiAffinity specifies whether the anti-affinity that keeps
+	// router pods of the same generation on different nodes is "Required"
+	// or "Preferred".  Required anti-affinity leaves replicas in excess of
+	// the number of eligible nodes pending, whereas preferred
+	// anti-affinity schedules them but may colocate them.  This applies
+	// only to endpoint publishing strategies that use anti-affinity.  If
+	// it is empty, "Required" is used.
+	PodAntiAffinity string `json:"podAntiAffinity"`
+
+	// AffinityTopologyKey specifies the node label that defines the
+	// topology domains, for example racks, in which the affinity policy
+	// colocates router pods of different generations and the
+	// anti-affinity policy keeps router pods of the same generation apart.
+	// This applies only to endpoint publishing strategies that use
+	// affinity.  If it is empty, "kubernetes.io/hostname" is used.
+	AffinityTopologyKey string `json:"affinityTopologyKey"`
+
+	// ZoneAwareAffinity, if true, adds a lower-weight affinity term that
+	// prefers to place a new router pod in the same zone as a router pod
+	// of a different generation when no node with such a pod can accept
+	// it, which keeps local endpoints in the zone during a rolling
+	// update.  This applies only to endpoint publishing strategies that
+	// use affinity.
+	ZoneAwareAffinity bool `json:"zoneAwareAffinity"`
+
+	// RollingUpdate specifies the parameters of the router deployment's
+	// rolling update strategy.  Parameters that are not set keep the
+	// values that the operator computes from the endpoint publishing
+	// strategy and replica count.
+	RollingUpdate *rollingUpdateOverride `json:"rollingUpdate"`
+
+	// HostNetworkSurge specifies that the operator should roll out the
+	// router deployment of an ingresscontroller with the HostNetwork
+	// endpoint publishing strategy using one surge pod at a time and
+	// without taking down replicas, which requires a free node for the
+	// surge pod.  The operator does this anyway if the cluster autoscaler
+	// is enabled, which adds a node for the surge pod.  This has no effect
+	// if rollingUpdate is set.
+	HostNetworkSurge bool `json:"hostNetworkSurge"`
+
+	// DisableSurgeForLocalTrafficPolicy specifies that the operator should
+	// roll out the router deployment of an ingresscontroller with the
+	// LoadBalancerService endpoint publishing strategy without surge pods
+	// if the load balancer service uses the "Local" external traffic
+	// policy, so that a surge pod does not make a node a load balancer
+	// target while the old pod on the node drains.  maxUnavailable is
+	// converted into a pod count of at least 1.  This has no effect if
+	// rollingUpdate is set.
+	DisableSurgeForLocalTrafficPolicy bool `json:"disableSurgeForLocalTrafficPolicy"`
+
+	// RelaxMaxUnavailableDuringDrain specifies that the operator should
+	// temporarily raise the router deployment's maxUnavailable while
+	// router pods are on nodes that are being drained so that rollouts
+	// can make progress.  The usual value is restored once no router pod
+	// is on a draining node.
+	RelaxMaxUnavailableDuringDrain bool `json:"relaxMaxUnavailableDuringDrain"`
+
+	// LimitRolloutToPodDisruptionBudget specifies that, when the router
+	// deployment has a pod disruption budget, the operator should roll out
+	// the deployment without surge and without taking down more replicas at
+	// once than the budget allows.  Surge pods cannot always be scheduled
+	// because of the router's pod anti-affinity policy, and a rollout that
+	// waits for them can deadlock with evictions that the budget blocks.
+	LimitRolloutToPodDisruptionBudget bool `json:"limitRolloutToPodDisruptionBudget"`
+
+	// DNSRecordDeletionGracePeriodSeconds specifies how long the operator
+	// retains the ingresscontroller's wildcard DNS record after the
+	// ingresscontroller is deleted.  If an ingresscontroller with the same
+	// name is created within this period, it adopts the retained record,
+	// which avoids DNS churn when an ingresscontroller is recreated.  The
+	// retained record keeps pointing at the deleted ingresscontroller's
+	// load balancer until it is adopted or deleted.  If it is zero, the
+	// record is deleted along with the ingresscontroller.
+	DNSRecordDeletionGracePeriodSeconds int32 `json:"dnsRecordDeletionGracePeriodSeconds"`
+
+	// FSGroup specifies the supplemental group that the router pod's
+	// security context applies to mounted volumes so that the router can
+	// read mounted secrets and configmaps regardless of the user ID it runs
+	// as.  If it is unset, the fsGroup is left to the pod's security context
+	// constraint.
+	FSGroup *int64 `json:"fsGroup"`
+
+	// AnnotateAdmittedRoutes specifies that the operator should list the
+	// ingresscontroller's name in the
+	// ingress.operator.openshift.io/admitted-by annotation on routes that
+	// the ingresscontroller has admitted, which helps identify which
+	// ingresscontroller serves a route.  The operator removes the name when
+	// the ingresscontroller no longer admits the route, when the override
+	// is removed, or when the ingresscontroller is deleted.
+	AnnotateAdmittedRoutes bool `json:"annotateAdmittedRoutes"`
+
+	// ReportRouteRejections specifies that the operator should emit a
+	// warning event on each route that the ingresscontroller rejects,
+	// explaining the rejection in terms of the ingresscontroller's route
+	// admission policy, and on each route that the ingresscontroller
+	// stops admitting because its route or namespace selector no longer
+	// selects the route.
+	ReportRouteRejections bool `json:"reportRouteRejections"`
+
+	// RouteQuota, if set, limits the number of routes per namespace that
+	// the ingresscontroller admits.  The operator labels the newest routes
+	// in a namespace that exceed the namespace's quota so that the router
+	// stops serving them, and reports each of them with a warning event.
+	// The quota is enforced when the operator reconciles the
+	// ingresscontroller, shortly after routes are created, so the router
+	// may briefly serve a new route that exceeds the quota.
+	RouteQuota *routeQuotaOverride `json:"routeQuota"`
+
+	// ReplicasFromNodeCount specifies that, if the ingresscontroller uses
+	// the HostNetwork endpoint publishing strategy and does not specify
+	// spec.replicas, the router deployment should have one replica for
+	// each node on which a router pod can run.  The operator follows nodes
+	// being added and removed and grows the deployment in steps so that a
+	// cluster scale-up does not cause it to thrash.
+	ReplicasFromNodeCount bool `json:"replicasFromNodeCount"`
+
+	// ReportActiveConnections specifies that the operator should
+	// periodically scrape the metrics of the ingresscontroller's router
+	// pods and report the number of active client connections to each pod
+	// in the ingresscontroller's "ActiveConnections" status condition.
+	ReportActiveConnections bool `json:"reportActiveConnections"`
+
+	// HeadlessService specifies that the operator should create a headless
+	// service (a ClusterIP service with clusterIP "None") for the
+	// ingresscontroller's router pods in addition to its other services.
+	// DNS for a headless service resolves to the addresses of the ready
+	// router pods, which allows clients to address router pods directly.
+	HeadlessService bool `json:"headlessService"`
+
+	// DefaultCertificateIssuerSecret specifies the name of a secret in the
+	// operator namespace with a CA certificate and key, such as an
+	// intermediate CA that is issued by a cluster-configured CA, from which
+	// the operator issues the ingresscontroller's default certificate
+	// instead of from the operator's self-signed CA.  The secret's
+	// "tls.crt" key has the CA certificate followed by any certificates
+	// that issued it, which are appended to the default certificate's
+	// chain, and its "tls.key" key has the CA's private key.  This has no
+	// effect if spec.defaultCertificate is set.
+	DefaultCertificateIssuerSecret string `json:"defaultCertificateIssuerSecret"`
+
+	// RouterNamespaceEgressIPs specifies IP addresses that the operator
+	// configures as the egress IPs of the router namespace so that
+	// traffic from router pods to endpoints outside the cluster has
+	// these source addresses and can be allowed by external firewalls.
+	// On OpenShift SDN, the operator sets the egress IPs of the router
+	// namespace's netnamespace.  On OVN-Kubernetes, the operator manages
+	// an egressip that selects the router namespace; nodes must be
+	// labeled as egress-assignable.  The router namespace is shared by
+	// all ingresscontrollers, so this may only be set on the default
+	// ingresscontroller.
+	RouterNamespaceEgressIPs []string `json:"routerNamespaceEgressIPs"`
+
+	// AuditEvents specifies that, in addition to logging an audit record
+	// whenever the operator updates the router deployment in response to
+	// a change, the operator should record an event on the
+	// ingresscontroller with the fields that it changed and the field
+	// manager that last changed the ingresscontroller's spec.
+	AuditEvents bool `json:"auditEvents"`
+
+	// AlertingRules, if set, makes the operator manage a prometheusrule
+	// in the operator namespace with alerts for degraded
+	// ingresscontrollers, expiring default certificates, and failing DNS
+	// provider calls, using the given thresholds.  The alerts apply to
+	// all ingresscontrollers, so this may only be set on the default
+	// ingresscontroller.
+	AlertingRules *alertingRulesOverride `json:"alertingRules"`
+}
+
+// alertingRulesOverride specifies the thresholds of the alerts that the
+// operator generates.
+type alertingRulesOverride struct {
+	// DegradedForSeconds is the number of seconds for which an
+	// ingresscontroller must be degraded before an alert fires.  If it is
+	// zero, 300 seconds is used.
+	DegradedForSeconds int32 `json:"degradedForSeconds"`
+	// CertificateExpiryDays is the number of days before an
+	// ingresscontroller's default certificate expires when an alert
+	// fires.  If it is zero, 14 days is used.
+	CertificateExpiryDays int32 `json:"certificateExpiryDays"`
+	// DNSFailureThreshold is the number of failed calls to the DNS
+	// provider within 15 minutes above which an alert fires.  If it is
+	// zero, any failure fires the alert.
+	DNSFailureThreshold int32 `json:"dnsFailureThreshold"`
+}
+
+// rollingUpdateOverride specifies rolling update parameters.
+type rollingUpdateOverride struct {
+	// MaxUnavailable is the maximum number of router pods, or percentage
+	// of the desired replicas, that may be unavailable during a rolling
+	// update.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable"`
+	// MaxSurge is the maximum number of router pods, or percentage of the
+	// desired replicas, that may be created above the desired replicas
+	// during a rolling update.
+	MaxSurge *intstr.IntOrString `json:"maxSurge"`
+	// UseIntegerCounts specifies that percentages of maxUnavailable and
+	// maxSurge, whether overridden or computed, are converted into pod
+	// counts based on the desired replicas, rounding maxSurge up and
+	// maxUnavailable according to maxUnavailableRounding, so that the
+	// deployment's parameters do not depend on the deployment
+	// controller's rounding.
+	UseIntegerCounts bool `json:"useIntegerCounts"`
+	// MaxUnavailableRounding specifies how useIntegerCounts rounds a
+	// percentage of maxUnavailable: "Floor" rounds down, as the deployment
+	// controller does, and "Ceil" rounds up, which speeds up rolling
+	// updates at the cost of availability.  If it is empty, "Floor" is
+	// used.
+	MaxUnavailableRounding string `json:"maxUnavailableRounding"`
+}
+
+// tcpKeepaliveOverride specifies TCP keepalive parameters.  Parameters that are
+// zero use the kernel's defaults.
+type tcpKeepaliveOverride struct {
+	// IdleSeconds is the number of seconds for which a connection must be
+	// idle before the router sends keepalive probes (HAProxy's
+	// clitcpka-idle and srvtcpka-idle).
+	IdleSeconds int32 `json:"idleSeconds"`
+	// IntervalSeconds is the number of seconds between keepalive probes
+	// (HAProxy's clitcpka-intvl and srvtcpka-intvl).
+	IntervalSeconds int32 `json:"intervalSeconds"`
+	// Count is the number of unanswered keepalive probes after which the
+	// router closes the connection (HAProxy's clitcpka-cnt and
+	// srvtcpka-cnt).
+	Count int32 `json:"count"`
+}
+
+// tracingOverride specifies OpenTelemetry tracing parameters.
+type tracingOverride struct {
+	// Endpoint is the host and port of the OpenTelemetry collector to
+	// which the router exports spans using OTLP, for example
+	// "otel-collector.observability.svc:4317".
+	Endpoint string `json:"endpoint"`
+	// SamplingRatio is the fraction, from 0 to 1, of requests for which
+	// the router emits spans.  If it is nil, the router's default is used.
+	SamplingRatio *float64 `json:"samplingRatio"`
+}
+
+// hstsOverride specifies an HTTP Strict Transport Security policy.
+type hstsOverride struct {
+	// MaxAge is the number of seconds for which clients should only
+	// access the host using HTTPS.  A value of zero makes clients forget
+	// the policy.
+	MaxAge int64 `json:"maxAge"`
+	// IncludeSubDomains specifies whether the policy applies to the
+	// host's subdomains too.
+	IncludeSubDomains bool `json:"includeSubDomains"`
+	// Preload specifies whether the host consents to being included in
+	// browsers' HSTS preload lists.
+	Preload bool `json:"preload"`
+}
+
+// header returns the value of the Strict-Transport-Security header for the
+// policy, in the format of the haproxy.router.openshift.io/hsts_header route
+// annotation.
+func (h *hstsOverride) header() string {
+	directives := []string{"max-age=" + strconv.FormatInt(h.MaxAge, 10)}
+	if h.IncludeSubDomains {
+		directives = append(directives, "includeSubDomains")
+	}
+	if h.Preload {
+		directives = append(directives, "preload")
+	}
+	return strings.Join(directives, ";")
+}
+
+// sourceHashBalancingOverride specifies the client attribute that the router
+// hashes to choose a backend server.
+type sourceHashBalancingOverride struct {
+	// Hash is "SourceIP" to hash the client's source IP address or
+	// "Header" to hash the value of the request header named by
+	// HeaderName.  If it is empty, "SourceIP" is used.
+	Hash string `json:"hash"`
+	// HeaderName is the name of the request header to hash.  It is
+	// required if Hash is "Header" and must be empty otherwise.
+	HeaderName string `json:"headerName"`
+}
+
+// algorithm returns the HAProxy balance algorithm for HTTP backends.
+// Requests without the hashed header are balanced round-robin by HAProxy.
+func (b *sourceHashBalancingOverride) algorithm() string {
+	if b.Hash == sourceHashHeader {
+		return "hdr(" + b.HeaderName + ")"
+	}
+	return "source"
+}
+
+// canaryRouterOverride specifies a canary router deployment.
+type canaryRouterOverride struct {
+	// Image is the router image that the canary deployment runs.
+	Image string `json:"image"`
+	// Weight is the percentage, from 0 to 100, of the ingresscontroller's
+	// replicas, and thus of the traffic, that the canary deployment
+	// receives.
+	Weight int32 `json:"weight"`
+}
+
+// resourceOverride specifies the request and limit of a resource.
+type resourceOverride struct {
+	// Request is the resource request.  If it is unset, the router
+	// deployment's default request, if any, is used.
+	Request *resource.Quantity `json:"request"`
+	// Limit is the resource limit.  If it is unset, no limit is set.
+	Limit *resource.Quantity `json:"limit"`
+}
+
+// defaultBackendOverride references a service that serves unmatched requests.
+type defaultBackendOverride struct {
+	// Name is the name of the service in the operand namespace.
+	Name string `json:"name"`
+	// Port is the service port.  If it is zero, port 80 is used.
+	Port int32 `json:"port"`
+}
+
+// getUnsupportedConfigOverrides parses the given ingresscontroller's
+// spec.unsupportedConfigOverrides field.  The return value is never nil unless
+// an error is returned.
+func getUnsupportedConfigOverrides(ic *operatorv1.IngressController) (*unsupportedConfigOverrides, error) {
+	var overrides unsupportedConfigOverrides
+	if len(ic.Spec.UnsupportedConfigOverrides.Raw) > 0 {
+		if err := json.Unmarshal(ic.Spec.UnsupportedConfigOverrides.Raw, &overrides); err != nil {
+			return nil, fmt.Errorf("ingresscontroller %q has invalid spec.unsupportedConfigOverrides: %w", ic.Name, err)
+		}
+	}
+	return &overrides, nil
+}
+
+// dnsRecordDeletionGracePeriod returns the period for which the given
+// ingresscontroller's wildcard DNS record is retained after the
+// ingresscontroller is deleted, or zero if the record should be deleted along
+// with the ingresscontroller.
+func dnsRecordDeletionGracePeriod(ic *operatorv1.IngressController) time.Duration {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil || overrides.DNSRecordDeletionGracePeriodSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(overrides.DNSRecordDeletionGracePeriodSeconds) * time.Second
+}
+
+// AdditionalWildcardDomains returns the additional wildcard domains from the
+// given ingresscontroller's spec.unsupportedConfigOverrides field, or nil if
+// the field is invalid.
+func AdditionalWildcardDomains(ic *operatorv1.IngressController) []string {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		return nil
+	}
+	return overrides.AdditionalWildcardDomains
+}
+
+// DefaultCertificateIssuerSecret returns the name of the secret with the CA
+// from which the given ingresscontroller's default certificate is issued, from
+// the ingresscontroller's spec.unsupportedConfigOverrides field, or the empty
+// string if the operator's self-signed CA is used or the field is invalid.
+func DefaultCertificateIssuerSecret(ic *operatorv1.IngressController) string {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		return ""
+	}
+	return overrides.DefaultCertificateIssuerSecret
+}
+
+// defaultBackendAddress returns the address of the given default backend
+// service in the form that the router expects for
+// ROUTER_DEFAULT_BACKEND_SERVICE.
+func defaultBackendAddress(backend *defaultBackendOverride) string {
+	port := backend.Port
+	if port == 0 {
+		port = 80
+	}
+	return fmt.Sprintf("%s.%s.svc:%d", backend.Name, controller.DefaultOperandNamespace, port)
+}
+
+// validateUnsupportedConfigOverrides validates the given ingresscontroller's
+// spec.unsupportedConfigOverrides field.
+func validateUnsupportedConfigOverrides(ic *operatorv1.IngressController) error {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	if backend := overrides.DefaultBackend; backend != nil {
+		if msgs := validation.IsDNS1035Label(backend.Name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.defaultBackend.name %q: %s", backend.Name, strings.Join(msgs, ", ")))
+		}
+		if backend.Port < 0 || backend.Port > 65535 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.defaultBackend.port %d: must be between 1 and 65535, or 0 to use port 80", backend.Port))
+		}
+	}
+
+	if name := overrides.ExistingLoadBalancerService; len(name) != 0 {
+		if msgs := validation.IsDNS1035Label(name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.existingLoadBalancerService %q: %s", name, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if name := overrides.MaintenancePageConfigMap; len(name) != 0 {
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.maintenancePageConfigMap %q: %s", name, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if name := overrides.DefaultCertificateIssuerSecret; len(name) != 0 {
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.defaultCertificateIssuerSecret %q: %s", name, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if name := overrides.SharedLoadBalancerService; len(name) != 0 {
+		if msgs := validation.IsDNS1035Label(name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sharedLoadBalancerService %q: %s", name, strings.Join(msgs, ", ")))
+		}
+		if len(overrides.ExistingLoadBalancerService) != 0 {
+			errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.sharedLoadBalancerService and spec.unsupportedConfigOverrides.existingLoadBalancerService are mutually exclusive"))
+		}
+	}
+
+	if host := overrides.DefaultHostHeader; len(host) != 0 {
+		if msgs := validation.IsDNS1123Subdomain(host); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.defaultHostHeader %q: %s", host, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if len(overrides.ReadMethods) != 0 {
+		errs = append(errs, validateReadMethods(overrides.ReadMethods)...)
+	}
+
+	if len(overrides.AdditionalWildcardDomains) != 0 {
+		errs = append(errs, validateAdditionalWildcardDomains(ic, overrides.AdditionalWildcardDomains)...)
+	}
+
+	if rules := overrides.AlertingRules; rules != nil {
+		if ic.Name != manifests.DefaultIngressControllerName {
+			errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.alertingRules may only be set on the %q ingresscontroller", manifests.DefaultIngressControllerName))
+		}
+		if rules.DegradedForSeconds < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.alertingRules.degradedForSeconds %d: must not be negative", rules.DegradedForSeconds))
+		}
+		if rules.CertificateExpiryDays < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.alertingRules.certificateExpiryDays %d: must not be negative", rules.CertificateExpiryDays))
+		}
+		if rules.DNSFailureThreshold < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.alertingRules.dnsFailureThreshold %d: must not be negative", rules.DNSFailureThreshold))
+		}
+	}
+
+	switch overrides.PodAntiAffinity {
+	case "", podAntiAffinityRequired, podAntiAffinityPreferred:
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.podAntiAffinity %q: must be %q or %q", overrides.PodAntiAffinity, podAntiAffinityRequired, podAntiAffinityPreferred))
+	}
+
+	if key := overrides.AffinityTopologyKey; len(key) != 0 {
+		if msgs := validation.IsQualifiedName(key); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.affinityTopologyKey %q: %s", key, strings.Join(msgs, ", ")))
+		}
+	}
+
+	switch overrides.DeploymentStrategy {
+	case "", deploymentStrategyRollingUpdate:
+	case deploymentStrategyRecreate:
+		if eps := ic.Status.EndpointPublishingStrategy; eps == nil || eps.Type != operatorv1.HostNetworkStrategyType {
+			errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.deploymentStrategy %q requires the %q endpoint publishing strategy", deploymentStrategyRecreate, operatorv1.HostNetworkStrategyType))
+		}
+		if overrides.RollingUpdate != nil {
+			errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.rollingUpdate may not be set with spec.unsupportedConfigOverrides.deploymentStrategy %q", deploymentStrategyRecreate))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.deploymentStrategy %q: must be %q or %q", overrides.DeploymentStrategy, deploymentStrategyRollingUpdate, deploymentStrategyRecreate))
+	}
+
+	if overrides.RollingUpdate != nil {
+		errs = append(errs, validateRollingUpdate(ic, overrides.RollingUpdate)...)
+	}
+
+	if len(overrides.RouterNamespaceEgressIPs) != 0 {
+		errs = append(errs, validateRouterNamespaceEgressIPs(ic, overrides.RouterNamespaceEgressIPs)...)
+	}
+
+	if v := overrides.MaxHeaderCount; v < 0 || v > maxHeaderCountLimit {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.maxHeaderCount %d: must be 0 (default) or between 1 and %d", v, maxHeaderCountLimit))
+	}
+
+	if overrides.SSLCacheSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sslCacheSize %d: must not be negative", overrides.SSLCacheSize))
+	}
+
+	if v := overrides.SSLMaxRecordSize; v != 0 && (v < minSSLMaxRecordSize || v > maxSSLMaxRecordSize) {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sslMaxRecordSize %d: must be between %d and %d", v, minSSLMaxRecordSize, maxSSLMaxRecordSize))
+	}
+
+	if overrides.MaxSSLHandshakeRate < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.maxSSLHandshakeRate %d: must not be negative", overrides.MaxSSLHandshakeRate))
+	}
+
+	if overrides.BackendPoolMaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.backendPoolMaxConnections %d: must not be negative", overrides.BackendPoolMaxConnections))
+	}
+
+	if overrides.ServerMaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.serverMaxConnections %d: must not be negative", overrides.ServerMaxConnections))
+	}
+
+	if overrides.Backlog < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.backlog %d: must not be negative", overrides.Backlog))
+	}
+
+	if overrides.MinReadySeconds != nil && *overrides.MinReadySeconds < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.minReadySeconds %d: must not be negative", *overrides.MinReadySeconds))
+	}
+
+	if deadline := overrides.ProgressDeadlineSeconds; deadline != nil {
+		minReadySeconds := int32(routerDefaultMinReadySeconds)
+		if overrides.MinReadySeconds != nil {
+			minReadySeconds = *overrides.MinReadySeconds
+		}
+		if *deadline <= 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.progressDeadlineSeconds %d: must be positive", *deadline))
+		} else if *deadline <= minReadySeconds {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.progressDeadlineSeconds %d: must be greater than minReadySeconds %d", *deadline, minReadySeconds))
+		}
+	}
+
+	if keepalive := overrides.TCPKeepalive; keepalive != nil {
+		if keepalive.IdleSeconds < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tcpKeepalive.idleSeconds %d: must not be negative", keepalive.IdleSeconds))
+		}
+		if keepalive.IntervalSeconds < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tcpKeepalive.intervalSeconds %d: must not be negative", keepalive.IntervalSeconds))
+		}
+		if keepalive.Count < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tcpKeepalive.count %d: must not be negative", keepalive.Count))
+		}
+	}
+
+	if quota := overrides.RouteQuota; quota != nil {
+		errs = append(errs, validateRouteQuota(ic, quota)...)
+	}
+
+	if tracing := overrides.Tracing; tracing != nil {
+		errs = append(errs, validateTracing(tracing)...)
+	}
+
+	if balancing := overrides.SourceHashBalancing; balancing != nil {
+		if len(overrides.LoadBalancingAlgorithm) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sourceHashBalancing: may not be specified with loadBalancingAlgorithm"))
+		}
+		switch balancing.Hash {
+		case "", sourceHashSourceIP:
+			if len(balancing.HeaderName) != 0 {
+				errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sourceHashBalancing.headerName %q: may only be specified when hash is %q", balancing.HeaderName, sourceHashHeader))
+			}
+		case sourceHashHeader:
+			if !httpTokenRegexp.MatchString(balancing.HeaderName) {
+				errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sourceHashBalancing.headerName %q: must be a valid HTTP header name", balancing.HeaderName))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.sourceHashBalancing.hash %q: must be %q or %q", balancing.Hash, sourceHashSourceIP, sourceHashHeader))
+		}
+	}
+
+	if webhook := overrides.StatusWebhook; webhook != nil {
+		errs = append(errs, validateStatusWebhook(webhook)...)
+	}
+
+	if hsts := overrides.HSTS; hsts != nil && hsts.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.hsts.maxAge %d: must not be negative", hsts.MaxAge))
+	}
+
+	for _, zone := range overrides.LoadBalancerZones {
+		if len(zone) == 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.loadBalancerZones: zone must not be empty"))
+		}
+	}
+	if zones := overrides.LoadBalancerZones; sets.NewString(zones...).Len() != len(zones) {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.loadBalancerZones %v: zones must be unique", zones))
+	}
+
+	if canary := overrides.CanaryRouter; canary != nil {
+		if len(canary.Image) == 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.canaryRouter.image: must not be empty"))
+		}
+		if canary.Weight < 0 || canary.Weight > 100 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.canaryRouter.weight %d: must be between 0 and 100", canary.Weight))
+		}
+	}
+
+	if overrides.StatsTimeoutSeconds < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.statsTimeoutSeconds %d: must not be negative", overrides.StatsTimeoutSeconds))
+	}
+
+	if overrides.HTTP2MaxConcurrentStreams < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.http2MaxConcurrentStreams %d: must not be negative", overrides.HTTP2MaxConcurrentStreams))
+	}
+
+	seenALPNProtocols := sets.NewString()
+	for _, protocol := range overrides.ALPNProtocols {
+		switch {
+		case !alpnProtocols.Has(protocol):
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.alpnProtocols value %q: must be one of %v", protocol, alpnProtocols.List()))
+		case seenALPNProtocols.Has(protocol):
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.alpnProtocols value %q: duplicate protocol", protocol))
+		}
+		seenALPNProtocols.Insert(protocol)
+	}
+
+	if storage := overrides.EphemeralStorage; storage != nil {
+		if storage.Request != nil && storage.Request.Sign() < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.ephemeralStorage.request %s: must not be negative", storage.Request.String()))
+		}
+		if storage.Limit != nil && storage.Limit.Sign() < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.ephemeralStorage.limit %s: must not be negative", storage.Limit.String()))
+		}
+		if storage.Request != nil && storage.Limit != nil && storage.Limit.Cmp(*storage.Request) < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.ephemeralStorage: limit (%s) must not be less than request (%s)", storage.Limit.String(), storage.Request.String()))
+		}
+	}
+
+	if cpu := overrides.CPU; cpu != nil {
+		if cpu.Request != nil && cpu.Request.Sign() <= 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.cpu.request %s: must be positive", cpu.Request.String()))
+		}
+		if cpu.Limit != nil && cpu.Limit.Sign() <= 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.cpu.limit %s: must be positive", cpu.Limit.String()))
+		}
+		if cpu.Request != nil && cpu.Limit != nil && cpu.Limit.Cmp(*cpu.Request) < 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.cpu: limit (%s) must not be less than request (%s)", cpu.Limit.String(), cpu.Request.String()))
+		}
+	}
+
+	if overrides.DefaultRouteRequestRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.defaultRouteRequestRateLimit %d: must not be negative", overrides.DefaultRouteRequestRateLimit))
+	}
+
+	if overrides.DNSRecordDeletionGracePeriodSeconds < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.dnsRecordDeletionGracePeriodSeconds %d: must not be negative", overrides.DNSRecordDeletionGracePeriodSeconds))
+	}
+
+	if overrides.FSGroup != nil && *overrides.FSGroup < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.fsGroup %d: must not be negative", *overrides.FSGroup))
+	}
+
+	if overrides.AccessLogRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.accessLogRateLimit %d: must not be negative", overrides.AccessLogRateLimit))
+	}
+
+	switch overrides.CipherPreference {
+	case "", cipherPreferenceServer, cipherPreferenceClient:
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.cipherPreference %q: must be %q or %q", overrides.CipherPreference, cipherPreferenceServer, cipherPreferenceClient))
+	}
+
+	switch overrides.CertificateFailurePolicy {
+	case "", certificateFailurePolicyFailOpen, certificateFailurePolicyFailClosed:
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.certificateFailurePolicy %q: must be %q or %q", overrides.CertificateFailurePolicy, certificateFailurePolicyFailOpen, certificateFailurePolicyFailClosed))
+	}
+
+	switch overrides.RequestSmugglingProtection {
+	case "", requestSmugglingProtectionStrict, requestSmugglingProtectionLenient:
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.requestSmugglingProtection %q: must be %q or %q", overrides.RequestSmugglingProtection, requestSmugglingProtectionStrict, requestSmugglingProtectionLenient))
+	}
+
+	switch overrides.SyslogProtocol {
+	case "", syslogProtocolUDP, syslogProtocolTCP:
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.syslogProtocol %q: must be %q or %q", overrides.SyslogProtocol, syslogProtocolUDP, syslogProtocolTCP))
+	}
+
+	if mode := overrides.HTTPConnectionMode; len(mode) != 0 {
+		if _, ok := httpConnectionModeOptions[mode]; !ok {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.httpConnectionMode %q: must be %q, %q, or %q", mode, httpConnectionModeKeepAlive, httpConnectionModeServerClose, httpConnectionModeClose))
+		}
+	}
+
+	if overrides.GRPCMode {
+		switch overrides.HTTPConnectionMode {
+		case "", httpConnectionModeKeepAlive:
+		default:
+			errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.grpcMode requires spec.unsupportedConfigOverrides.httpConnectionMode to be %q", httpConnectionModeKeepAlive))
+		}
+	}
+
+	if overrides.PerRouteClientCertificatePolicy && len(ic.Spec.ClientTLS.ClientCertificatePolicy) == 0 {
+		errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.perRouteClientCertificatePolicy requires spec.clientTLS.clientCertificatePolicy to be set"))
+	}
+
+	if overrides.TrustForwardedProto && ic.Spec.HTTPHeaders != nil && ic.Spec.HTTPHeaders.ForwardedHeaderPolicy == operatorv1.ReplaceHTTPHeaderPolicy {
+		errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.trustForwardedProto requires spec.httpHeaders.forwardedHeaderPolicy not to be %q", operatorv1.ReplaceHTTPHeaderPolicy))
+	}
+
+	if v := overrides.CompressionLevel; v != 0 {
+		if v < minCompressionLevel || v > maxCompressionLevel {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.compressionLevel %d: must be between %d and %d", v, minCompressionLevel, maxCompressionLevel))
+		}
+		if len(ic.Spec.HTTPCompression.MimeTypes) == 0 {
+			errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.compressionLevel requires spec.httpCompression.mimeTypes to be set"))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateAdditionalWildcardDomains returns errors for any of the given
+// additional wildcard domains that are not valid domain names or that overlap
+// with one another or with the ingresscontroller's own domain.  Because a
+// wildcard matches exactly one label, two wildcard certificates overlap only if
+// their domains are equal.
+func validateAdditionalWildcardDomains(ic *operatorv1.IngressController, domains []string) []error {
+	var errs []error
+	seen := map[string]bool{}
+	if len(ic.Spec.Domain) != 0 {
+		seen[strings.ToLower(ic.Spec.Domain)] = true
+	}
+	if len(ic.Status.Domain) != 0 {
+		seen[strings.ToLower(ic.Status.Domain)] = true
+	}
+	for _, domain := range domains {
+		if msgs := validation.IsDNS1123Subdomain(domain); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.additionalWildcardDomains entry %q: %s", domain, strings.Join(msgs, ", ")))
+			continue
+		}
+		if seen[strings.ToLower(domain)] {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.additionalWildcardDomains entry %q: overlaps with another wildcard domain", domain))
+		}
+		secretName := controller.RouterWildcardCertificateSecretName(ic, controller.DefaultOperandNamespace, domain)
+		if msgs := validation.IsDNS1123Subdomain(secretName.Name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.additionalWildcardDomains entry %q: certificate secret name %q is invalid: %s", domain, secretName.Name, strings.Join(msgs, ", ")))
+		}
+		seen[strings.ToLower(domain)] = true
+	}
+	return errs
+}
+
+// validateTracing returns errors for a tracing collector endpoint that is not
+// a valid host and port or for a sampling ratio that is not between 0 and 1.
+func validateTracing(tracing *tracingOverride) []error {
+	var errs []error
+	if len(tracing.Endpoint) == 0 {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tracing.endpoint: must not be empty"))
+	} else if host, port, err := net.SplitHostPort(tracing.Endpoint); err != nil {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tracing.endpoint %q: %v", tracing.Endpoint, err))
+	} else {
+		if net.ParseIP(host) == nil {
+			if msgs := validation.IsDNS1123Subdomain(host); len(msgs) != 0 {
+				errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tracing.endpoint %q: invalid host: %s", tracing.Endpoint, strings.Join(msgs, ", ")))
+			}
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tracing.endpoint %q: port must be between 1 and 65535", tracing.Endpoint))
+		}
+	}
+	if ratio := tracing.SamplingRatio; ratio != nil && (*ratio < 0 || *ratio > 1) {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.tracing.samplingRatio %v: must be between 0 and 1", *ratio))
+	}
+	return errs
+}
+
+// validateReadMethods returns errors for any of the given HTTP methods that
+// are not known methods or that are repeated.  Methods are case-sensitive.
+func validateReadMethods(methods []string) []error {
+	var errs []error
+	seen := sets.NewString()
+	for _, method := range methods {
+		if !httpMethods.Has(method) {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.readMethods entry %q: must be one of %s", method, strings.Join(httpMethods.List(), ", ")))
+			continue
+		}
+		if seen.Has(method) {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.readMethods entry %q: duplicate method", method))
+		}
+		seen.Insert(method)
+	}
+	return errs
+}
+
+// validateRollingUpdate returns errors for rolling update parameters that are
+// not a non-negative integer or percentage, or that would leave both
+// maxUnavailable and maxSurge zero, which would prevent rollouts from making
+// progress.  Unset parameters take the values that desiredRouterDeployment
+// computes, which for maxSurge is zero with the HostNetwork endpoint publishing
+// strategy.
+func validateRollingUpdate(ic *operatorv1.IngressController, rollingUpdate *rollingUpdateOverride) []error {
+	var errs []error
+	// isZero returns a Boolean indicating whether the given value is
+	// zero, or an error if the value is invalid.
+	isZero := func(field string, value *intstr.IntOrString) (bool, error) {
+		v, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+		if err != nil {
+			return false, fmt.Errorf("invalid spec.unsupportedConfigOverrides.rollingUpdate.%s %q: %v", field, value.String(), err)
+		}
+		if v < 0 {
+			return false, fmt.Errorf("invalid spec.unsupportedConfigOverrides.rollingUpdate.%s %q: must not be negative", field, value.String())
+		}
+		return v == 0, nil
+	}
+
+	maxUnavailableZero := false
+	if rollingUpdate.MaxUnavailable != nil {
+		zero, err := isZero("maxUnavailable", rollingUpdate.MaxUnavailable)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		maxUnavailableZero = zero
+	}
+	maxSurgeZero := ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType
+	if rollingUpdate.MaxSurge != nil {
+		zero, err := isZero("maxSurge", rollingUpdate.MaxSurge)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		maxSurgeZero = zero
+	}
+	if maxUnavailableZero && maxSurgeZero {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.rollingUpdate: maxUnavailable and maxSurge must not both be zero"))
+	}
+	switch rollingUpdate.MaxUnavailableRounding {
+	case "", roundingFloor, roundingCeil:
+	default:
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.rollingUpdate.maxUnavailableRounding %q: must be %q or %q", rollingUpdate.MaxUnavailableRounding, roundingFloor, roundingCeil))
+	}
+	if len(rollingUpdate.MaxUnavailableRounding) != 0 && !rollingUpdate.UseIntegerCounts {
+		errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.rollingUpdate: maxUnavailableRounding requires useIntegerCounts"))
+	}
+	return errs
+}
+
+// validateRouterNamespaceEgressIPs returns errors for any of the given egress
+// IPs that are not valid IP addresses or that are repeated, or an error if the
+// given ingresscontroller is not the default ingresscontroller.
+func validateRouterNamespaceEgressIPs(ic *operatorv1.IngressController, ips []string) []error {
+	var errs []error
+	if ic.Name != manifests.DefaultIngressControllerName {
+		errs = append(errs, fmt.Errorf("spec.unsupportedConfigOverrides.routerNamespaceEgressIPs may only be set on the %q ingresscontroller", manifests.DefaultIngressControllerName))
+	}
+	seen := map[string]bool{}
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.routerNamespaceEgressIPs entry %q: must be an IP address", ip))
+			continue
+		}
+		if seen[parsed.String()] {
+			errs = append(errs, fmt.Errorf("invalid spec.unsupportedConfigOverrides.routerNamespaceEgressIPs entry %q: duplicate IP address", ip))
+		}
+		seen[parsed.String()] = true
+	}
+	return errs
+}
+
+// validateHTTP2MaxConcurrentStreams returns an error if the given
+// ingresscontroller sets spec.unsupportedConfigOverrides.http2MaxConcurrentStreams
+// without enabling HTTP/2.
+func validateHTTP2MaxConcurrentStreams(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress) error {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		// validateUnsupportedConfigOverrides reports the error.
+		return nil
+	}
+	if overrides.HTTP2MaxConcurrentStreams != 0 && !HTTP2IsEnabled(ic, ingressConfig) {
+		return fmt.Errorf("spec.unsupportedConfigOverrides.http2MaxConcurrentStreams requires HTTP/2 to be enabled using spec.unsupportedConfigOverrides.http2 or the %s annotation", RouterDefaultEnableHTTP2Annotation)
+	}
+	return nil
+}
+
+// validateALPNProtocols returns an error if the given ingresscontroller sets
+// spec.unsupportedConfigOverrides.alpnProtocols to a list that is inconsistent
+// with whether HTTP/2 is enabled: advertising "h2" with HTTP/2 disabled would
+// make clients negotiate a protocol that the router does not serve, and
+// omitting it with HTTP/2 enabled would silently disable HTTP/2.
+func validateALPNProtocols(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress) error {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil || len(overrides.ALPNProtocols) == 0 {
+		// validateUnsupportedConfigOverrides reports the error.
+		return nil
+	}
+	hasHTTP2 := sets.NewString(overrides.ALPNProtocols...).Has(alpnProtocolHTTP2)
+	switch enabled := HTTP2IsEnabled(ic, ingressConfig); {
+	case hasHTTP2 && !enabled:
+		return fmt.Errorf("spec.unsupportedConfigOverrides.alpnProtocols includes %q, which requires HTTP/2 to be enabled using spec.unsupportedConfigOverrides.http2 or the %s annotation", alpnProtocolHTTP2, RouterDefaultEnableHTTP2Annotation)
+	case !hasHTTP2 && enabled:
+		return fmt.Errorf("spec.unsupportedConfigOverrides.alpnProtocols must include %q when HTTP/2 is enabled", alpnProtocolHTTP2)
+	}
+	return nil
+}
+
+// validateGRPCMode returns an error if the given ingresscontroller sets
+// spec.unsupportedConfigOverrides.grpcMode without enabling HTTP/2.
+func validateGRPCMode(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress) error {
+	overrides, err := getUnsupportedConfigOverrides(ic)
+	if err != nil {
+		// validateUnsupportedConfigOverrides reports the error.
+		return nil
+	}
+	if overrides.GRPCMode && !HTTP2IsEnabled(ic, ingressConfig) {
+		return fmt.Errorf("spec.unsupportedConfigOverrides.grpcMode requires HTTP/2 to be enabled using spec.unsupportedConfigOverrides.http2 or the %s annotation", RouterDefaultEnableHTTP2Annotation)
+	}
+	return nil
+}
@@ -0,0 +1,1066 @@
+package ingress
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestValidateUnsupportedConfigOverrides verifies that
+// validateUnsupportedConfigOverrides accepts valid overrides and rejects
+// invalid ones.
+func TestValidateUnsupportedConfigOverrides(t *testing.T) {
+	testCases := []struct {
+		description     string
+		name            string
+		clientTLS       operatorv1.ClientTLS
+		httpCompression operatorv1.HTTPCompressionPolicy
+		httpHeaders     *operatorv1.IngressControllerHTTPHeaders
+		endpointType    operatorv1.EndpointPublishingStrategyType
+		overrides       string
+		expectError     bool
+	}{
+		{
+			description: "no overrides",
+			overrides:   "",
+			expectError: false,
+		},
+		{
+			description: "malformed overrides",
+			overrides:   `{"defaultBackend":"fallback"}`,
+			expectError: true,
+		},
+		{
+			description: "valid default backend",
+			overrides:   `{"defaultBackend":{"name":"fallback","port":8080}}`,
+			expectError: false,
+		},
+		{
+			description: "default backend with invalid name",
+			overrides:   `{"defaultBackend":{"name":"Fall_Back"}}`,
+			expectError: true,
+		},
+		{
+			description: "default backend with empty name",
+			overrides:   `{"defaultBackend":{"port":8080}}`,
+			expectError: true,
+		},
+		{
+			description: "default backend with port 0",
+			overrides:   `{"defaultBackend":{"name":"fallback","port":0}}`,
+			expectError: false,
+		},
+		{
+			description: "default backend with negative port",
+			overrides:   `{"defaultBackend":{"name":"fallback","port":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "default backend with invalid port",
+			overrides:   `{"defaultBackend":{"name":"fallback","port":65536}}`,
+			expectError: true,
+		},
+		{
+			description: "per-route client certificate policy without client TLS",
+			overrides:   `{"perRouteClientCertificatePolicy":true}`,
+			expectError: true,
+		},
+		{
+			description: "per-route client certificate policy with client TLS",
+			clientTLS: operatorv1.ClientTLS{
+				ClientCertificatePolicy: operatorv1.ClientCertificatePolicyRequired,
+			},
+			overrides:   `{"perRouteClientCertificatePolicy":true}`,
+			expectError: false,
+		},
+		{
+			description: "valid existing load balancer service",
+			overrides:   `{"existingLoadBalancerService":"preprovisioned-lb"}`,
+			expectError: false,
+		},
+		{
+			description: "existing load balancer service with invalid name",
+			overrides:   `{"existingLoadBalancerService":"Not_Valid"}`,
+			expectError: true,
+		},
+		{
+			description: "valid SSL cache size",
+			overrides:   `{"sslCacheSize":100000}`,
+			expectError: false,
+		},
+		{
+			description: "negative SSL cache size",
+			overrides:   `{"sslCacheSize":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid SSL max record size",
+			overrides:   `{"sslMaxRecordSize":1400}`,
+			expectError: false,
+		},
+		{
+			description: "SSL max record size too small",
+			overrides:   `{"sslMaxRecordSize":511}`,
+			expectError: true,
+		},
+		{
+			description: "SSL max record size too large",
+			overrides:   `{"sslMaxRecordSize":16385}`,
+			expectError: true,
+		},
+		{
+			description: "valid route quota",
+			name:        "default",
+			overrides:   `{"routeQuota":{"default":100,"namespaces":{"big-app":1000,"unlimited":0}}}`,
+			expectError: false,
+		},
+		{
+			description: "negative default route quota",
+			name:        "default",
+			overrides:   `{"routeQuota":{"default":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "negative namespace route quota",
+			name:        "default",
+			overrides:   `{"routeQuota":{"namespaces":{"app":-1}}}`,
+			expectError: true,
+		},
+		{
+			description: "route quota with invalid namespace",
+			name:        "default",
+			overrides:   `{"routeQuota":{"namespaces":{"App_1":10}}}`,
+			expectError: true,
+		},
+		{
+			description: "route quota with an ingresscontroller name that is too long for a label",
+			name:        strings.Repeat("a", 64),
+			overrides:   `{"routeQuota":{"default":100}}`,
+			expectError: true,
+		},
+		{
+			description: "valid HSTS policy",
+			overrides:   `{"hsts":{"maxAge":31536000,"includeSubDomains":true,"preload":true}}`,
+			expectError: false,
+		},
+		{
+			description: "HSTS policy with zero maxAge",
+			overrides:   `{"hsts":{"maxAge":0}}`,
+			expectError: false,
+		},
+		{
+			description: "HSTS policy with negative maxAge",
+			overrides:   `{"hsts":{"maxAge":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "source hash balancing by source IP",
+			overrides:   `{"sourceHashBalancing":{"hash":"SourceIP"}}`,
+			expectError: false,
+		},
+		{
+			description: "source hash balancing by header",
+			overrides:   `{"sourceHashBalancing":{"hash":"Header","headerName":"X-Session-Id"}}`,
+			expectError: false,
+		},
+		{
+			description: "source hash balancing by header without header name",
+			overrides:   `{"sourceHashBalancing":{"hash":"Header"}}`,
+			expectError: true,
+		},
+		{
+			description: "source hash balancing by header with invalid header name",
+			overrides:   `{"sourceHashBalancing":{"hash":"Header","headerName":"X Session"}}`,
+			expectError: true,
+		},
+		{
+			description: "source hash balancing by source IP with header name",
+			overrides:   `{"sourceHashBalancing":{"hash":"SourceIP","headerName":"X-Session-Id"}}`,
+			expectError: true,
+		},
+		{
+			description: "source hash balancing with load balancing algorithm",
+			overrides:   `{"loadBalancingAlgorithm":"leastconn","sourceHashBalancing":{"hash":"SourceIP"}}`,
+			expectError: true,
+		},
+		{
+			description: "source hash balancing with unknown hash",
+			overrides:   `{"sourceHashBalancing":{"hash":"Cookie"}}`,
+			expectError: true,
+		},
+		{
+			description: "valid status webhook",
+			overrides:   `{"statusWebhook":{"endpoints":["https://alerts.example.com/hook","http://10.0.0.1:8080"],"timeoutSeconds":10,"retries":0}}`,
+			expectError: false,
+		},
+		{
+			description: "status webhook without endpoints",
+			overrides:   `{"statusWebhook":{"endpoints":[]}}`,
+			expectError: true,
+		},
+		{
+			description: "status webhook with non-http endpoint",
+			overrides:   `{"statusWebhook":{"endpoints":["ftp://alerts.example.com/hook"]}}`,
+			expectError: true,
+		},
+		{
+			description: "status webhook with relative endpoint",
+			overrides:   `{"statusWebhook":{"endpoints":["/hook"]}}`,
+			expectError: true,
+		},
+		{
+			description: "status webhook with negative timeout",
+			overrides:   `{"statusWebhook":{"endpoints":["https://alerts.example.com/hook"],"timeoutSeconds":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "status webhook with negative retries",
+			overrides:   `{"statusWebhook":{"endpoints":["https://alerts.example.com/hook"],"retries":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "status webhook with too long a timeout",
+			overrides:   `{"statusWebhook":{"endpoints":["https://alerts.example.com/hook"],"timeoutSeconds":31}}`,
+			expectError: true,
+		},
+		{
+			description: "status webhook with too many retries",
+			overrides:   `{"statusWebhook":{"endpoints":["https://alerts.example.com/hook"],"retries":11}}`,
+			expectError: true,
+		},
+		{
+			description: "valid tracing endpoint and sampling ratio",
+			overrides:   `{"tracing":{"endpoint":"otel-collector.observability.svc:4317","samplingRatio":0.1}}`,
+			expectError: false,
+		},
+		{
+			description: "valid tracing IPv6 endpoint",
+			overrides:   `{"tracing":{"endpoint":"[fd00::5]:4317"}}`,
+			expectError: false,
+		},
+		{
+			description: "empty tracing endpoint",
+			overrides:   `{"tracing":{"samplingRatio":0.1}}`,
+			expectError: true,
+		},
+		{
+			description: "tracing endpoint without port",
+			overrides:   `{"tracing":{"endpoint":"otel-collector.observability.svc"}}`,
+			expectError: true,
+		},
+		{
+			description: "tracing endpoint with invalid port",
+			overrides:   `{"tracing":{"endpoint":"otel-collector:65536"}}`,
+			expectError: true,
+		},
+		{
+			description: "tracing endpoint with invalid host",
+			overrides:   `{"tracing":{"endpoint":"otel_collector:4317"}}`,
+			expectError: true,
+		},
+		{
+			description: "tracing endpoint URL",
+			overrides:   `{"tracing":{"endpoint":"http://otel-collector:4317"}}`,
+			expectError: true,
+		},
+		{
+			description: "negative tracing sampling ratio",
+			overrides:   `{"tracing":{"endpoint":"otel-collector:4317","samplingRatio":-0.1}}`,
+			expectError: true,
+		},
+		{
+			description: "tracing sampling ratio above 1",
+			overrides:   `{"tracing":{"endpoint":"otel-collector:4317","samplingRatio":1.5}}`,
+			expectError: true,
+		},
+		{
+			description: "negative HTTP/2 max concurrent streams",
+			overrides:   `{"http2MaxConcurrentStreams":-1}`,
+			expectError: true,
+		},
+		{
+			description: "server cipher preference",
+			overrides:   `{"cipherPreference":"Server"}`,
+			expectError: false,
+		},
+		{
+			description: "client cipher preference",
+			overrides:   `{"cipherPreference":"Client"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid cipher preference",
+			overrides:   `{"cipherPreference":"server"}`,
+			expectError: true,
+		},
+		{
+			description:  "Recreate deployment strategy for HostNetwork",
+			endpointType: operatorv1.HostNetworkStrategyType,
+			overrides:    `{"deploymentStrategy":"Recreate"}`,
+			expectError:  false,
+		},
+		{
+			description:  "Recreate deployment strategy for LoadBalancerService",
+			endpointType: operatorv1.LoadBalancerServiceStrategyType,
+			overrides:    `{"deploymentStrategy":"Recreate"}`,
+			expectError:  true,
+		},
+		{
+			description:  "Recreate deployment strategy with rolling update parameters",
+			endpointType: operatorv1.HostNetworkStrategyType,
+			overrides:    `{"deploymentStrategy":"Recreate","rollingUpdate":{"maxUnavailable":1}}`,
+			expectError:  true,
+		},
+		{
+			description: "preferred pod anti-affinity",
+			overrides:   `{"podAntiAffinity":"Preferred"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid pod anti-affinity",
+			overrides:   `{"podAntiAffinity":"Sometimes"}`,
+			expectError: true,
+		},
+		{
+			description: "affinity topology key",
+			overrides:   `{"affinityTopologyKey":"topology.example.com/rack"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid affinity topology key",
+			overrides:   `{"affinityTopologyKey":"rack/"}`,
+			expectError: true,
+		},
+		{
+			description: "RollingUpdate deployment strategy",
+			overrides:   `{"deploymentStrategy":"RollingUpdate"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid deployment strategy",
+			overrides:   `{"deploymentStrategy":"recreate"}`,
+			expectError: true,
+		},
+		{
+			description: "rolling update parameters",
+			overrides:   `{"rollingUpdate":{"maxUnavailable":2,"maxSurge":"10%"}}`,
+			expectError: false,
+		},
+		{
+			description: "zero maxUnavailable with default maxSurge",
+			overrides:   `{"rollingUpdate":{"maxUnavailable":0}}`,
+			expectError: false,
+		},
+		{
+			description:  "zero maxUnavailable with default maxSurge for HostNetwork",
+			endpointType: operatorv1.HostNetworkStrategyType,
+			overrides:    `{"rollingUpdate":{"maxUnavailable":"0%"}}`,
+			expectError:  true,
+		},
+		{
+			description:  "zero maxUnavailable with maxSurge for HostNetwork",
+			endpointType: operatorv1.HostNetworkStrategyType,
+			overrides:    `{"rollingUpdate":{"maxUnavailable":0,"maxSurge":1}}`,
+			expectError:  false,
+		},
+		{
+			description: "zero maxUnavailable and maxSurge",
+			overrides:   `{"rollingUpdate":{"maxUnavailable":0,"maxSurge":"0%"}}`,
+			expectError: true,
+		},
+		{
+			description: "negative maxUnavailable",
+			overrides:   `{"rollingUpdate":{"maxUnavailable":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "invalid maxSurge",
+			overrides:   `{"rollingUpdate":{"maxSurge":"ten"}}`,
+			expectError: true,
+		},
+		{
+			description: "rolling update with integer counts rounded up",
+			overrides:   `{"rollingUpdate":{"useIntegerCounts":true,"maxUnavailableRounding":"Ceil"}}`,
+			expectError: false,
+		},
+		{
+			description: "rolling update with invalid rounding",
+			overrides:   `{"rollingUpdate":{"useIntegerCounts":true,"maxUnavailableRounding":"Round"}}`,
+			expectError: true,
+		},
+		{
+			description: "rolling update with rounding but without integer counts",
+			overrides:   `{"rollingUpdate":{"maxUnavailableRounding":"Ceil"}}`,
+			expectError: true,
+		},
+		{
+			description: "minReadySeconds",
+			overrides:   `{"minReadySeconds":60}`,
+			expectError: false,
+		},
+		{
+			description: "negative minReadySeconds",
+			overrides:   `{"minReadySeconds":-1}`,
+			expectError: true,
+		},
+		{
+			description: "ALPN protocols",
+			overrides:   `{"alpnProtocols":["h2","http/1.1"]}`,
+			expectError: false,
+		},
+		{
+			description: "unknown ALPN protocol",
+			overrides:   `{"alpnProtocols":["h3"]}`,
+			expectError: true,
+		},
+		{
+			description: "duplicate ALPN protocol",
+			overrides:   `{"alpnProtocols":["http/1.1","http/1.1"]}`,
+			expectError: true,
+		},
+		{
+			description: "progressDeadlineSeconds",
+			overrides:   `{"progressDeadlineSeconds":300}`,
+			expectError: false,
+		},
+		{
+			description: "zero progressDeadlineSeconds",
+			overrides:   `{"progressDeadlineSeconds":0}`,
+			expectError: true,
+		},
+		{
+			description: "progressDeadlineSeconds not greater than default minReadySeconds",
+			overrides:   `{"progressDeadlineSeconds":30}`,
+			expectError: true,
+		},
+		{
+			description: "progressDeadlineSeconds not greater than minReadySeconds",
+			overrides:   `{"minReadySeconds":60,"progressDeadlineSeconds":60}`,
+			expectError: true,
+		},
+		{
+			description: "TCP keepalive",
+			overrides:   `{"tcpKeepalive":{"idleSeconds":60,"intervalSeconds":10,"count":3}}`,
+			expectError: false,
+		},
+		{
+			description: "TCP keepalive with defaults",
+			overrides:   `{"tcpKeepalive":{}}`,
+			expectError: false,
+		},
+		{
+			description: "negative TCP keepalive interval",
+			overrides:   `{"tcpKeepalive":{"intervalSeconds":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "load balancer zones",
+			overrides:   `{"loadBalancerZones":["az1","az2"]}`,
+			expectError: false,
+		},
+		{
+			description: "duplicate load balancer zones",
+			overrides:   `{"loadBalancerZones":["az1","az1"]}`,
+			expectError: true,
+		},
+		{
+			description: "empty load balancer zone",
+			overrides:   `{"loadBalancerZones":[""]}`,
+			expectError: true,
+		},
+		{
+			description: "canary router",
+			overrides:   `{"canaryRouter":{"image":"quay.io/openshift/router:canary","weight":10}}`,
+			expectError: false,
+		},
+		{
+			description: "canary router without image",
+			overrides:   `{"canaryRouter":{"weight":10}}`,
+			expectError: true,
+		},
+		{
+			description: "canary router weight above 100",
+			overrides:   `{"canaryRouter":{"image":"quay.io/openshift/router:canary","weight":101}}`,
+			expectError: true,
+		},
+		{
+			description: "close HTTP connection mode",
+			overrides:   `{"httpConnectionMode":"Close"}`,
+			expectError: false,
+		},
+		{
+			description: "server-close HTTP connection mode",
+			overrides:   `{"httpConnectionMode":"ServerClose"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid HTTP connection mode",
+			overrides:   `{"httpConnectionMode":"httpclose"}`,
+			expectError: true,
+		},
+		{
+			description: "gRPC mode",
+			overrides:   `{"grpcMode":true,"httpConnectionMode":"KeepAlive"}`,
+			expectError: false,
+		},
+		{
+			description: "gRPC mode with closed server connections",
+			overrides:   `{"grpcMode":true,"httpConnectionMode":"ServerClose"}`,
+			expectError: true,
+		},
+		{
+			description: "valid access log rate limit",
+			overrides:   `{"accessLogRateLimit":1000}`,
+			expectError: false,
+		},
+		{
+			description: "negative access log rate limit",
+			overrides:   `{"accessLogRateLimit":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid shared load balancer service",
+			overrides:   `{"sharedLoadBalancerService":"shared-lb"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid shared load balancer service name",
+			overrides:   `{"sharedLoadBalancerService":"Shared_LB"}`,
+			expectError: true,
+		},
+		{
+			description: "shared and existing load balancer services",
+			overrides:   `{"sharedLoadBalancerService":"shared-lb","existingLoadBalancerService":"preprovisioned-lb"}`,
+			expectError: true,
+		},
+		{
+			description: "CPU request and limit",
+			overrides:   `{"cpu":{"request":"500m","limit":"2"}}`,
+			expectError: false,
+		},
+		{
+			description: "CPU limit less than request",
+			overrides:   `{"cpu":{"request":"2","limit":"1"}}`,
+			expectError: true,
+		},
+		{
+			description: "zero CPU limit",
+			overrides:   `{"cpu":{"limit":"0"}}`,
+			expectError: true,
+		},
+		{
+			description: "ephemeral storage limit greater than request",
+			overrides:   `{"ephemeralStorage":{"request":"1Gi","limit":"2Gi"}}`,
+			expectError: false,
+		},
+		{
+			description: "ephemeral storage limit equal to request",
+			overrides:   `{"ephemeralStorage":{"request":"1Gi","limit":"1024Mi"}}`,
+			expectError: false,
+		},
+		{
+			description: "ephemeral storage limit less than request",
+			overrides:   `{"ephemeralStorage":{"request":"2Gi","limit":"1Gi"}}`,
+			expectError: true,
+		},
+		{
+			description: "negative ephemeral storage request",
+			overrides:   `{"ephemeralStorage":{"request":"-1Gi"}}`,
+			expectError: true,
+		},
+		{
+			description: "invalid ephemeral storage quantity",
+			overrides:   `{"ephemeralStorage":{"limit":"lots"}}`,
+			expectError: true,
+		},
+		{
+			description: "valid default route request rate limit",
+			overrides:   `{"defaultRouteRequestRateLimit":100}`,
+			expectError: false,
+		},
+		{
+			description: "negative default route request rate limit",
+			overrides:   `{"defaultRouteRequestRateLimit":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid maintenance page configmap",
+			overrides:   `{"maintenancePageConfigMap":"maintenance"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid maintenance page configmap",
+			overrides:   `{"maintenancePageConfigMap":"Maintenance_Page"}`,
+			expectError: true,
+		},
+		{
+			description: "valid backend pool max connections",
+			overrides:   `{"backendPoolMaxConnections":64}`,
+			expectError: false,
+		},
+		{
+			description: "negative backend pool max connections",
+			overrides:   `{"backendPoolMaxConnections":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid server max connections",
+			overrides:   `{"serverMaxConnections":100}`,
+			expectError: false,
+		},
+		{
+			description: "negative server max connections",
+			overrides:   `{"serverMaxConnections":-1}`,
+			expectError: true,
+		},
+		{
+			description: "fail-closed certificate failure policy",
+			overrides:   `{"certificateFailurePolicy":"FailClosed"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid certificate failure policy",
+			overrides:   `{"certificateFailurePolicy":"Reject"}`,
+			expectError: true,
+		},
+		{
+			description: "strict request smuggling protection",
+			overrides:   `{"requestSmugglingProtection":"Strict"}`,
+			expectError: false,
+		},
+		{
+			description: "lenient request smuggling protection",
+			overrides:   `{"requestSmugglingProtection":"Lenient"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid request smuggling protection",
+			overrides:   `{"requestSmugglingProtection":"strict"}`,
+			expectError: true,
+		},
+		{
+			description: "TCP syslog protocol",
+			overrides:   `{"syslogProtocol":"TCP"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid syslog protocol",
+			overrides:   `{"syslogProtocol":"TLS"}`,
+			expectError: true,
+		},
+		{
+			description: "valid additional wildcard domains",
+			overrides:   `{"additionalWildcardDomains":["internal.example.com","partners.example.com"]}`,
+			expectError: false,
+		},
+		{
+			description: "duplicate additional wildcard domains",
+			overrides:   `{"additionalWildcardDomains":["internal.example.com","Internal.example.com"]}`,
+			expectError: true,
+		},
+		{
+			description: "invalid additional wildcard domain",
+			overrides:   `{"additionalWildcardDomains":["*.internal.example.com"]}`,
+			expectError: true,
+		},
+		{
+			description: "valid backlog",
+			overrides:   `{"backlog":4096}`,
+			expectError: false,
+		},
+		{
+			description: "negative backlog",
+			overrides:   `{"backlog":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid dns record deletion grace period",
+			overrides:   `{"dnsRecordDeletionGracePeriodSeconds":300}`,
+			expectError: false,
+		},
+		{
+			description: "negative dns record deletion grace period",
+			overrides:   `{"dnsRecordDeletionGracePeriodSeconds":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid fsGroup",
+			overrides:   `{"fsGroup":1000}`,
+			expectError: false,
+		},
+		{
+			description: "negative fsGroup",
+			overrides:   `{"fsGroup":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid default host header",
+			overrides:   `{"defaultHostHeader":"backend.example.svc"}`,
+			expectError: false,
+		},
+		{
+			description: "default host header with uppercase characters",
+			overrides:   `{"defaultHostHeader":"Backend.example.svc"}`,
+			expectError: true,
+		},
+		{
+			description: "default host header with a port",
+			overrides:   `{"defaultHostHeader":"backend.example.svc:8080"}`,
+			expectError: true,
+		},
+		{
+			description: "valid stats timeout",
+			overrides:   `{"statsTimeoutSeconds":30}`,
+			expectError: false,
+		},
+		{
+			description: "negative stats timeout",
+			overrides:   `{"statsTimeoutSeconds":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid default certificate issuer secret",
+			overrides:   `{"defaultCertificateIssuerSecret":"ingress-intermediate-ca"}`,
+			expectError: false,
+		},
+		{
+			description: "invalid default certificate issuer secret",
+			overrides:   `{"defaultCertificateIssuerSecret":"Ingress_CA"}`,
+			expectError: true,
+		},
+		{
+			description: "valid max SSL handshake rate",
+			overrides:   `{"maxSSLHandshakeRate":500}`,
+			expectError: false,
+		},
+		{
+			description: "negative max SSL handshake rate",
+			overrides:   `{"maxSSLHandshakeRate":-1}`,
+			expectError: true,
+		},
+		{
+			description: "valid router namespace egress IPs",
+			name:        "default",
+			overrides:   `{"routerNamespaceEgressIPs":["192.0.2.10","2001:db8::10"]}`,
+			expectError: false,
+		},
+		{
+			description: "router namespace egress IPs on a non-default ingresscontroller",
+			name:        "sharded",
+			overrides:   `{"routerNamespaceEgressIPs":["192.0.2.10"]}`,
+			expectError: true,
+		},
+		{
+			description: "invalid router namespace egress IP",
+			name:        "default",
+			overrides:   `{"routerNamespaceEgressIPs":["192.0.2.300"]}`,
+			expectError: true,
+		},
+		{
+			description: "duplicate router namespace egress IPs",
+			name:        "default",
+			overrides:   `{"routerNamespaceEgressIPs":["2001:db8::10","2001:DB8:0::10"]}`,
+			expectError: true,
+		},
+		{
+			description:     "valid compression level",
+			httpCompression: operatorv1.HTTPCompressionPolicy{MimeTypes: []operatorv1.CompressionMIMEType{"text/html"}},
+			overrides:       `{"compressionLevel":9}`,
+			expectError:     false,
+		},
+		{
+			description:     "compression level too high",
+			httpCompression: operatorv1.HTTPCompressionPolicy{MimeTypes: []operatorv1.CompressionMIMEType{"text/html"}},
+			overrides:       `{"compressionLevel":10}`,
+			expectError:     true,
+		},
+		{
+			description:     "negative compression level",
+			httpCompression: operatorv1.HTTPCompressionPolicy{MimeTypes: []operatorv1.CompressionMIMEType{"text/html"}},
+			overrides:       `{"compressionLevel":-1}`,
+			expectError:     true,
+		},
+		{
+			description: "compression level without compression",
+			overrides:   `{"compressionLevel":5}`,
+			expectError: true,
+		},
+		{
+			description: "valid read methods",
+			overrides:   `{"readMethods":["GET","HEAD","OPTIONS"]}`,
+			expectError: false,
+		},
+		{
+			description: "unknown read method",
+			overrides:   `{"readMethods":["GET","FETCH"]}`,
+			expectError: true,
+		},
+		{
+			description: "lowercase read method",
+			overrides:   `{"readMethods":["get"]}`,
+			expectError: true,
+		},
+		{
+			description: "duplicate read methods",
+			overrides:   `{"readMethods":["GET","GET"]}`,
+			expectError: true,
+		},
+		{
+			description: "valid alerting rules",
+			name:        "default",
+			overrides:   `{"alertingRules":{"degradedForSeconds":600,"certificateExpiryDays":30,"dnsFailureThreshold":5}}`,
+			expectError: false,
+		},
+		{
+			description: "alerting rules on a non-default ingresscontroller",
+			name:        "sharded",
+			overrides:   `{"alertingRules":{}}`,
+			expectError: true,
+		},
+		{
+			description: "negative alerting rules threshold",
+			name:        "default",
+			overrides:   `{"alertingRules":{"certificateExpiryDays":-1}}`,
+			expectError: true,
+		},
+		{
+			description: "trust forwarded proto with default forwarded header policy",
+			overrides:   `{"trustForwardedProto":true}`,
+			expectError: false,
+		},
+		{
+			description: "trust forwarded proto with if-none forwarded header policy",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: operatorv1.IfNoneHTTPHeaderPolicy},
+			overrides:   `{"trustForwardedProto":true}`,
+			expectError: false,
+		},
+		{
+			description: "trust forwarded proto with replace forwarded header policy",
+			httpHeaders: &operatorv1.IngressControllerHTTPHeaders{ForwardedHeaderPolicy: operatorv1.ReplaceHTTPHeaderPolicy},
+			overrides:   `{"trustForwardedProto":true}`,
+			expectError: true,
+		},
+		{
+			description: "valid max header count",
+			overrides:   `{"maxHeaderCount":200}`,
+			expectError: false,
+		},
+		{
+			description: "zero max header count uses the default",
+			overrides:   `{"maxHeaderCount":0}`,
+			expectError: false,
+		},
+		{
+			description: "negative max header count",
+			overrides:   `{"maxHeaderCount":-1}`,
+			expectError: true,
+		},
+		{
+			description: "max header count too large",
+			overrides:   `{"maxHeaderCount":32768}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: tc.name},
+			Spec: operatorv1.IngressControllerSpec{
+				ClientTLS:                  tc.clientTLS,
+				HTTPCompression:            tc.httpCompression,
+				HTTPHeaders:                tc.httpHeaders,
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+			},
+		}
+		if len(tc.endpointType) != 0 {
+			ic.Status.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: tc.endpointType}
+		}
+		switch err := validateUnsupportedConfigOverrides(ic); {
+		case err == nil && tc.expectError:
+			t.Errorf("%s: expected error, got nil", tc.description)
+		case err != nil && !tc.expectError:
+			t.Errorf("%s: expected success, got error: %v", tc.description, err)
+		}
+	}
+}
+
+// TestValidateHTTP2MaxConcurrentStreams verifies that
+// validateHTTP2MaxConcurrentStreams rejects the http2MaxConcurrentStreams
+// unsupported config override unless HTTP/2 is enabled.
+func TestValidateHTTP2MaxConcurrentStreams(t *testing.T) {
+	testCases := []struct {
+		description       string
+		icAnnotations     map[string]string
+		configAnnotations map[string]string
+		overrides         string
+		expectError       bool
+	}{
+		{
+			description: "no override, HTTP/2 disabled",
+			overrides:   "",
+			expectError: false,
+		},
+		{
+			description: "override, HTTP/2 disabled",
+			overrides:   `{"http2MaxConcurrentStreams":200}`,
+			expectError: true,
+		},
+		{
+			description:   "override, HTTP/2 enabled on the ingresscontroller",
+			icAnnotations: map[string]string{RouterDefaultEnableHTTP2Annotation: "true"},
+			overrides:     `{"http2MaxConcurrentStreams":200}`,
+			expectError:   false,
+		},
+		{
+			description:       "override, HTTP/2 enabled on the ingress config",
+			configAnnotations: map[string]string{RouterDefaultEnableHTTP2Annotation: "true"},
+			overrides:         `{"http2MaxConcurrentStreams":200}`,
+			expectError:       false,
+		},
+		{
+			description:       "override, HTTP/2 disabled on the ingresscontroller",
+			icAnnotations:     map[string]string{RouterDefaultEnableHTTP2Annotation: "false"},
+			configAnnotations: map[string]string{RouterDefaultEnableHTTP2Annotation: "true"},
+			overrides:         `{"http2MaxConcurrentStreams":200}`,
+			expectError:       true,
+		},
+		{
+			description: "override, HTTP/2 enabled using the http2 override",
+			overrides:   `{"http2":true,"http2MaxConcurrentStreams":200}`,
+			expectError: false,
+		},
+		{
+			description:   "override, HTTP/2 disabled using the http2 override",
+			icAnnotations: map[string]string{RouterDefaultEnableHTTP2Annotation: "true"},
+			overrides:     `{"http2":false,"http2MaxConcurrentStreams":200}`,
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.icAnnotations},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+			},
+		}
+		ingressConfig := &configv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.configAnnotations},
+		}
+		switch err := validateHTTP2MaxConcurrentStreams(ic, ingressConfig); {
+		case tc.expectError && err == nil:
+			t.Errorf("%s: expected error, got nil", tc.description)
+		case !tc.expectError && err != nil:
+			t.Errorf("%s: expected success, got error: %v", tc.description, err)
+		}
+	}
+}
+
+// TestValidateALPNProtocols verifies that validateALPNProtocols rejects an
+// alpnProtocols unsupported config override that is inconsistent with whether
+// HTTP/2 is enabled.
+func TestValidateALPNProtocols(t *testing.T) {
+	testCases := []struct {
+		description  string
+		http2Enabled bool
+		overrides    string
+		expectError  bool
+	}{
+		{
+			description:  "no override, HTTP/2 enabled",
+			http2Enabled: true,
+			overrides:    "",
+			expectError:  false,
+		},
+		{
+			description:  "h2, HTTP/2 enabled",
+			http2Enabled: true,
+			overrides:    `{"alpnProtocols":["h2","http/1.1"]}`,
+			expectError:  false,
+		},
+		{
+			description:  "h2, HTTP/2 disabled",
+			http2Enabled: false,
+			overrides:    `{"alpnProtocols":["h2","http/1.1"]}`,
+			expectError:  true,
+		},
+		{
+			description:  "no h2, HTTP/2 enabled",
+			http2Enabled: true,
+			overrides:    `{"alpnProtocols":["http/1.1"]}`,
+			expectError:  true,
+		},
+		{
+			description:  "no h2, HTTP/2 disabled",
+			http2Enabled: false,
+			overrides:    `{"alpnProtocols":["http/1.1","http/1.0"]}`,
+			expectError:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{RouterDefaultEnableHTTP2Annotation: strconv.FormatBool(tc.http2Enabled)},
+			},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+			},
+		}
+		switch err := validateALPNProtocols(ic, &configv1.Ingress{}); {
+		case tc.expectError && err == nil:
+			t.Errorf("%s: expected error, got nil", tc.description)
+		case !tc.expectError && err != nil:
+			t.Errorf("%s: expected success, got error: %v", tc.description, err)
+		}
+	}
+}
+
+// TestValidateGRPCMode verifies that validateGRPCMode rejects the grpcMode
+// unsupported config override unless HTTP/2 is enabled.
+func TestValidateGRPCMode(t *testing.T) {
+	testCases := []struct {
+		description   string
+		icAnnotations map[string]string
+		overrides     string
+		expectError   bool
+	}{
+		{
+			description: "gRPC mode disabled, HTTP/2 disabled",
+			overrides:   `{"grpcMode":false}`,
+			expectError: false,
+		},
+		{
+			description: "gRPC mode, HTTP/2 disabled",
+			overrides:   `{"grpcMode":true}`,
+			expectError: true,
+		},
+		{
+			description:   "gRPC mode, HTTP/2 enabled",
+			icAnnotations: map[string]string{RouterDefaultEnableHTTP2Annotation: "true"},
+			overrides:     `{"grpcMode":true}`,
+			expectError:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.icAnnotations},
+			Spec: operatorv1.IngressControllerSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.overrides)},
+			},
+		}
+		switch err := validateGRPCMode(ic, &configv1.Ingress{}); {
+		case tc.expectError && err == nil:
+			t.Errorf("%s: expected error, got nil", tc.description)
+		case !tc.expectError && err != nil:
+			t.Errorf("%s: expected success, got error: %v", tc.description, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"strings"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 
@@ -29,6 +30,22 @@ const (
 	// of the same generation of the same ingress controller.
 	ControllerDeploymentHashLabel = "ingresscontroller.operator.openshift.io/hash"
 
+	// CanaryRouterLabel identifies the pods of an ingress controller's
+	// canary router deployment, which runs a candidate router image
+	// alongside the stable router deployment.
+	CanaryRouterLabel = "ingresscontroller.operator.openshift.io/canary-router"
+
+	// SharedLoadBalancerServiceLabel identifies a load balancer service as
+	// shared by multiple ingress controllers, and identifies the router
+	// pods of the ingress controllers that share it.  The value is the
+	// name of the shared service.
+	SharedLoadBalancerServiceLabel = "ingresscontroller.operator.openshift.io/shared-load-balancer-service"
+
+	// WildcardCertificateLabel identifies a secret as an operator-generated
+	// wildcard certificate for one of an ingress controller's additional
+	// wildcard domains.  The value is the name of the ingress controller.
+	WildcardCertificateLabel = "ingresscontroller.operator.openshift.io/wildcard-certificate"
+
 	// CanaryDaemonsetLabel identifies a daemonset as an ingress canary daemonset, and
 	// the value is the name of the owning canary controller.
 	CanaryDaemonSetLabel = "ingresscanary.operator.openshift.io/daemonset-ingresscanary"
@@ -74,6 +91,15 @@ func RouterDeploymentName(ci *operatorv1.IngressController) types.NamespacedName
 	}
 }
 
+// CanaryRouterDeploymentName returns the namespaced name for the canary router
+// deployment.
+func CanaryRouterDeploymentName(ci *operatorv1.IngressController) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: DefaultOperandNamespace,
+		Name:      "router-" + ci.Name + "-canary",
+	}
+}
+
 // RouterCASecretName returns the namespaced name for the router CA secret.
 // This secret holds the CA certificate that the operator will use to create
 // default certificates for ingresscontrollers.
@@ -114,6 +140,16 @@ func RouterOperatorGeneratedDefaultCertificateSecretName(ci *operatorv1.IngressC
 	}
 }
 
+// RouterWildcardCertificateSecretName returns the namespaced name for the
+// operator-generated wildcard certificate secret for the given additional
+// wildcard domain of the given ingress controller.
+func RouterWildcardCertificateSecretName(ci *operatorv1.IngressController, namespace, domain string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      fmt.Sprintf("router-certs-%s-%s", ci.Name, strings.ToLower(domain)),
+	}
+}
+
 // ClientCAConfigMapName returns the namespaced name for the operator-managed
 // client CA configmap, which is a copy of the user-managed configmap from the
 // openshift-config namespace.
@@ -192,6 +228,12 @@ func InternalIngressControllerServiceName(ic *operatorv1.IngressController) type
 	return types.NamespacedName{Namespace: DefaultOperandNamespace, Name: "router-internal-" + ic.Name}
 }
 
+// HeadlessIngressControllerServiceName returns the name of the headless
+// service for the ingresscontroller's router pods.
+func HeadlessIngressControllerServiceName(ic *operatorv1.IngressController) types.NamespacedName {
+	return types.NamespacedName{Namespace: DefaultOperandNamespace, Name: "router-headless-" + ic.Name}
+}
+
 func IngressControllerServiceMonitorName(ic *operatorv1.IngressController) types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: DefaultOperandNamespace,
@@ -199,6 +241,16 @@ func IngressControllerServiceMonitorName(ic *operatorv1.IngressController) types
 	}
 }
 
+// IngressControllerPrometheusRuleName returns the namespaced name of the
+// prometheusrule with the alerts that the operator generates, in the given
+// operator namespace.
+func IngressControllerPrometheusRuleName(operatorNamespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: operatorNamespace,
+		Name:      "ingress-operator-generated",
+	}
+}
+
 func LoadBalancerServiceName(ic *operatorv1.IngressController) types.NamespacedName {
 	return types.NamespacedName{Namespace: DefaultOperandNamespace, Name: "router-" + ic.Name}
 }
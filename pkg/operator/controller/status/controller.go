@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilclock "k8s.io/apimachinery/pkg/util/clock"
 
@@ -103,6 +104,10 @@ type Config struct {
 	CanaryImage            string
 	OperatorReleaseVersion string
 	Namespace              string
+	// StatusSummary indicates whether the controller publishes a summary
+	// of all ingresscontrollers' status in the clusteroperator's
+	// status.extension field.
+	StatusSummary bool
 }
 
 // reconciler handles the actual status reconciliation logic in response to
@@ -191,6 +196,15 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		computeOperatorUpgradeableCondition(state.IngressControllers),
 	)
 
+	co.Status.Extension = runtime.RawExtension{}
+	if r.config.StatusSummary {
+		extension, err := computeIngressControllerStatusSummary(state.IngressControllers)
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to compute ingresscontroller status summary: %v", err)
+		}
+		co.Status.Extension = extension
+	}
+
 	if !operatorStatusesEqual(*oldStatus, co.Status) {
 		if err := r.client.Status().Update(ctx, co); err != nil {
 			return reconcile.Result{}, fmt.Errorf("failed to update clusteroperator %s: %v", co.Name, err)
@@ -578,5 +592,9 @@ func operatorStatusesEqual(a, b configv1.ClusterOperatorStatus) bool {
 		return false
 	}
 
+	if !extensionsEqual(a.Extension, b.Extension) {
+		return false
+	}
+
 	return true
 }
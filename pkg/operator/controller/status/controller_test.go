@@ -10,6 +10,7 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func TestComputeOperatorProgressingCondition(t *testing.T) {
@@ -390,6 +391,33 @@ func TestOperatorStatusesEqual(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "reformatted extension should be equal",
+			expected:    true,
+			a: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{"ingressControllers":[{"name":"default","availableReplicas":2}]}`)},
+			},
+			b: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{ "ingressControllers": [ { "availableReplicas": 2, "name": "default" } ] }`)},
+			},
+		},
+		{
+			description: "changed extension should not be equal",
+			expected:    false,
+			a: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{"ingressControllers":[{"name":"default","availableReplicas":2}]}`)},
+			},
+			b: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{"ingressControllers":[{"name":"default","availableReplicas":1}]}`)},
+			},
+		},
+		{
+			description: "removed extension should not be equal",
+			expected:    false,
+			a: configv1.ClusterOperatorStatus{
+				Extension: runtime.RawExtension{Raw: []byte(`{"ingressControllers":[]}`)},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -590,3 +618,73 @@ func TestComputeOperatorUpgradeableCondition(t *testing.T) {
 		}
 	}
 }
+
+// TestComputeIngressControllerStatusSummary verifies that
+// computeIngressControllerStatusSummary summarizes each ingresscontroller's
+// key status, sorted by name, and that the summary reflects ingresscontrollers
+// being added and removed.
+func TestComputeIngressControllerStatusSummary(t *testing.T) {
+	ic := func(name, domain string, replicas int32, available, degraded operatorv1.ConditionStatus) operatorv1.IngressController {
+		return operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: operatorv1.IngressControllerStatus{
+				AvailableReplicas: replicas,
+				Domain:            domain,
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+				Conditions: []operatorv1.OperatorCondition{
+					{Type: operatorv1.OperatorStatusTypeAvailable, Status: available},
+					{Type: operatorv1.OperatorStatusTypeDegraded, Status: degraded},
+				},
+			},
+		}
+	}
+	defaultIC := ic("default", "apps.example.com", 2, operatorv1.ConditionTrue, operatorv1.ConditionFalse)
+	shardIC := ic("shard", "shard.example.com", 0, operatorv1.ConditionFalse, operatorv1.ConditionTrue)
+	const (
+		defaultSummary = `{"name":"default","domain":"apps.example.com","endpointPublishingStrategy":"LoadBalancerService","availableReplicas":2,"available":"True","degraded":"False"}`
+		shardSummary   = `{"name":"shard","domain":"shard.example.com","endpointPublishingStrategy":"LoadBalancerService","availableReplicas":0,"available":"False","degraded":"True"}`
+	)
+	testCases := []struct {
+		description string
+		ingresses   []operatorv1.IngressController
+		expect      string
+	}{
+		{
+			description: "no ingresscontrollers",
+			expect:      `{"ingressControllers":[]}`,
+		},
+		{
+			description: "one ingresscontroller",
+			ingresses:   []operatorv1.IngressController{defaultIC},
+			expect:      `{"ingressControllers":[` + defaultSummary + `]}`,
+		},
+		{
+			description: "ingresscontroller added",
+			ingresses:   []operatorv1.IngressController{shardIC, defaultIC},
+			expect:      `{"ingressControllers":[` + defaultSummary + `,` + shardSummary + `]}`,
+		},
+		{
+			description: "ingresscontroller removed",
+			ingresses:   []operatorv1.IngressController{shardIC},
+			expect:      `{"ingressControllers":[` + shardSummary + `]}`,
+		},
+	}
+	var previous runtime.RawExtension
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := computeIngressControllerStatusSummary(tc.ingresses)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual.Raw) != tc.expect {
+				t.Errorf("expected %s, got %s", tc.expect, string(actual.Raw))
+			}
+			if previous.Raw != nil && operatorStatusesEqual(configv1.ClusterOperatorStatus{Extension: previous}, configv1.ClusterOperatorStatus{Extension: actual}) {
+				t.Errorf("expected the clusteroperator status to change from %s to %s", string(previous.Raw), string(actual.Raw))
+			}
+			previous = actual
+		})
+	}
+}
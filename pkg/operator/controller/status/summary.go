@@ -0,0 +1,86 @@
+package status
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ingressControllerStatusSummary is the summary of all ingresscontrollers'
+// status that the status controller publishes in the ingress clusteroperator's
+// status.extension field.
+type ingressControllerStatusSummary struct {
+	// IngressControllers has the status of each ingresscontroller, sorted
+	// by name.
+	IngressControllers []ingressControllerSummary `json:"ingressControllers"`
+}
+
+// ingressControllerSummary is the key status of a single ingresscontroller.
+type ingressControllerSummary struct {
+	Name                       string                                    `json:"name"`
+	Domain                     string                                    `json:"domain,omitempty"`
+	EndpointPublishingStrategy operatorv1.EndpointPublishingStrategyType `json:"endpointPublishingStrategy,omitempty"`
+	AvailableReplicas          int32                                     `json:"availableReplicas"`
+	Available                  operatorv1.ConditionStatus                `json:"available,omitempty"`
+	Progressing                operatorv1.ConditionStatus                `json:"progressing,omitempty"`
+	Degraded                   operatorv1.ConditionStatus                `json:"degraded,omitempty"`
+}
+
+// computeIngressControllerStatusSummary returns a summary of the given
+// ingresscontrollers' status for the clusteroperator's status.extension field.
+func computeIngressControllerStatusSummary(ingresses []operatorv1.IngressController) (runtime.RawExtension, error) {
+	summary := ingressControllerStatusSummary{
+		IngressControllers: make([]ingressControllerSummary, 0, len(ingresses)),
+	}
+	for i := range ingresses {
+		ic := &ingresses[i]
+		s := ingressControllerSummary{
+			Name:              ic.Name,
+			Domain:            ic.Status.Domain,
+			AvailableReplicas: ic.Status.AvailableReplicas,
+		}
+		if ic.Status.EndpointPublishingStrategy != nil {
+			s.EndpointPublishingStrategy = ic.Status.EndpointPublishingStrategy.Type
+		}
+		for _, cond := range ic.Status.Conditions {
+			switch cond.Type {
+			case operatorv1.OperatorStatusTypeAvailable:
+				s.Available = cond.Status
+			case operatorv1.OperatorStatusTypeProgressing:
+				s.Progressing = cond.Status
+			case operatorv1.OperatorStatusTypeDegraded:
+				s.Degraded = cond.Status
+			}
+		}
+		summary.IngressControllers = append(summary.IngressControllers, s)
+	}
+	sort.Slice(summary.IngressControllers, func(i, j int) bool {
+		return summary.IngressControllers[i].Name < summary.IngressControllers[j].Name
+	})
+	raw, err := json.Marshal(summary)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+// extensionsEqual returns true if the given extensions have equivalent JSON
+// values.  The API may reformat the extension, so a byte-wise comparison could
+// report spurious differences.
+func extensionsEqual(a, b runtime.RawExtension) bool {
+	if len(a.Raw) == 0 || len(b.Raw) == 0 {
+		return len(a.Raw) == len(b.Raw)
+	}
+	var av, bv interface{}
+	if err := json.Unmarshal(a.Raw, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b.Raw, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/client-go/util/retry"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpdateWithRetryOnConflict updates an object that the operator manages.  The
+// given update function computes the updated object from current, which must
+// be the latest version of the object that the caller has read, and returns
+// nil if no update is needed.  The updated object carries current's resource
+// version, so the API rejects the update with a conflict if another writer,
+// such as another replica of the operator, has modified the object since it
+// was read.  In that case, the latest version of the object is read into
+// current, and update is called again, subject to the default retry backoff.
+// Returns a Boolean value indicating whether the object was updated, and an
+// error value.
+func UpdateWithRetryOnConflict(c client.Client, current client.Object, update func() client.Object) (bool, error) {
+	updated, refetch := false, false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if refetch {
+			if err := c.Get(context.TODO(), client.ObjectKeyFromObject(current), current); err != nil {
+				return err
+			}
+		}
+		refetch = true
+		obj := update()
+		if obj == nil {
+			updated = false
+			return nil
+		}
+		if err := c.Update(context.TODO(), obj); err != nil {
+			return err
+		}
+		updated = true
+		return nil
+	})
+	return updated, err
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// concurrentWriterClient is a client that simulates another writer by
+// modifying the object before each of the first few updates, which causes
+// those updates to conflict.
+type concurrentWriterClient struct {
+	client.Client
+
+	// conflicts is the number of updates that should conflict.
+	conflicts int
+	// updates counts calls to Update.
+	updates int
+}
+
+func (c *concurrentWriterClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	if c.conflicts > 0 {
+		c.conflicts--
+		other := &corev1.ConfigMap{}
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), other); err != nil {
+			return err
+		}
+		if other.Labels == nil {
+			other.Labels = map[string]string{}
+		}
+		other.Labels["writer"] = "other"
+		if err := c.Client.Update(ctx, other); err != nil {
+			return err
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// TestUpdateWithRetryOnConflict verifies that UpdateWithRetryOnConflict
+// retries an update that conflicts with another writer's using the latest
+// version of the object, preserving the other writer's change.
+func TestUpdateWithRetryOnConflict(t *testing.T) {
+	testCases := []struct {
+		name          string
+		conflicts     int
+		expectUpdated bool
+		expectUpdates int
+		expectError   bool
+	}{
+		{
+			name:          "no conflict",
+			conflicts:     0,
+			expectUpdated: true,
+			expectUpdates: 1,
+		},
+		{
+			name:          "one conflict",
+			conflicts:     1,
+			expectUpdated: true,
+			expectUpdates: 2,
+		},
+		{
+			name:          "persistent conflicts",
+			conflicts:     100,
+			expectUpdated: false,
+			expectError:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "test"},
+				Data:       map[string]string{"key": "old"},
+			}
+			c := &concurrentWriterClient{
+				Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build(),
+				conflicts: tc.conflicts,
+			}
+			current := &corev1.ConfigMap{}
+			if err := c.Get(context.TODO(), client.ObjectKeyFromObject(cm), current); err != nil {
+				t.Fatal(err)
+			}
+			updated, err := UpdateWithRetryOnConflict(c, current, func() client.Object {
+				if current.Data["key"] == "new" {
+					return nil
+				}
+				updated := current.DeepCopy()
+				updated.Data["key"] = "new"
+				return updated
+			})
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectError, err)
+			}
+			if updated != tc.expectUpdated {
+				t.Errorf("expected updated %t, got %t", tc.expectUpdated, updated)
+			}
+			if tc.expectError {
+				return
+			}
+			if c.updates != tc.expectUpdates {
+				t.Errorf("expected %d updates, got %d", tc.expectUpdates, c.updates)
+			}
+			actual := &corev1.ConfigMap{}
+			if err := c.Get(context.TODO(), client.ObjectKeyFromObject(cm), actual); err != nil {
+				t.Fatal(err)
+			}
+			if actual.Data["key"] != "new" {
+				t.Errorf("expected the update to be applied, got data %v", actual.Data)
+			}
+			if tc.conflicts > 0 && actual.Labels["writer"] != "other" {
+				t.Errorf("expected the other writer's change to be preserved, got labels %v", actual.Labels)
+			}
+		})
+	}
+
+	t.Run("no update needed", func(t *testing.T) {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "test"}}
+		c := &concurrentWriterClient{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build()}
+		updated, err := UpdateWithRetryOnConflict(c, cm, func() client.Object { return nil })
+		if err != nil || updated || c.updates != 0 {
+			t.Errorf("expected no update, got updated %t, %d updates, error %v", updated, c.updates, err)
+		}
+	})
+}
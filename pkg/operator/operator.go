@@ -100,6 +100,9 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 	if _, err := ingresscontroller.New(mgr, ingresscontroller.Config{
 		Namespace:              config.Namespace,
 		IngressControllerImage: config.IngressControllerImage,
+		FieldManager:           config.FieldManager,
+		MaxReconcileBackoff:    config.MaxReconcileBackoff,
+		Finalizer:              config.IngressControllerFinalizer,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create ingress controller: %v", err)
 	}
@@ -144,12 +147,13 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 		IngressControllerImage: config.IngressControllerImage,
 		CanaryImage:            config.CanaryImage,
 		OperatorReleaseVersion: config.OperatorReleaseVersion,
+		StatusSummary:          config.IngressControllerStatusSummary,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create status controller: %v", err)
 	}
 
 	// Set up the certificate controller
-	if _, err := certcontroller.New(mgr, config.Namespace); err != nil {
+	if _, err := certcontroller.New(mgr, config.Namespace, config.CertificateGenerationConcurrency); err != nil {
 		return nil, fmt.Errorf("failed to create cacert controller: %v", err)
 	}
 
@@ -185,6 +189,8 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 	if _, err := dnscontroller.New(mgr, dnscontroller.Config{
 		Namespace:              config.Namespace,
 		OperatorReleaseVersion: config.OperatorReleaseVersion,
+		ZoneWriteRate:          config.DNSZoneWriteRate,
+		ZoneWriteBurst:         config.DNSZoneWriteBurst,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create dns controller: %v", err)
 	}